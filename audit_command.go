@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+)
+
+// auditCommand is a function responsible for the audit subcommands
+// audit verify checks that no record of the audit log was changed, removed or inserted,
+// audit -dir compares a local directory to the inventory of the cluster
+func auditCommand(args []string) error {
+	if len(args) > 0 && args[0] == "verify" {
+		return auditVerifyCommand(args[1:])
+	}
+	return auditDirCommand(args)
+}
+
+// auditVerifyCommand is a function responsible for verifying the hash chain of the audit log
+func auditVerifyCommand(args []string) error {
+	flags := flag.NewFlagSet("audit verify", flag.ExitOnError)
+	flags.Usage = func() {
+		log.Println("Usage: audit verify")
+	}
+	parseFlags(flags, args)
+
+	count, err := viderasdk.SDKInstance().VerifyAuditLog()
+	if err != nil {
+		log.Println(fmt.Sprintf("First %v records are intact", count))
+		return err
+	}
+
+	log.Println(fmt.Sprintf("Audit log is intact, %v records", count))
+	return nil
+}
+
+// auditDirCommand is a function responsible for reporting the files of a directory missing from, extra in
+// or differing from the cluster without transferring payload data, inconsistencies are reported as an error
+func auditDirCommand(args []string) error {
+	flags := flag.NewFlagSet("audit", flag.ExitOnError)
+	dir := flags.String("dir", "", "Local directory whose files are compared to the cluster")
+	project := flags.String("project", "", "Only compare to the assets of this project")
+	assetType := flags.String("type", "", "Only compare to assets of this type, model or video")
+	output := flags.String("output", "human", "Format of the printed report, human or json")
+	all := flags.Bool("all", false, "Also print matching files")
+	flags.Usage = func() {
+		log.Println("Usage: audit -dir <directory> [-project <project>] [-type model|video] [-all] [-output human|json]")
+		flags.PrintDefaults()
+	}
+	parseFlags(flags, args)
+	if *dir == "" {
+		flags.Usage()
+		return errors.New("Missing directory")
+	}
+	if err := validateOutputFormat(*output); err != nil {
+		return err
+	}
+
+	report, err := viderasdk.SDKInstance().AuditDirectory(*dir, viderasdk.ListFilter{Project: *project, Type: *assetType})
+	if err != nil {
+		return err
+	}
+
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(writer, "STATUS\tNAME\tID\tLOCAL SIZE\tSIZE\tPATH")
+		for _, item := range report.Items {
+			if item.Status == viderasdk.InventoryMatch && !*all {
+				continue
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%v\t%v\t%s\n", item.Status, item.Name, item.ID, item.LocalSize, item.Size, item.Path)
+		}
+		writer.Flush()
+		fmt.Printf("%v matching, %v missing, %v extra, %v mismatched, %v unverified\n", report.Counts[viderasdk.InventoryMatch],
+			report.Counts[viderasdk.InventoryMissing], report.Counts[viderasdk.InventoryExtra],
+			report.Counts[viderasdk.InventoryMismatch], report.Counts[viderasdk.InventoryUnverified])
+	}
+
+	if !report.Consistent() {
+		return errors.New("Directory differs from the cluster inventory")
+	}
+	log.Println("Directory matches the cluster inventory")
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"log"
+
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// bundleCommand is a function responsible for the bundle subcommands
+// bundle create packages job artifacts offline, bundle push uploads a previously created bundle
+func bundleCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("Missing bundle subcommand, expected create or push")
+	}
+
+	switch args[0] {
+	case "create":
+		return bundleCreateCommand(args[1:])
+	case "push":
+		return bundlePushCommand(args[1:])
+	}
+
+	return errors.New("Unknown bundle subcommand, expected create or push")
+}
+
+// bundleCreateCommand is a function responsible for packaging job artifacts into a bundle file
+func bundleCreateCommand(args []string) error {
+	flags := flag.NewFlagSet("bundle create", flag.ExitOnError)
+	bundlePath := flags.String("out", "", "Path of the bundle file to create")
+	videoPath := flags.String("video", "", "Path to video file")
+	modelPath := flags.String("model", "", "Path to model file")
+	configPath := flags.String("config", "", "Path to config file")
+	codePath := flags.String("code", "", "Path to code file")
+	parseFlags(flags, args)
+
+	err := utils.ValidateFlags(*bundlePath, *videoPath, *modelPath, *configPath, *codePath)
+	if err != nil {
+		flags.PrintDefaults()
+		return err
+	}
+
+	return viderasdk.CreateBundle(*bundlePath, *videoPath, *modelPath, *configPath, *codePath)
+}
+
+// bundlePushCommand is a function responsible for uploading a previously created bundle file
+func bundlePushCommand(args []string) error {
+	flags := flag.NewFlagSet("bundle push", flag.ExitOnError)
+	output := flags.String("output", "human", "Format of the printed result, human or json")
+	flags.Usage = func() {
+		log.Println("Usage: bundle push [-output human|json] <bundle file>")
+	}
+	parseFlags(flags, args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("Missing bundle file")
+	}
+	if err := validateOutputFormat(*output); err != nil {
+		return err
+	}
+
+	vSDK := viderasdk.SDKInstance()
+	result, err := vSDK.PushBundle(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	log.Println("Bundle submitted successfully!")
+	return printJobResult(*output, result)
+}
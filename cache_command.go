@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+)
+
+// cacheCommand is a function responsible for the cache subcommands
+// cache ls lists the uploads remembered locally, cache clear forgets all of them
+func cacheCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("Missing cache subcommand, expected ls or clear")
+	}
+
+	switch args[0] {
+	case "ls":
+		return cacheListCommand(args[1:])
+	case "clear":
+		return viderasdk.SDKInstance().ClearUploadCache()
+	}
+
+	return errors.New("Unknown cache subcommand, expected ls or clear")
+}
+
+// cacheListCommand is a function responsible for printing the entries of the upload cache
+func cacheListCommand(args []string) error {
+	flags := flag.NewFlagSet("cache ls", flag.ExitOnError)
+	output := flags.String("output", "human", "Format of the printed entries, human or json")
+	parseFlags(flags, args)
+
+	if err := validateOutputFormat(*output); err != nil {
+		return err
+	}
+
+	entries, err := viderasdk.SDKInstance().UploadCache()
+	if err != nil {
+		return err
+	}
+
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tTYPE\tUPLOADED AT\tFILES")
+	for _, entry := range entries {
+		var paths []string
+		for _, file := range entry.Files {
+			paths = append(paths, file.Path)
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", entry.ID, entry.Filetype, entry.UploadedAt.Format(time.RFC3339), strings.Join(paths, ", "))
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"text/tabwriter"
+
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// capabilitiesCommand is a function responsible for listing the optional integrations the current binary supports
+// integrations are dropped by build tags, e.g. -tags noffmpeg, or unsupported by the platform, the rest are checked on the host
+func capabilitiesCommand(args []string) error {
+	flags := flag.NewFlagSet("capabilities", flag.ExitOnError)
+	output := flags.String("output", "human", "Format of the printed list, human or json")
+	flags.Usage = func() {
+		log.Println("Usage: capabilities [-output human|json]")
+		flags.PrintDefaults()
+	}
+	parseFlags(flags, args)
+
+	if err := validateOutputFormat(*output); err != nil {
+		return err
+	}
+
+	// the SDK applies ffmpeg_path of the config
+	viderasdk.SDKInstance()
+	features := utils.Features()
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(features)
+	}
+
+	fmt.Printf("Platform: %s/%s, %s\n", runtime.GOOS, runtime.GOARCH, runtime.Version())
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "FEATURE\tCOMPILED\tAVAILABLE\tDETAIL")
+	for _, feature := range features {
+		fmt.Fprintf(writer, "%s\t%v\t%v\t%s\n", feature.Name, feature.Compiled, feature.Available, feature.Detail)
+	}
+	return writer.Flush()
+}
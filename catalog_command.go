@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+)
+
+// catalogCommand is a function responsible for listing the uploads of the local catalog without asking the cluster
+// it takes the filters of list so operators without cluster access can browse what was uploaded from this machine
+func catalogCommand(args []string) error {
+	flags := flag.NewFlagSet("catalog", flag.ExitOnError)
+	assetType := flags.String("type", "", "Only list uploads of this type, model or video")
+	namePrefix := flags.String("prefix", "", "Only list uploads whose name starts with this prefix")
+	project := flags.String("project", "", "Only list uploads of this project")
+	after := flags.String("after", "", "Only list uploads completed at or after this date")
+	before := flags.String("before", "", "Only list uploads completed before this date")
+	output := flags.String("output", "table", "Format of the printed uploads, table or json")
+	tags := tagsFlag{}
+	flags.Var(tags, "tag", "Only list uploads carrying this key=value tag, can be repeated")
+	parseFlags(flags, args)
+
+	if *output != "table" && *output != "json" {
+		return errors.New("Unknown output format, expected table or json")
+	}
+	createdAfter, err := parseDate(*after)
+	if err != nil {
+		return err
+	}
+	createdBefore, err := parseDate(*before)
+	if err != nil {
+		return err
+	}
+
+	entries, err := viderasdk.SDKInstance().Catalog(viderasdk.ListFilter{
+		Type:          *assetType,
+		NamePrefix:    *namePrefix,
+		Project:       *project,
+		Tags:          tags,
+		CreatedAfter:  createdAfter,
+		CreatedBefore: createdBefore,
+	})
+	if err != nil {
+		return err
+	}
+
+	// json output has one upload per line like list
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, entry := range entries {
+			if err = encoder.Encode(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tTYPE\tNAME\tSIZE\tPROJECT\tTAGS\tCREATED\tCLUSTER")
+	for _, entry := range entries {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%v\t%s\t%s\t%s\t%s\n", entry.ID, entry.Type, entry.Name, entry.Size,
+			entry.Project, tagsFlag(entry.Tags).String(), entry.CreatedAt.Local().Format(time.RFC3339), entry.Cluster)
+	}
+	return writer.Flush()
+}
@@ -2,12 +2,13 @@ package config
 
 import (
 	"fmt"
-	"io/ioutil"
+	"io/fs"
 	"log"
 	"os"
+	"path/filepath"
 	"sync"
 
-	"gopkg.in/yaml.v2"
+	"github.com/SayedAlesawy/Videra-SDK/utils"
 )
 
 // logPrefix Used for hierarchical logging
@@ -22,7 +23,7 @@ var configManagerInstance *ConfigurationManager
 // ConfigurationManagerInstance A function to return a configuration manager instance
 func ConfigurationManagerInstance(configFilesDir string) *ConfigurationManager {
 	configManagerOnce.Do(func() {
-		manager := ConfigurationManager{configFilesDir: configFilesDir}
+		manager := ConfigurationManager{configFilesDir: configFilesDir, fileSystem: utils.OSFileSystem{}}
 
 		configManagerInstance = &manager
 	})
@@ -30,24 +31,35 @@ func ConfigurationManagerInstance(configFilesDir string) *ConfigurationManager {
 	return configManagerInstance
 }
 
-// retrieveConfig A function to read a config file
+// SetFileSystem A function to set the filesystem config files are read from, e.g. an in-memory one in tests
+func (manager *ConfigurationManager) SetFileSystem(fileSystem fs.FS) {
+	manager.fileSystem = fileSystem
+}
+
+// retrieveConfig A function to read a config file, panicking when it can't be read
 func (manager *ConfigurationManager) retrieveConfig(configObj interface{}, filePath string) {
-	configFileContent, err := ioutil.ReadFile(filePath)
-	if err != nil {
-		log.Println(fmt.Sprintf("%s %s\n", logPrefix, fmt.Sprintf("%s %s", "Unable to read config file:", filePath)))
+	if err := manager.loadConfig(configObj, filePath); err != nil {
+		log.Println(fmt.Sprintf("%s %s\n", logPrefix, err))
 		log.Panic(err)
 	}
+}
 
-	err = yaml.Unmarshal([]byte(configFileContent), configObj)
+// loadConfig A function to read a config file and the files it includes into configObj, see decodeConfig
+func (manager *ConfigurationManager) loadConfig(configObj interface{}, filePath string) error {
+	configFileContent, err := readConfigFile(manager.fileSystem, filePath)
 	if err != nil {
-		log.Println(fmt.Sprintf("%s %s\n", logPrefix, fmt.Sprintf("%s %s", "Unable to unmarshal config file:", filePath)))
-		log.Panic(err)
+		return fmt.Errorf("Unable to read config file: %s: %v", filePath, err)
+	}
+
+	if err = decodeConfig(configObj, configFileContent); err != nil {
+		return fmt.Errorf("%s: %v", filePath, err)
 	}
+	return nil
 }
 
 // getFilePath A function to get the file path given the name
 func (manager *ConfigurationManager) getFilePath(filename string) string {
-	filePath := fmt.Sprintf("%s%s", os.ExpandEnv(fmt.Sprintf("%s/", manager.configFilesDir)), filename)
+	filePath := filepath.Join(os.ExpandEnv(manager.configFilesDir), filename)
 
 	return filePath
 }
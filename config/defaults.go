@@ -0,0 +1,60 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// applyDefaults A function to fill the fields of keys missing from a config file with the value of their default tag
+// and to fail on missing keys of fields tagged required:"true", keys set through the environment aren't missing
+// defaults are parsed like environment values, e.g. default:"4MiB" or default:"[zstd, gzip]"
+func applyDefaults(configObj interface{}, configFileContent []byte) error {
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(configFileContent, &raw); err != nil {
+		return err
+	}
+
+	configValue := reflect.ValueOf(configObj).Elem()
+	configType := configValue.Type()
+	var missing []string
+	for idx := 0; idx < configType.NumField(); idx++ {
+		field := configType.Field(idx)
+		key := configKey(field)
+		if value, ok := raw[key]; ok && value != nil {
+			continue
+		}
+		if _, ok := os.LookupEnv(EnvName(key)); ok {
+			continue
+		}
+
+		if defaultValue, ok := field.Tag.Lookup("default"); ok {
+			if err := setValue(configValue.Field(idx), defaultValue); err != nil {
+				return fmt.Errorf("%s: invalid default: %v", key, err)
+			}
+		} else if field.Tag.Get("required") == "true" {
+			missing = append(missing, key)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("Missing required keys: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// setValue A function to set a field from text, flag values parse it themselves, strings are taken as is and the rest is parsed as YAML
+func setValue(fieldValue reflect.Value, text string) error {
+	if flagValue, ok := fieldValue.Addr().Interface().(flag.Value); ok {
+		return flagValue.Set(text)
+	}
+	if fieldValue.Kind() == reflect.String {
+		fieldValue.SetString(text)
+		return nil
+	}
+	return yaml.Unmarshal([]byte(text), fieldValue.Addr().Interface())
+}
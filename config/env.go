@@ -0,0 +1,106 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// EnvPrefix Prefix of the environment variables overriding config keys and CLI flags
+// precedence is CLI flags, then environment variables, then the config file
+const EnvPrefix = "VIDERA_"
+
+// Setting Describes the effective value of a config key and where it comes from
+type Setting struct {
+	Key    string      `json:"key"`    //Key of the setting in the config file
+	Value  interface{} `json:"value"`  //Effective value
+	Source string      `json:"source"` //Config file, or the environment variable the value comes from
+}
+
+// EnvName A function to get the environment variable overriding a config key or CLI flag
+// e.g. chunk_size and chunk-size are both overridden by VIDERA_CHUNK_SIZE
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+}
+
+// configKey A function to get the key of a config field in the config file
+func configKey(field reflect.StructField) string {
+	return strings.Split(field.Tag.Get("yaml"), ",")[0]
+}
+
+// applyEnv A function to override the fields of a config object with the environment variables named after their keys
+// values are parsed as YAML, e.g. VIDERA_RELAYS='[http://relay:8080]', except plain strings which are taken as is,
+// fields of sections such as security are named after their dotted key, e.g. VIDERA_SECURITY_REQUIRE_TLS
+func applyEnv(configObj interface{}) error {
+	return applyEnvSection(reflect.ValueOf(configObj).Elem(), "")
+}
+
+// applyEnvSection A function to override the fields of a config section whose keys start with prefix
+func applyEnvSection(configValue reflect.Value, prefix string) error {
+	configType := configValue.Type()
+	for idx := 0; idx < configType.NumField(); idx++ {
+		field := configType.Field(idx)
+		key := prefix + configKey(field)
+		fieldValue := configValue.Field(idx)
+		if fieldValue.Kind() == reflect.Struct {
+			if err := applyEnvSection(fieldValue, key+"."); err != nil {
+				return err
+			}
+			continue
+		}
+
+		envName := EnvName(key)
+		envValue, ok := os.LookupEnv(envName)
+		if !ok {
+			continue
+		}
+
+		if fieldValue.Kind() == reflect.String {
+			fieldValue.SetString(envValue)
+			continue
+		}
+		if err := yaml.Unmarshal([]byte(envValue), fieldValue.Addr().Interface()); err != nil {
+			return fmt.Errorf("%s: %v", envName, err)
+		}
+	}
+	return nil
+}
+
+// Settings A function to list the effective values of a config object along with their source, configObj is a pointer
+// values of fields tagged secret:"true" are masked
+func Settings(configObj interface{}) []Setting {
+	return sectionSettings(reflect.ValueOf(configObj).Elem(), "")
+}
+
+// sectionSettings A function to list the effective values of a config section whose keys start with prefix
+func sectionSettings(configValue reflect.Value, prefix string) []Setting {
+	configType := configValue.Type()
+	settings := make([]Setting, 0, configType.NumField())
+	for idx := 0; idx < configType.NumField(); idx++ {
+		field := configType.Field(idx)
+		key := prefix + configKey(field)
+		if configValue.Field(idx).Kind() == reflect.Struct {
+			settings = append(settings, sectionSettings(configValue.Field(idx), key+".")...)
+			continue
+		}
+
+		var value interface{} = configValue.Field(idx).Interface()
+		if flagValue, ok := configValue.Field(idx).Addr().Interface().(flag.Value); ok {
+			value = flagValue.String()
+		}
+		if field.Tag.Get("secret") == "true" && !configValue.Field(idx).IsZero() {
+			value = "********"
+		}
+
+		source := "config file"
+		if _, ok := os.LookupEnv(EnvName(key)); ok {
+			source = EnvName(key)
+		}
+		settings = append(settings, Setting{Key: key, Value: value, Source: source})
+	}
+	return settings
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// includeKey Key of the directive listing the config files a config file builds on
+const includeKey = "include"
+
+// readConfigFile A function to read a config file along with the files it includes, merged into one YAML document
+// include takes a path or a list of paths, relative paths are relative to the including file
+// precedence is the including file, then its includes from last to first, maps are merged key by key
+// while lists and other values replace those of lower precedence
+func readConfigFile(fileSystem fs.FS, filePath string) ([]byte, error) {
+	merged, err := resolveIncludes(fileSystem, filePath, nil)
+	if err != nil {
+		return nil, err
+	}
+	if merged == nil {
+		return []byte{}, nil
+	}
+	return yaml.Marshal(merged)
+}
+
+// resolveIncludes A function to read a config file and merge it over the files it includes
+// stack holds the files being included, a file including one of them is a cycle
+func resolveIncludes(fileSystem fs.FS, filePath string, stack []string) (map[interface{}]interface{}, error) {
+	for _, including := range stack {
+		if including == filePath {
+			return nil, fmt.Errorf("Include cycle: %s", strings.Join(append(stack, filePath), " -> "))
+		}
+	}
+	stack = append(stack, filePath)
+
+	content, err := fs.ReadFile(fileSystem, filePath)
+	if err != nil {
+		return nil, err
+	}
+	var document map[interface{}]interface{}
+	if err = yaml.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("%s: %v", filePath, err)
+	}
+
+	includes, err := includePaths(document[includeKey])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filePath, err)
+	}
+	delete(document, includeKey)
+	if len(includes) == 0 {
+		return document, nil
+	}
+
+	merged := make(map[interface{}]interface{})
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(filePath), include)
+		}
+		included, err := resolveIncludes(fileSystem, include, stack)
+		if err != nil {
+			return nil, err
+		}
+		merged = mergeMaps(merged, included)
+	}
+	return mergeMaps(merged, document), nil
+}
+
+// includePaths A function to get the paths of an include directive, a single path or a list of them
+func includePaths(value interface{}) ([]string, error) {
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{value}, nil
+	case []interface{}:
+		paths := make([]string, 0, len(value))
+		for _, path := range value {
+			path, ok := path.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: expected a path or a list of paths", includeKey)
+			}
+			paths = append(paths, path)
+		}
+		return paths, nil
+	}
+	return nil, fmt.Errorf("%s: expected a path or a list of paths", includeKey)
+}
+
+// mergeMaps A function to merge override over base, nested maps are merged and other values of override replace those of base
+func mergeMaps(base map[interface{}]interface{}, override map[interface{}]interface{}) map[interface{}]interface{} {
+	merged := make(map[interface{}]interface{}, len(base)+len(override))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range override {
+		baseMap, baseIsMap := merged[key].(map[interface{}]interface{})
+		overrideMap, overrideIsMap := value.(map[interface{}]interface{})
+		if baseIsMap && overrideIsMap {
+			merged[key] = mergeMaps(baseMap, overrideMap)
+		} else {
+			merged[key] = value
+		}
+	}
+	return merged
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultConfigFilesDir Directory config files are read from when no configuration manager was created before
+var DefaultConfigFilesDir = filepath.Join("config", "config_files")
+
+// registeredConfig A registration of any config type, used to reload every registered file
+type registeredConfig interface {
+	Reload() error
+}
+
+// registryMutex Guards registry
+var registryMutex sync.Mutex
+
+// registry Registration of each config file keyed by file name
+var registry = make(map[string]registeredConfig)
+
+// Registration A typed and cached handle on a config file, see Register
+// the config is kept as an immutable snapshot which reloads replace atomically, so readers never see a half updated config
+type Registration[T any] struct {
+	filename string       //Name of the config file inside the config files directory
+	mutex    sync.Mutex   //Serializes loads and reloads
+	snapshot atomic.Value //*T read from the file, nil until first read
+}
+
+// Register A function to register filename as a config file holding a T, the file is read when first needed
+// registering a file again returns the same registration, registering it with another type panics
+func Register[T any](filename string) *Registration[T] {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if existing, ok := registry[filename]; ok {
+		registration, ok := existing.(*Registration[T])
+		if !ok {
+			panic(fmt.Sprintf("%s %s is already registered with type %T", logPrefix, filename, existing))
+		}
+		return registration
+	}
+
+	registration := &Registration[T]{filename: filename}
+	registry[filename] = registration
+	return registration
+}
+
+// Registered A function to list the names of the registered config files
+func Registered() []string {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	filenames := make([]string, 0, len(registry))
+	for filename := range registry {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	return filenames
+}
+
+// ReloadAll A function to re-read every registered config file that was read before, the first failure is returned
+func ReloadAll() error {
+	registryMutex.Lock()
+	registrations := make([]registeredConfig, 0, len(registry))
+	for _, registration := range registry {
+		registrations = append(registrations, registration)
+	}
+	registryMutex.Unlock()
+
+	var firstErr error
+	for _, registration := range registrations {
+		if err := registration.Reload(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Get returns the config, reading the file on first use and panicking when it can't be read like SDKConfig
+// the returned config is a read-only copy of the current snapshot, its maps and slices are shared with it and must not be modified
+func (registration *Registration[T]) Get() T {
+	if snapshot, ok := registration.snapshot.Load().(*T); ok {
+		return *snapshot
+	}
+
+	registration.mutex.Lock()
+	defer registration.mutex.Unlock()
+	if snapshot, ok := registration.snapshot.Load().(*T); ok {
+		return *snapshot
+	}
+
+	value := new(T)
+	manager := ConfigurationManagerInstance(DefaultConfigFilesDir)
+	manager.retrieveConfig(value, manager.getFilePath(registration.filename))
+	registration.snapshot.Store(value)
+	return *value
+}
+
+// Reload re-reads the file if it was read before into a new snapshot replacing the current one
+// the current snapshot is kept when the file is invalid
+func (registration *Registration[T]) Reload() error {
+	registration.mutex.Lock()
+	defer registration.mutex.Unlock()
+
+	if registration.snapshot.Load() == nil {
+		return nil
+	}
+
+	value := new(T)
+	manager := ConfigurationManagerInstance(DefaultConfigFilesDir)
+	if err := manager.loadConfig(value, manager.getFilePath(registration.filename)); err != nil {
+		return err
+	}
+	registration.snapshot.Store(value)
+	return nil
+}
+
+// Set replaces the snapshot with value as if it was read from the file, e.g. a config received from a browser page
+// that has no config files directory, later reloads keep value until the file can be read
+func (registration *Registration[T]) Set(value T) {
+	registration.mutex.Lock()
+	defer registration.mutex.Unlock()
+
+	registration.snapshot.Store(&value)
+}
@@ -1,13 +1,133 @@
 package config
 
+import "github.com/SayedAlesawy/Videra-SDK/utils"
+
 // SDKConfig Houses the configurations of the SDK
 type SDKConfig struct {
-	NameNodeEndpoint string `yaml:"name_node_endpoint"` //Upload endpoint
-	ChunkSize        int64  `yaml:"chunk_size"`         //Size of chunk uploaded at a time
-	MaxRetries       int    `yaml:"max_retries"`        //Max number of retries when failure
-	WaitingTime      int    `yaml:"waiting_time"`       //Waiting time between consecutive retries
+	NameNodeEndpoint      string         `yaml:"name_node_endpoint" required:"true"`         //Upload endpoint
+	MasterEndpoints       []string       `yaml:"master_endpoints"`                           //Upload endpoints of further masters of the cluster, probed at once with name_node_endpoint for the leader
+	DiscoveryTimeout      utils.Duration `yaml:"discovery_timeout" default:"3s"`             //Time each master is given to answer a discovery probe
+	ChunkSize             utils.Size     `yaml:"chunk_size" default:"4MiB"`                  //Size of chunk uploaded at a time, e.g. 4MiB or 4194304 bytes
+	MaxRetries            int            `yaml:"max_retries" default:"3"`                    //Max number of retries when failure
+	SessionReinits        int            `yaml:"session_reinits" default:"3"`                //Times an upload replaces a session expired by the data node before failing
+	ExpiryMargin          int            `yaml:"session_expiry_margin" default:"60"`         //Seconds before the announced expiry of a session at which it is extended or replaced
+	WaitingTime           int            `yaml:"waiting_time" default:"10"`                  //Waiting time between consecutive retries
+	ProbeNodeCapabilities bool           `yaml:"probe_node_capabilities"`                    //Ask data nodes for their limits and features before init, cached per node in the state directory
+	DedupChunks           bool           `yaml:"dedup_chunks"`                               //Skip chunks already stored by the data node
+	SparseUpload          bool           `yaml:"sparse_upload"`                              //Send holes of sparse files without their zero bytes
+	ReadAhead             int64          `yaml:"read_ahead"`                                 //MB of a file read ahead of the upload in the background
+	DropPageCache         bool           `yaml:"drop_page_cache"`                            //Keep uploaded files out of the page cache with posix_fadvise
+	Compression           []string       `yaml:"compression"`                                //Codecs offered to data nodes for chunks of compressible files, in preference order
+	Checksums             []string       `yaml:"checksum_algorithms"`                        //Chunk checksum algorithms offered to data nodes in preference order, empty offers all from the fastest on the CPU
+	AuditLog              bool           `yaml:"audit_log"`                                  //Append every completed upload to a hash chained audit log in the state directory
+	Catalog               bool           `yaml:"catalog"`                                    //Append every completed upload to the local catalog in the state directory, see videra catalog
+	UsageAccounting       bool           `yaml:"usage_accounting"`                           //Append every completed upload to the usage ledger in the state directory, see videra usage local
+	StateDir              string         `yaml:"state_dir" default:"$HOME/.videra"`          //Directory holding local state such as the upload cache
+	LogLevel              string         `yaml:"log_level" default:"info"`                   //info, or debug to log every request and chunk
+	LogFile               string         `yaml:"log_file"`                                   //File logs are written to instead of stderr
+	LogFormat             string         `yaml:"log_format" default:"text"`                  //text, or json for log shippers
+	LogMaxSize            int64          `yaml:"log_max_size" default:"100"`                 //Size in MB after which the log file is rotated
+	LogMaxBackups         int            `yaml:"log_max_backups" default:"5"`                //Number of rotated log files to keep
+	LogMaxAge             int            `yaml:"log_max_age" default:"30"`                   //Days after which rotated log files are removed
+	StatsDAddr            string         `yaml:"statsd_addr"`                                //host:port of a StatsD/DogStatsD agent receiving metrics
+	StatsDPrefix          string         `yaml:"statsd_prefix" default:"videra."`            //Prefix of every metric name
+	ProgressInterval      int            `yaml:"progress_interval" default:"10"`             //Seconds between upload progress summaries
+	ProgressPercent       int            `yaml:"progress_percent" default:"10"`              //Percentage between upload progress summaries
+	PreUpload             string         `yaml:"pre_upload"`                                 //Command run before uploads with the upload described as JSON on stdin
+	PostUpload            string         `yaml:"post_upload"`                                //Command run after uploads with the upload and its result as JSON on stdin
+	HookFailure           string         `yaml:"hook_failure" default:"abort"`               //abort to fail the upload when a hook command fails, warn to only log it
+	Project               string         `yaml:"project"`                                    //Project whose defaults apply to uploads, see projects
+	Projects              Projects       `yaml:"projects"`                                   //Defaults of each project keyed by project name
+	Templates             Templates      `yaml:"templates"`                                  //Recurring upload shapes keyed by template name, see videra upload -template
+	Profile               string         `yaml:"profile"`                                    //Profile whose stored credentials are used, see videra login
+	Relays                []string       `yaml:"relays"`                                     //Relay endpoints forwarding to data nodes, the fastest is used when faster than direct
+	Routing               string         `yaml:"routing" default:"master"`                   //master asks the master for the data node of every upload, content computes it from the content checksum and the shard map of the master
+	BindInterfaces        []string       `yaml:"bind_interfaces"`                            //Network interfaces connections are bound to in preference order, e.g. [eth0, wwan0], empty leaves it to the routing table
+	InterfaceFailover     int            `yaml:"interface_failover_after" default:"2"`       //Consecutive requests failed without a response after which connections move to the next of bind_interfaces
+	TrafficShaping        Shaping        `yaml:"traffic_shaping"`                            //Rate limits by network type, interface and time of day, the first matching rule applies
+	FFmpegPath            string         `yaml:"ffmpeg_path" default:"ffmpeg"`               //ffmpeg binary used to process videos before upload
+	VideoPrecheck         string         `yaml:"video_precheck"`                             //warn or refuse to parse and decode a sample of videos before init, warning about or refusing unreadable ones, empty skips it
+	FilenameMaxLength     int            `yaml:"filename_max_length" default:"255"`          //Bytes after which sanitized file names are shortened keeping their extension, 0 keeps any length
+	FilenameCollision     string         `yaml:"filename_collision" default:"rename-suffix"` //rename-suffix, overwrite or fail when the data node already stores a file of the same name
+	WatchdogMinRate       utils.Rate     `yaml:"watchdog_min_rate"`                          //Throughput below which uploads are remediated, e.g. 100KB/s, 0 disables the watchdog
+	WatchdogWindow        int            `yaml:"watchdog_window" default:"30"`               //Seconds throughput must stay below watchdog_min_rate before remediating
+	WatchdogAction        string         `yaml:"watchdog_action" default:"alert"`            //alert, renegotiate to halve the chunk size or failover to continue on another data node
+	MaxBufferMemory       utils.Size     `yaml:"max_buffer_memory"`                          //Memory chunk and read ahead buffers of all uploads may use at most, e.g. 256MiB, unset leaves it unlimited
+	MaxGoroutines         int            `yaml:"max_goroutines"`                             //Goroutines the upload pipeline starts at once for parallel artifacts and read ahead, 0 leaves it unlimited
+	MaxProcs              int            `yaml:"max_procs"`                                  //GOMAXPROCS override, 0 keeps the Go default
+	CgroupAware           bool           `yaml:"cgroup_aware"`                               //Derive max_procs and max_buffer_memory from the cgroup CPU quota and memory limit when unset
+	MaxUploads            int            `yaml:"max_concurrent_uploads"`                     //Uploads running at once across every goroutine using the SDK, 0 leaves them unbounded
+	MaxChunks             int            `yaml:"max_concurrent_chunks"`                      //Chunk requests in flight at once across every upload, 0 leaves them unbounded
+	PriorityWeights       Weights        `yaml:"priority_weights"`                           //Share of max_concurrent_chunks uploads of each priority get relative to each other, other priorities weigh 1
+	DownloadRanges        int            `yaml:"download_ranges" default:"4"`                //Ranges of an artifact model pulls download at once
+	PoolMaxIdle           int            `yaml:"pool_max_idle_per_node"`                     //Idle keep-alive connections kept to each master, data node and relay, 0 keeps GOMAXPROCS+1
+	PoolMaxConns          int            `yaml:"pool_max_per_node"`                          //Connections to each master, data node and relay at most, 0 leaves them unbounded
+	PoolIdleTimeout       int            `yaml:"pool_idle_timeout" default:"90"`             //Seconds after which idle keep-alive connections are closed
+	SourceIsolation       string         `yaml:"source_isolation"`                           //hardlink or reflink to stage local files before upload, isolating it from producers rotating or rewriting them
+	StagingDir            string         `yaml:"staging_dir"`                                //Directory files are staged in, on the filesystem of the files, empty stages them in state_dir
+	SigningKeyID          string         `yaml:"signing_key_id"`                             //Key ID of the HMAC-SHA256 signature added to every request for gateways verifying them, empty sends requests unsigned
+	SigningSecret         string         `yaml:"signing_secret" secret:"true"`               //Secret the signatures are computed with, better set through VIDERA_SIGNING_SECRET
+	ServerVersion         string         `yaml:"server_version"`                             //Version of the cluster whose quirks requests adapt to, e.g. 1.2, empty probes it from the Server-Version header of responses
+	Security              Security       `yaml:"security"`                                   //Transport security policies enforced on every upload
+}
+
+// Security Houses the transport security policies of the SDK
+type Security struct {
+	RequireTLS bool `yaml:"require_tls"` //Refuse to talk to any master, data node or relay over plain http, including URLs discovered from the master
+}
+
+// Projects Houses the defaults of each project keyed by project name
+type Projects map[string]ProjectConfig
+
+// ProjectConfig Houses the defaults of uploads made for a project, zero values keep the SDK defaults
+type ProjectConfig struct {
+	ChunkSize  utils.Size        `yaml:"chunk_size"`  //Size of chunk uploaded at a time
+	Tags       map[string]string `yaml:"tags"`        //Tags sent with every upload of the project
+	RequireTLS bool              `yaml:"require_tls"` //Refuse to upload to master or data nodes over plain http
+	RateLimit  utils.Rate        `yaml:"rate_limit"`  //Max rate files are read for upload, e.g. 20MB/s
+	Retention  utils.Duration    `yaml:"retention"`   //How long the cluster is asked to keep uploads, e.g. 30d
+}
+
+// Weights Houses the scheduling weight of uploads by their priority, e.g. high: 4 and low: 0.5
+type Weights map[string]float64
+
+// Shaping Houses the traffic shaping rules in order of precedence
+type Shaping []ShapingRule
+
+// ShapingRule Houses a rate limit and the uploads it applies to, empty fields match everything
+type ShapingRule struct {
+	Network    string     `yaml:"network"`    //Type of the network uploads go through, ethernet, wifi, cellular or loopback
+	Interfaces []string   `yaml:"interfaces"` //Names of the interfaces uploads go through, e.g. wwan0, * matches any suffix as in wwan*
+	Days       []string   `yaml:"days"`       //Days of the week the rule applies on, e.g. [sat, sun]
+	Hours      string     `yaml:"hours"`      //Local time window the rule applies in, e.g. 08:00-20:00, windows may wrap past midnight
+	RateLimit  utils.Rate `yaml:"rate_limit"` //Max rate files are read for upload while the rule applies, 0/s leaves them unlimited
 }
 
+// Templates Houses the upload templates keyed by template name
+type Templates map[string]TemplateConfig
+
+// TemplateConfig Houses the shape of a recurring upload, zero values keep the SDK defaults
+// templates with a model upload their file as the video of a job, the others as a video for model_id
+type TemplateConfig struct {
+	Model           string                 `yaml:"model"`            //Path or URL of the model file of jobs
+	Config          string                 `yaml:"config"`           //Path or URL of the config file of jobs
+	Code            string                 `yaml:"code"`             //Path or URL of the code file of jobs
+	ModelID         string                 `yaml:"model_id"`         //ID of the model videos are uploaded for when the template has no model
+	Project         string                 `yaml:"project"`          //Project whose defaults apply before those of the template
+	Tags            map[string]string      `yaml:"tags"`             //Tags sent with every upload, added to those of the project
+	Metadata        map[string]interface{} `yaml:"metadata"`         //Metadata sent with every upload
+	Priority        string                 `yaml:"priority"`         //Priority the cluster is asked to process uploads with, e.g. low or high
+	RequireTLS      bool                   `yaml:"require_tls"`      //Refuse to upload over plain http, uploads are encrypted in transit
+	ChunkSize       utils.Size             `yaml:"chunk_size"`       //Size of chunk uploaded at a time
+	Compression     []string               `yaml:"compression"`      //Codecs offered for chunks in preference order
+	SegmentDuration utils.Duration         `yaml:"segment_duration"` //Split job videos into segments of about this duration
+	StripAudio      bool                   `yaml:"strip_audio"`      //Drop every audio track of videos
+	ScrubMetadata   bool                   `yaml:"scrub_metadata"`   //Remove metadata such as GPS location from videos
+}
+
+// SDKConfigFile The registration of the SDK config file
+var SDKConfigFile = Register[SDKConfig]("sdk_config.yaml")
+
 // SDKConfig A function to return the healthcheck monitor config
 func (manager *ConfigurationManager) SDKConfig(filename string) SDKConfig {
 	var configObj SDKConfig
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ErrIncludeWithoutFile Returned when config content read from memory has an include directive, there is no file to resolve it against
+var ErrIncludeWithoutFile = errors.New("Include is only supported in config files")
+
+// RetrieveFromReader A function to read a config from r into configObj, e.g. one received over the network
+// the content is handled like a config file, it is checked, missing keys are filled from default tags and environment overrides apply
+func RetrieveFromReader(r io.Reader, configObj interface{}) error {
+	configContent, err := ioutil.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("Unable to read config: %v", err)
+	}
+
+	return RetrieveFromBytes(configContent, configObj)
+}
+
+// RetrieveFromBytes A function to read a config from content into configObj, e.g. one embedded with go:embed
+func RetrieveFromBytes(configContent []byte, configObj interface{}) error {
+	var document map[interface{}]interface{}
+	if err := yaml.Unmarshal(configContent, &document); err != nil {
+		return fmt.Errorf("Unable to unmarshal config: %v", err)
+	}
+	if _, ok := document[includeKey]; ok {
+		return ErrIncludeWithoutFile
+	}
+
+	return decodeConfig(configObj, configContent)
+}
+
+// decodeConfig A function to read config content into configObj, checking its values,
+// filling missing keys with the default tags of their fields and applying environment overrides
+func decodeConfig(configObj interface{}, configContent []byte) error {
+	err := checkValues(configObj, configContent)
+	if err != nil {
+		return fmt.Errorf("Invalid value in config: %v", err)
+	}
+
+	err = yaml.Unmarshal(configContent, configObj)
+	if err != nil {
+		return fmt.Errorf("Unable to unmarshal config: %v", err)
+	}
+
+	err = applyDefaults(configObj, configContent)
+	if err != nil {
+		return fmt.Errorf("Invalid config: %v", err)
+	}
+
+	err = applyEnv(configObj)
+	if err != nil {
+		return fmt.Errorf("Invalid value in environment: %v", err)
+	}
+	return nil
+}
@@ -1,6 +1,9 @@
 package config
 
+import "io/fs"
+
 // ConfigurationManager An interface for all config objects
 type ConfigurationManager struct {
 	configFilesDir string //Directroy in which to look for config files
+	fileSystem     fs.FS  //Filesystem config files are read from
 }
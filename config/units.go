@@ -0,0 +1,41 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// flagValueType Type of values parsed from text like flags, e.g. utils.Size or utils.Duration
+var flagValueType = reflect.TypeOf((*flag.Value)(nil)).Elem()
+
+// checkValues A function to parse the text values of a config file, such as sizes and durations, one key at a time
+// so that an invalid value is reported along with its key, e.g. chunk_size: invalid size "4XB"
+func checkValues(configObj interface{}, configFileContent []byte) error {
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(configFileContent, &raw); err != nil {
+		return err
+	}
+
+	configType := reflect.TypeOf(configObj).Elem()
+	for idx := 0; idx < configType.NumField(); idx++ {
+		field := configType.Field(idx)
+		if !reflect.PtrTo(field.Type).Implements(flagValueType) {
+			continue
+		}
+
+		key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		value, ok := raw[key]
+		if !ok || value == nil {
+			continue
+		}
+		parsed := reflect.New(field.Type).Interface().(flag.Value)
+		if err := parsed.Set(fmt.Sprint(value)); err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/SayedAlesawy/Videra-SDK/config"
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+)
+
+// configCommand is a function responsible for the config subcommands
+// config effective prints the config the SDK runs with once environment variables are applied
+func configCommand(args []string) error {
+	if len(args) == 0 || args[0] != "effective" {
+		return errors.New("Missing or unknown config subcommand, expected effective")
+	}
+
+	flags := flag.NewFlagSet("config effective", flag.ExitOnError)
+	output := flags.String("output", "human", "Format of the printed config, human or json")
+	flags.Usage = func() {
+		log.Println("Usage: config effective [-output human|json]")
+		log.Println(fmt.Sprintf("Each value comes from a CLI flag, else a %s environment variable, else the config file", config.EnvPrefix))
+		flags.PrintDefaults()
+	}
+	parseFlags(flags, args[1:])
+
+	if err := validateOutputFormat(*output); err != nil {
+		return err
+	}
+
+	settings := viderasdk.EffectiveConfig()
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(settings)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "KEY\tVALUE\tSOURCE")
+	for _, setting := range settings {
+		fmt.Fprintf(writer, "%s\t%v\t%s\n", setting.Key, setting.Value, setting.Source)
+	}
+	return writer.Flush()
+}
+
+// parseFlags is a function responsible for parsing the arguments of a flag set
+// flags not given on the command line take their value from the VIDERA_ environment variable named after them
+func parseFlags(flags *flag.FlagSet, args []string) {
+	flags.VisitAll(func(f *flag.Flag) {
+		value, ok := os.LookupEnv(config.EnvName(f.Name))
+		if !ok {
+			return
+		}
+		if err := f.Value.Set(value); err != nil {
+			fmt.Fprintf(flags.Output(), "invalid value %q for %s: %v\n", value, config.EnvName(f.Name), err)
+			os.Exit(2)
+		}
+	})
+	flags.Parse(args)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// debugCommand is a function responsible for the debug subcommands
+// debug connections exercises the connection pool against the master and prints its statistics
+func debugCommand(args []string) error {
+	if len(args) == 0 || args[0] != "connections" {
+		return errors.New("Missing or unknown debug subcommand, expected connections")
+	}
+
+	flags := flag.NewFlagSet("debug connections", flag.ExitOnError)
+	requests := flags.Int("requests", 10, "Requests sent to the master")
+	output := flags.String("output", "human", "Format of the printed statistics, human or json")
+	poolMaxIdle, poolMaxConns, poolIdleTimeout := poolFlags(flags)
+	flags.Usage = func() {
+		log.Println("Usage: debug connections [-requests n] [pool flags] [-output human|json]")
+		flags.PrintDefaults()
+	}
+	parseFlags(flags, args[1:])
+
+	if err := validateOutputFormat(*output); err != nil {
+		return err
+	}
+
+	vSDK := viderasdk.SDKInstance()
+	applyPoolFlags(*poolMaxIdle, *poolMaxConns, *poolIdleTimeout)
+	if err := vSDK.ProbeConnections(*requests); err != nil {
+		return err
+	}
+
+	stats := utils.ConnectionStats()
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "HOST\tNEW\tREUSED\tDIAL AVG\tHANDSHAKES\tHANDSHAKE AVG\tHANDSHAKE MAX")
+	for _, host := range stats {
+		fmt.Fprintf(writer, "%s\t%v\t%v\t%v\t%v\t%v\t%v\n", host.Host, host.New, host.Reused, average(host.DialTime, host.New),
+			host.Handshakes, average(host.HandshakeTime, host.Handshakes), host.MaxHandshake)
+	}
+	return writer.Flush()
+}
+
+// poolFlags is a function to add the flags tuning the connection pool to a flag set
+func poolFlags(flags *flag.FlagSet) (*int, *int, *utils.Duration) {
+	maxIdle := flags.Int("pool-max-idle", 0, "Idle keep-alive connections kept to each data node, overrides pool_max_idle_per_node of the config")
+	maxConns := flags.Int("pool-max-conns", 0, "Connections to each data node at most, overrides pool_max_per_node of the config")
+	var idleTimeout utils.Duration
+	flags.Var(&idleTimeout, "pool-idle-timeout", "Close keep-alive connections idle for this duration, e.g. 30s, overrides pool_idle_timeout of the config")
+	return maxIdle, maxConns, &idleTimeout
+}
+
+// applyPoolFlags is a function responsible for applying the pool flags that were set over the pool of the config
+func applyPoolFlags(maxIdle int, maxConns int, idleTimeout utils.Duration) {
+	if maxIdle == 0 && maxConns == 0 && idleTimeout == 0 {
+		return
+	}
+
+	options := utils.CurrentPoolOptions()
+	if maxIdle > 0 {
+		options.MaxIdlePerHost = maxIdle
+	}
+	if maxConns > 0 {
+		options.MaxPerHost = maxConns
+	}
+	if idleTimeout > 0 {
+		options.IdleTimeout = time.Duration(idleTimeout)
+	}
+	utils.SetPoolOptions(options)
+}
+
+// average is a function to get the mean of a total duration over count samples, 0 without samples
+func average(total time.Duration, count int64) time.Duration {
+	if count == 0 {
+		return 0
+	}
+	return (total / time.Duration(count)).Round(time.Microsecond)
+}
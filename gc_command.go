@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+)
+
+// gcCommand is a function responsible for removing stale local state and printing what was reclaimed
+func gcCommand(args []string) error {
+	flags := flag.NewFlagSet("gc", flag.ExitOnError)
+	sessionDays := flags.Int("session-days", 7, "Remove journaled sessions started more than this many days ago, 0 keeps them")
+	abort := flags.Bool("abort", false, "Ask the data nodes to abort the removed sessions")
+	cacheDays := flags.Int("cache-days", 0, "Forget cached uploads older than this many days, 0 only forgets uploads of changed files")
+	output := flags.String("output", "human", "Format of the printed report, human or json")
+	parseFlags(flags, args)
+
+	if err := validateOutputFormat(*output); err != nil {
+		return err
+	}
+	if *abort {
+		if err := viderasdk.SDKInstance().RequireCapability(viderasdk.CapabilityDelete); err != nil {
+			return err
+		}
+	}
+
+	report, err := viderasdk.SDKInstance().GC(viderasdk.GCOptions{
+		SessionMaxAge: time.Duration(*sessionDays) * 24 * time.Hour,
+		AbortSessions: *abort,
+		CacheMaxAge:   time.Duration(*cacheDays) * 24 * time.Hour,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	fmt.Printf("Removed sessions:      %v (%v aborted)\n", report.RemovedSessions, report.AbortedSessions)
+	fmt.Printf("Removed cache entries: %v\n", report.RemovedCacheEntries)
+	fmt.Printf("Removed temp files:    %v\n", report.RemovedTempFiles)
+	fmt.Printf("Reclaimed:             %v bytes\n", report.ReclaimedBytes)
+	return nil
+}
@@ -0,0 +1,98 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// Policy Describes how a client retries requests failing without a response or with a 429 or 5xx response other than 501
+type Policy struct {
+	MaxRetries  int                                                   //Max number of request retrials
+	WaitingTime time.Duration                                         //Time waited between a failed request and the next one
+	OnRetry     func(attempt int, err error, nextDelay time.Duration) //Called before each retry with the number of the retry counted from 1, the failure causing it and the delay before it, nil when unset
+	Logger      Logger                                                //Receives the messages of the retrying client, nil discards them
+}
+
+// Logger An interface for loggers the messages of retrying clients are written to, satisfied by *log.Logger
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// NewClient is a function that returns an http client sending requests through base, retrying them according to policy
+// bodies of requests with a GetBody, such as those of NewRewindableRequest, are re-created on every attempt, nil base uses http.DefaultTransport
+// the client is safe for concurrent use, each request is retried on its own
+func NewClient(policy Policy, base http.RoundTripper) *http.Client {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &http.Client{Transport: rewindingTransport{policy: policy, client: &http.Client{Transport: base}}}
+}
+
+// retryingClient is a function that returns the retrying client sending one request according to policy through client
+func retryingClient(policy Policy, client *http.Client) *retryablehttp.Client {
+	clientretry := retryablehttp.NewClient()
+	clientretry.RetryMax = policy.MaxRetries
+	clientretry.RetryWaitMin = policy.WaitingTime
+	clientretry.RetryWaitMax = policy.WaitingTime
+	clientretry.Logger = policy.Logger
+	clientretry.HTTPClient = client
+
+	// the retry policy sees the failure and the backoff sees the attempt number and delay
+	var lastErr error
+	clientretry.CheckRetry = func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		retry, checkErr := retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+		// servers shedding load answer 429, the default policy only retries 5xx
+		if checkErr == nil && err == nil && resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+			retry = true
+		}
+		lastErr = err
+		if lastErr == nil && resp != nil {
+			lastErr = fmt.Errorf("Unexpected response %s", resp.Status)
+		}
+		return retry, checkErr
+	}
+	if policy.OnRetry != nil {
+		clientretry.Backoff = func(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+			delay := retryablehttp.DefaultBackoff(min, max, attemptNum, resp)
+			policy.OnRetry(attemptNum+1, lastErr, delay)
+			return delay
+		}
+	}
+	return clientretry
+}
+
+// rewindingTransport Sends requests through a retrying client, bodies with a GetBody are re-created from it
+// on every attempt instead of being buffered, so a retry never resends a body left half read by a failed attempt
+type rewindingTransport struct {
+	policy Policy       //Policy requests are retried with
+	client *http.Client //Client sending each attempt
+}
+
+// RoundTrip sends a request, retrying it according to the policy
+func (transport rewindingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	client := retryingClient(transport.policy, transport.client)
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody == nil {
+		retryableReq, err := retryablehttp.FromRequest(req)
+		if err != nil {
+			return nil, err
+		}
+		return client.Do(retryableReq)
+	}
+
+	req.Body.Close()
+	getBody := req.GetBody
+	retryableReq, err := retryablehttp.NewRequest(req.Method, req.URL.String(), retryablehttp.ReaderFunc(func() (io.Reader, error) {
+		return getBody()
+	}))
+	if err != nil {
+		return nil, err
+	}
+	// keep the caller's request so headers, context and content length are sent as built
+	retryableReq.Request = req
+	return client.Do(retryableReq)
+}
@@ -0,0 +1,197 @@
+package httpx
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyServer is a function that starts a server answering the first failures requests with status and the others with 200,
+// it returns the server and a function to get the requests it received and the body length of each
+func flakyServer(failures int, status int) (*httptest.Server, func() []int) {
+	var mutex sync.Mutex
+	var lengths []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mutex.Lock()
+		lengths = append(lengths, len(body))
+		attempt := len(lengths)
+		mutex.Unlock()
+		if attempt <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	return server, func() []int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]int(nil), lengths...)
+	}
+}
+
+func TestNewClientRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		failures   int
+		maxRetries int
+		attempts   int
+		finalCode  int
+	}{
+		{"recovers from 5xx", http.StatusInternalServerError, 2, 3, 3, http.StatusOK},
+		{"recovers from 429", http.StatusTooManyRequests, 1, 3, 2, http.StatusOK},
+		{"4xx isn't retried", http.StatusConflict, 5, 3, 1, http.StatusConflict},
+		{"501 isn't retried", http.StatusNotImplemented, 5, 3, 1, http.StatusNotImplemented},
+		{"gives up after max retries", http.StatusBadGateway, 5, 2, 3, 0},
+		{"no retries", http.StatusServiceUnavailable, 1, 0, 1, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, received := flakyServer(test.failures, test.status)
+			defer server.Close()
+
+			client := NewClient(Policy{MaxRetries: test.maxRetries}, nil)
+			body := bytes.Repeat([]byte("x"), 4096)
+			req, _ := NewRewindableRequest(http.MethodPost, server.URL, body)
+			res, err := client.Do(req)
+			if test.finalCode == 0 {
+				if err == nil {
+					res.Body.Close()
+					t.Fatalf("expected an error once retries are exhausted, got %s", res.Status)
+				}
+			} else {
+				if err != nil {
+					t.Fatal(err)
+				}
+				res.Body.Close()
+				if res.StatusCode != test.finalCode {
+					t.Errorf("status %v, expected %v", res.StatusCode, test.finalCode)
+				}
+			}
+
+			lengths := received()
+			if len(lengths) != test.attempts {
+				t.Errorf("%v attempts, expected %v", len(lengths), test.attempts)
+			}
+			for attempt, length := range lengths {
+				if length != len(body) {
+					t.Errorf("attempt %v carried %v bytes, expected %v", attempt+1, length, len(body))
+				}
+			}
+		})
+	}
+}
+
+func TestNewClientOnRetry(t *testing.T) {
+	server, _ := flakyServer(2, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	type retry struct {
+		attempt int
+		err     error
+		delay   time.Duration
+	}
+	var mutex sync.Mutex
+	var retries []retry
+	waitingTime := 5 * time.Millisecond
+	client := NewClient(Policy{MaxRetries: 3, WaitingTime: waitingTime, OnRetry: func(attempt int, err error, nextDelay time.Duration) {
+		mutex.Lock()
+		retries = append(retries, retry{attempt, err, nextDelay})
+		mutex.Unlock()
+	}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if len(retries) != 2 {
+		t.Fatalf("OnRetry called %v times, expected 2", len(retries))
+	}
+	for idx, retry := range retries {
+		if retry.attempt != idx+1 {
+			t.Errorf("retry %v reported attempt %v, expected %v", idx, retry.attempt, idx+1)
+		}
+		if retry.err == nil {
+			t.Errorf("retry %v reported no failure", idx)
+		}
+		if retry.delay != waitingTime {
+			t.Errorf("retry %v reported delay %v, expected %v", idx, retry.delay, waitingTime)
+		}
+	}
+}
+
+// countingTransport Counts the requests sent through it
+type countingTransport struct {
+	mutex    sync.Mutex
+	requests int
+}
+
+// RoundTrip counts the request and sends it
+func (transport *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport.mutex.Lock()
+	transport.requests++
+	transport.mutex.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// TestNewClientConcurrentRetries checks requests retried at once through one client report their own failures
+func TestNewClientConcurrentRetries(t *testing.T) {
+	failing, _ := flakyServer(1, http.StatusServiceUnavailable)
+	defer failing.Close()
+	throttling, _ := flakyServer(1, http.StatusTooManyRequests)
+	defer throttling.Close()
+
+	var mutex sync.Mutex
+	failures := map[string]int{}
+	client := NewClient(Policy{MaxRetries: 1, OnRetry: func(attempt int, err error, nextDelay time.Duration) {
+		mutex.Lock()
+		failures[err.Error()]++
+		mutex.Unlock()
+	}}, nil)
+
+	var wg sync.WaitGroup
+	for _, url := range []string{failing.URL, throttling.URL} {
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodGet, url, nil)
+			res, err := client.Do(req)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			res.Body.Close()
+		}(url)
+	}
+	wg.Wait()
+
+	if failures["Unexpected response 503 Service Unavailable"] != 1 || failures["Unexpected response 429 Too Many Requests"] != 1 {
+		t.Errorf("retries reported %v, expected one 503 and one 429", failures)
+	}
+}
+
+func TestNewClientBaseTransport(t *testing.T) {
+	server, _ := flakyServer(1, http.StatusInternalServerError)
+	defer server.Close()
+
+	base := &countingTransport{}
+	client := NewClient(Policy{MaxRetries: 1}, base)
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if base.requests != 2 {
+		t.Errorf("%v requests went through the base transport, expected 2", base.requests)
+	}
+}
@@ -0,0 +1,47 @@
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Int64Header is a function to parse a header holding an integer, false when it is missing or isn't an integer
+func Int64Header(header http.Header, name string) (int64, bool) {
+	value, err := strconv.ParseInt(header.Get(name), 10, 64)
+	return value, err == nil
+}
+
+// OffsetCorrection is a function to get the offset a data node expects the next chunk of a session at
+// data nodes answer chunks sent at another offset, and resumed or adopted sessions, with it in the Offset header
+func OffsetCorrection(res *http.Response) (int64, bool) {
+	return Int64Header(res.Header, "Offset")
+}
+
+// MaxRequestSize is a function to get the largest chunk a data node accepts, announced on init and on chunks it rejects as too large
+func MaxRequestSize(res *http.Response) (int64, bool) {
+	size, ok := Int64Header(res.Header, "Max-Request-Size")
+	return size, ok && size > 0
+}
+
+// MaxFileSize is a function to get the largest file a cluster accepts, announced by the master in the Max-File-Size header
+func MaxFileSize(res *http.Response) (int64, bool) {
+	return Int64Header(res.Header, "Max-File-Size")
+}
+
+// SessionExpiry is a function to get the time a data node expires a session at, zero when it doesn't say
+// it is announced in a Session-TTL header holding seconds from now or a Session-Expires header holding an RFC 3339 or HTTP date
+func SessionExpiry(res *http.Response, now time.Time) time.Time {
+	if ttl, ok := Int64Header(res.Header, "Session-TTL"); ok && ttl > 0 {
+		return now.Add(time.Duration(ttl) * time.Second)
+	}
+	expires := res.Header.Get("Session-Expires")
+	if expires == "" {
+		return time.Time{}
+	}
+	expiry, err := time.Parse(time.RFC3339, expires)
+	if err != nil {
+		expiry, _ = http.ParseTime(expires)
+	}
+	return expiry
+}
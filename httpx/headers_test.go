@@ -0,0 +1,116 @@
+package httpx
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// response is a function that returns a response carrying the given headers
+func response(headers map[string]string) *http.Response {
+	res := &http.Response{Header: make(http.Header)}
+	for key, val := range headers {
+		res.Header.Set(key, val)
+	}
+	return res
+}
+
+func TestOffsetCorrection(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		offset int64
+		ok     bool
+	}{
+		{"missing", "", 0, false},
+		{"zero", "0", 0, true},
+		{"offset", "1048576", 1048576, true},
+		{"not a number", "abc", 0, false},
+		{"with spaces", " 12", 0, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := response(map[string]string{})
+			if test.header != "" {
+				res.Header.Set("Offset", test.header)
+			}
+			offset, ok := OffsetCorrection(res)
+			if offset != test.offset || ok != test.ok {
+				t.Errorf("OffsetCorrection(%q) = %v, %v, expected %v, %v", test.header, offset, ok, test.offset, test.ok)
+			}
+		})
+	}
+}
+
+func TestMaxRequestSize(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		size   int64
+		ok     bool
+	}{
+		{"missing", "", 0, false},
+		{"size", "4194304", 4194304, true},
+		{"zero", "0", 0, false},
+		{"negative", "-1", -1, false},
+		{"not a number", "4MiB", 0, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := response(map[string]string{"Max-Request-Size": test.header})
+			size, ok := MaxRequestSize(res)
+			if ok != test.ok || (ok && size != test.size) {
+				t.Errorf("MaxRequestSize(%q) = %v, %v, expected %v, %v", test.header, size, ok, test.size, test.ok)
+			}
+		})
+	}
+}
+
+func TestMaxFileSize(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		size   int64
+		ok     bool
+	}{
+		{"missing", "", 0, false},
+		{"size", "10737418240", 10737418240, true},
+		{"not a number", "10G", 0, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := response(map[string]string{"Max-File-Size": test.header})
+			size, ok := MaxFileSize(res)
+			if size != test.size || ok != test.ok {
+				t.Errorf("MaxFileSize(%q) = %v, %v, expected %v, %v", test.header, size, ok, test.size, test.ok)
+			}
+		})
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		headers map[string]string
+		expiry  time.Time
+	}{
+		{"unannounced", map[string]string{}, time.Time{}},
+		{"ttl", map[string]string{"Session-TTL": "3600"}, now.Add(time.Hour)},
+		{"ttl wins over expires", map[string]string{"Session-TTL": "60", "Session-Expires": "2026-10-15T00:00:00Z"}, now.Add(time.Minute)},
+		{"zero ttl falls back to expires", map[string]string{"Session-TTL": "0", "Session-Expires": "2026-10-15T00:00:00Z"},
+			time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)},
+		{"rfc 3339", map[string]string{"Session-Expires": "2026-10-14T13:30:00+01:00"}, time.Date(2026, 10, 14, 12, 30, 0, 0, time.UTC)},
+		{"http date", map[string]string{"Session-Expires": "Wed, 14 Oct 2026 18:00:00 GMT"}, time.Date(2026, 10, 14, 18, 0, 0, 0, time.UTC)},
+		{"invalid", map[string]string{"Session-Expires": "tomorrow"}, time.Time{}},
+		{"invalid ttl", map[string]string{"Session-TTL": "soon"}, time.Time{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expiry := SessionExpiry(response(test.headers), now)
+			if !expiry.Equal(test.expiry) {
+				t.Errorf("SessionExpiry(%v) = %v, expected %v", test.headers, expiry, test.expiry)
+			}
+		})
+	}
+}
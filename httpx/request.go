@@ -0,0 +1,27 @@
+package httpx
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// NewRewindableRequest is a function that returns a request whose body can be re-created for every retry attempt
+// the body is read from the same slice on each attempt so a retried request always carries the full payload
+func NewRewindableRequest(method string, url string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		return req, nil
+	}
+
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.Body, _ = req.GetBody()
+	req.ContentLength = int64(len(body))
+	return req, nil
+}
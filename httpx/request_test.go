@@ -0,0 +1,55 @@
+package httpx
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestNewRewindableRequest(t *testing.T) {
+	body := []byte("chunk payload")
+	req, err := NewRewindableRequest(http.MethodPost, "http://localhost/data", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.ContentLength != int64(len(body)) {
+		t.Errorf("ContentLength = %v, expected %v", req.ContentLength, len(body))
+	}
+
+	// the body read by a failed attempt doesn't shorten the next ones
+	first, _ := ioutil.ReadAll(req.Body)
+	if !bytes.Equal(first, body) {
+		t.Errorf("Body = %q, expected %q", first, body)
+	}
+	for attempt := 0; attempt < 3; attempt++ {
+		rewound, err := req.GetBody()
+		if err != nil {
+			t.Fatal(err)
+		}
+		half := make([]byte, len(body)/2)
+		rewound.Read(half)
+
+		again, _ := req.GetBody()
+		payload, _ := ioutil.ReadAll(again)
+		if !bytes.Equal(payload, body) {
+			t.Errorf("GetBody after a partial read = %q, expected %q", payload, body)
+		}
+	}
+}
+
+func TestNewRewindableRequestWithoutBody(t *testing.T) {
+	req, err := NewRewindableRequest(http.MethodGet, "http://localhost/data", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Body != nil || req.GetBody != nil || req.ContentLength != 0 {
+		t.Errorf("request without body has Body %v, GetBody set %v and ContentLength %v", req.Body, req.GetBody != nil, req.ContentLength)
+	}
+}
+
+func TestNewRewindableRequestInvalidURL(t *testing.T) {
+	if _, err := NewRewindableRequest(http.MethodPost, "://no-scheme", []byte("x")); err == nil {
+		t.Error("expected an error for an invalid URL")
+	}
+}
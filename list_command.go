@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+)
+
+// tagsFlag A flag collecting key=value tags, it can be repeated
+type tagsFlag map[string]string
+
+// String returns the tags as comma separated key=value pairs
+func (tags tagsFlag) String() string {
+	var pairs []string
+	for key, val := range tags {
+		pairs = append(pairs, key+"="+val)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Set adds a key=value tag
+func (tags tagsFlag) Set(tag string) error {
+	separator := strings.Index(tag, "=")
+	if separator <= 0 {
+		return fmt.Errorf("Invalid tag %s, expected key=value", tag)
+	}
+	tags[tag[:separator]] = tag[separator+1:]
+	return nil
+}
+
+// parseDate is a function to parse a date flag given as RFC 3339 or YYYY-MM-DD, empty gives the zero time
+func parseDate(date string) (time.Time, error) {
+	if date == "" {
+		return time.Time{}, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, date); err == nil {
+		return parsed, nil
+	}
+	parsed, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid date %s, expected YYYY-MM-DD or RFC 3339", date)
+	}
+	return parsed, nil
+}
+
+// listCommand is a function responsible for listing the assets stored by the cluster
+func listCommand(args []string) error {
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+	assetType := flags.String("type", "", "Only list assets of this type, model or video")
+	namePrefix := flags.String("prefix", "", "Only list assets whose name starts with this prefix")
+	project := flags.String("project", "", "Only list assets of this project")
+	after := flags.String("after", "", "Only list assets created at or after this date")
+	before := flags.String("before", "", "Only list assets created before this date")
+	sortOrder := flags.String("sort", "", "Field assets are ordered by, prefix with - for descending order, e.g. -created_at")
+	limit := flags.Int("limit", 0, "Stop after this many assets, 0 lists all of them")
+	output := flags.String("output", "table", "Format of the printed assets, table or json")
+	tags := tagsFlag{}
+	flags.Var(tags, "tag", "Only list assets carrying this key=value tag, can be repeated")
+	parseFlags(flags, args)
+
+	if *output != "table" && *output != "json" {
+		return errors.New("Unknown output format, expected table or json")
+	}
+	createdAfter, err := parseDate(*after)
+	if err != nil {
+		return err
+	}
+	createdBefore, err := parseDate(*before)
+	if err != nil {
+		return err
+	}
+
+	iterator := viderasdk.SDKInstance().ListAssets(viderasdk.ListFilter{
+		Type:          *assetType,
+		NamePrefix:    *namePrefix,
+		Project:       *project,
+		Tags:          tags,
+		CreatedAfter:  createdAfter,
+		CreatedBefore: createdBefore,
+		Sort:          *sortOrder,
+	})
+
+	var writer *tabwriter.Writer
+	var encoder *json.Encoder
+	if *output == "json" {
+		encoder = json.NewEncoder(os.Stdout)
+	} else {
+		writer = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(writer, "ID\tTYPE\tNAME\tSIZE\tPROJECT\tTAGS\tCREATED")
+	}
+
+	// assets are printed as they are fetched, json output has one asset per line
+	for count := 0; (*limit == 0 || count < *limit) && iterator.Next(); count++ {
+		asset := iterator.Asset()
+		if encoder != nil {
+			if err = encoder.Encode(asset); err != nil {
+				return err
+			}
+			continue
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%v\t%s\t%s\t%s\n", asset.ID, asset.Type, asset.Name, asset.Size,
+			asset.Project, tagsFlag(asset.Tags).String(), asset.CreatedAt.Local().Format(time.RFC3339))
+	}
+	if writer != nil {
+		writer.Flush()
+	}
+	return iterator.Err()
+}
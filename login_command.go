@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+)
+
+// loginCommand is a function responsible for storing the credentials of a cluster under a profile
+// with -issuer the OAuth2 device flow is used, otherwise a static token
+// the static token is read from stdin when not given as a flag so it doesn't end up in the shell history,
+// credentials are encrypted with a key derived from the passphrase in VIDERA_CREDENTIALS_KEY
+func loginCommand(args []string) error {
+	flags := flag.NewFlagSet("login", flag.ExitOnError)
+	profile := flags.String("profile", "default", "Name the credentials are stored under")
+	endpoint := flags.String("endpoint", "", "Upload endpoint of the cluster master, empty keeps the configured one")
+	token := flags.String("token", "", "API token of the cluster, read from stdin when empty")
+	issuer := flags.String("issuer", "", "OpenID Connect issuer to log in with the device flow instead of a token")
+	clientID := flags.String("client-id", "videra-cli", "OAuth2 client ID registered with the issuer")
+	scope := flags.String("scope", "openid offline_access", "OAuth2 scopes requested from the issuer")
+	parseFlags(flags, args)
+
+	if err := viderasdk.RequireCredentialsPassphrase(); err != nil {
+		return err
+	}
+
+	vSDK := viderasdk.SDKInstance()
+	var credentials viderasdk.Credentials
+	var err error
+	if *issuer != "" {
+		credentials, err = vSDK.DeviceLogin(*issuer, *clientID, *scope, printDeviceAuthorization)
+	} else {
+		credentials.Token, err = readToken(*token)
+	}
+	if err != nil {
+		return err
+	}
+	credentials.Endpoint = *endpoint
+
+	if err = vSDK.SaveCredentials(*profile, credentials); err != nil {
+		return err
+	}
+
+	log.Println(fmt.Sprintf("Stored credentials for profile %s", *profile))
+	capabilities, err := vSDK.RefreshCapabilities(*profile)
+	if err != nil {
+		log.Println("Unable to get the capabilities of the credentials, actions aren't checked locally:", err)
+	} else if capabilities != nil {
+		log.Println(fmt.Sprintf("Credentials may: %s", strings.Join(capabilities, ", ")))
+	}
+	return nil
+}
+
+// readToken is a function to get the static token from its flag or stdin
+func readToken(token string) (string, error) {
+	if token == "" {
+		fmt.Fprint(os.Stderr, "Token: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		token = strings.TrimSpace(line)
+	}
+	if token == "" {
+		return "", errors.New("Missing token")
+	}
+	return token, nil
+}
+
+// printDeviceAuthorization is a function to tell the user where to approve the device login
+func printDeviceAuthorization(authorization viderasdk.DeviceAuthorization) {
+	if authorization.VerificationURIComplete != "" {
+		fmt.Fprintf(os.Stderr, "Open %s to approve the login (code %s)\n", authorization.VerificationURIComplete, authorization.UserCode)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Open %s and enter the code %s\n", authorization.VerificationURI, authorization.UserCode)
+}
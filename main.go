@@ -1,32 +1,272 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"log"
+	"os"
+	"strings"
+	"time"
 
 	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
 	"github.com/SayedAlesawy/Videra-SDK/utils"
 )
 
+// commandCapabilities Capability each subcommand requires, subcommands missing here only read local state or listings
+// gc requires delete only when it aborts sessions, which it checks itself
+var commandCapabilities = map[string]string{
+	"bundle":   viderasdk.CapabilityUpload,
+	"model":    viderasdk.CapabilityInfer,
+	"selftest": viderasdk.CapabilityUpload,
+	"session":  viderasdk.CapabilityUpload,
+	"update":   viderasdk.CapabilityUpload,
+	"upload":   viderasdk.CapabilityUpload,
+}
+
+// commands Maps each subcommand name to its handler, handlers receive the arguments after the name
+var commands = map[string]func(args []string) error{
+	"audit":          auditCommand,
+	"bundle":         bundleCommand,
+	"cache":          cacheCommand,
+	"catalog":        catalogCommand,
+	"capabilities":   capabilitiesCommand,
+	"config":         configCommand,
+	"debug":          debugCommand,
+	"gc":             gcCommand,
+	"list":           listCommand,
+	"login":          loginCommand,
+	"model":          modelCommand,
+	"selftest":       selftestCommand,
+	"session":        sessionCommand,
+	"state":          stateCommand,
+	"support-bundle": supportBundleCommand,
+	"update":         updateCommand,
+	"upload":         uploadCommand,
+	"usage":          usageCommand,
+	"verify":         verifyCommand,
+}
+
 func main() {
-	videoPath := flag.String("video", "", "Path to video file")
-	modelPath := flag.String("model", "", "Path to model file")
-	configPath := flag.String("config", "", "Path to config file")
-	codePath := flag.String("code", "", "Path to code file")
-	flag.Parse()
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			err := requireCommandCapability(os.Args[1])
+			if err == nil {
+				err = command(os.Args[2:])
+			}
+			if err != nil {
+				log.Println(err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	videoPath := flag.String("video", "", "Path or URL (http, https, s3, gs) to video file")
+	modelPath := flag.String("model", "", "Path or URL to model file")
+	configPath := flag.String("config", "", "Path or URL to config file")
+	codePath := flag.String("code", "", "Path or URL to code file")
+	output := flag.String("output", "human", "Format of the printed result, human or json")
+	skipUploaded := flag.Bool("skip-uploaded", false, "Reuse the IDs of files found unchanged in the local upload cache")
+	debug := flag.Bool("debug", false, "Log every request and chunk")
+	logFile := flag.String("log-file", "", "Write logs to this file, overrides log_file of the config")
+	logFormat := flag.String("log-format", "", "Log format, text or json, overrides log_format of the config")
+	logMaxSize := flag.Int64("log-max-size", 100, "Size in MB after which the log file is rotated, overrides log_max_size of the config")
+	logMaxBackups := flag.Int("log-max-backups", 5, "Number of rotated log files to keep, overrides log_max_backups of the config")
+	logMaxAge := flag.Int("log-max-age", 30, "Days after which rotated log files are removed, overrides log_max_age of the config")
+	profile := flag.String("profile", "", "Use the credentials stored by login for this profile, overrides profile of the config")
+	project := flag.String("project", "", "Apply the defaults of this project of the config, overrides project of the config")
+	var segmentDuration, deadline, fileTimeout utils.Duration
+	var chunkSize utils.Size
+	flag.Var(&chunkSize, "chunk-size", "Size of uploaded chunks, e.g. 4MiB, overrides chunk_size of the config")
+	flag.Var(&segmentDuration, "segment-duration", "Split the video into segments of about this duration, e.g. 10m, uploaded as a linked series")
+	trim := flag.String("trim", "", "Upload only this clip of the video, e.g. 00:05:00-00:07:30")
+	stripAudio := flag.Bool("strip-audio", false, "Drop every audio track of the video before upload")
+	audioTrack := flag.Int("audio-track", 0, "Keep only this audio track of the video, counted from 1")
+	scrubMetadata := flag.Bool("scrub-metadata", false, "Remove metadata such as GPS location and device identifiers from the video")
+	keepMetadata := flag.String("keep-metadata", "", "Comma separated metadata tags kept when scrubbing, e.g. title,creation_time")
+	flag.Var(&deadline, "deadline", "Give up the upload after this duration including retries, e.g. 2h, its session is kept in the journal")
+	flag.Var(&fileTimeout, "file-timeout", "Give up the upload when transferring any one file takes longer than this duration")
+	compression := flag.String("compression", "", "Comma separated codecs offered for chunks in preference order, e.g. zstd,gzip, overrides compression of the config")
+	maxBufferedChunks := flag.Int("max-buffered-chunks", 0, "Chunks read ahead of the network at most, 0 reads each chunk when it is sent")
+	metadata := flag.String("metadata", "", "JSON object of metadata sent with the upload, e.g. '{\"title\": {\"en\": \"Intro\"}}', or @file to read it from a file")
+	onCollision := flag.String("on-collision", "", "rename-suffix, overwrite or fail when the cluster already has a file of the same name, overrides filename_collision of the config")
+	bindInterface := flag.String("bind-interface", "", "Comma separated network interfaces connections are bound to in preference order, e.g. eth0,wwan0, failing over to the next when requests keep failing")
+	precheck := flag.String("precheck", "", "warn or refuse to parse the container and decode a sample of frames of the video before upload, overrides video_precheck of the config")
+	strictFiletype := flag.Bool("strict-filetype", false, "Fail when the video doesn't look like a video container instead of warning")
+	isolation := flag.String("isolation", "", "hardlink or reflink to stage local files before upload so rotating or rewriting them doesn't affect it, overrides source_isolation of the config")
+	requireTLS := flag.Bool("require-tls", false, "Refuse to talk to any master, data node or relay over plain http, see security.require_tls of the config")
+	proposedID := flag.String("id", "", "ID proposed for the upload to clusters letting clients pick it, auto generates a UUIDv7, the cluster has the final say")
+	idNamespace := flag.String("id-namespace", "", "Namespace the proposed ID is prefixed with as namespace/id")
+	metricsFile := flag.String("metrics-file", "", "JSON file of evaluation metrics, e.g. eval.json, attached to the model and listed by model versions")
+	modelCard := flag.String("model-card", "", "Markdown file describing the model, e.g. MODEL_CARD.md, attached to it and printed by model describe")
+	framework := flag.String("framework", "", "Framework of the model whose config schema the master serves, e.g. pytorch, guessed from the model extension by default")
+	skipValidation := flag.Bool("skip-validation", false, "Upload the config without validating it against the schema of the framework")
+	versionOf := flag.String("version-of", "", "ID of a stored model the uploaded model is a new version of")
+	sampleChunks := flag.Float64("debug-sample-chunks", 0, "Fraction of chunks recorded with their hashes and responses in a bundle of the state directory for support, e.g. 0.01")
+	sampleChunkData := flag.Bool("debug-sample-data", false, "Also store raw copies of the chunks sampled by -debug-sample-chunks")
+	allowChanged := flag.Bool("allow-changed", false, "Restart the upload from scratch when a file is modified while uploaded instead of failing")
+	sink := flag.String("sink", "cluster", "Where the upload goes, cluster or local to simulate it against an in-process data node and print what was sent")
+	poolMaxIdle, poolMaxConns, poolIdleTimeout := poolFlags(flag.CommandLine)
+	statsDAddr := flag.String("statsd-addr", "", "host:port of a StatsD/DogStatsD agent, overrides statsd_addr of the config")
+	parseFlags(flag.CommandLine, os.Args[1:])
 
 	flags := []string{*videoPath, *modelPath, *configPath, *codePath}
 	err := utils.ValidateFlags(flags...)
+	if err == nil {
+		err = validateOutputFormat(*output)
+	}
+	if err == nil {
+		err = validateSink(*sink)
+	}
 	if err != nil {
 		flag.PrintDefaults()
 		return
 	}
 
 	vSDK := viderasdk.SDKInstance()
-	err = vSDK.UploadJob(*videoPath, *modelPath, *configPath, *codePath)
+	applyPoolFlags(*poolMaxIdle, *poolMaxConns, *poolIdleTimeout)
+	if *bindInterface != "" {
+		if err = vSDK.BindInterfaces(strings.Split(*bindInterface, ",")); err != nil {
+			log.Println(err)
+			return
+		}
+	}
+	if *debug {
+		utils.SetDebugLogging(true)
+	}
+	// the log flags that were set override the logging of the config field by field, the others keep its values
+	logOptions, logFlagsSet := viderasdk.LogOptions(), false
+	flag.Visit(func(set *flag.Flag) {
+		switch set.Name {
+		case "log-file":
+			logOptions.File = *logFile
+		case "log-format":
+			logOptions.JSON = *logFormat == "json"
+		case "log-max-size":
+			logOptions.MaxSize = *logMaxSize << 20
+		case "log-max-backups":
+			logOptions.MaxBackups = *logMaxBackups
+		case "log-max-age":
+			logOptions.MaxAge = time.Duration(*logMaxAge) * 24 * time.Hour
+		default:
+			return
+		}
+		logFlagsSet = true
+	})
+	if logFlagsSet {
+		if err = utils.SetupLogging(logOptions); err != nil {
+			log.Println(err)
+			return
+		}
+	}
+	if *profile != "" {
+		if err = vSDK.UseProfile(*profile); err != nil {
+			log.Println(err)
+			return
+		}
+	}
+	if *project != "" {
+		if err = vSDK.UseProject(*project); err != nil {
+			log.Println(err)
+			return
+		}
+	}
+	if err = vSDK.RequireCapability(viderasdk.CapabilityUpload); err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+	options := vSDK.ClientOptions()
+	options.SkipUploaded = *skipUploaded
+	options.SegmentDuration = time.Duration(segmentDuration)
+	options.Deadline, options.FileTimeout = time.Duration(deadline), time.Duration(fileTimeout)
+	options.StrictFiletype, options.AllowChanged = *strictFiletype, *allowChanged
+	options.SampleChunks, options.SampleChunkData = *sampleChunks, *sampleChunkData
+	options.RequireTLS = options.RequireTLS || *requireTLS
+	if options.ProposedID, err = resolveProposedID(*proposedID); err != nil {
+		log.Println(err)
+		return
+	}
+	options.IDNamespace = *idNamespace
+	options.MetricsFile, options.ModelCard, options.VersionOf = *metricsFile, *modelCard, *versionOf
+	options.ModelFramework, options.SkipValidation = *framework, *skipValidation
+	if *precheck != "" {
+		options.VideoPrecheck = *precheck
+	}
+	if *isolation != "" {
+		options.SourceIsolation = *isolation
+	}
+	if *onCollision != "" {
+		options.FilenameCollision = *onCollision
+	}
+	if *metadata != "" {
+		if options.Metadata, err = parseMetadata(*metadata); err != nil {
+			log.Println(err)
+			return
+		}
+	}
+	options.MaxBufferedChunks = *maxBufferedChunks
+	if *compression != "" {
+		options.Compression = strings.Split(*compression, ",")
+	}
+	options.StripAudio, options.AudioTrack = *stripAudio, *audioTrack
+	options.ScrubMetadata = *scrubMetadata
+	if *keepMetadata != "" {
+		options.KeepMetadata = strings.Split(*keepMetadata, ",")
+	}
+	if *trim != "" {
+		start, end, err := utils.ParseTimeRange(*trim)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		options.Trim = &viderasdk.TimeRange{Start: start, End: end}
+	}
+	if *statsDAddr != "" {
+		options.Metrics, err = viderasdk.NewStatsDEmitter(*statsDAddr)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+	}
+	vSDK.SetClientOptions(options)
+	if chunkSize > 0 {
+		vSDK.SetChunkSize(int64(chunkSize))
+	}
+	if *sink == "local" {
+		localSink, stop, err := startLocalSink(vSDK)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		defer stop()
+		_, err = vSDK.UploadJob(*videoPath, *modelPath, *configPath, *codePath)
+		if err != nil {
+			log.Println(err)
+		}
+		printSinkReport(*output, localSink.Requests())
+		return
+	}
+	result, err := vSDK.UploadJob(*videoPath, *modelPath, *configPath, *codePath)
 	if err == nil {
 		log.Println("Job submitted successfully!")
+		printJobResult(*output, result)
+	} else if errors.Is(err, viderasdk.ErrFileTooLarge) || errors.Is(err, viderasdk.ErrHookFailed) ||
+		errors.Is(err, utils.ErrFFmpegNotFound) || errors.Is(err, viderasdk.ErrDeadlineExceeded) ||
+		errors.Is(err, viderasdk.ErrFiletypeMismatch) || errors.Is(err, viderasdk.ErrFilenameExists) ||
+		errors.Is(err, viderasdk.ErrMissingCapability) || errors.Is(err, viderasdk.ErrInsecureTransport) ||
+		errors.Is(err, viderasdk.ErrSourceChanged) || errors.Is(err, viderasdk.ErrInvalidCompanion) ||
+		errors.Is(err, viderasdk.ErrInvalidConfig) || errors.Is(err, viderasdk.ErrCorruptVideo) {
+		log.Println(err)
 	} else {
 		log.Println("An error has occured, please try again later.")
 	}
 }
+
+// requireCommandCapability is a function to refuse a subcommand the credentials in use can't perform
+func requireCommandCapability(name string) error {
+	capability, ok := commandCapabilities[name]
+	if !ok {
+		return nil
+	}
+	return viderasdk.SDKInstance().RequireCapability(capability)
+}
@@ -0,0 +1,25 @@
+package metrics
+
+import "time"
+
+// Emitter An interface for metrics backends the SDK reports counters, timings and gauges to
+type Emitter interface {
+	// Count adds value to the counter name
+	Count(name string, value int64, tags map[string]string)
+	// Timing records a duration sample of the timer name
+	Timing(name string, value time.Duration, tags map[string]string)
+	// Gauge sets the current value of the gauge name
+	Gauge(name string, value float64, tags map[string]string)
+}
+
+// NoopEmitter An emitter discarding every metric, used when no metrics backend is configured
+type NoopEmitter struct{}
+
+// Count discards the counter
+func (NoopEmitter) Count(name string, value int64, tags map[string]string) {}
+
+// Timing discards the timing
+func (NoopEmitter) Timing(name string, value time.Duration, tags map[string]string) {}
+
+// Gauge discards the gauge
+func (NoopEmitter) Gauge(name string, value float64, tags map[string]string) {}
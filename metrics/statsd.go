@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StatsDEmitter An emitter sending metrics over UDP in the StatsD line format with DogStatsD tags
+type StatsDEmitter struct {
+	conn   net.Conn //UDP connection to the StatsD agent
+	prefix string   //Prefix prepended to every metric name
+}
+
+// NewStatsDEmitter is a function that returns an emitter sending metrics to the StatsD agent at addr
+func NewStatsDEmitter(addr string, prefix string) (*StatsDEmitter, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StatsDEmitter{conn: conn, prefix: prefix}, nil
+}
+
+// Count sends a counter increment
+func (emitter *StatsDEmitter) Count(name string, value int64, tags map[string]string) {
+	emitter.send(name, fmt.Sprintf("%v|c", value), tags)
+}
+
+// Timing sends a timing in milliseconds
+func (emitter *StatsDEmitter) Timing(name string, value time.Duration, tags map[string]string) {
+	emitter.send(name, fmt.Sprintf("%v|ms", float64(value)/float64(time.Millisecond)), tags)
+}
+
+// Gauge sends a gauge value
+func (emitter *StatsDEmitter) Gauge(name string, value float64, tags map[string]string) {
+	emitter.send(name, fmt.Sprintf("%v|g", value), tags)
+}
+
+// Close closes the connection to the StatsD agent
+func (emitter *StatsDEmitter) Close() error {
+	return emitter.conn.Close()
+}
+
+// send writes one metric line, metrics are best effort so write errors are ignored
+func (emitter *StatsDEmitter) send(name string, value string, tags map[string]string) {
+	line := fmt.Sprintf("%s%s:%s", emitter.prefix, name, value)
+	if len(tags) > 0 {
+		var pairs []string
+		for key, tagValue := range tags {
+			pairs = append(pairs, fmt.Sprintf("%s:%s", key, tagValue))
+		}
+		sort.Strings(pairs)
+		line = fmt.Sprintf("%s|#%s", line, strings.Join(pairs, ","))
+	}
+
+	emitter.conn.Write([]byte(line))
+}
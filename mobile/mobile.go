@@ -0,0 +1,173 @@
+package mobile
+
+// the bindings are built with gomobile, see golang.org/x/mobile/cmd/gomobile, into build/ by go generate ./mobile
+//go:generate gomobile bind -target=android -javapkg=com.videra -o ../build/videra.aar .
+//go:generate gomobile bind -target=ios,iossimulator,macos -prefix=Videra -o ../build/Videra.xcframework .
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/SayedAlesawy/Videra-SDK/config"
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+)
+
+// ProgressListener Receives the progress of uploads, implemented by the host app
+type ProgressListener interface {
+	OnProgress(name string, done int64, total int64)
+}
+
+// WarningListener Receives the non-fatal anomalies of uploads such as offset corrections and failovers, implemented by the host app
+type WarningListener interface {
+	OnWarning(kind string, message string)
+}
+
+// Client A façade of the SDK for gomobile bindings, e.g. in Android industrial devices and iOS apps acting as edge gateways
+// its signatures only use types gomobile can bind, strings, integers, booleans, errors and structs of them, and callbacks
+// are listener objects. Uploads block until they complete, host apps call them from a background thread
+type Client struct {
+	sdk *viderasdk.VideraSDK
+}
+
+// Result Describes a completed upload
+type Result struct {
+	ID             string //ID assigned to the upload by the data node
+	BytesSent      int64  //Payload bytes transmitted, including failed attempts
+	DurationMillis int64  //Milliseconds from the first attempt until completion
+	DataNode       string //Upload URL of the data node that accepted the upload
+	Checksum       string //Hex encoded sha256 digest of the uploaded content
+	Retries        int    //Number of failed attempts before the successful one
+}
+
+// JobResult Describes a completed job upload
+type JobResult struct {
+	Model *Result //Result of the model upload
+	Video *Result //Result of the video upload
+}
+
+// NewClient is a function that returns the client of the SDK reading sdk_config.yaml from configDir,
+// e.g. a directory of the app the config was copied to from its assets, the SDK is a singleton so every client shares it
+// and a config that can't be read fails every later client too, instead of panicking in the host app like SDKInstance
+func NewClient(configDir string) (client *Client, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			client, err = nil, fmt.Errorf("Unable to read the config of %s: %v", configDir, recovered)
+		}
+	}()
+
+	config.DefaultConfigFilesDir = filepath.Clean(configDir)
+	sdk := viderasdk.SDKInstance()
+	if sdk == nil {
+		return nil, errors.New("The SDK failed to start with an earlier config")
+	}
+	return &Client{sdk: sdk}, nil
+}
+
+// SetMasterURL is a function to set the upload endpoint of the cluster master, overriding name_node_endpoint of the config
+func (client *Client) SetMasterURL(url string) {
+	client.sdk.SetMasterURL(url)
+}
+
+// SetStateDir is a function to set the directory holding local state such as the upload cache, e.g. the files directory of the app
+func (client *Client) SetStateDir(dir string) {
+	client.sdk.SetStateDir(dir)
+}
+
+// SetChunkSize is a function to set the size of uploaded chunks in bytes, overriding chunk_size of the config
+func (client *Client) SetChunkSize(size int64) {
+	client.sdk.SetChunkSize(size)
+}
+
+// SetRateLimit is a function to set the bytes per second files are read for upload at most, 0 leaves it unlimited
+func (client *Client) SetRateLimit(bytesPerSecond int64) {
+	options := client.sdk.ClientOptions()
+	options.RateLimit = bytesPerSecond
+	client.sdk.SetClientOptions(options)
+}
+
+// SetTag is a function to add a tag sent with every upload, an empty value removes it
+func (client *Client) SetTag(key string, value string) {
+	options := client.sdk.ClientOptions()
+	tags := make(map[string]string, len(options.Tags)+1)
+	for existingKey, existingValue := range options.Tags {
+		tags[existingKey] = existingValue
+	}
+	if value == "" {
+		delete(tags, key)
+	} else {
+		tags[key] = value
+	}
+	options.Tags = tags
+	client.sdk.SetClientOptions(options)
+}
+
+// SetProgressListener is a function to set the listener receiving the progress of uploads, nil removes it
+func (client *Client) SetProgressListener(listener ProgressListener) {
+	options := client.sdk.ClientOptions()
+	options.OnProgress = nil
+	if listener != nil {
+		options.OnProgress = listener.OnProgress
+	}
+	client.sdk.SetClientOptions(options)
+}
+
+// SetWarningListener is a function to set the listener receiving the warnings of uploads, nil removes it
+func (client *Client) SetWarningListener(listener WarningListener) {
+	options := client.sdk.ClientOptions()
+	options.OnWarning = nil
+	if listener != nil {
+		options.OnWarning = func(warning viderasdk.Warning) {
+			listener.OnWarning(warning.Kind, warning.Message)
+		}
+	}
+	client.sdk.SetClientOptions(options)
+}
+
+// UploadVideo is a function responsible for uploading a video for the model with the given ID
+func (client *Client) UploadVideo(videoPath string, associatedModelID string) (*Result, error) {
+	result, err := client.sdk.UploadVideo(videoPath, associatedModelID)
+	if err != nil {
+		return nil, err
+	}
+	return newResult(result), nil
+}
+
+// UploadModel is a function responsible for uploading a model with its config and code
+func (client *Client) UploadModel(modelPath string, configPath string, codePath string) (*Result, error) {
+	result, err := client.sdk.UploadModel(modelPath, configPath, codePath)
+	if err != nil {
+		return nil, err
+	}
+	return newResult(result), nil
+}
+
+// UploadJob is a function responsible for uploading a model and a video for it
+func (client *Client) UploadJob(videoPath string, modelPath string, configPath string, codePath string) (*JobResult, error) {
+	result, err := client.sdk.UploadJob(videoPath, modelPath, configPath, codePath)
+	if err != nil {
+		return nil, err
+	}
+	return &JobResult{Model: newResult(result.Model), Video: newResult(result.Video)}, nil
+}
+
+// UploadToSignedURL is a function responsible for uploading a video to a presigned data node URL of a session created out of band
+func (client *Client) UploadToSignedURL(videoPath string, signedURL string) (*Result, error) {
+	result, err := client.sdk.UploadToSignedURL(videoPath, signedURL)
+	if err != nil {
+		return nil, err
+	}
+	return newResult(result), nil
+}
+
+// newResult is a function to convert the result of an upload to its bindable form
+func newResult(result viderasdk.UploadResult) *Result {
+	return &Result{
+		ID:             result.ID,
+		BytesSent:      result.BytesSent,
+		DurationMillis: result.Duration.Milliseconds(),
+		DataNode:       result.DataNode,
+		Checksum:       result.Checksum,
+		Retries:        result.Retries,
+	}
+}
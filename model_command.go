@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+)
+
+// modelCommand is a function responsible for the model subcommands
+// model pull downloads the artifacts of a stored model for local inference,
+// model versions lists the versions of a model with their evaluation metrics,
+// model describe prints the model card of a model version,
+// model update-config uploads a new config of a model as a minor version reusing its stored model and code
+func modelCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("Missing model subcommand, expected pull, versions, describe or update-config")
+	}
+
+	switch args[0] {
+	case "pull":
+		return modelPullCommand(args[1:])
+	case "versions":
+		return modelVersionsCommand(args[1:])
+	case "describe":
+		return modelDescribeCommand(args[1:])
+	case "update-config":
+		return modelUpdateConfigCommand(args[1:])
+	}
+	return fmt.Errorf("Unknown model subcommand %s, expected pull, versions, describe or update-config", args[0])
+}
+
+// modelPullCommand is a function responsible for downloading the artifacts of a stored model for local inference
+func modelPullCommand(args []string) error {
+	flags := flag.NewFlagSet("model pull", flag.ExitOnError)
+	dir := flags.String("out", ".", "Directory the artifacts are placed in")
+	output := flags.String("output", "human", "Format of the printed manifest, human or json")
+	flags.Usage = func() {
+		log.Println("Usage: model pull [-out dir] [-output human|json] <model id>")
+	}
+	parseFlags(flags, args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("Missing model ID")
+	}
+	if err := validateOutputFormat(*output); err != nil {
+		return err
+	}
+
+	manifest, err := viderasdk.SDKInstance().PullModel(flags.Arg(0), *dir)
+	if err != nil {
+		return err
+	}
+
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(manifest)
+	}
+	for _, artifact := range manifest.Artifacts {
+		fmt.Printf("%s: %s (%v bytes, %s)\n", artifact.Name, artifact.Filename, artifact.Size, artifact.Checksum)
+	}
+	return nil
+}
+
+// modelVersionsCommand is a function responsible for listing the versions of a model side by side with their evaluation metrics
+func modelVersionsCommand(args []string) error {
+	flags := flag.NewFlagSet("model versions", flag.ExitOnError)
+	output := flags.String("output", "human", "Format of the printed versions, human or json")
+	flags.Usage = func() {
+		log.Println("Usage: model versions [-output human|json] <model id>")
+	}
+	parseFlags(flags, args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("Missing model ID")
+	}
+	if err := validateOutputFormat(*output); err != nil {
+		return err
+	}
+
+	versions, err := viderasdk.SDKInstance().ModelVersions(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(versions)
+	}
+
+	// every metric any version has gets a column so versions compare at a glance
+	var metrics []string
+	seen := make(map[string]bool)
+	for _, version := range versions {
+		for name := range version.Metrics {
+			if !seen[name] {
+				seen[name] = true
+				metrics = append(metrics, name)
+			}
+		}
+	}
+	sort.Strings(metrics)
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(writer, "VERSION\tID\tUPLOADED")
+	for _, name := range metrics {
+		fmt.Fprintf(writer, "\t%s", strings.ToUpper(name))
+	}
+	fmt.Fprintln(writer)
+	for _, version := range versions {
+		fmt.Fprintf(writer, "%s\t%s\t%s", version.Version, version.ID, version.UploadedAt.Local().Format("2006-01-02 15:04"))
+		for _, name := range metrics {
+			fmt.Fprintf(writer, "\t%s", formatMetric(version.Metrics[name]))
+		}
+		fmt.Fprintln(writer)
+	}
+	return writer.Flush()
+}
+
+// modelDescribeCommand is a function responsible for printing the model card attached to a model version
+func modelDescribeCommand(args []string) error {
+	flags := flag.NewFlagSet("model describe", flag.ExitOnError)
+	flags.Usage = func() {
+		log.Println("Usage: model describe <model id>")
+	}
+	parseFlags(flags, args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("Missing model ID")
+	}
+
+	card, err := viderasdk.SDKInstance().ModelCard(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	fmt.Print(card)
+	if !strings.HasSuffix(card, "\n") {
+		fmt.Println()
+	}
+	return nil
+}
+
+// modelUpdateConfigCommand is a function responsible for uploading only a new config of a stored model
+func modelUpdateConfigCommand(args []string) error {
+	flags := flag.NewFlagSet("model update-config", flag.ExitOnError)
+	configPath := flags.String("config", "", "Path or URL to the new config file")
+	framework := flags.String("framework", "", "Framework of the model whose config schema the master serves, guessed from the model extension by default")
+	skipValidation := flags.Bool("skip-validation", false, "Upload the config without validating it against the schema of the framework")
+	output := flags.String("output", "human", "Format of the printed result, human or json")
+	flags.Usage = func() {
+		log.Println("Usage: model update-config <model id> -config <config file> [-framework name] [-skip-validation] [-output human|json]")
+	}
+
+	// the ID comes first on the command line, flags follow it
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		flags.Usage()
+		return errors.New("Missing model ID")
+	}
+	parseFlags(flags, args[1:])
+	if *configPath == "" {
+		flags.Usage()
+		return errors.New("Missing config file")
+	}
+	if err := validateOutputFormat(*output); err != nil {
+		return err
+	}
+
+	vSDK := viderasdk.SDKInstance()
+	options := vSDK.ClientOptions()
+	options.ModelFramework, options.SkipValidation = *framework, *skipValidation
+	vSDK.SetClientOptions(options)
+	result, err := vSDK.UpdateModelConfig(args[0], *configPath)
+	if err != nil {
+		return err
+	}
+	log.Println("Config submitted successfully!")
+	return printSingleResult(*output, "Model", result)
+}
+
+// formatMetric is a function to format an evaluation metric for a table cell, nested values are printed as JSON
+func formatMetric(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "-"
+	case string, float64, bool:
+		return fmt.Sprintf("%v", value)
+	}
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+)
+
+// outputFormats Formats accepted by the -output flag
+var outputFormats = map[string]bool{"human": true, "json": true}
+
+// validateOutputFormat is a function to check that an -output flag value is supported
+func validateOutputFormat(format string) error {
+	if !outputFormats[format] {
+		return errors.New("Unknown output format, expected human or json")
+	}
+	return nil
+}
+
+// printJobResult is a function responsible for printing a job result to stdout in the given format
+func printJobResult(format string, result viderasdk.JobResult) error {
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	printUploadResult("Model", result.Model)
+	printUploadResult("Video", result.Video)
+	for idx, segment := range result.Segments {
+		printUploadResult(fmt.Sprintf("Segment %v", idx), segment)
+	}
+	return nil
+}
+
+// printSingleResult is a function responsible for printing the result of a single upload to stdout in the given format
+func printSingleResult(format string, title string, result viderasdk.UploadResult) error {
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	printUploadResult(title, result)
+	return nil
+}
+
+// printUploadResult is a function responsible for printing a single upload result in human readable form
+func printUploadResult(title string, result viderasdk.UploadResult) {
+	fmt.Printf("%s ID:    %s\n", title, result.ID)
+	fmt.Printf("  Bytes sent: %v\n", result.BytesSent)
+	fmt.Printf("  Duration:   %v\n", result.Duration)
+	fmt.Printf("  Data node:  %s\n", result.DataNode)
+	fmt.Printf("  Checksum:   %s\n", result.Checksum)
+	fmt.Printf("  Retries:    %v\n", result.Retries)
+	if result.ProposedID != "" && result.ProposedID != result.ID {
+		fmt.Printf("  Proposed:   %s\n", result.ProposedID)
+	}
+	if len(result.ScrubbedMetadata) > 0 {
+		fmt.Printf("  Scrubbed:   %s\n", strings.Join(result.ScrubbedMetadata, ", "))
+	}
+	if latency := result.ChunkLatency; latency != nil {
+		fmt.Printf("  Latency:    p50 %.1fms, p95 %.1fms, p99 %.1fms over %v chunks\n", latency.P50, latency.P95, latency.P99, latency.Count)
+	}
+	if throughput := result.ChunkThroughput; throughput != nil {
+		fmt.Printf("  Throughput: p50 %.1fMB/s, p95 %.1fMB/s, p99 %.1fMB/s\n", throughput.P50/1e6, throughput.P95/1e6, throughput.P99/1e6)
+	}
+}
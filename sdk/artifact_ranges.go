@@ -0,0 +1,110 @@
+package viderasdk
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// artifactRanges Describes the byte ranges of an artifact already written to its partial file,
+// persisted next to it so an interrupted pull resumes the ranges it didn't complete
+type artifactRanges struct {
+	Size     int64      `json:"size"`     //Size of the artifact the ranges belong to
+	Checksum string     `json:"checksum"` //Checksum of the artifact the ranges belong to, a new version starts over
+	Done     [][2]int64 `json:"done"`     //Sorted and merged inclusive ranges written to the partial file
+}
+
+// rangesPath is a function that returns the path the range map of a partial file is persisted at
+func rangesPath(partPath string) string {
+	return partPath + ".ranges"
+}
+
+// loadArtifactRanges is a function to read the range map of the partial file of artifact
+// partial files left by pulls downloading sequentially have no range map and hold a prefix of the artifact,
+// partial files of another version of the artifact are truncated and start over
+func loadArtifactRanges(partFile *os.File, partPath string, artifact ModelArtifact) (artifactRanges, error) {
+	ranges := artifactRanges{Size: artifact.Size, Checksum: artifact.Checksum}
+	fileInfo, err := partFile.Stat()
+	if err != nil {
+		return ranges, err
+	}
+
+	content, err := ioutil.ReadFile(rangesPath(partPath))
+	if os.IsNotExist(err) && fileInfo.Size() <= artifact.Size {
+		if fileInfo.Size() > 0 {
+			ranges.add(0, fileInfo.Size()-1)
+		}
+		return ranges, nil
+	}
+
+	var stored artifactRanges
+	if err == nil && json.Unmarshal(content, &stored) == nil &&
+		stored.Size == artifact.Size && stored.Checksum == artifact.Checksum && fileInfo.Size() <= artifact.Size {
+		return stored, nil
+	}
+	return ranges, partFile.Truncate(0)
+}
+
+// save is a function responsible for persisting the range map of a partial file, replacing it atomically
+func (ranges artifactRanges) save(partPath string) error {
+	content, err := json.Marshal(ranges)
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(rangesPath(partPath), content)
+}
+
+// add is a function responsible for recording that the inclusive range start-end was written, merging it with adjacent ranges
+func (ranges *artifactRanges) add(start int64, end int64) {
+	done := append(ranges.Done, [2]int64{start, end})
+	sort.Slice(done, func(i, j int) bool { return done[i][0] < done[j][0] })
+
+	merged := [][2]int64{}
+	for _, span := range done {
+		if last := len(merged) - 1; last >= 0 && span[0] <= merged[last][1]+1 {
+			if span[1] > merged[last][1] {
+				merged[last][1] = span[1]
+			}
+			continue
+		}
+		merged = append(merged, span)
+	}
+	ranges.Done = merged
+}
+
+// written is a function that returns the number of bytes of the artifact already written
+func (ranges artifactRanges) written() int64 {
+	written := int64(0)
+	for _, span := range ranges.Done {
+		written += span[1] - span[0] + 1
+	}
+	return written
+}
+
+// missing is a function that returns the inclusive ranges of the artifact not written yet, split in ranges of at most rangeSize bytes
+func (ranges artifactRanges) missing(rangeSize int64) [][2]int64 {
+	if rangeSize <= 0 {
+		rangeSize = ranges.Size
+	}
+	var missing [][2]int64
+	gap := func(start int64, end int64) {
+		for ; start <= end; start += rangeSize {
+			rangeEnd := start + rangeSize - 1
+			if rangeEnd > end {
+				rangeEnd = end
+			}
+			missing = append(missing, [2]int64{start, rangeEnd})
+		}
+	}
+
+	next := int64(0)
+	for _, span := range ranges.Done {
+		gap(next, span[0]-1)
+		next = span[1] + 1
+	}
+	gap(next, ranges.Size-1)
+	return missing
+}
@@ -0,0 +1,165 @@
+package viderasdk
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// auditLogFile Name of the audit log inside the state directory, one JSON record per line
+const auditLogFile = "audit.log"
+
+// auditMutex Serializes appends to the audit log so every record links to the one before it
+var auditMutex sync.Mutex
+
+// ErrAuditLogTampered Returned when a record of the audit log was changed, removed or inserted
+var ErrAuditLogTampered = errors.New("Audit log was tampered with")
+
+// AuditRecord Describes a completed upload in the audit log
+// each record carries the hash of the previous one, so changing any record breaks the chain from there on
+type AuditRecord struct {
+	Seq      int64                   `json:"seq"`               //Position of the record in the log, counted from 1
+	Time     time.Time               `json:"time"`              //Time at which the upload completed
+	User     string                  `json:"user"`              //Local user who ran the upload
+	Host     string                  `json:"host"`              //Machine the upload ran on
+	Profile  string                  `json:"profile,omitempty"` //Profile whose credentials authenticated the upload
+	Cluster  string                  `json:"cluster"`           //Upload endpoint of the cluster master
+	Filetype string                  `json:"filetype"`          //Type of the upload, job, model or video
+	Files    map[string]string       `json:"files"`             //Path or URL of each artifact keyed by artifact name
+	Results  map[string]UploadResult `json:"results"`           //Result of each completed upload keyed by model or video
+	PrevHash string                  `json:"prev_hash"`         //Hash of the previous record, empty for the first one
+	Hash     string                  `json:"hash"`              //Hex encoded sha256 digest of the record with an empty hash
+}
+
+// hashAuditRecord is a function to get the hash of a record, the digest of its JSON encoding without the hash itself
+func hashAuditRecord(record AuditRecord) (string, error) {
+	record.Hash = ""
+	content, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha256.Sum256(content)
+	return hex.EncodeToString(digest[:]), nil
+}
+
+// AuditLog is a function to read the records of the audit log in order
+func (sdk VideraSDK) AuditLog() ([]AuditRecord, error) {
+	var records []AuditRecord
+	file, err := os.Open(filepath.Join(sdk.stateDir, auditLogFile))
+	if os.IsNotExist(err) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var record AuditRecord
+		if err = json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return records, fmt.Errorf("%w: line %v isn't a record: %v", ErrAuditLogTampered, line, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// VerifyAuditLog is a function to check the hash chain of the audit log, it returns the number of intact records
+// records removed from the end of the log leave an intact chain, compare the count with the one expected to detect that
+func (sdk VideraSDK) VerifyAuditLog() (int, error) {
+	records, err := sdk.AuditLog()
+	if err != nil {
+		return len(records), err
+	}
+
+	prevHash := ""
+	for idx, record := range records {
+		hash, err := hashAuditRecord(record)
+		if err != nil {
+			return idx, err
+		}
+		if record.Seq != int64(idx+1) {
+			return idx, fmt.Errorf("%w: record %v has sequence number %v", ErrAuditLogTampered, idx+1, record.Seq)
+		}
+		if record.PrevHash != prevHash {
+			return idx, fmt.Errorf("%w: record %v doesn't link to the previous record", ErrAuditLogTampered, record.Seq)
+		}
+		if record.Hash != hash {
+			return idx, fmt.Errorf("%w: record %v doesn't match its hash", ErrAuditLogTampered, record.Seq)
+		}
+		prevHash = record.Hash
+	}
+	return len(records), nil
+}
+
+// auditUpload is a function responsible for appending a completed upload to the audit log when audit_log is set
+// failures are only logged as the upload already completed
+func (sdk VideraSDK) auditUpload(filetype string, files map[string]string, results map[string]UploadResult) {
+	if !sdk.auditLog {
+		return
+	}
+
+	if err := sdk.appendAuditRecord(filetype, files, results); err != nil {
+		log.Println("Unable to record upload in audit log:", err)
+	}
+}
+
+// appendAuditRecord is a function responsible for appending a record linked to the last one of the audit log
+func (sdk VideraSDK) appendAuditRecord(filetype string, files map[string]string, results map[string]UploadResult) error {
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+
+	records, err := sdk.AuditLog()
+	if err != nil {
+		return err
+	}
+
+	record := AuditRecord{Seq: 1, Time: time.Now().UTC(), Cluster: sdk.masterURL, Filetype: filetype, Files: files, Results: results}
+	if len(records) > 0 {
+		last := records[len(records)-1]
+		record.Seq, record.PrevHash = last.Seq+1, last.Hash
+	}
+	if current, err := user.Current(); err == nil {
+		record.User = current.Username
+	}
+	record.Host, _ = os.Hostname()
+	if sdk.credentials != nil {
+		record.Profile = sdk.credentials.profile
+	}
+	if record.Hash, err = hashAuditRecord(record); err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(sdk.stateDir, 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filepath.Join(sdk.stateDir, auditLogFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,277 @@
+package viderasdk
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// bundleManifestName Name of the manifest entry inside a bundle
+const bundleManifestName = "manifest.json"
+
+// bundleVersion Version of the bundle layout written by this SDK
+const bundleVersion = 1
+
+// bundleArtifactsOrder Order in which job artifacts are stored inside a bundle
+var bundleArtifactsOrder = []string{"video", "model", "config", "code"}
+
+// CreateBundle is a function responsible for packaging job artifacts, their checksums and a manifest
+// into a single tar file that can be pushed later from a machine with connectivity
+func CreateBundle(bundlePath string, videoPath string, modelPath string, configPath string, codePath string) error {
+	artifactsPaths := map[string]string{
+		"video":  videoPath,
+		"model":  modelPath,
+		"config": configPath,
+		"code":   codePath,
+	}
+
+	manifest := bundleManifest{Version: bundleVersion, CreatedAt: time.Now().UTC()}
+	for _, name := range bundleArtifactsOrder {
+		artifactPath := artifactsPaths[name]
+		size, err := utils.GetFileSize(artifactPath)
+		if err != nil {
+			return err
+		}
+		checksum, err := utils.GetFileChecksum(artifactPath)
+		if err != nil {
+			return err
+		}
+
+		manifest.Artifacts = append(manifest.Artifacts, bundleArtifact{
+			Name:     name,
+			Path:     path.Join("artifacts", name, utils.NormalizeFilename(artifactPath)),
+			Size:     size,
+			Checksum: checksum,
+		})
+	}
+
+	manifestContent, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	bundleFile, err := os.Create(bundlePath)
+	if err != nil {
+		return err
+	}
+	defer bundleFile.Close()
+
+	writer := tar.NewWriter(bundleFile)
+	err = writer.WriteHeader(&tar.Header{
+		Name:    bundleManifestName,
+		Mode:    0644,
+		Size:    int64(len(manifestContent)),
+		ModTime: manifest.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err = writer.Write(manifestContent); err != nil {
+		return err
+	}
+
+	for _, artifact := range manifest.Artifacts {
+		err = writeBundleEntry(writer, artifact, artifactsPaths[artifact.Name])
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = writer.Close(); err != nil {
+		return err
+	}
+
+	log.Println(fmt.Sprintf("%s Created bundle %s", logPrefix, bundlePath))
+	return bundleFile.Close()
+}
+
+// writeBundleEntry is a function responsible for copying one artifact into the bundle
+func writeBundleEntry(writer *tar.Writer, artifact bundleArtifact, artifactPath string) error {
+	file, err := os.Open(artifactPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	err = writer.WriteHeader(&tar.Header{
+		Name:    artifact.Path,
+		Mode:    0644,
+		Size:    artifact.Size,
+		ModTime: time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	written, err := io.Copy(writer, file)
+	if err != nil {
+		return err
+	}
+	if written != artifact.Size {
+		return fmt.Errorf("File %s changed while bundling", artifactPath)
+	}
+
+	return nil
+}
+
+// PushBundle is a function responsible for unpacking a bundle created by CreateBundle,
+// verifying its checksums and uploading its artifacts as a job
+func (sdk VideraSDK) PushBundle(bundlePath string) (JobResult, error) {
+	stagingDir, err := ioutil.TempDir("", "videra-bundle-")
+	if err != nil {
+		return JobResult{}, err
+	}
+	defer os.RemoveAll(stagingDir)
+
+	artifactsPaths, err := extractBundle(bundlePath, stagingDir)
+	if err != nil {
+		return JobResult{}, err
+	}
+
+	return sdk.UploadJob(artifactsPaths["video"], artifactsPaths["model"], artifactsPaths["config"], artifactsPaths["code"])
+}
+
+// extractBundle is a function responsible for extracting and verifying bundle artifacts into a directory
+// returns the extracted path of each artifact keyed by artifact name
+func extractBundle(bundlePath string, dir string) (map[string]string, error) {
+	bundleFile, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	defer bundleFile.Close()
+
+	reader := tar.NewReader(bundleFile)
+	header, err := reader.Next()
+	if err != nil {
+		return nil, err
+	}
+	if header.Name != bundleManifestName {
+		return nil, errors.New("Bundle is missing its manifest")
+	}
+
+	var manifest bundleManifest
+	if err = json.NewDecoder(reader).Decode(&manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Version != bundleVersion {
+		return nil, fmt.Errorf("Unsupported bundle version %v", manifest.Version)
+	}
+
+	// names and paths come from the bundle, only the known artifacts are extracted, each once and inside dir
+	artifacts := make(map[string]bundleArtifact)
+	names := make(map[string]bool)
+	for _, artifact := range manifest.Artifacts {
+		if !bundleArtifactName(artifact.Name) {
+			return nil, fmt.Errorf("Bundle lists unknown artifact %q", artifact.Name)
+		}
+		if names[artifact.Name] {
+			return nil, fmt.Errorf("Bundle lists the %s artifact more than once", artifact.Name)
+		}
+		if _, ok := artifacts[artifact.Path]; ok {
+			return nil, fmt.Errorf("Bundle lists entry %s more than once", artifact.Path)
+		}
+		if bundleEntryFilename(artifact.Path) == "" {
+			return nil, fmt.Errorf("Bundle entry %q of the %s artifact isn't a file", artifact.Path, artifact.Name)
+		}
+		names[artifact.Name] = true
+		artifacts[artifact.Path] = artifact
+	}
+
+	artifactsPaths := make(map[string]string)
+	for {
+		header, err = reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		artifact, ok := artifacts[header.Name]
+		if !ok {
+			return nil, fmt.Errorf("Bundle entry %s is not listed in the manifest", header.Name)
+		}
+		if _, ok = artifactsPaths[artifact.Name]; ok {
+			return nil, fmt.Errorf("Bundle holds entry %s more than once", header.Name)
+		}
+
+		// each artifact gets its own directory so that the original file name is kept
+		artifactDir := filepath.Join(dir, artifact.Name)
+		if err = os.MkdirAll(artifactDir, 0755); err != nil {
+			return nil, err
+		}
+		artifactPath := filepath.Join(artifactDir, bundleEntryFilename(artifact.Path))
+		if rel, err := filepath.Rel(artifactDir, artifactPath); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("Bundle entry %q of the %s artifact extracts outside its directory", artifact.Path, artifact.Name)
+		}
+		if err = extractBundleEntry(reader, artifact, artifactPath); err != nil {
+			return nil, err
+		}
+		artifactsPaths[artifact.Name] = artifactPath
+	}
+
+	for _, name := range bundleArtifactsOrder {
+		if _, ok := artifactsPaths[name]; !ok {
+			return nil, fmt.Errorf("Bundle is missing the %s artifact", name)
+		}
+	}
+
+	return artifactsPaths, nil
+}
+
+// bundleArtifactName is a function to check name is one of the artifacts a bundle stores, see bundleArtifactsOrder
+func bundleArtifactName(name string) bool {
+	for _, artifact := range bundleArtifactsOrder {
+		if name == artifact {
+			return true
+		}
+	}
+	return false
+}
+
+// bundleEntryFilename is a function that returns the name a bundle entry is extracted under, the last element of its slash separated path
+// returns an empty string for entries that aren't a plain file name on every platform, e.g. ..\..\evil.exe or C:evil.exe
+func bundleEntryFilename(entryPath string) string {
+	base := path.Base(entryPath)
+	if base == "." || base == ".." || base == "/" || strings.ContainsAny(base, `\:`) || filepath.VolumeName(base) != "" {
+		return ""
+	}
+	if filepath.Base(filepath.FromSlash(entryPath)) != base {
+		return ""
+	}
+	return base
+}
+
+// extractBundleEntry is a function responsible for writing one bundle entry to disk and verifying its checksum
+func extractBundleEntry(reader io.Reader, artifact bundleArtifact, artifactPath string) error {
+	file, err := os.Create(artifactPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(file, hash), reader)
+	if err != nil {
+		return err
+	}
+
+	if written != artifact.Size || hex.EncodeToString(hash.Sum(nil)) != artifact.Checksum {
+		return fmt.Errorf("Checksum mismatch for %s artifact", artifact.Name)
+	}
+
+	return file.Close()
+}
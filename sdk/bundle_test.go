@@ -0,0 +1,29 @@
+package viderasdk
+
+import "testing"
+
+// TestBundleEntryFilename checks only entries naming a plain file on every platform are extracted
+func TestBundleEntryFilename(t *testing.T) {
+	tests := []struct {
+		entry    string
+		filename string
+	}{
+		{"artifacts/video/clip.mp4", "clip.mp4"},
+		{"artifacts/model/model.pt", "model.pt"},
+		{"artifacts/video/../../evil.exe", "evil.exe"},
+		{"artifacts/video/..", ""},
+		{"artifacts/video/", "video"},
+		{"/", ""},
+		{"", ""},
+		{`artifacts/video/..\..\evil.exe`, ""},
+		{`artifacts/video/evil\clip.mp4`, ""},
+		{"artifacts/video/C:evil.exe", ""},
+		{"artifacts/video/clip.mp4:stream", ""},
+	}
+
+	for _, test := range tests {
+		if filename := bundleEntryFilename(test.entry); filename != test.filename {
+			t.Errorf("bundleEntryFilename(%q) = %q, expected %q", test.entry, filename, test.filename)
+		}
+	}
+}
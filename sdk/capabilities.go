@@ -0,0 +1,96 @@
+package viderasdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Capabilities the master may grant to credentials
+const (
+	CapabilityUpload = "upload" //Upload jobs, models and videos and edit their metadata
+	CapabilityDelete = "delete" //Abort sessions and delete stored assets
+	CapabilityInfer  = "infer"  //Download models for inference
+	CapabilityAdmin  = "admin"  //Administer the cluster
+)
+
+// ErrMissingCapability Returned when the credentials in use lack the capability an action requires
+var ErrMissingCapability = errors.New("Missing capability")
+
+// capabilitiesDocument Describes the capabilities document served by the master
+type capabilitiesDocument struct {
+	Capabilities []string `json:"capabilities"` //Capabilities granted to the credentials of the request
+}
+
+// FetchCapabilities is a function to ask the master what the credentials in use may do
+// nil is returned when the master doesn't serve capabilities, in which case nothing is gated
+func (sdk VideraSDK) FetchCapabilities() ([]string, error) {
+	client := sdk.newClient()
+	req, _ := http.NewRequest(http.MethodGet, sdk.master(), nil)
+	req.Header.Set("Request-Type", "CAPABILITIES")
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusNotImplemented {
+		return nil, nil
+	}
+	if err = permissionError(res); err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unable to get capabilities: %s", res.Status)
+	}
+
+	document := capabilitiesDocument{Capabilities: []string{}}
+	err = json.NewDecoder(res.Body).Decode(&document)
+	return document.Capabilities, err
+}
+
+// RefreshCapabilities is a function responsible for fetching the capabilities of a profile and storing them with its credentials
+func (sdk VideraSDK) RefreshCapabilities(profile string) ([]string, error) {
+	if err := sdk.UseProfile(profile); err != nil {
+		return nil, err
+	}
+	capabilities, err := sdk.FetchCapabilities()
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := sdk.LoadCredentials(profile)
+	if err != nil {
+		return nil, err
+	}
+	credentials.Capabilities = capabilities
+	return capabilities, sdk.SaveCredentials(profile, credentials)
+}
+
+// RequireCapability is a function to check that the credentials in use may perform actions requiring capability
+// without a profile or stored capabilities nothing is refused locally and the master decides
+func (sdk VideraSDK) RequireCapability(capability string) error {
+	if sdk.credentials == nil || sdk.credentials.credentials.Capabilities == nil {
+		return nil
+	}
+
+	for _, granted := range sdk.credentials.credentials.Capabilities {
+		if granted == capability || granted == CapabilityAdmin {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: profile %s lacks the %s capability", ErrMissingCapability, sdk.credentials.profile, capability)
+}
+
+// permissionError is a function to get the error of a forbidden response, naming the capability the master reported missing
+func permissionError(res *http.Response) error {
+	if res.StatusCode != http.StatusForbidden {
+		return nil
+	}
+
+	if capability := res.Header.Get("Missing-Capability"); capability != "" {
+		return fmt.Errorf("%w: the credentials lack the %s capability", ErrMissingCapability, capability)
+	}
+	return fmt.Errorf("%w: %s", ErrMissingCapability, res.Status)
+}
@@ -0,0 +1,139 @@
+package viderasdk
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// catalogFile Name of the catalog of completed uploads inside the state directory, one JSON entry per line
+const catalogFile = "catalog.jsonl"
+
+// catalogMutex Serializes appends to the catalog
+var catalogMutex sync.Mutex
+
+// CatalogEntry Describes a completed upload in the local catalog, a read-only mirror of the assets uploaded from this machine
+type CatalogEntry struct {
+	Asset
+	Cluster string `json:"cluster"` //Upload endpoint of the cluster master the upload went to
+}
+
+// catalogUpload is a function responsible for appending completed uploads to the local catalog when catalog is set
+// sizes are those of the local files uploaded, 0 for remote sources, failures are only logged as the upload already completed
+func (sdk VideraSDK) catalogUpload(files map[string]string, results map[string]UploadResult) {
+	if !sdk.catalog {
+		return
+	}
+
+	var lines []byte
+	artifacts := map[string][]string{"model": modelUploadOrder, "video": {"video"}}
+	for _, filetype := range []string{"model", "video"} {
+		result, ok := results[filetype]
+		// results reused from the upload cache were catalogued when first uploaded
+		if !ok || result.Duration == 0 {
+			continue
+		}
+		entry := CatalogEntry{Asset: Asset{ID: result.ID, Type: filetype, Project: sdk.project, Tags: sdk.options.Tags,
+			CreatedAt: time.Now().UTC()}, Cluster: sdk.masterURL}
+		for _, artifact := range artifacts[filetype] {
+			path, ok := files[artifact]
+			if !ok || path == "" {
+				continue
+			}
+			if entry.Name == "" {
+				entry.Name = utils.NormalizeFilename(path)
+			}
+			if info, err := fs.Stat(sdk.fileSystem, path); err == nil {
+				entry.Size += info.Size()
+			}
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			log.Println("Unable to record upload in catalog:", err)
+			return
+		}
+		lines = append(append(lines, line...), '\n')
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	catalogMutex.Lock()
+	defer catalogMutex.Unlock()
+	err := os.MkdirAll(sdk.stateDir, 0700)
+	if err == nil {
+		var file *os.File
+		file, err = os.OpenFile(filepath.Join(sdk.stateDir, catalogFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err == nil {
+			_, err = file.Write(lines)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+	}
+	if err != nil {
+		log.Println("Unable to record upload in catalog:", err)
+	}
+}
+
+// Catalog is a function to list the uploads of the local catalog matching the filter, in the order they completed
+// it never asks the cluster so it works offline, the Sort of the filter is ignored
+func (sdk VideraSDK) Catalog(filter ListFilter) ([]CatalogEntry, error) {
+	var entries []CatalogEntry
+	file, err := os.Open(filepath.Join(sdk.stateDir, catalogFile))
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		var entry CatalogEntry
+		if err = json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// a line cut short by a crash mid append is skipped
+			log.Println(fmt.Sprintf("Skipping line %v of the catalog: %v", line, err))
+			continue
+		}
+		if filter.matches(entry.Asset) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// matches is a function to check whether an asset passes the filter, as the master applies it to listings
+func (filter ListFilter) matches(asset Asset) bool {
+	if filter.Type != "" && asset.Type != filter.Type {
+		return false
+	}
+	if filter.NamePrefix != "" && !strings.HasPrefix(asset.Name, filter.NamePrefix) {
+		return false
+	}
+	if filter.Project != "" && asset.Project != filter.Project {
+		return false
+	}
+	for key, val := range filter.Tags {
+		if asset.Tags[key] != val {
+			return false
+		}
+	}
+	if !filter.CreatedAfter.IsZero() && asset.CreatedAt.Before(filter.CreatedAfter) {
+		return false
+	}
+	if !filter.CreatedBefore.IsZero() && !asset.CreatedAt.Before(filter.CreatedBefore) {
+		return false
+	}
+	return true
+}
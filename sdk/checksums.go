@@ -0,0 +1,112 @@
+package viderasdk
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+	"github.com/cespare/xxhash/v2"
+)
+
+// checksumBenchmarkSize Bytes hashed with each algorithm to rank them by speed on the running CPU
+const checksumBenchmarkSize = 1 << 20
+
+// castagnoliTable CRC32C table, the hash/crc32 package uses SSE4.2 or ARMv8 CRC instructions with it when available
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// checksumAlgorithms Chunk checksum algorithms offered to data nodes, keyed by the name sent in Checksum-Algorithms
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"crc32c": func() hash.Hash { return crc32.New(castagnoliTable) },
+	"xxh64":  func() hash.Hash { return xxhash.New() },
+	"sha256": sha256.New,
+}
+
+// rankedChecksums Names of the checksum algorithms from the fastest on the running CPU, see rankChecksums
+var rankedChecksums []string
+
+// rankChecksumsOnce Ranks the checksum algorithms on first use
+var rankChecksumsOnce sync.Once
+
+// rankChecksums is a function that returns the names of the checksum algorithms ordered from the fastest on the running CPU
+// the implementations pick hardware accelerated code paths such as SSE4.2, ARMv8 CRC or SHA extensions themselves,
+// timing them on a sample ranks whichever is accelerated first without probing CPU features
+func rankChecksums() []string {
+	rankChecksumsOnce.Do(func() {
+		sample := make([]byte, checksumBenchmarkSize)
+		durations := make(map[string]time.Duration, len(checksumAlgorithms))
+		for name, newHash := range checksumAlgorithms {
+			hash := newHash()
+			start := time.Now()
+			hash.Write(sample)
+			hash.Sum(nil)
+			durations[name] = time.Since(start)
+			rankedChecksums = append(rankedChecksums, name)
+		}
+		sort.Slice(rankedChecksums, func(i, j int) bool {
+			return durations[rankedChecksums[i]] < durations[rankedChecksums[j]]
+		})
+		utils.Debugln(fmt.Sprintf("Chunk checksum algorithms from the fastest: %s", strings.Join(rankedChecksums, ", ")))
+	})
+	return rankedChecksums
+}
+
+// offeredChecksums is a function to get the Checksum-Algorithms header of init requests, ClientOptions.Checksums
+// in preference order or every algorithm from the fastest when it is empty, none offers no checksum
+func (sdk VideraSDK) offeredChecksums() string {
+	names := sdk.options.Checksums
+	if len(names) == 0 {
+		names = rankChecksums()
+	}
+
+	var offered []string
+	for _, name := range names {
+		if name == "none" {
+			return ""
+		}
+		if _, ok := checksumAlgorithms[name]; ok {
+			offered = append(offered, name)
+		} else {
+			log.Println(fmt.Sprintf("Unknown checksum algorithm %s, expected crc32c, xxh64 or sha256", name))
+		}
+	}
+	return strings.Join(offered, ", ")
+}
+
+// recordSessionChecksum is a function responsible for remembering the checksum algorithm the data node picked for a session
+// data nodes not answering with one of the offered algorithms get chunks without checksums
+func (sdk VideraSDK) recordSessionChecksum(id string, offered string, res *http.Response) {
+	algorithm := strings.TrimSpace(res.Header.Get("Checksum-Algorithm"))
+	if algorithm == "" {
+		return
+	}
+
+	for _, name := range strings.Split(offered, ",") {
+		if strings.TrimSpace(name) == algorithm {
+			sdk.updateSession(id, func(state *sessionState) { state.checksum = algorithm })
+			return
+		}
+	}
+	log.Println(fmt.Sprintf("Data node picked checksum algorithm %s which wasn't offered, chunks are sent without checksums", algorithm))
+}
+
+// setChunkChecksum is a function responsible for adding the Chunk-Checksum header of a chunk request
+// the checksum covers the chunk as read, before compression, the algorithm is the one picked for the session
+func (sdk VideraSDK) setChunkChecksum(req *http.Request, id string, chunk []byte) {
+	algorithm := sdk.loadSession(id).checksum
+	if algorithm == "" {
+		return
+	}
+
+	hash := checksumAlgorithms[algorithm]()
+	hash.Write(chunk)
+	req.Header.Set("Chunk-Checksum", hex.EncodeToString(hash.Sum(nil)))
+}
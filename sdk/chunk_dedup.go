@@ -0,0 +1,96 @@
+package viderasdk
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/SayedAlesawy/Videra-SDK/httpx"
+)
+
+// dedupQueryBatchSize Max number of chunk hashes sent in a single query to the data node
+const dedupQueryBatchSize = 1024
+
+// chunkHash is a function to get the hex encoded sha256 digest of a chunk
+func chunkHash(chunk []byte) string {
+	hash := sha256.Sum256(chunk)
+	return hex.EncodeToString(hash[:])
+}
+
+// queryKnownChunks is a function responsible for asking the data node which chunks of the upload it already stores
+// chunks are hashed with the current chunk size, returns the set of hashes the data node reported
+func (sdk VideraSDK) queryKnownChunks(client *http.Client, id string, sources map[string]Source, uploadOrder []string) (map[string]bool, error) {
+	knownChunks := make(map[string]bool)
+	buffer := make([]byte, sdk.chunkSize)
+	var batch []string
+
+	for _, fileName := range uploadOrder {
+		reader, err := sources[fileName].Open(0)
+		if err != nil {
+			return nil, err
+		}
+
+		for {
+			bytesread, err := io.ReadFull(reader, buffer)
+			if err == io.EOF {
+				break
+			}
+			if err != nil && err != io.ErrUnexpectedEOF {
+				reader.Close()
+				return nil, err
+			}
+
+			batch = append(batch, chunkHash(buffer[:bytesread]))
+			if len(batch) == dedupQueryBatchSize {
+				err = sdk.sendChunksQuery(client, id, batch, knownChunks)
+				if err != nil {
+					reader.Close()
+					return nil, err
+				}
+				batch = batch[:0]
+			}
+		}
+		reader.Close()
+	}
+
+	if len(batch) > 0 {
+		err := sdk.sendChunksQuery(client, id, batch, knownChunks)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return knownChunks, nil
+}
+
+// sendChunksQuery is a function responsible for sending one batch of chunk hashes to the data node
+// the request body and the response body are newline separated hashes, found hashes are added to knownChunks
+func (sdk VideraSDK) sendChunksQuery(client *http.Client, id string, hashes []string, knownChunks map[string]bool) error {
+	req, _ := httpx.NewRewindableRequest(http.MethodPost, sdk.uploadURL(), []byte(strings.Join(hashes, "\n")))
+	req.Header.Set("Request-Type", "QUERY-CHUNKS")
+	req.Header.Set("ID", id)
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return errors.New("Data node doesn't support chunk dedup")
+	}
+
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		hash := strings.TrimSpace(scanner.Text())
+		if hash != "" {
+			knownChunks[hash] = true
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,140 @@
+package viderasdk
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// chunkSamplesDir Directory of the state directory chunk sample bundles are written to
+const chunkSamplesDir = "chunk-samples"
+
+// ChunkSample Describes a chunk sent and how the data node answered it, recorded for debugging corrupt uploads
+type ChunkSample struct {
+	Session        string      `json:"session"`             //Session the chunk was sent to
+	Artifact       string      `json:"artifact,omitempty"`  //Artifact of a parallel session the chunk belongs to
+	DataNode       string      `json:"data_node"`           //Data node the chunk was sent to
+	Offset         int64       `json:"offset"`              //Offset the chunk was sent at
+	Length         int         `json:"length"`              //Bytes of the chunk before compression
+	Checksum       string      `json:"checksum"`            //Hex encoded sha256 digest of the chunk
+	BodyChecksum   string      `json:"body_checksum"`       //Hex encoded sha256 digest of the request body, differs when compressed, deduplicated or zero filled
+	RequestHeader  http.Header `json:"request_header"`      //Headers of the chunk request
+	Status         int         `json:"status"`              //Status code of the response
+	ResponseHeader http.Header `json:"response_header"`     //Headers of the response
+	DataFile       string      `json:"data_file,omitempty"` //Entry of the bundle holding the raw chunk when raw copies are kept
+	Time           time.Time   `json:"time"`                //Time the response was received at
+}
+
+// chunkSampler Records a random sample of the chunks of an upload into a tar bundle
+// the bundle holds samples.json listing the samples and, when raw copies are kept, chunks/<offset>.bin
+type chunkSampler struct {
+	path    string        //Path of the bundle
+	file    *os.File      //Bundle being written
+	writer  *tar.Writer   //Writes entries of the bundle
+	rate    float64       //Fraction of chunks sampled
+	data    bool          //Whether raw copies of sampled chunks are kept
+	samples []ChunkSample //Samples recorded so far
+}
+
+// newChunkSampler is a function that returns the sampler of the chunks of session id sent by uploadFiles,
+// nil when SampleChunks is unset or the bundle can't be created
+func (sdk VideraSDK) newChunkSampler(id string) *chunkSampler {
+	if sdk.options.SampleChunks <= 0 {
+		return nil
+	}
+
+	dir := filepath.Join(sdk.stateDir, chunkSamplesDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		log.Println("Unable to sample chunks:", err)
+		return nil
+	}
+	name := id
+	if sdk.artifact != "" {
+		name += "-" + sdk.artifact
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.tar", name, time.Now().UTC().Format("20060102T150405")))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		log.Println("Unable to sample chunks:", err)
+		return nil
+	}
+
+	return &chunkSampler{path: path, file: file, writer: tar.NewWriter(file), rate: sdk.options.SampleChunks, data: sdk.options.SampleChunkData}
+}
+
+// sample is a function responsible for recording the chunk request req with its raw chunk and response when it falls in the sample
+func (sampler *chunkSampler) sample(req *http.Request, res *http.Response, chunk []byte, offset int64) {
+	if sampler == nil || rand.Float64() >= sampler.rate {
+		return
+	}
+
+	bodyChecksum, _ := requestBodyHash(req)
+	sample := ChunkSample{
+		Session:        req.Header.Get("ID"),
+		Artifact:       req.Header.Get("Artifact"),
+		DataNode:       req.URL.String(),
+		Offset:         offset,
+		Length:         len(chunk),
+		Checksum:       chunkHash(chunk),
+		BodyChecksum:   bodyChecksum,
+		RequestHeader:  req.Header.Clone(),
+		Status:         res.StatusCode,
+		ResponseHeader: res.Header.Clone(),
+		Time:           time.Now().UTC(),
+	}
+	// the bearer token and signature of the request don't belong in a support bundle
+	sample.RequestHeader.Del("Authorization")
+	sample.RequestHeader.Del("Signature")
+
+	if sampler.data {
+		sample.DataFile = "chunks/" + strconv.FormatInt(offset, 10) + ".bin"
+		if err := sampler.writeEntry(sample.DataFile, chunk); err != nil {
+			log.Println("Unable to store sampled chunk:", err)
+			sample.DataFile = ""
+		}
+	}
+	sampler.samples = append(sampler.samples, sample)
+}
+
+// writeEntry is a function responsible for adding a file to the bundle
+func (sampler *chunkSampler) writeEntry(name string, content []byte) error {
+	header := &tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), ModTime: time.Now().UTC()}
+	if err := sampler.writer.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := sampler.writer.Write(content)
+	return err
+}
+
+// close is a function responsible for writing the list of samples and closing the bundle, bundles without samples are removed
+func (sampler *chunkSampler) close() {
+	if sampler == nil {
+		return
+	}
+
+	content, err := json.MarshalIndent(sampler.samples, "", "  ")
+	if err == nil && len(sampler.samples) > 0 {
+		err = sampler.writeEntry("samples.json", content)
+	}
+	if closeErr := sampler.writer.Close(); err == nil {
+		err = closeErr
+	}
+	sampler.file.Close()
+
+	if len(sampler.samples) == 0 {
+		os.Remove(sampler.path)
+		return
+	}
+	if err != nil {
+		log.Println("Unable to write chunk samples:", err)
+		return
+	}
+	log.Println(fmt.Sprintf("%v chunk samples written to %s", len(sampler.samples), sampler.path))
+}
@@ -0,0 +1,79 @@
+package viderasdk
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/httpx"
+)
+
+// chunkOutcome How a data node answered a chunk request, see sendChunk
+type chunkOutcome int
+
+const (
+	chunkAccepted        chunkOutcome = iota //The chunk was stored and the session continues
+	chunkCompleted                           //The chunk was stored and completed the session
+	chunkOffsetCorrected                     //The data node expects the session to continue from another offset
+	chunkTooLarge                            //The data node accepts smaller chunks
+	chunkSessionExpired                      //The data node expired the session
+	chunkRejected                            //Any other answer
+)
+
+// chunkResponse Describes how a data node answered a chunk request
+type chunkResponse struct {
+	outcome   chunkOutcome   //How the data node answered
+	res       *http.Response //Response of the data node, its body already read and closed
+	sent      int64          //Payload bytes transmitted when the chunk was stored, chunks referenced by hash count none
+	offset    int64          //Offset the session continues from when chunkOffsetCorrected
+	chunkSize int64          //Largest chunk the data node accepts when chunkTooLarge
+}
+
+// sendChunk is a function responsible for sending the chunk request req of size bytes at offset of session id and interpreting the answer
+// it holds a chunk slot while the request is in flight, abandons chunks the data node announces another offset for early and
+// records the metrics and latency of stored chunks under tags, every upload path sends its chunks through it
+func (sdk VideraSDK) sendChunk(client *http.Client, id string, req *http.Request, offset int64, size int64, tags map[string]string) (chunkResponse, error) {
+	req, early := withEarlyOffset(req, offset)
+	defer early.close()
+	flow := chunkFlow(req.URL.String(), id)
+	sdk.chunkSlots.acquire(flow, sdk.chunkWeight(), size)
+	chunkStart := time.Now()
+	res, err := client.Do(req)
+	sdk.metrics().Timing("chunk_latency", time.Since(chunkStart), tags)
+	if earlyRes := early.response(); earlyRes != nil {
+		if err == nil {
+			res.Body.Close()
+		}
+		res, err = earlyRes, nil
+	}
+	if err != nil {
+		sdk.chunkSlots.release(flow)
+		return chunkResponse{}, err
+	}
+	readTrailerOffset(res)
+	sdk.chunkSlots.release(flow)
+	sdk.recordSessionExpiry(id, res)
+
+	response := chunkResponse{res: res, outcome: chunkRejected}
+	if res.StatusCode == http.StatusOK || res.StatusCode == http.StatusCreated {
+		response.outcome = chunkAccepted
+		if res.StatusCode == http.StatusCreated {
+			response.outcome = chunkCompleted
+		}
+		if req.ContentLength > 0 {
+			response.sent = req.ContentLength
+			sdk.metrics().Count("bytes_sent", req.ContentLength, tags)
+		}
+		sdk.metrics().Count("chunks_sent", 1, tags)
+		sdk.chunkStats.record(tags["filetype"], int(size), time.Since(chunkStart))
+		return response, nil
+	}
+
+	if newOffset, ok := httpx.OffsetCorrection(res); ok {
+		response.outcome, response.offset = chunkOffsetCorrected, newOffset
+	} else if newChunkSize, ok := httpx.MaxRequestSize(res); ok {
+		response.outcome, response.chunkSize = chunkTooLarge, newChunkSize
+	} else if sessionExpired(res) {
+		response.outcome = chunkSessionExpired
+	}
+	return response, nil
+}
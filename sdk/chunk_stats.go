@@ -0,0 +1,99 @@
+package viderasdk
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Distribution Describes the distribution of a per chunk measurement over an upload
+type Distribution struct {
+	Count int     `json:"count"` //Number of chunks measured
+	Min   float64 `json:"min"`   //Smallest value
+	Mean  float64 `json:"mean"`  //Average value
+	P50   float64 `json:"p50"`   //Median
+	P95   float64 `json:"p95"`   //Value 95% of the chunks are at or below
+	P99   float64 `json:"p99"`   //Value 99% of the chunks are at or below
+	Max   float64 `json:"max"`   //Largest value
+}
+
+// chunkStats Latency and throughput of every chunk acknowledged during an upload, keyed by filetype
+// copies of the SDK uploading parallel artifacts record into the same stats
+type chunkStats struct {
+	mutex       sync.Mutex
+	latencies   map[string][]float64 //Milliseconds from sending each chunk to its response
+	throughputs map[string][]float64 //Bytes per second each chunk was sent at
+}
+
+// newChunkStats is a function that returns empty chunk stats
+func newChunkStats() *chunkStats {
+	return &chunkStats{latencies: make(map[string][]float64), throughputs: make(map[string][]float64)}
+}
+
+// record is a function responsible for recording a chunk of size bytes acknowledged latency after it was sent
+func (stats *chunkStats) record(filetype string, size int, latency time.Duration) {
+	if stats == nil || latency <= 0 {
+		return
+	}
+
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+	stats.latencies[filetype] = append(stats.latencies[filetype], float64(latency)/float64(time.Millisecond))
+	stats.throughputs[filetype] = append(stats.throughputs[filetype], float64(size)/latency.Seconds())
+}
+
+// summarize is a function responsible for adding the distributions of the chunks of filetype to result and reporting
+// their percentiles as gauges, e.g. chunk_latency_p99 in milliseconds and chunk_throughput_p50 in bytes per second
+func (stats *chunkStats) summarize(sdk VideraSDK, filetype string, result *UploadResult) {
+	if stats == nil {
+		return
+	}
+
+	stats.mutex.Lock()
+	result.ChunkLatency = newDistribution(stats.latencies[filetype])
+	result.ChunkThroughput = newDistribution(stats.throughputs[filetype])
+	stats.mutex.Unlock()
+
+	tags := map[string]string{"filetype": filetype}
+	for name, distribution := range map[string]*Distribution{"chunk_latency": result.ChunkLatency, "chunk_throughput": result.ChunkThroughput} {
+		if distribution == nil {
+			continue
+		}
+		sdk.metrics().Gauge(name+"_p50", distribution.P50, tags)
+		sdk.metrics().Gauge(name+"_p95", distribution.P95, tags)
+		sdk.metrics().Gauge(name+"_p99", distribution.P99, tags)
+	}
+}
+
+// newDistribution is a function to get the distribution of samples, nil when there are none
+func newDistribution(samples []float64) *Distribution {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+	sum := 0.0
+	for _, sample := range sorted {
+		sum += sample
+	}
+	return &Distribution{
+		Count: len(sorted),
+		Min:   sorted[0],
+		Mean:  sum / float64(len(sorted)),
+		P50:   percentile(sorted, 50),
+		P95:   percentile(sorted, 95),
+		P99:   percentile(sorted, 99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+// percentile is a function to get the nearest rank percentile of sorted samples
+func percentile(sorted []float64, rank float64) float64 {
+	idx := int(math.Ceil(rank/100*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
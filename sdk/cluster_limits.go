@@ -0,0 +1,66 @@
+package viderasdk
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// clusterLimitsFile Name of the file remembering the limits negotiated with each cluster inside the state directory
+const clusterLimitsFile = "cluster_limits.json"
+
+// clusterLimitsMutex Serializes updates of the cluster limits file, e.g. by concurrent artifact uploads
+var clusterLimitsMutex sync.Mutex
+
+// ClusterLimits Describes the limits a cluster pushed to the SDK
+type ClusterLimits struct {
+	MaxRequestSize int64     `json:"max_request_size"` //Max chunk size pushed with Max-Request-Size, 0 if none was
+	LearnedAt      time.Time `json:"learned_at"`       //Time at which the limits were last updated
+}
+
+// loadClusterLimits is a function to read the limits learned from every cluster, keyed by master URL
+func (sdk VideraSDK) loadClusterLimits() (map[string]ClusterLimits, error) {
+	limits := make(map[string]ClusterLimits)
+	if err := sdk.loadStateFile(clusterLimitsFile, &limits); err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+// learnedChunkSize is a function to get the chunk size uploads to the cluster start with
+// that is the configured chunk size unless the cluster pushed a smaller max request size before
+func (sdk VideraSDK) learnedChunkSize() int64 {
+	limits, err := sdk.loadClusterLimits()
+	if err != nil {
+		return sdk.chunkSize
+	}
+
+	learned := limits[sdk.masterURL].MaxRequestSize
+	if learned > 0 && learned < sdk.chunkSize {
+		utils.Debugln(fmt.Sprintf("Starting with chunk size %v learned from %s", learned, sdk.masterURL))
+		return learned
+	}
+	return sdk.chunkSize
+}
+
+// recordMaxRequestSize is a function responsible for remembering the max request size a cluster pushed, failures are only logged
+func (sdk VideraSDK) recordMaxRequestSize(size int64) {
+	if size <= 0 {
+		return
+	}
+
+	clusterLimitsMutex.Lock()
+	defer clusterLimitsMutex.Unlock()
+
+	limits, err := sdk.loadClusterLimits()
+	if err == nil && limits[sdk.masterURL].MaxRequestSize != size {
+		limits[sdk.masterURL] = ClusterLimits{MaxRequestSize: size, LearnedAt: time.Now().UTC()}
+		err = sdk.saveStateFile(clusterLimitsFile, limits)
+	}
+	if err != nil {
+		log.Println("Unable to record cluster limits:", err)
+	}
+}
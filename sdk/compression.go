@@ -0,0 +1,189 @@
+package viderasdk
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+// compressionSampleSize Number of leading bytes of a file sampled to decide whether its chunks are compressed
+const compressionSampleSize = 64 << 10
+
+// compressionMinRatio Max compressed to original size ratio of the sample for a file to be compressed
+const compressionMinRatio = 0.9
+
+// Codec A chunk compression algorithm, offered to data nodes by name when sessions are initialized
+type Codec interface {
+	Name() string                          //Name of the encoding sent in the Content-Encoding of compressed chunks, e.g. zstd
+	Compress(chunk []byte) ([]byte, error) //Compresses a whole chunk
+}
+
+// codecsMutex Guards codecs
+var codecsMutex sync.RWMutex
+
+// codecs Codecs chunks can be compressed with, keyed by name
+var codecs = map[string]Codec{
+	"gzip": gzipCodec{},
+	"zstd": zstdCodec{},
+	"lz4":  lz4Codec{},
+}
+
+// RegisterCodec is a function to make a codec selectable by name in ClientOptions.Compression, replacing any codec of that name
+func RegisterCodec(codec Codec) {
+	codecsMutex.Lock()
+	defer codecsMutex.Unlock()
+	codecs[codec.Name()] = codec
+}
+
+// lookupCodec is a function to get a registered codec by name, nil if there is none
+func lookupCodec(name string) Codec {
+	codecsMutex.RLock()
+	defer codecsMutex.RUnlock()
+	return codecs[name]
+}
+
+// offeredEncodings is a function to get the Chunk-Encodings header of init requests, the registered codecs of
+// ClientOptions.Compression in preference order, empty when chunks are sent as is
+func (sdk VideraSDK) offeredEncodings() string {
+	var names []string
+	for _, name := range sdk.options.Compression {
+		if lookupCodec(name) != nil {
+			names = append(names, name)
+		} else {
+			sdk.warn(Warning{Kind: WarningUnsupportedEncoding, New: name, Message: fmt.Sprintf("Unknown compression codec %s", name)})
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// recordSessionEncoding is a function responsible for remembering the chunk encoding the data node accepted for a session
+// the init request offers the codecs the SDK can send and the data node answers with the one it picked, if any
+func (sdk VideraSDK) recordSessionEncoding(id string, offered string, res *http.Response) {
+	encoding := strings.TrimSpace(res.Header.Get("Chunk-Encoding"))
+	if encoding == "" {
+		return
+	}
+
+	for _, name := range strings.Split(offered, ",") {
+		if strings.TrimSpace(name) == encoding {
+			sdk.updateSession(id, func(state *sessionState) { state.encoding = encoding })
+			return
+		}
+	}
+	sdk.warn(Warning{Kind: WarningUnsupportedEncoding, Session: id, New: encoding,
+		Message: fmt.Sprintf("Data node picked chunk encoding %s which wasn't offered, chunks are sent uncompressed", encoding)})
+}
+
+// sessionCodec is a function to get the codec accepted for a session, nil if chunks are sent as is
+func (sdk VideraSDK) sessionCodec(id string) Codec {
+	encoding := sdk.loadSession(id).encoding
+	if encoding == "" {
+		return nil
+	}
+	return lookupCodec(encoding)
+}
+
+// incompressibleTypes MIME type prefixes of content that is already compressed
+var incompressibleTypes = []string{"video/", "audio/", "image/", "application/zip", "application/x-gzip", "application/x-rar-compressed"}
+
+// sourceCompressible is a function to decide whether the chunks of a source are worth compressing with codec
+// the leading bytes are sniffed for already compressed formats then compressed to estimate their entropy
+func sourceCompressible(source Source, codec Codec) bool {
+	reader, err := source.Open(0)
+	if err != nil {
+		return false
+	}
+	defer reader.Close()
+
+	sample := make([]byte, compressionSampleSize)
+	n, err := io.ReadFull(reader, sample)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return false
+	}
+	sample = sample[:n]
+	if n == 0 {
+		return false
+	}
+
+	contentType := utils.SniffContentType(sample)
+	for _, prefix := range incompressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			utils.Debugln(source.Name(), "is", contentType, "chunks are sent uncompressed")
+			return false
+		}
+	}
+
+	compressed, err := codec.Compress(sample)
+	if err != nil {
+		return false
+	}
+	ratio := float64(len(compressed)) / float64(n)
+	utils.Debugln(source.Name(), "compresses to", int(ratio*100), "% of its size with", codec.Name())
+	return ratio <= compressionMinRatio
+}
+
+// gzipCodec Compresses chunks with gzip
+type gzipCodec struct{}
+
+// Name returns gzip
+func (gzipCodec) Name() string {
+	return "gzip"
+}
+
+// Compress gzip compresses a chunk
+func (gzipCodec) Compress(chunk []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(chunk); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return compressed.Bytes(), nil
+}
+
+// zstdEncoder Shared zstd encoder, safe for concurrent EncodeAll calls
+var zstdEncoder, _ = zstd.NewWriter(nil)
+
+// zstdCodec Compresses chunks with zstd
+type zstdCodec struct{}
+
+// Name returns zstd
+func (zstdCodec) Name() string {
+	return "zstd"
+}
+
+// Compress zstd compresses a chunk into a single frame
+func (zstdCodec) Compress(chunk []byte) ([]byte, error) {
+	return zstdEncoder.EncodeAll(chunk, nil), nil
+}
+
+// lz4Codec Compresses chunks with lz4
+type lz4Codec struct{}
+
+// Name returns lz4
+func (lz4Codec) Name() string {
+	return "lz4"
+}
+
+// Compress lz4 compresses a chunk into a single frame
+func (lz4Codec) Compress(chunk []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	writer := lz4.NewWriter(&compressed)
+	if _, err := writer.Write(chunk); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return compressed.Bytes(), nil
+}
@@ -0,0 +1,40 @@
+package viderasdk
+
+// uploadSlots Limits the upload attempts running at once through an SDK, shared by the copies of the SDK the upload methods work on
+type uploadSlots chan struct{}
+
+// SetMaxConcurrentUploads is a function to bound the upload attempts running at once across every goroutine using the SDK,
+// further uploads wait for a running one to finish, 0 removes the bound, overriding max_concurrent_uploads of the config
+// model pulls count as uploads, a job runs its model and video uploads one after the other so it holds one slot at a time
+func (sdk *VideraSDK) SetMaxConcurrentUploads(limit int) {
+	sdk.uploadSlots = newSlots(limit)
+}
+
+// SetMaxConcurrentChunks is a function to bound the chunk requests in flight at once across every upload through the SDK,
+// parallel artifacts included, 0 removes the bound, overriding max_concurrent_chunks of the config
+// bounded slots are shared between uploads in proportion to their weights, see chunkScheduler
+func (sdk *VideraSDK) SetMaxConcurrentChunks(limit int) {
+	sdk.chunkSlots = newChunkScheduler(limit)
+}
+
+// newSlots is a function that returns limit slots, nil when limit isn't positive
+func newSlots(limit int) uploadSlots {
+	if limit <= 0 {
+		return nil
+	}
+	return make(uploadSlots, limit)
+}
+
+// acquire is a function responsible for waiting for a free slot, nil slots are unbounded
+func (slots uploadSlots) acquire() {
+	if slots != nil {
+		slots <- struct{}{}
+	}
+}
+
+// release is a function responsible for freeing a slot taken by acquire
+func (slots uploadSlots) release() {
+	if slots != nil {
+		<-slots
+	}
+}
@@ -0,0 +1,285 @@
+package viderasdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+	"gopkg.in/yaml.v2"
+)
+
+// ErrInvalidConfig Returned before upload when the model config doesn't match the schema the master expects for its framework
+var ErrInvalidConfig = errors.New("Model config doesn't match the schema of its framework")
+
+// configSchemaMaxSize Largest config validated, bigger ones are uploaded unchecked
+const configSchemaMaxSize = 4 << 20
+
+// maxSchemaProblems Problems listed in an ErrInvalidConfig at most
+const maxSchemaProblems = 10
+
+// frameworkExtensions Framework of a model guessed from the extension of the model artifact when ClientOptions.ModelFramework is empty
+var frameworkExtensions = map[string]string{
+	".pt":     "pytorch",
+	".pth":    "pytorch",
+	".onnx":   "onnx",
+	".pb":     "tensorflow",
+	".h5":     "keras",
+	".keras":  "keras",
+	".tflite": "tflite",
+}
+
+// configSchemas JSON schemas served by the master keyed by framework, nil when it serves none for a framework
+var configSchemas sync.Map
+
+// modelFramework is a function to get the framework of the model artifact of the given name, empty when unknown
+func (sdk VideraSDK) modelFramework(modelName string) string {
+	if sdk.options.ModelFramework != "" {
+		return sdk.options.ModelFramework
+	}
+	return frameworkExtensions[strings.ToLower(filepath.Ext(modelName))]
+}
+
+// validateModelConfig is a function responsible for checking the config of a model against the schema the master serves for its framework
+// nothing is checked when ClientOptions.SkipValidation is set, the framework is unknown or the master serves no schema for it
+func (sdk VideraSDK) validateModelConfig(modelName string, config Source) error {
+	framework := sdk.modelFramework(modelName)
+	if sdk.options.SkipValidation || framework == "" {
+		return nil
+	}
+	schema, err := sdk.configSchema(framework)
+	if err != nil || schema == nil {
+		utils.Debugln(fmt.Sprintf("Config of %s not validated, no %s schema: %v", modelName, framework, err))
+		return nil
+	}
+
+	size, err := config.Size()
+	if err != nil {
+		return err
+	}
+	if size > configSchemaMaxSize {
+		utils.Warnln(fmt.Sprintf("Config %s is too large to be validated against the %s schema", config.Name(), framework))
+		return nil
+	}
+	reader, err := config.Open(0)
+	if err != nil {
+		return err
+	}
+	body, err := ioutil.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return err
+	}
+
+	// JSON configs are YAML too
+	var document interface{}
+	if err = yaml.Unmarshal(body, &document); err != nil {
+		return fmt.Errorf("%w: %s isn't JSON or YAML: %v", ErrInvalidConfig, config.Name(), err)
+	}
+	problems := validateSchema(schema, normalizeYAML(document), "$")
+	if len(problems) == 0 {
+		return nil
+	}
+	if len(problems) > maxSchemaProblems {
+		problems = append(problems[:maxSchemaProblems], fmt.Sprintf("and %v more", len(problems)-maxSchemaProblems))
+	}
+	return fmt.Errorf("%w: %s against the %s schema: %s, pass -skip-validation to upload it anyway",
+		ErrInvalidConfig, config.Name(), framework, strings.Join(problems, "; "))
+}
+
+// configSchema is a function responsible for asking the master for the JSON schema of the configs of a framework
+// returns nil when the master doesn't serve one, answers are remembered for the life of the process
+func (sdk VideraSDK) configSchema(framework string) (map[string]interface{}, error) {
+	if cached, ok := configSchemas.Load(framework); ok {
+		return cached.(map[string]interface{}), nil
+	}
+
+	client := sdk.newClient()
+	req, _ := http.NewRequest(http.MethodGet, sdk.master(), nil)
+	req.Header.Set("Request-Type", "CONFIG-SCHEMA")
+	req.Header.Set("Framework", framework)
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var schema map[string]interface{}
+	switch res.StatusCode {
+	case http.StatusOK:
+		if err = json.NewDecoder(io.LimitReader(res.Body, configSchemaMaxSize)).Decode(&schema); err != nil {
+			return nil, err
+		}
+	case http.StatusNotFound, http.StatusNotImplemented:
+	default:
+		return nil, fmt.Errorf("Unable to get the %s config schema: %s", framework, res.Status)
+	}
+	configSchemas.Store(framework, schema)
+	return schema, nil
+}
+
+// normalizeYAML is a function to convert a decoded YAML document to the types of a decoded JSON one
+// maps get string keys and every number becomes a float64
+func normalizeYAML(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(value))
+		for key, val := range value {
+			converted[fmt.Sprintf("%v", key)] = normalizeYAML(val)
+		}
+		return converted
+	case []interface{}:
+		for idx, val := range value {
+			value[idx] = normalizeYAML(val)
+		}
+		return value
+	case int:
+		return float64(value)
+	case int64:
+		return float64(value)
+	case uint64:
+		return float64(value)
+	}
+	return value
+}
+
+// validateSchema is a function to check a value against the common subset of JSON schema, returns the problems found
+// type, enum, properties, required, additionalProperties, items and the length and range bounds are checked, other keywords are ignored
+func validateSchema(schema map[string]interface{}, value interface{}, path string) []string {
+	var problems []string
+	if types := schemaTypes(schema["type"]); len(types) > 0 && !matchesType(types, value) {
+		return []string{fmt.Sprintf("%s must be %s", path, strings.Join(types, " or "))}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && !inEnum(enum, value) {
+		problems = append(problems, fmt.Sprintf("%s must be one of %v", path, enum))
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if _, ok := value[fmt.Sprintf("%v", name)]; !ok {
+					problems = append(problems, fmt.Sprintf("%s.%v is required", path, name))
+				}
+			}
+		}
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := properties[name].(map[string]interface{}); ok {
+				problems = append(problems, validateSchema(property, value[name], path+"."+name)...)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					problems = append(problems, fmt.Sprintf("%s.%s isn't allowed", path, name))
+				}
+			case map[string]interface{}:
+				problems = append(problems, validateSchema(additional, value[name], path+"."+name)...)
+			}
+		}
+	case []interface{}:
+		problems = append(problems, checkBounds(schema, "minItems", "maxItems", float64(len(value)), path, "items")...)
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for idx, item := range value {
+				problems = append(problems, validateSchema(items, item, fmt.Sprintf("%s[%v]", path, idx))...)
+			}
+		}
+	case string:
+		problems = append(problems, checkBounds(schema, "minLength", "maxLength", float64(len(value)), path, "characters")...)
+	case float64:
+		problems = append(problems, checkBounds(schema, "minimum", "maximum", value, path, "")...)
+	}
+	return problems
+}
+
+// schemaTypes is a function to get the types a schema allows, its type keyword may be a single type or a list
+func schemaTypes(keyword interface{}) []string {
+	switch keyword := keyword.(type) {
+	case string:
+		return []string{keyword}
+	case []interface{}:
+		types := make([]string, 0, len(keyword))
+		for _, name := range keyword {
+			types = append(types, fmt.Sprintf("%v", name))
+		}
+		return types
+	}
+	return nil
+}
+
+// matchesType is a function to check whether a decoded value is of one of the given JSON schema types
+func matchesType(types []string, value interface{}) bool {
+	for _, name := range types {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			if name == "object" {
+				return true
+			}
+		case []interface{}:
+			if name == "array" {
+				return true
+			}
+		case string:
+			if name == "string" {
+				return true
+			}
+		case bool:
+			if name == "boolean" {
+				return true
+			}
+		case float64:
+			if name == "number" || (name == "integer" && value == float64(int64(value))) {
+				return true
+			}
+		case nil:
+			if name == "null" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// inEnum is a function to check whether a value is one of the values of an enum keyword
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if reflect.DeepEqual(normalizeYAML(allowed), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkBounds is a function to check a measure of a value against the lower and upper bound keywords of a schema
+func checkBounds(schema map[string]interface{}, lowerKeyword string, upperKeyword string, measure float64, path string, unit string) []string {
+	var problems []string
+	if lower, ok := schema[lowerKeyword].(float64); ok && measure < lower {
+		problems = append(problems, boundProblem(path, "at least", lower, unit))
+	}
+	if upper, ok := schema[upperKeyword].(float64); ok && measure > upper {
+		problems = append(problems, boundProblem(path, "at most", upper, unit))
+	}
+	return problems
+}
+
+// boundProblem is a function to describe a value out of a bound, unit is empty for numbers bounding the value itself
+func boundProblem(path string, relation string, bound float64, unit string) string {
+	if unit == "" {
+		return fmt.Sprintf("%s must be %s %v", path, relation, bound)
+	}
+	return fmt.Sprintf("%s must have %s %v %s", path, relation, bound, unit)
+}
@@ -0,0 +1,76 @@
+package viderasdk
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/config"
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// poolOptions is a function to get the connection pool described by the SDK config
+func poolOptions(configObj config.SDKConfig) utils.PoolOptions {
+	return utils.PoolOptions{
+		MaxIdlePerHost: configObj.PoolMaxIdle,
+		MaxPerHost:     configObj.PoolMaxConns,
+		IdleTimeout:    time.Duration(configObj.PoolIdleTimeout) * time.Second,
+	}
+}
+
+// BindInterfaces is a function to bind new connections to the first of the given network interfaces, overriding bind_interfaces of the config
+// connections move to the next interface once interface_failover_after requests failed in a row
+func (sdk VideraSDK) BindInterfaces(interfaces []string) error {
+	return utils.SetInterfaces(interfaces, sdkConfig.InterfaceFailover)
+}
+
+// reportRouteFailover is a function responsible for reporting connections moving to the next of bind_interfaces
+func (sdk *VideraSDK) reportRouteFailover(from string, to string) {
+	sdk.metrics().Count("route_failovers", 1, map[string]string{"interface": to})
+	sdk.warn(Warning{Kind: WarningRouteFailover, Old: from, New: to,
+		Message: fmt.Sprintf("Requests keep failing through %s, connecting through %s", from, to)})
+}
+
+// reportConnection is a function responsible for reporting the connection of a request to metrics
+func (sdk *VideraSDK) reportConnection(event utils.ConnectionEvent) {
+	tags := map[string]string{"host": event.Host}
+	if event.Reused {
+		sdk.metrics().Count("connections_reused", 1, tags)
+		return
+	}
+
+	sdk.metrics().Count("connections_new", 1, tags)
+	sdk.metrics().Timing("connection_dial", event.DialTime, tags)
+	if event.HandshakeTime > 0 {
+		sdk.metrics().Timing("tls_handshake", event.HandshakeTime, tags)
+	}
+}
+
+// ProbeConnections is a function responsible for asking the master for a data node requests times in a row
+// to exercise the connection pool, see utils.ConnectionStats for the resulting statistics
+func (sdk VideraSDK) ProbeConnections(requests int) error {
+	if err := sdk.checkTransport(sdk.master()); err != nil {
+		return err
+	}
+
+	client := sdk.newClient()
+	for idx := 0; idx < requests; idx++ {
+		req, _ := http.NewRequest(http.MethodGet, sdk.master(), nil)
+		res, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		// the body is drained so the connection goes back to the pool
+		io.Copy(ioutil.Discard, res.Body)
+		res.Body.Close()
+		if err = permissionError(res); err != nil {
+			return err
+		}
+		if res.StatusCode != http.StatusOK {
+			return fmt.Errorf("Master answered %s", res.Status)
+		}
+	}
+	return nil
+}
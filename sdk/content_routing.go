@@ -0,0 +1,175 @@
+package viderasdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+	"github.com/cespare/xxhash/v2"
+)
+
+// Values of ClientOptions.Routing
+const (
+	RoutingMaster  = "master"  //Ask the master for the data node of every upload attempt
+	RoutingContent = "content" //Compute the data node from the checksum of the content and the shard map of the master
+)
+
+// Algorithms of shard maps understood by HashRouter
+const (
+	ShardRendezvous = "rendezvous" //Weighted rendezvous hashing, the default, moving only the content of changed shards when shards change
+	ShardModulo     = "modulo"     //The first 8 bytes of the checksum modulo the number of shards, ignoring weights
+)
+
+// ErrNoShard Returned by content routers when the shard map holds no data node the content can go to
+var ErrNoShard = errors.New("No shard for the content")
+
+// shardMapTTL Time after which the shard map is fetched again, failed attempts fetch it again sooner
+const shardMapTTL = 5 * time.Minute
+
+// shardMapMaxSize Largest shard map accepted from the master
+const shardMapMaxSize = 4 << 20
+
+// ShardMap Describes how the master shards data nodes by content
+type ShardMap struct {
+	Version   int     `json:"version"`   //Version of the map, bumped by the master whenever shards change
+	Algorithm string  `json:"algorithm"` //Hash the content is placed with, rendezvous or modulo, empty for rendezvous
+	Shards    []Shard `json:"shards"`    //Shards in the order the master lists them
+}
+
+// Shard Describes a data node of a shard map
+type Shard struct {
+	Node   string  `json:"node"`   //Upload URL of the data node
+	Weight float64 `json:"weight"` //Share of the content the data node takes relative to the others, 0 counts as 1
+}
+
+// ContentRouter Picks the data node an upload goes to from the shard map of the master and the checksum of its content,
+// it must be deterministic so every client routes the same content to the same data node
+type ContentRouter interface {
+	Route(shards ShardMap, checksum string) (string, error)
+}
+
+// HashRouter The default ContentRouter, placing content with the algorithm named by the shard map
+type HashRouter struct{}
+
+// Route picks the data node of the content with the given hex encoded checksum
+func (HashRouter) Route(shards ShardMap, checksum string) (string, error) {
+	if len(shards.Shards) == 0 {
+		return "", fmt.Errorf("%w: shard map %v is empty", ErrNoShard, shards.Version)
+	}
+
+	switch shards.Algorithm {
+	case "", ShardRendezvous:
+		best, bestScore := "", math.Inf(-1)
+		for _, shard := range shards.Shards {
+			weight := shard.Weight
+			if weight == 0 {
+				weight = 1
+			}
+			// the hash mapped into (0, 1) turns into a score the weights scale, the highest wins
+			unit := (float64(xxhash.Sum64String(checksum+"/"+shard.Node)>>11) + 0.5) / (1 << 53)
+			if score := -weight / math.Log(unit); score > bestScore {
+				best, bestScore = shard.Node, score
+			}
+		}
+		return best, nil
+	case ShardModulo:
+		var prefix uint64
+		if _, err := fmt.Sscanf(checksum, "%16x", &prefix); err != nil {
+			return "", fmt.Errorf("%w: checksum %q isn't hex", ErrNoShard, checksum)
+		}
+		return shards.Shards[prefix%uint64(len(shards.Shards))].Node, nil
+	}
+	return "", fmt.Errorf("%w: unknown shard algorithm %s", ErrNoShard, shards.Algorithm)
+}
+
+// shardMapMutex Guards the variables below
+var shardMapMutex sync.Mutex
+
+// shardMap Shard map last fetched from the master, nil when it doesn't shard data nodes by content
+var shardMap *ShardMap
+
+// shardMapFetchedAt Time the shard map was last fetched, zero when it wasn't
+var shardMapFetchedAt time.Time
+
+// routeUpload is a function responsible for picking the data node of an upload attempt, computed from the checksum of its content
+// under content routing, saving the round trip to the master. Retries fetch the shard map again as it may be stale, the master
+// routes the attempt when it serves no shard map or the map can't route the content
+func (sdk VideraSDK) routeUpload(checksum string, trial int) error {
+	if sdk.options.Routing != RoutingContent || checksum == "" {
+		return sdk.updateUploadURL()
+	}
+
+	shards, err := sdk.currentShardMap(trial > 0)
+	if err != nil || shards == nil {
+		utils.Debugln(fmt.Sprintf("Asking the master for a data node, no shard map: %v", err))
+		return sdk.updateUploadURL()
+	}
+	router := sdk.options.ContentRouter
+	if router == nil {
+		router = HashRouter{}
+	}
+	node, err := router.Route(*shards, checksum)
+	if err == nil {
+		err = sdk.checkTransport(node)
+	}
+	if err != nil {
+		utils.Warnln(fmt.Sprintf("Asking the master for a data node, shard map %v can't route %s: %v", shards.Version, checksum, err))
+		return sdk.updateUploadURL()
+	}
+
+	utils.Debugln(fmt.Sprintf("Shard map %v routes %s to %s", shards.Version, checksum, node))
+	sdk.setUploadURL(node, fmt.Sprintf("Shard map %v", shards.Version))
+	return nil
+}
+
+// currentShardMap is a function responsible for getting the shard map of the master, fetched again once shardMapTTL old or when refresh is set
+// returns nil when the master doesn't shard data nodes by content
+func (sdk VideraSDK) currentShardMap(refresh bool) (*ShardMap, error) {
+	shardMapMutex.Lock()
+	defer shardMapMutex.Unlock()
+
+	if !refresh && !shardMapFetchedAt.IsZero() && time.Since(shardMapFetchedAt) < shardMapTTL {
+		return shardMap, nil
+	}
+	if err := sdk.checkTransport(sdk.master()); err != nil {
+		return nil, err
+	}
+
+	client := sdk.newClient()
+	req, _ := http.NewRequest(http.MethodGet, sdk.master(), nil)
+	req.Header.Set("Request-Type", "SHARD-MAP")
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if err = permissionError(res); err != nil {
+		return nil, err
+	}
+	var fetched *ShardMap
+	switch res.StatusCode {
+	case http.StatusOK:
+		fetched = &ShardMap{}
+		if err = json.NewDecoder(io.LimitReader(res.Body, shardMapMaxSize)).Decode(fetched); err != nil {
+			return nil, fmt.Errorf("Invalid shard map: %v", err)
+		}
+		sdk.updateMaxFileSize(res)
+	case http.StatusNotFound, http.StatusNotImplemented:
+	default:
+		return nil, fmt.Errorf("Unable to get the shard map: %s", res.Status)
+	}
+
+	if fetched != nil && (shardMap == nil || shardMap.Version != fetched.Version) {
+		log.Println(fmt.Sprintf("Routing uploads with shard map %v of %v data nodes", fetched.Version, len(fetched.Shards)))
+	}
+	shardMap, shardMapFetchedAt = fetched, time.Now()
+	return shardMap, nil
+}
@@ -0,0 +1,236 @@
+package viderasdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// credentialsFile Name of the encrypted credentials file inside the state directory
+const credentialsFile = "credentials.enc"
+
+// credentialsKeyFile Name of the file that held a generated credentials key inside the state directory before keys were
+// derived from a passphrase, only read to migrate credentials encrypted with it and removed once they are
+const credentialsKeyFile = "credentials.key"
+
+// credentialsKeyEnv Environment variable holding the passphrase the credentials key is derived from
+const credentialsKeyEnv = "VIDERA_CREDENTIALS_KEY"
+
+// credentialsMagic Starts credentials files encrypted with a key derived from the passphrase, followed by the salt of the key
+var credentialsMagic = []byte("videra-credentials-scrypt\n")
+
+// credentialsSaltSize Size in bytes of the random salt the credentials key is derived with
+const credentialsSaltSize = 16
+
+// credentialsMutex Serializes reads and updates of the credentials file, e.g. by concurrent token refreshes of several profiles
+var credentialsMutex sync.Mutex
+
+// SaveCredentials is a function to store the credentials of a profile in the encrypted credentials file
+func (sdk VideraSDK) SaveCredentials(profile string, credentials Credentials) error {
+	credentialsMutex.Lock()
+	defer credentialsMutex.Unlock()
+
+	profiles, err := sdk.loadProfiles()
+	if err != nil {
+		return err
+	}
+	profiles[profile] = credentials
+	return sdk.saveProfiles(profiles)
+}
+
+// LoadCredentials is a function to get the stored credentials of a profile
+func (sdk VideraSDK) LoadCredentials(profile string) (Credentials, error) {
+	credentialsMutex.Lock()
+	defer credentialsMutex.Unlock()
+
+	profiles, err := sdk.loadProfiles()
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	credentials, ok := profiles[profile]
+	if !ok {
+		return Credentials{}, fmt.Errorf("No credentials stored for profile %s, run login first", profile)
+	}
+	return credentials, nil
+}
+
+// UseProfile is a function to make the SDK talk to the cluster of a profile with its credentials
+func (sdk *VideraSDK) UseProfile(profile string) error {
+	credentials, err := sdk.LoadCredentials(profile)
+	if err != nil {
+		return err
+	}
+
+	if credentials.Endpoint != "" {
+		sdk.masterURL, sdk.masters = credentials.Endpoint, nil
+	}
+	sdk.credentials = &profileCredentials{profile: profile, credentials: credentials, sdk: *sdk}
+	return nil
+}
+
+// profileCredentials Credentials of the active profile, shared by the clients of the SDK
+type profileCredentials struct {
+	mutex       sync.Mutex  //Guards credentials while they are refreshed
+	profile     string      //Profile the credentials are stored under
+	credentials Credentials //Current credentials
+	sdk         VideraSDK   //SDK storing refreshed credentials
+}
+
+// bearerToken is a function to get the bearer token, refreshing and storing it first when it expired
+// the refresh is given up on when ctx is done or after oauthRequestTimeout, requests of other goroutines wait for it
+func (active *profileCredentials) bearerToken(ctx context.Context) (string, error) {
+	active.mutex.Lock()
+	defer active.mutex.Unlock()
+
+	if active.credentials.expired() && active.credentials.RefreshToken != "" {
+		ctx, cancel := context.WithTimeout(ctx, oauthRequestTimeout)
+		defer cancel()
+		if err := active.credentials.refreshToken(ctx, active.sdk.oauthClient()); err != nil {
+			return "", err
+		}
+		if err := active.sdk.SaveCredentials(active.profile, active.credentials); err != nil {
+			log.Println("Unable to store refreshed credentials:", err)
+		}
+	}
+	return active.credentials.Token, nil
+}
+
+// saveProfiles is a function responsible for encrypting the credentials of every profile with a key derived from the passphrase
+// and a new salt, the salt is stored in the file and the key nowhere, callers hold credentialsMutex
+func (sdk VideraSDK) saveProfiles(profiles map[string]Credentials) error {
+	content, err := json.Marshal(profiles)
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := credentialsPassphrase()
+	if err != nil {
+		return err
+	}
+	salt := make([]byte, credentialsSaltSize)
+	if _, err = rand.Read(salt); err != nil {
+		return err
+	}
+	key, err := utils.PassphraseKey(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	encrypted, err := utils.Encrypt(key, content)
+	if err != nil {
+		return err
+	}
+
+	header := append(append([]byte{}, credentialsMagic...), salt...)
+	if err = utils.WriteFileAtomic(filepath.Join(sdk.stateDir, credentialsFile), append(header, encrypted...)); err != nil {
+		return err
+	}
+	if err = os.Remove(filepath.Join(sdk.stateDir, credentialsKeyFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// loadProfiles is a function responsible for decrypting the credentials of every profile
+// credentials encrypted before keys were derived with a salt are migrated to a salted key, callers hold credentialsMutex
+func (sdk VideraSDK) loadProfiles() (map[string]Credentials, error) {
+	profiles := make(map[string]Credentials)
+	encrypted, err := ioutil.ReadFile(filepath.Join(sdk.stateDir, credentialsFile))
+	if os.IsNotExist(err) {
+		return profiles, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	passphrase, err := credentialsPassphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	var content []byte
+	legacy := !bytes.HasPrefix(encrypted, credentialsMagic)
+	if legacy {
+		content, err = sdk.decryptLegacyCredentials(passphrase, encrypted)
+	} else {
+		encrypted = encrypted[len(credentialsMagic):]
+		if len(encrypted) < credentialsSaltSize {
+			return nil, errors.New("Credentials file is too short")
+		}
+		var key []byte
+		if key, err = utils.PassphraseKey(passphrase, encrypted[:credentialsSaltSize]); err != nil {
+			return nil, err
+		}
+		content, err = utils.Decrypt(key, encrypted[credentialsSaltSize:])
+	}
+	if err != nil {
+		return nil, errors.New("Unable to decrypt credentials, check " + credentialsKeyEnv)
+	}
+
+	if err = json.Unmarshal(content, &profiles); err != nil {
+		return nil, err
+	}
+	if legacy {
+		if err = sdk.saveProfiles(profiles); err != nil {
+			return nil, fmt.Errorf("Unable to migrate credentials to a salted key: %w", err)
+		}
+		log.Println("Migrated credentials to a key derived with a salt from " + credentialsKeyEnv)
+	}
+	return profiles, nil
+}
+
+// decryptLegacyCredentials is a function to decrypt credentials encrypted before keys were derived with a salt,
+// with the key generated in the credentials key file or else the unsalted key of the passphrase
+func (sdk VideraSDK) decryptLegacyCredentials(passphrase string, encrypted []byte) ([]byte, error) {
+	key, err := ioutil.ReadFile(filepath.Join(sdk.stateDir, credentialsKeyFile))
+	if os.IsNotExist(err) {
+		key = utils.UnsaltedPassphraseKey(passphrase)
+	} else if err != nil {
+		return nil, err
+	}
+	return utils.Decrypt(key, encrypted)
+}
+
+// RequireCredentialsPassphrase is a function to check the passphrase credentials are stored with is set, e.g. before logging in
+func RequireCredentialsPassphrase() error {
+	_, err := credentialsPassphrase()
+	return err
+}
+
+// credentialsPassphrase is a function to get the passphrase the credentials key is derived from
+func credentialsPassphrase() (string, error) {
+	passphrase := os.Getenv(credentialsKeyEnv)
+	if passphrase == "" {
+		return "", fmt.Errorf("Set %s to the passphrase stored credentials are encrypted with", credentialsKeyEnv)
+	}
+	return passphrase, nil
+}
+
+// credentialsTransport Attaches the credentials of the active profile to every request
+type credentialsTransport struct {
+	base        http.RoundTripper   //Transport sending the requests
+	credentials *profileCredentials //Credentials of the active profile
+}
+
+// RoundTrip sends a copy of the request carrying the bearer token
+func (transport credentialsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := transport.credentials.bearerToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return transport.base.RoundTrip(req)
+}
@@ -0,0 +1,110 @@
+package viderasdk
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// TestCredentialsEncryption checks credentials are stored under a salted key derived from the passphrase that isn't
+// kept on disk, and that credentials stored under a generated key file or an unsalted key are migrated
+func TestCredentialsEncryption(t *testing.T) {
+	credentials := Credentials{Endpoint: "https://master/upload", Token: "secret-token"}
+	tests := []struct {
+		name    string
+		prepare func(sdk VideraSDK)
+		legacy  bool
+	}{
+		{"fresh", func(sdk VideraSDK) {}, false},
+		{"generated key file", func(sdk VideraSDK) {
+			key := bytes.Repeat([]byte{7}, 32)
+			ioutil.WriteFile(filepath.Join(sdk.stateDir, credentialsKeyFile), key, 0600)
+			writeLegacyCredentials(t, sdk, key, credentials)
+		}, true},
+		{"unsalted passphrase", func(sdk VideraSDK) {
+			writeLegacyCredentials(t, sdk, utils.UnsaltedPassphraseKey("passphrase"), credentials)
+		}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv(credentialsKeyEnv, "passphrase")
+			sdk := VideraSDK{stateDir: t.TempDir()}
+			test.prepare(sdk)
+			if test.legacy {
+				if loaded, err := sdk.LoadCredentials("default"); err != nil || loaded.Token != credentials.Token {
+					t.Fatalf("legacy credentials loaded %+v, %v", loaded, err)
+				}
+			}
+			if err := sdk.SaveCredentials("default", credentials); err != nil {
+				t.Fatal(err)
+			}
+			first, _ := ioutil.ReadFile(filepath.Join(sdk.stateDir, credentialsFile))
+			if err := sdk.SaveCredentials("default", credentials); err != nil {
+				t.Fatal(err)
+			}
+			second, _ := ioutil.ReadFile(filepath.Join(sdk.stateDir, credentialsFile))
+
+			if !bytes.HasPrefix(second, credentialsMagic) || bytes.Equal(first[:len(credentialsMagic)+credentialsSaltSize], second[:len(credentialsMagic)+credentialsSaltSize]) {
+				t.Errorf("credentials weren't stored with a new salt")
+			}
+			if bytes.Contains(second, []byte(credentials.Token)) {
+				t.Errorf("credentials stored in the clear")
+			}
+			if _, err := os.Stat(filepath.Join(sdk.stateDir, credentialsKeyFile)); !os.IsNotExist(err) {
+				t.Errorf("credentials key kept on disk: %v", err)
+			}
+			if loaded, err := sdk.LoadCredentials("default"); err != nil || loaded.Token != credentials.Token || loaded.Endpoint != credentials.Endpoint {
+				t.Errorf("loaded %+v, %v, expected %+v", loaded, err, credentials)
+			}
+
+			t.Setenv(credentialsKeyEnv, "another passphrase")
+			if _, err := sdk.LoadCredentials("default"); err == nil {
+				t.Errorf("credentials decrypted with another passphrase")
+			}
+			t.Setenv(credentialsKeyEnv, "")
+			if _, err := sdk.LoadCredentials("default"); err == nil {
+				t.Errorf("credentials decrypted without a passphrase")
+			}
+		})
+	}
+}
+
+// writeLegacyCredentials is a function responsible for writing credentials the way they were encrypted before keys were salted
+func writeLegacyCredentials(t *testing.T, sdk VideraSDK, key []byte, credentials Credentials) {
+	encrypted, err := utils.Encrypt(key, []byte(`{"default": {"endpoint": "`+credentials.Endpoint+`", "token": "`+credentials.Token+`"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioutil.WriteFile(filepath.Join(sdk.stateDir, credentialsFile), encrypted, 0600)
+}
+
+// TestConcurrentCredentialsUpdates checks credentials stored concurrently for several profiles, e.g. by token refreshes, all reach the file
+func TestConcurrentCredentialsUpdates(t *testing.T) {
+	t.Setenv(credentialsKeyEnv, "passphrase")
+	sdk := VideraSDK{stateDir: t.TempDir()}
+	const profiles = 8
+	var wg sync.WaitGroup
+	for idx := 0; idx < profiles; idx++ {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			if err := sdk.SaveCredentials(fmt.Sprint("profile-", idx), Credentials{Token: fmt.Sprint(idx)}); err != nil {
+				t.Error(err)
+			}
+		}(idx)
+	}
+	wg.Wait()
+
+	for idx := 0; idx < profiles; idx++ {
+		if loaded, err := sdk.LoadCredentials(fmt.Sprint("profile-", idx)); err != nil || loaded.Token != fmt.Sprint(idx) {
+			t.Errorf("profile-%v loaded %+v, %v", idx, loaded, err)
+		}
+	}
+}
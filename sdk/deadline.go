@@ -0,0 +1,73 @@
+package viderasdk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ErrDeadlineExceeded Returned when an upload runs past ClientOptions.Deadline or FileTimeout
+// the session is left in the session journal with the offset it reached
+var ErrDeadlineExceeded = errors.New("Upload deadline exceeded")
+
+// uploadDeadline is a function that returns the time by which the upload being started must complete
+// an upload started by another one keeps the deadline of the outer upload, zero means no deadline
+func (sdk VideraSDK) uploadDeadline() time.Time {
+	if !sdk.deadline.IsZero() || sdk.options.Deadline <= 0 {
+		return sdk.deadline
+	}
+	return time.Now().Add(sdk.options.Deadline)
+}
+
+// fileDeadline is a function that returns the time by which the file upload being started must complete
+// that is the upload deadline or FileTimeout from now, whichever comes first
+func (sdk VideraSDK) fileDeadline() time.Time {
+	if sdk.options.FileTimeout <= 0 {
+		return sdk.deadline
+	}
+
+	fileDeadline := time.Now().Add(sdk.options.FileTimeout)
+	if !sdk.deadline.IsZero() && sdk.deadline.Before(fileDeadline) {
+		return sdk.deadline
+	}
+	return fileDeadline
+}
+
+// deadlineExceeded is a function to check whether the deadline of the current upload passed
+func (sdk VideraSDK) deadlineExceeded() bool {
+	return !sdk.deadline.IsZero() && !time.Now().Before(sdk.deadline)
+}
+
+// deadlineTransport Cancels requests still running at the deadline, retries included
+type deadlineTransport struct {
+	base     http.RoundTripper //Transport sending the requests
+	deadline time.Time         //Time at which requests are cancelled
+}
+
+// RoundTrip sends a request bounded by the deadline
+func (transport deadlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithDeadline(req.Context(), transport.deadline)
+	res, err := transport.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	res.Body = cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+// cancelOnClose A response body releasing the context of its request once closed
+type cancelOnClose struct {
+	io.ReadCloser                    //Body of the response
+	cancel        context.CancelFunc //Releases the context of the request
+}
+
+// Close closes the body and releases the context of its request
+func (body cancelOnClose) Close() error {
+	err := body.ReadCloser.Close()
+	body.cancel()
+	return err
+}
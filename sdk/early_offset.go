@@ -0,0 +1,79 @@
+package viderasdk
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptrace"
+	"net/textproto"
+	"strconv"
+	"sync"
+)
+
+// earlyOffset Offset a data node announced in an informational response while a chunk was still being sent
+// data nodes may send a 1xx response with an Offset header as soon as they see a chunk doesn't start where the session is,
+// the chunk is then abandoned instead of being transmitted in full only to be rejected
+type earlyOffset struct {
+	mutex     sync.Mutex
+	offset    int64              //Offset the chunk was sent at
+	announced string             //Offset header of the informational response, empty until one differs from offset
+	cancel    context.CancelFunc //Abandons the chunk request
+}
+
+// withEarlyOffset is a function responsible for watching the informational responses to the chunk request sent at offset
+func withEarlyOffset(req *http.Request, offset int64) (*http.Request, *earlyOffset) {
+	ctx, cancel := context.WithCancel(req.Context())
+	early := &earlyOffset{offset: offset, cancel: cancel}
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			early.got(header.Get("Offset"))
+			return nil
+		},
+	}
+
+	return req.WithContext(httptrace.WithClientTrace(ctx, trace)), early
+}
+
+// got is a function responsible for abandoning the chunk request when an informational response announces another offset
+func (early *earlyOffset) got(announced string) {
+	newOffset, err := strconv.ParseInt(announced, 10, 64)
+	if err != nil || newOffset == early.offset {
+		return
+	}
+
+	early.mutex.Lock()
+	early.announced = announced
+	early.mutex.Unlock()
+	early.cancel()
+}
+
+// response is a function that returns a response carrying the announced offset when the chunk request was abandoned for one,
+// handled like the data node rejecting the chunk with that offset, or nil otherwise
+func (early *earlyOffset) response() *http.Response {
+	early.mutex.Lock()
+	defer early.mutex.Unlock()
+
+	if early.announced == "" {
+		return nil
+	}
+	header := make(http.Header)
+	header.Set("Offset", early.announced)
+	return &http.Response{Status: "409 Offset announced early", StatusCode: http.StatusConflict, Header: header, Body: http.NoBody}
+}
+
+// close is a function responsible for releasing the context of the chunk request
+func (early *earlyOffset) close() {
+	early.cancel()
+}
+
+// readTrailerOffset is a function responsible for reading a chunk response to its end and closing it
+// an Offset trailer is copied to the headers when they lack one, data nodes streaming the response may only know it at the end
+func readTrailerOffset(res *http.Response) {
+	io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+
+	if res.Header.Get("Offset") == "" && res.Trailer.Get("Offset") != "" {
+		res.Header.Set("Offset", res.Trailer.Get("Offset"))
+	}
+}
@@ -0,0 +1,194 @@
+package viderasdk
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// chunkAnticipation Time a slot freed by a flow is kept for its next chunk when that chunk is due before every waiting one
+const chunkAnticipation = 10 * time.Millisecond
+
+// chunkScheduler Grants the chunk slots of max_concurrent_chunks to the uploads waiting for one by weighted fair queuing
+// so a large upload doesn't starve the others, each session is a flow whose chunks are tagged with the virtual time they would
+// finish at if every waiting flow was served at once in proportion to its weight, and a freed slot goes to the smallest tag
+// a session sends its chunks one after the other, so between two of them it has none waiting and would be served in turns with
+// the others whatever its weight, the slot it frees is kept for chunkAnticipation when its next chunk is due before every waiting one
+type chunkScheduler struct {
+	mutex    sync.Mutex         //Guards the fields below
+	free     int                //Slots not granted, including the reserved one
+	virtual  float64            //Virtual time, the start tag of the chunk granted last
+	finishes map[string]float64 //Finish tag of the last chunk of each flow
+	costs    map[string]float64 //Virtual time the last chunk of each flow took, the next is expected to take as long
+	waiting  waitingChunks      //Chunks waiting for a slot, by finish tag
+	sequence uint64             //Order of arrival of waiting chunks, breaking ties of their tags first come first served
+	reserved string             //Flow a free slot is kept for, empty when none is
+	expiry   *time.Timer        //Gives the kept slot to the waiting chunks once chunkAnticipation passed, nil when none is kept
+	keeps    uint64             //Number of times a slot was kept, telling the timers of earlier keeps of the same flow apart
+}
+
+// waitingChunk Describes a chunk waiting for a slot
+type waitingChunk struct {
+	start    float64       //Virtual time the chunk starts at, the later of the virtual time and the finish of the previous chunk of its flow
+	finish   float64       //Virtual time the chunk finishes at, its size over the weight of its flow after start
+	sequence uint64        //Order of arrival
+	granted  chan struct{} //Closed when the chunk is granted a slot
+}
+
+// waitingChunks A heap of the waiting chunks, see container/heap
+type waitingChunks []*waitingChunk
+
+// Len returns the number of waiting chunks
+func (chunks waitingChunks) Len() int { return len(chunks) }
+
+// Less orders chunks by finish tag then by arrival
+func (chunks waitingChunks) Less(i, j int) bool {
+	if chunks[i].finish != chunks[j].finish {
+		return chunks[i].finish < chunks[j].finish
+	}
+	return chunks[i].sequence < chunks[j].sequence
+}
+
+// Swap swaps two chunks
+func (chunks waitingChunks) Swap(i, j int) { chunks[i], chunks[j] = chunks[j], chunks[i] }
+
+// Push adds a chunk
+func (chunks *waitingChunks) Push(chunk interface{}) {
+	*chunks = append(*chunks, chunk.(*waitingChunk))
+}
+
+// Pop removes the last chunk
+func (chunks *waitingChunks) Pop() interface{} {
+	old := *chunks
+	chunk := old[len(old)-1]
+	*chunks = old[:len(old)-1]
+	return chunk
+}
+
+// newChunkScheduler is a function that returns a scheduler of limit chunk slots, nil when limit isn't positive
+func newChunkScheduler(limit int) *chunkScheduler {
+	if limit <= 0 {
+		return nil
+	}
+	return &chunkScheduler{free: limit, finishes: make(map[string]float64), costs: make(map[string]float64)}
+}
+
+// acquire is a function responsible for waiting for a slot to send a chunk of size bytes of the flow with the given weight,
+// nil schedulers are unbounded
+func (scheduler *chunkScheduler) acquire(flow string, weight float64, size int64) {
+	if scheduler == nil {
+		return
+	}
+	if weight <= 0 {
+		weight = 1
+	}
+
+	scheduler.mutex.Lock()
+	start := scheduler.virtual
+	if finish := scheduler.finishes[flow]; finish > start {
+		start = finish
+	}
+	chunk := &waitingChunk{start: start, finish: start + float64(size)/weight, sequence: scheduler.sequence, granted: make(chan struct{})}
+	scheduler.sequence++
+	scheduler.finishes[flow], scheduler.costs[flow] = chunk.finish, float64(size)/weight
+	available := scheduler.free
+	if scheduler.reserved != "" && scheduler.reserved != flow {
+		available--
+	}
+	if available > 0 && (len(scheduler.waiting) == 0 || scheduler.reserved == flow) {
+		if scheduler.reserved == flow {
+			scheduler.unreserve()
+		}
+		scheduler.free--
+		scheduler.virtual = chunk.start
+		scheduler.mutex.Unlock()
+		return
+	}
+	heap.Push(&scheduler.waiting, chunk)
+	scheduler.mutex.Unlock()
+
+	<-chunk.granted
+}
+
+// release is a function responsible for freeing a slot the flow took with acquire, granting it to the waiting chunk with the smallest
+// finish tag unless it is kept for the next chunk of the flow
+func (scheduler *chunkScheduler) release(flow string) {
+	if scheduler == nil {
+		return
+	}
+
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+	scheduler.free++
+	if len(scheduler.waiting) > 0 && scheduler.reserved == "" &&
+		scheduler.finishes[flow]+scheduler.costs[flow] < scheduler.waiting[0].finish {
+		scheduler.reserved = flow
+		scheduler.keeps++
+		keep := scheduler.keeps
+		scheduler.expiry = time.AfterFunc(chunkAnticipation, func() { scheduler.expire(keep) })
+		return
+	}
+	scheduler.grant()
+}
+
+// expire is a function responsible for giving the slot kept for a flow that didn't send its next chunk in time to the waiting chunks,
+// keep is the number of the keep the timer was started for, a slot kept again since then isn't expired
+func (scheduler *chunkScheduler) expire(keep uint64) {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+	if scheduler.reserved != "" && scheduler.keeps == keep {
+		scheduler.unreserve()
+		scheduler.grant()
+	}
+}
+
+// unreserve is a function responsible for no longer keeping a free slot for a flow, stopping its timer, called with the mutex held
+func (scheduler *chunkScheduler) unreserve() {
+	scheduler.reserved = ""
+	if scheduler.expiry != nil {
+		scheduler.expiry.Stop()
+		scheduler.expiry = nil
+	}
+}
+
+// grant is a function responsible for granting a free slot to the waiting chunk with the smallest finish tag, called with the mutex held
+func (scheduler *chunkScheduler) grant() {
+	if len(scheduler.waiting) == 0 {
+		// flows that finished before the virtual time start from it anyway, forgetting them keeps the maps to the active ones
+		for flow, finish := range scheduler.finishes {
+			if finish <= scheduler.virtual && flow != scheduler.reserved {
+				delete(scheduler.finishes, flow)
+				delete(scheduler.costs, flow)
+			}
+		}
+		return
+	}
+	if scheduler.free == 0 || (scheduler.free == 1 && scheduler.reserved != "") {
+		return
+	}
+
+	chunk := heap.Pop(&scheduler.waiting).(*waitingChunk)
+	scheduler.free--
+	if chunk.start > scheduler.virtual {
+		scheduler.virtual = chunk.start
+	}
+	close(chunk.granted)
+}
+
+// chunkFlow is a function that returns the flow the chunks of session id of dataNode are scheduled as, data nodes assign IDs
+// independently so two sessions of uploads routed to different data nodes may share one
+func chunkFlow(dataNode string, id string) string {
+	return dataNode + " " + id
+}
+
+// chunkWeight is a function that returns the weight the chunks of uploads through the SDK are scheduled with,
+// ClientOptions.Weight when set, the weight of the priority of the uploads in priority_weights otherwise and 1 without either
+func (sdk VideraSDK) chunkWeight() float64 {
+	if sdk.options.Weight > 0 {
+		return sdk.options.Weight
+	}
+	if weight := sdk.priorityWeights[sdk.options.Priority]; weight > 0 {
+		return weight
+	}
+	return 1
+}
@@ -0,0 +1,145 @@
+package viderasdk
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestChunkSchedulerWeights checks two sessions sending their chunks one after the other share a slot in proportion to their weights
+func TestChunkSchedulerWeights(t *testing.T) {
+	tests := []struct {
+		name     string
+		weights  [2]float64
+		min, max float64
+	}{
+		{"equal", [2]float64{1, 1}, 0.75, 1.35},
+		{"three to one", [2]float64{3, 1}, 2.4, 3.6},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scheduler := newChunkScheduler(1)
+			flows := [2]string{"heavy", "light"}
+			var mutex sync.Mutex
+			var grants []int
+			done := make(chan struct{})
+			var wg sync.WaitGroup
+			for idx := range flows {
+				wg.Add(1)
+				go func(idx int) {
+					defer wg.Done()
+					for {
+						select {
+						case <-done:
+							return
+						default:
+						}
+						scheduler.acquire(flows[idx], test.weights[idx], 1<<20)
+						mutex.Lock()
+						grants = append(grants, idx)
+						mutex.Unlock()
+						time.Sleep(time.Millisecond)
+						scheduler.release(flows[idx])
+					}
+				}(idx)
+			}
+			time.Sleep(400 * time.Millisecond)
+			close(done)
+			wg.Wait()
+
+			var counts [2]int
+			for _, idx := range grants {
+				counts[idx]++
+			}
+			if counts[1] == 0 {
+				t.Fatalf("light session was starved: %v", counts)
+			}
+			ratio := float64(counts[0]) / float64(counts[1])
+			if ratio < test.min || ratio > test.max {
+				t.Errorf("heavy:light chunks = %v:%v, ratio %.2f outside [%v, %v]", counts[0], counts[1], ratio, test.min, test.max)
+			}
+		})
+	}
+}
+
+// TestChunkSchedulerLimit checks no more chunks than the limit are in flight at once
+func TestChunkSchedulerLimit(t *testing.T) {
+	const limit = 2
+	scheduler := newChunkScheduler(limit)
+	var mutex sync.Mutex
+	inFlight, peak := 0, 0
+	var wg sync.WaitGroup
+	for _, flow := range []string{"a", "b", "c", "d"} {
+		wg.Add(1)
+		go func(flow string) {
+			defer wg.Done()
+			for chunk := 0; chunk < 20; chunk++ {
+				scheduler.acquire(flow, 1, 1<<20)
+				mutex.Lock()
+				inFlight++
+				if inFlight > peak {
+					peak = inFlight
+				}
+				mutex.Unlock()
+				time.Sleep(time.Millisecond)
+				mutex.Lock()
+				inFlight--
+				mutex.Unlock()
+				scheduler.release(flow)
+			}
+		}(flow)
+	}
+	wg.Wait()
+
+	if peak > limit {
+		t.Errorf("%v chunks in flight at once, limit is %v", peak, limit)
+	}
+}
+
+// TestChunkSchedulerStaleExpiry checks the timer of a slot kept for a flow doesn't expire the slot kept again for it after its next chunk
+func TestChunkSchedulerStaleExpiry(t *testing.T) {
+	scheduler := newChunkScheduler(1)
+	scheduler.acquire("small", 1, 1)
+	granted := make(chan struct{})
+	go func() {
+		scheduler.acquire("large", 1, 1000)
+		close(granted)
+	}()
+	for waiting := 0; waiting == 0; {
+		time.Sleep(time.Millisecond)
+		scheduler.mutex.Lock()
+		waiting = len(scheduler.waiting)
+		scheduler.mutex.Unlock()
+	}
+
+	scheduler.release("small")
+	scheduler.mutex.Lock()
+	firstKeep := scheduler.keeps
+	scheduler.mutex.Unlock()
+	scheduler.acquire("small", 1, 1)
+	scheduler.release("small")
+
+	scheduler.mutex.Lock()
+	if scheduler.reserved != "small" || scheduler.expiry == nil {
+		scheduler.mutex.Unlock()
+		t.Fatalf("slot kept for %q, expected small", scheduler.reserved)
+	}
+	// the timer is stopped so only the expiries of the test run
+	scheduler.expiry.Stop()
+	secondKeep := scheduler.keeps
+	scheduler.mutex.Unlock()
+
+	scheduler.expire(firstKeep)
+	select {
+	case <-granted:
+		t.Fatal("stale timer gave the slot kept again for small away")
+	case <-time.After(2 * chunkAnticipation):
+	}
+	scheduler.expire(secondKeep)
+	select {
+	case <-granted:
+	case <-time.After(time.Second):
+		t.Fatal("expired slot wasn't granted to the waiting chunk")
+	}
+}
@@ -1,7 +1,7 @@
 package viderasdk
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -9,102 +9,415 @@ import (
 	"os"
 	"strconv"
 
+	"github.com/SayedAlesawy/Videra-SDK/httpx"
 	"github.com/SayedAlesawy/Videra-SDK/utils"
 )
 
 // uploadFiles is a function responsible for uploading files contents to data node
-func (sdk VideraSDK) uploadFiles(id string, filesPaths map[string]string, uploadOrder []string) error {
-	client := utils.NewClient(sdk.defaultMaxRetries, sdk.defaultWaitingTime)
+// expectedDigests maps digest headers to the values the data node is expected to echo on completion
+// returns the number of payload bytes transmitted, chunks referenced by hash are not counted
+// past the deadline the transfer stops and the offset reached is recorded in the session journal
+// for an artifact of a parallel session offsets are within the artifact and errArtifactUploaded is returned
+// when its last chunk was acknowledged without the data node completing the session
+// uploads slower than watchdog_min_rate are remediated as set by watchdog_action, see slowThroughput
+// a SampleChunks fraction of chunks is recorded with their responses in a bundle of the state directory
+// files are checked for changes before each chunk is sent, a changed file fails the upload with ErrSourceChanged
+// offset corrections are also taken from informational responses, abandoning the chunk, and from Offset trailers
+// sessions the data node accepted in tail first order get the end of the file first, see uploadTail
+func (sdk VideraSDK) uploadFiles(id string, sources map[string]Source, uploadOrder []string, expectedDigests map[string]string) (int64, error) {
+	sdk.deadline = sdk.fileDeadline()
+	sdk.chunkSize = sdk.learnedChunkSize()
+	client := sdk.newClientWithPolicy(sdk.appendRetryPolicy())
 
-	buffer := make([]byte, sdk.chunkSize)
+	buffer := sdk.acquireBuffer(sdk.chunkSize)
+	defer func() { sdk.releaseBuffer(buffer) }()
 	offset := int64(0)
-	readOffset := int64(-1) //for re-reading file file content, in case of failure
+	bytesSent := int64(0)
+
+	knownChunks := make(map[string]bool)
+	if sdk.dedupChunks {
+		var err error
+		knownChunks, err = sdk.queryKnownChunks(client, id, sources, uploadOrder)
+		if err != nil {
+			log.Println("Chunk dedup unavailable, uploading all chunks:", err)
+			knownChunks = make(map[string]bool)
+		}
+	}
+
+	// holes are sent as zero fill requests until the data node rejects one
+	sparseUpload := sdk.sparseUpload
+	codec := sdk.sessionCodec(id)
+	reinits := 0
+	watchdog := sdk.newThroughputWatchdog()
+	snapshots := snapshotSources(sources)
+	sampler := sdk.newChunkSampler(id)
+	defer sampler.close()
 
 	filesSizes := make([]int64, len(uploadOrder))
 	for idx := 0; idx < len(uploadOrder); idx++ {
-		fileName := uploadOrder[idx]
-		fileSize, _ := utils.GetFileSize(filesPaths[fileName])
+		fileSize, err := sources[uploadOrder[idx]].Size()
+		if err != nil {
+			log.Println(err)
+			return bytesSent, err
+		}
 		filesSizes[idx] = fileSize
 	}
-	for idx := 0; idx < len(uploadOrder); idx++ {
+
+	totalSize := int64(0)
+	for _, size := range filesSizes {
+		totalSize += size
+	}
+	progress := sdk.newUploadProgress(uploadOrder[0], totalSize)
+	tags := map[string]string{"filetype": uploadOrder[0]}
+
+	// the offset an adopted session reached is read once, it decides both whether the tail is sent first and where the upload continues
+	adopted := sdk.adoptedOffset(id)
+
+	// the end of a tail first session is sent before the rest
+	if tailStart, ok := sdk.tailStart(id, filesSizes); ok && adopted == 0 {
+		sent, completed, err := sdk.uploadTail(client, id, sources[uploadOrder[0]], tailStart, buffer, progress, tags, expectedDigests)
+		bytesSent += sent
+		if err != nil || completed {
+			return bytesSent, err
+		}
+	}
+
+	// an adopted session continues where it stopped, one the data node holds whole is sent again to complete it
+	startIdx := 0
+	if adopted > 0 && sdk.artifact == "" {
+		if adoptedIdx, _, err := utils.GetFileFromOffset(filesSizes, adopted); err == nil && adoptedIdx < len(filesSizes) {
+			startIdx, offset = adoptedIdx, adopted
+		}
+	}
+	for idx := startIdx; idx < len(uploadOrder); idx++ {
 		fileName := uploadOrder[idx]
-		file, err := os.Open(filesPaths[fileName])
-		if readOffset != -1 {
-			file.Seek(readOffset, 0)
+		source := sources[fileName]
+
+		// offset within the current file, non zero when resuming after an offset correction
+		fileOffset := offset
+		for _, size := range filesSizes[:idx] {
+			fileOffset -= size
 		}
-		readOffset = -1
-		log.Println("Uploading", fileName, file.Name())
-		defer file.Close()
+
+		reader, err := sdk.openSource(source, fileOffset, tags)
 		if err != nil {
-			fmt.Println(err)
-			return err
+			log.Println(err)
+			return bytesSent, err
+		}
+		log.Println("Uploading", fileName, source.Name())
+		// only files that compress well are compressed, video usually doesn't
+		var fileCodec Codec
+		if codec != nil && sourceCompressible(source, codec) {
+			fileCodec = codec
+		}
+
+		var holes []utils.ByteRange
+		if sparseUpload {
+			holes = sourceHoles(source)
 		}
 
 		for {
-			bytesread, err := file.Read(buffer)
+			bytesread, err := io.ReadFull(reader, buffer)
+			if err == io.ErrUnexpectedEOF {
+				// last chunk of the file is shorter than the buffer
+				err = nil
+			}
 
 			if err != nil {
+				reader.Close()
 				if err == io.EOF {
 					if idx == len(uploadOrder)-1 {
+						if sdk.artifact != "" {
+							return bytesSent, errArtifactUploaded
+						}
 						log.Println(err)
 						// reached the end of last file, but didn't receive ack from server
-						return err
+						return bytesSent, err
 					}
 					// finished current file
-					file.Close()
 					break
 				}
-				return err
+				return bytesSent, err
 			}
 
-			r := bytes.NewReader(buffer[:bytesread])
+			// the chunk read must be the content the upload started with
+			if err = snapshots[fileName].check(); err != nil {
+				reader.Close()
+				log.Println(err)
+				return bytesSent, err
+			}
+
+			// a session about to expire is extended or replaced before the chunk is sent to it
+			if sdk.sessionExpiring(id, offset) && reinits < sdk.sessionReinits {
+				newID, newOffset, err := sdk.renewSession(id, offset)
+				if err != nil {
+					reader.Close()
+					log.Println(err)
+					return bytesSent, err
+				}
+				if newID != id {
+					reader.Close()
+					reinits++
+					sdk.metrics().Count("session_reinits", 1, tags)
+					id, offset, codec = newID, newOffset, sdk.sessionCodec(newID)
 
-			req, _ := http.NewRequest(http.MethodPost, uploadURL, r)
-			req.Header.Set("Request-Type", "APPEND")
-			req.Header.Set("ID", id)
-			req.Header.Set("Offset", strconv.FormatInt(offset, 10))
+					var newIdx int
+					newIdx, _, err = utils.GetFileFromOffset(filesSizes, offset)
+					if err != nil {
+						log.Println(err)
+						return bytesSent, err
+					}
+					idx = newIdx - 1 //subtracted 1 to cancel the 1 added by loop
+					break
+				}
+			}
 
-			res, err := client.Do(req)
+			zeroFill := sparseUpload && inHoles(holes, fileOffset, int64(bytesread))
+			var req *http.Request
+			if zeroFill {
+				req = newZeroFillRequest(sdk.uploadURL(), id, offset, int64(bytesread))
+			} else {
+				req = newChunkRequest(sdk.uploadURL(), id, offset, buffer[:bytesread], knownChunks, fileCodec)
+				sdk.setChunkChecksum(req, id, buffer[:bytesread])
+			}
+
+			if sdk.artifact != "" {
+				req.Header.Set("Artifact", sdk.artifact)
+			}
+			if sdk.deadlineExceeded() {
+				reader.Close()
+				sdk.recordSessionOffset(id, sdk.uploadURL(), offset)
+				return bytesSent, ErrDeadlineExceeded
+			}
+			response, err := sdk.sendChunk(client, id, req, offset, int64(bytesread), tags)
 			if err != nil {
+				reader.Close()
 				log.Println(err)
-				return err
+				if sdk.deadlineExceeded() {
+					sdk.recordSessionOffset(id, sdk.uploadURL(), offset)
+					return bytesSent, fmt.Errorf("%w: %v", ErrDeadlineExceeded, err)
+				}
+				return bytesSent, err
 			}
-			if res.StatusCode != http.StatusOK {
-				if res.StatusCode == http.StatusCreated {
-					file.Close()
-					return nil
-				} else if res.Header.Get("Offset") != "" {
-					newOffset, _ := strconv.ParseInt(res.Header.Get("Offset"), 10, 64)
-					log.Println(fmt.Sprintf("Offset error: changing from %v to %v", offset, newOffset))
+			res := response.res
+			sampler.sample(req, res, buffer[:bytesread], offset)
+			bytesSent += response.sent
+			if response.outcome != chunkAccepted {
+				if response.outcome == chunkCompleted {
+					reader.Close()
+					progress.update(totalSize)
+					sdk.completeSession(id, sdk.uploadURL())
+					return bytesSent, verifyDigestsEcho(res, expectedDigests)
+				} else if response.outcome == chunkOffsetCorrected {
+					newOffset := response.offset
+					reader.Close()
+					sdk.warn(Warning{Kind: WarningOffsetCorrection, Session: id, Old: strconv.FormatInt(offset, 10), New: strconv.FormatInt(newOffset, 10),
+						Message: fmt.Sprintf("Offset error: changing from %v to %v", offset, newOffset)})
+					sdk.metrics().Count("offset_corrections", 1, tags)
 					offset = newOffset
 					var newIdx int
-					newIdx, readOffset, err = utils.GetFileFromOffset(filesSizes, offset)
+					newIdx, _, err = utils.GetFileFromOffset(filesSizes, offset)
 					if err != nil {
 						log.Println(err)
-						return err
+						return bytesSent, err
 					}
 
 					// file completly uploaded
 					if newIdx == len(filesSizes) {
-						return nil
+						if sdk.artifact != "" {
+							return bytesSent, errArtifactUploaded
+						}
+						return bytesSent, nil
 					}
 
 					idx = newIdx - 1 //subtracted 1 to cancel the 1 added by loop
-					file.Close()
 					break
-				} else if res.Header.Get("Max-Request-Size") != "" {
-					newChunkSize, _ := strconv.ParseInt(res.Header.Get("Max-Request-Size"), 10, 64)
-					log.Println(fmt.Sprintf("Chunk size error: changing from %v to %v", sdk.chunkSize, newChunkSize))
+				} else if response.outcome == chunkTooLarge {
+					newChunkSize := response.chunkSize
+					sdk.warn(Warning{Kind: WarningChunkSizeChange, Session: id, Old: strconv.FormatInt(sdk.chunkSize, 10), New: strconv.FormatInt(newChunkSize, 10),
+						Message: fmt.Sprintf("Chunk size error: changing from %v to %v", sdk.chunkSize, newChunkSize)})
+					sdk.metrics().Count("chunk_size_renegotiations", 1, tags)
 					sdk.chunkSize = newChunkSize
-					buffer = make([]byte, sdk.chunkSize)
-					file.Seek(-int64(bytesread), 1) //revert current read bytes, 1 means relative to current offset
+					sdk.recordMaxRequestSize(newChunkSize)
+					sdk.releaseBuffer(buffer)
+					buffer = sdk.acquireBuffer(sdk.chunkSize)
+					// reopen the source at the current position to revert the bytes just read
+					reader.Close()
+					reader, err = sdk.openSource(source, fileOffset, tags)
+					if err != nil {
+						log.Println(err)
+						return bytesSent, err
+					}
+					continue
+				} else if response.outcome == chunkSessionExpired && sdk.reinit != nil && sdk.artifact == "" && reinits < sdk.sessionReinits {
+					reader.Close()
+					reinits++
+					sdk.metrics().Count("session_reinits", 1, tags)
+					id, offset, err = sdk.reinitSession(id, offset)
+					if err != nil {
+						log.Println(err)
+						return bytesSent, err
+					}
+					codec = sdk.sessionCodec(id)
+
+					var newIdx int
+					newIdx, _, err = utils.GetFileFromOffset(filesSizes, offset)
+					if err != nil {
+						log.Println(err)
+						return bytesSent, err
+					}
+					idx = newIdx - 1 //subtracted 1 to cancel the 1 added by loop
+					break
+				} else if zeroFill {
+					log.Println(fmt.Sprintf("Zero fill rejected with %s, uploading holes as regular chunks", res.Status))
+					sparseUpload = false
+					reader.Close()
+					reader, err = sdk.openSource(source, fileOffset, tags)
+					if err != nil {
+						log.Println(err)
+						return bytesSent, err
+					}
 					continue
 				}
 
-				return err
+				reader.Close()
+				return bytesSent, errors.New(res.Status)
 			}
+			utils.Debugln(fmt.Sprintf("Chunk of %v bytes at offset %v: %s", bytesread, offset, res.Status))
 			offset += int64(bytesread)
-			log.Println(res.Status)
+			fileOffset += int64(bytesread)
+			progress.update(offset)
+			sdk.reportResourceUsage(tags)
+
+			rate, slow := watchdog.observe(int64(bytesread))
+			if !slow {
+				continue
+			}
+			watchdog.reset()
+			if action := sdk.slowThroughput(id, rate, reinits); action == WatchdogRenegotiate {
+				sdk.chunkSize /= 2
+				sdk.releaseBuffer(buffer)
+				buffer = sdk.acquireBuffer(sdk.chunkSize)
+			} else if action == WatchdogFailover {
+				reader.Close()
+				reinits++
+				sdk.metrics().Count("watchdog_failovers", 1, tags)
+				id, offset, err = sdk.failover(id, offset)
+				if err != nil {
+					log.Println(err)
+					return bytesSent, err
+				}
+				codec = sdk.sessionCodec(id)
+
+				var newIdx int
+				newIdx, _, err = utils.GetFileFromOffset(filesSizes, offset)
+				if err != nil {
+					log.Println(err)
+					return bytesSent, err
+				}
+				idx = newIdx - 1 //subtracted 1 to cancel the 1 added by loop
+				break
+			}
+		}
+	}
+
+	return bytesSent, nil
+}
+
+// openSource is a function responsible for opening a source for upload at offset
+// local files skip the page cache when drop_page_cache is set, every source is read ahead by up to MaxBufferedChunks chunks
+// when set, or by read_ahead otherwise, reading pausing while the network is slower than the source, and read no faster than RateLimit
+// or the limit of the traffic shaping rule matching while reading
+// read ahead buffers count towards max_buffer_memory
+func (sdk VideraSDK) openSource(source Source, offset int64, tags map[string]string) (io.ReadCloser, error) {
+	reader, err := source.Open(offset)
+	if err != nil {
+		return nil, err
+	}
+
+	if file, ok := reader.(*os.File); ok && sdk.dropPageCache {
+		reader = utils.NewUncachedFileReader(file, offset)
+	}
+	if sdk.options.MaxBufferedChunks > 0 {
+		maxChunks := sdk.options.MaxBufferedChunks
+		reader = sdk.readAheadSource(reader, sdk.chunkSize, maxChunks, func(depth int) {
+			sdk.metrics().Gauge("buffered_chunks", float64(depth), tags)
+			if depth == maxChunks {
+				sdk.metrics().Count("buffer_full", 1, tags)
+			}
+		})
+	} else if sdk.readAhead > 0 {
+		reader = sdk.readAheadSource(reader, utils.ReadAheadBlockSize, int(sdk.readAhead/utils.ReadAheadBlockSize), nil)
+	}
+	if sdk.options.RateLimit > 0 || sdk.shaper != nil {
+		reader = utils.NewShapedReader(reader, sdk.uploadRate)
+	}
+	return reader, nil
+}
+
+// newChunkRequest is a function responsible for building the APPEND request of a chunk to the data node of its session
+// chunks already stored by the data node are referenced by their hash instead of being transmitted
+// when a codec is given the chunk is sent compressed with it unless that doesn't make it smaller
+func newChunkRequest(dataNode string, id string, offset int64, chunk []byte, knownChunks map[string]bool, codec Codec) *http.Request {
+	var req *http.Request
+	hash := ""
+	if len(knownChunks) > 0 {
+		hash = chunkHash(chunk)
+	}
+
+	if knownChunks[hash] {
+		req, _ = http.NewRequest(http.MethodPost, dataNode, nil)
+		req.Header.Set("Chunk-Hash", hash)
+		req.Header.Set("Chunk-Size", strconv.Itoa(len(chunk)))
+	} else {
+		body := chunk
+		if codec != nil {
+			if compressed, err := codec.Compress(chunk); err == nil && len(compressed) < len(chunk) {
+				body = compressed
+			}
+		}
+		req, _ = httpx.NewRewindableRequest(http.MethodPost, dataNode, body)
+		if len(body) != len(chunk) {
+			req.Header.Set("Content-Encoding", codec.Name())
+			req.Header.Set("Chunk-Size", strconv.Itoa(len(chunk)))
+		}
+	}
+	req.Header.Set("Request-Type", "APPEND")
+	req.Header.Set("ID", id)
+	req.Header.Set("Offset", strconv.FormatInt(offset, 10))
+
+	return req
+}
+
+// newZeroFillRequest is a function responsible for building the APPEND request of a chunk that lies in a file hole
+// the data node writes length zero bytes at the offset instead of receiving them
+func newZeroFillRequest(dataNode string, id string, offset int64, length int64) *http.Request {
+	req, _ := http.NewRequest(http.MethodPost, dataNode, nil)
+	req.Header.Set("Request-Type", "APPEND")
+	req.Header.Set("ID", id)
+	req.Header.Set("Offset", strconv.FormatInt(offset, 10))
+	req.Header.Set("Zero-Fill", strconv.FormatInt(length, 10))
+
+	return req
+}
+
+// inHoles is a function to check whether length bytes starting at offset fall entirely inside one of the holes
+func inHoles(holes []utils.ByteRange, offset int64, length int64) bool {
+	for _, hole := range holes {
+		if hole.Contains(offset, length) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyDigestsEcho is a function responsible for comparing digests echoed by the data node on completion
+// against the ones computed locally, headers the data node doesn't echo are skipped
+func verifyDigestsEcho(res *http.Response, expectedDigests map[string]string) error {
+	for header, digest := range expectedDigests {
+		echoedDigest := res.Header.Get(header)
+		if echoedDigest != "" && echoedDigest != digest {
+			return fmt.Errorf("Data node reported %s %s, expected %s", header, echoedDigest, digest)
 		}
 	}
 
@@ -0,0 +1,84 @@
+package viderasdk
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// TestRetriedChunksCarryFullPayloads checks an APPEND retried after a failed attempt sends the whole chunk again,
+// whether the data node answered the first attempt with an error or dropped the connection half way through its body
+func TestRetriedChunksCarryFullPayloads(t *testing.T) {
+	chunk := bytes.Repeat([]byte("videra chunk "), 64<<10)
+	tests := []struct {
+		name  string
+		codec Codec
+		fail  func(w http.ResponseWriter, r *http.Request)
+	}{
+		{"server error", nil, func(w http.ResponseWriter, r *http.Request) {
+			ioutil.ReadAll(r.Body)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}},
+		{"dropped connection", nil, func(w http.ResponseWriter, r *http.Request) {
+			io.CopyN(ioutil.Discard, r.Body, r.ContentLength/2)
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+		}},
+		{"compressed chunk", gzipCodec{}, func(w http.ResponseWriter, r *http.Request) {
+			io.CopyN(ioutil.Discard, r.Body, r.ContentLength/2)
+			w.WriteHeader(http.StatusBadGateway)
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var mutex sync.Mutex
+			attempts := 0
+			var received []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mutex.Lock()
+				attempts++
+				attempt := attempts
+				mutex.Unlock()
+				if attempt == 1 {
+					test.fail(w, r)
+					return
+				}
+				body, _ := ioutil.ReadAll(r.Body)
+				mutex.Lock()
+				received = body
+				mutex.Unlock()
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			sdk := VideraSDK{}
+			client := sdk.newClientWithPolicy(RetryPolicy{MaxRetries: 2})
+			req := newChunkRequest(server.URL, "1", 0, chunk, nil, test.codec)
+			response, err := sdk.sendChunk(client, "1", req, 0, int64(len(chunk)), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.outcome != chunkAccepted {
+				t.Fatalf("chunk answered with %s", response.res.Status)
+			}
+
+			if attempts != 2 {
+				t.Errorf("%v attempts, expected the chunk to be retried once", attempts)
+			}
+			if int64(len(received)) != req.ContentLength {
+				t.Fatalf("retry carried %v bytes, the chunk request has %v", len(received), req.ContentLength)
+			}
+			if test.codec == nil && !bytes.Equal(received, chunk) {
+				t.Errorf("retry carried other bytes than the chunk")
+			}
+			if response.sent != req.ContentLength {
+				t.Errorf("%v bytes counted as sent, expected %v", response.sent, req.ContentLength)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package viderasdk
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// ErrFilenameExists Returned when the data node already stores a file of the same name under the fail collision policy
+var ErrFilenameExists = errors.New("File name already exists")
+
+// Filename collision policies, sent to the data node in the Filename-Collision header of init requests
+const (
+	CollisionRenameSuffix = "rename-suffix" //The data node stores the file under the name with a numbered suffix
+	CollisionOverwrite    = "overwrite"     //The file replaces the stored one of the same name
+	CollisionFail         = "fail"          //The upload fails with ErrFilenameExists
+)
+
+// checkFilenameCollision is a function responsible for interpreting the collision outcome of an init response
+// a conflict fails the upload and a renamed file is logged, conflicts with a session are adopted before, see sessionConflict
+func checkFilenameCollision(filename string, res *http.Response) error {
+	if res.StatusCode == http.StatusConflict {
+		return fmt.Errorf("%w: %s", ErrFilenameExists, filename)
+	}
+
+	if stored := res.Header.Get("Filename"); stored != "" && stored != filename {
+		log.Println(fmt.Sprintf("Data node stores %s as %s", filename, stored))
+	}
+	return nil
+}
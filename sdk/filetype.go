@@ -0,0 +1,49 @@
+package viderasdk
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// ErrFiletypeMismatch Returned under StrictFiletype when a video doesn't look like a video container
+var ErrFiletypeMismatch = errors.New("File doesn't match its declared type")
+
+// sniffSource is a function to detect the MIME type of a source from its leading bytes
+func sniffSource(source Source) (string, error) {
+	reader, err := source.Open(0)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	header := make([]byte, utils.SniffLength)
+	n, err := io.ReadFull(reader, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+
+	return utils.SniffContentType(header[:n]), nil
+}
+
+// checkFiletype is a function responsible for detecting the MIME type of a source and checking it against the declared filetype
+// a video that isn't a recognized container is reported as a warning, or fails with ErrFiletypeMismatch under StrictFiletype
+func (sdk VideraSDK) checkFiletype(source Source, filetype string) (string, error) {
+	contentType, err := sniffSource(source)
+	if err != nil {
+		return "", err
+	}
+	if filetype != "video" || strings.HasPrefix(contentType, "video/") {
+		return contentType, nil
+	}
+
+	if sdk.options.StrictFiletype {
+		return "", fmt.Errorf("%w: %s is %s, not a video", ErrFiletypeMismatch, source.Name(), contentType)
+	}
+	sdk.warn(Warning{Kind: WarningFiletypeMismatch, New: contentType,
+		Message: fmt.Sprintf("%s is %s, it doesn't look like a video", source.Name(), contentType)})
+	return contentType, nil
+}
@@ -0,0 +1,191 @@
+package viderasdk
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// staleTempAge Age after which temporary files left by the SDK are assumed to belong to no running upload
+const staleTempAge = 24 * time.Hour
+
+// GCOptions Describes which local state GC removes
+type GCOptions struct {
+	SessionMaxAge time.Duration //Remove journaled sessions started longer ago than this, 0 keeps every session
+	AbortSessions bool          //Ask the data node to abort the removed sessions
+	CacheMaxAge   time.Duration //Forget cached uploads older than this, 0 only forgets uploads whose files changed
+}
+
+// GCReport Describes what GC removed
+type GCReport struct {
+	RemovedSessions     int   `json:"removed_sessions"`      //Sessions removed from the journal
+	AbortedSessions     int   `json:"aborted_sessions"`      //Removed sessions the data node aborted
+	RemovedCacheEntries int   `json:"removed_cache_entries"` //Entries removed from the upload cache
+	RemovedTempFiles    int   `json:"removed_temp_files"`    //Temporary files and directories removed
+	ReclaimedBytes      int64 `json:"reclaimed_bytes"`       //Disk space freed
+}
+
+// GC is a function responsible for removing stale local state
+// expired sessions, cache entries of changed files and temporary files left by interrupted uploads are removed
+func (sdk VideraSDK) GC(options GCOptions) (GCReport, error) {
+	var report GCReport
+	stateSize := dirSize(sdk.stateDir)
+
+	if options.SessionMaxAge > 0 {
+		if err := sdk.gcSessions(options, &report); err != nil {
+			return report, err
+		}
+	}
+
+	if err := sdk.gcUploadCache(options, &report); err != nil {
+		return report, err
+	}
+
+	report.RemovedTempFiles, report.ReclaimedBytes = removeStaleTempFiles(sdk.stateDir, os.TempDir())
+	if reclaimed := stateSize - dirSize(sdk.stateDir); reclaimed > 0 {
+		report.ReclaimedBytes += reclaimed
+	}
+	return report, nil
+}
+
+// gcSessions is a function responsible for removing the sessions older than the max age of options from the journal
+// and counting them in report, the journal stays locked throughout so concurrent uploads don't lose their records
+func (sdk VideraSDK) gcSessions(options GCOptions, report *GCReport) error {
+	sessionJournalMutex.Lock()
+	defer sessionJournalMutex.Unlock()
+	sessions, err := sdk.Sessions()
+	if err != nil {
+		return err
+	}
+
+	remaining := []SessionRecord{}
+	for _, session := range sessions {
+		if time.Since(session.StartedAt) <= options.SessionMaxAge {
+			remaining = append(remaining, session)
+			continue
+		}
+
+		report.RemovedSessions++
+		if options.AbortSessions {
+			if err := sdk.abortSession(session); err != nil {
+				log.Println(fmt.Sprintf("Unable to abort session %s: %v", session.ID, err))
+			} else {
+				report.AbortedSessions++
+			}
+		}
+	}
+	if report.RemovedSessions > 0 {
+		if err = sdk.saveSessions(remaining); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gcUploadCache is a function responsible for removing the expired entries and entries of changed files from the upload cache
+// and counting them in report
+func (sdk VideraSDK) gcUploadCache(options GCOptions, report *GCReport) error {
+	uploadCacheMutex.Lock()
+	defer uploadCacheMutex.Unlock()
+	entries, err := sdk.UploadCache()
+	if err != nil {
+		return err
+	}
+	kept := []CacheEntry{}
+	for _, entry := range entries {
+		expired := options.CacheMaxAge > 0 && time.Since(entry.UploadedAt) > options.CacheMaxAge
+		if expired || !cachedFilesUnchanged(entry.Files) {
+			report.RemovedCacheEntries++
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	if report.RemovedCacheEntries > 0 {
+		if err = sdk.saveUploadCache(kept); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// abortSession is a function responsible for asking the data node of a session to discard it
+func (sdk VideraSDK) abortSession(session SessionRecord) error {
+	client := sdk.newClient()
+	req, _ := http.NewRequest(http.MethodPost, session.DataNode, nil)
+	req.Header.Set("Request-Type", "ABORT")
+	req.Header.Set("ID", session.ID)
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	if err = permissionError(res); err != nil {
+		return err
+	}
+	// a session the data node doesn't know anymore needs no abort
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("Unexpected response %s", res.Status)
+	}
+	return nil
+}
+
+// cachedFilesUnchanged is a function to check whether the files of a cache entry still exist unchanged
+func cachedFilesUnchanged(files []CachedFile) bool {
+	for _, file := range files {
+		fileInfo, err := os.Stat(file.Path)
+		if err != nil || fileInfo.Size() != file.Size || !fileInfo.ModTime().Equal(file.ModTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// removeStaleTempFiles is a function responsible for removing old temporary files left by interrupted operations
+// these are atomic write leftovers in the state directory and staging directories in the system temp directory
+// returns the number of removed files and directories and their size
+func removeStaleTempFiles(stateDir string, tempDir string) (int, int64) {
+	var candidates []string
+	if files, err := ioutil.ReadDir(stateDir); err == nil {
+		for _, file := range files {
+			if strings.Contains(file.Name(), ".tmp-") && time.Since(file.ModTime()) > staleTempAge {
+				candidates = append(candidates, filepath.Join(stateDir, file.Name()))
+			}
+		}
+	}
+	if files, err := ioutil.ReadDir(tempDir); err == nil {
+		for _, file := range files {
+			if strings.HasPrefix(file.Name(), "videra-") && time.Since(file.ModTime()) > staleTempAge {
+				candidates = append(candidates, filepath.Join(tempDir, file.Name()))
+			}
+		}
+	}
+
+	removed, reclaimed := 0, int64(0)
+	for _, candidate := range candidates {
+		size := dirSize(candidate)
+		if err := os.RemoveAll(candidate); err != nil {
+			log.Println("Unable to remove", candidate, err)
+			continue
+		}
+		removed, reclaimed = removed+1, reclaimed+size
+	}
+	return removed, reclaimed
+}
+
+// dirSize is a function to get the total size of the files under a path, 0 if it doesn't exist
+func dirSize(root string) int64 {
+	size := int64(0)
+	filepath.Walk(root, func(_ string, fileInfo os.FileInfo, err error) error {
+		if err == nil && !fileInfo.IsDir() {
+			size += fileInfo.Size()
+		}
+		return nil
+	})
+	return size
+}
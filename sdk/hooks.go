@@ -0,0 +1,104 @@
+package viderasdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// Hook Custom logic run around uploads, e.g. a virus scan before them or a notification after them
+type Hook interface {
+	PreUpload(event HookEvent) error  //Runs before anything is sent, failing aborts the upload unless the policy is warn
+	PostUpload(event HookEvent) error //Runs after the upload completed
+}
+
+// HookEvent Describes the upload hooks run for
+type HookEvent struct {
+	Stage    string                  `json:"stage"`             //pre_upload or post_upload
+	Filetype string                  `json:"filetype"`          //Type of the upload, job, model or video
+	Files    map[string]string       `json:"files"`             //Path or URL of each artifact keyed by artifact name
+	Results  map[string]UploadResult `json:"results,omitempty"` //Result of each completed upload keyed by model or video, post upload only
+}
+
+// ErrHookFailed Returned when a hook fails under the abort policy
+var ErrHookFailed = errors.New("Upload hook failed")
+
+// Hook failure policies
+const (
+	HookFailureAbort = "abort" //A failing hook fails the upload
+	HookFailureWarn  = "warn"  //A failing hook is logged and the upload goes on
+)
+
+// CommandHook A Hook running shell commands, the event is written as JSON to their stdin
+type CommandHook struct {
+	PreCommand  string //Command run before uploads, empty to run nothing
+	PostCommand string //Command run after uploads, empty to run nothing
+}
+
+// PreUpload runs the pre upload command
+func (hook CommandHook) PreUpload(event HookEvent) error {
+	return runHookCommand(hook.PreCommand, event)
+}
+
+// PostUpload runs the post upload command
+func (hook CommandHook) PostUpload(event HookEvent) error {
+	return runHookCommand(hook.PostCommand, event)
+}
+
+// runHookCommand is a function responsible for running a hook command through the shell with the event on stdin
+func runHookCommand(command string, event HookEvent) error {
+	if command == "" {
+		return nil
+	}
+
+	input, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stdin = bytes.NewReader(input)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %v: %s", command, err, strings.TrimSpace(string(output)))
+	}
+	if len(output) > 0 {
+		utils.Debugln(fmt.Sprintf("%s hook output: %s", event.Stage, strings.TrimSpace(string(output))))
+	}
+	return nil
+}
+
+// runHooks is a function responsible for running the hooks of a stage in order
+// under the warn policy failures are logged, otherwise the first failure is returned
+func (sdk VideraSDK) runHooks(event HookEvent) error {
+	for _, hook := range sdk.options.Hooks {
+		var err error
+		if event.Stage == "pre_upload" {
+			err = hook.PreUpload(event)
+		} else {
+			err = hook.PostUpload(event)
+		}
+		if err == nil {
+			continue
+		}
+
+		if sdk.options.HookFailurePolicy == HookFailureWarn {
+			sdk.warn(Warning{Kind: WarningHookFailed, Message: fmt.Sprintf("%s hook failed: %v", event.Stage, err)})
+			continue
+		}
+		return fmt.Errorf("%w: %s: %v", ErrHookFailed, event.Stage, err)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package viderasdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// ErrInvalidDetections Returned before upload when the detections of an incident aren't JSON
+var ErrInvalidDetections = errors.New("Invalid detections")
+
+// Roles of the uploads of an incident, tagged on each as incident-role and as the filetype of those that aren't videos
+const (
+	incidentVideo      = "video"
+	incidentDetections = "detections"
+	incidentLogs       = "logs"
+)
+
+// UploadIncident is a function responsible for uploading the video, detections and logs of an incident as linked uploads
+// every upload is tagged incident=<ID> and incident-role with its role and carries an incident object in its metadata,
+// the detections and logs are uploaded first so the metadata of the video references their IDs, then they are tagged with
+// the IDs of the others. A failure to tag them is returned along with the result of the completed uploads
+func (sdk VideraSDK) UploadIncident(files IncidentFiles) (IncidentResult, error) {
+	result := IncidentResult{ID: files.ID}
+	if result.ID == "" {
+		var err error
+		if result.ID, err = utils.NewUUIDv7(); err != nil {
+			return result, err
+		}
+	}
+	if files.Detections != "" {
+		if err := sdk.checkDetections(files.Detections); err != nil {
+			return result, err
+		}
+	}
+
+	references := map[string]interface{}{"id": result.ID}
+	uploaded := map[string]string{}
+	for _, attachment := range []struct {
+		role     string
+		location string
+		result   **UploadResult
+	}{{incidentDetections, files.Detections, &result.Detections}, {incidentLogs, files.Logs, &result.Logs}} {
+		if attachment.location == "" {
+			continue
+		}
+		attachmentResult, err := sdk.incidentSDK(result.ID, attachment.role, references).uploadIncidentFile(attachment.location, attachment.role)
+		if err != nil {
+			return result, fmt.Errorf("Unable to upload the %s of incident %s: %w", attachment.role, result.ID, err)
+		}
+		*attachment.result = &attachmentResult
+		uploaded[attachment.role] = attachmentResult.ID
+		log.Println(fmt.Sprintf("Uploaded the %s of incident %s with ID = %s", attachment.role, result.ID, attachmentResult.ID))
+	}
+
+	videoReferences := map[string]interface{}{"id": result.ID}
+	for role, id := range uploaded {
+		videoReferences[role] = id
+	}
+	var err error
+	result.Video, err = sdk.incidentSDK(result.ID, incidentVideo, videoReferences).UploadVideo(files.Video, files.ModelID)
+	if err != nil {
+		return result, fmt.Errorf("Unable to upload the video of incident %s: %w", result.ID, err)
+	}
+
+	// the IDs assigned to the uploads are only known once they complete, they reference each other through tags
+	uploaded[incidentVideo] = result.Video.ID
+	for role, id := range uploaded {
+		if role == incidentVideo {
+			continue
+		}
+		tags := map[string]string{}
+		for otherRole, otherID := range uploaded {
+			if otherRole != role {
+				tags["incident-"+otherRole] = otherID
+			}
+		}
+		if _, err = sdk.UpdateMetadata(id, MetadataChanges{SetTags: tags}); err != nil {
+			return result, fmt.Errorf("Unable to link the %s of incident %s to its other uploads: %w", role, result.ID, err)
+		}
+	}
+	return result, nil
+}
+
+// incidentSDK is a function that returns the SDK uploading a file of an incident in the given role
+// its init metadata carries an incident object with the references given, and the upload cache is skipped
+// as an earlier upload of the file belongs to another incident
+func (sdk VideraSDK) incidentSDK(incidentID string, role string, references map[string]interface{}) VideraSDK {
+	incident := map[string]interface{}{"role": role}
+	for key, val := range references {
+		incident[key] = val
+	}
+	metadata := map[string]interface{}{"incident": incident}
+	for key, val := range sdk.options.Metadata {
+		if key != "incident" {
+			metadata[key] = val
+		}
+	}
+	tags := map[string]string{"incident": incidentID, "incident-role": role}
+	for key, val := range sdk.options.Tags {
+		if _, ok := tags[key]; !ok {
+			tags[key] = val
+		}
+	}
+
+	sdk.options.Metadata, sdk.options.Tags = metadata, tags
+	sdk.options.SkipUploaded = false
+	return sdk
+}
+
+// checkDetections is a function to check the detections of an incident hold JSON, a document or a stream of them like JSON lines,
+// before anything is uploaded
+func (sdk VideraSDK) checkDetections(location string) error {
+	source, err := sdk.newSource(location)
+	if err != nil {
+		return err
+	}
+	reader, err := source.Open(0)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	decoder := json.NewDecoder(reader)
+	for documents := 0; ; documents++ {
+		var document json.RawMessage
+		err = decoder.Decode(&document)
+		if err == io.EOF && documents > 0 {
+			return nil
+		}
+		if err == io.EOF {
+			return fmt.Errorf("%w: %s is empty", ErrInvalidDetections, location)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %s isn't JSON: %v", ErrInvalidDetections, location, err)
+		}
+	}
+}
+
+// uploadIncidentFile is a function responsible for uploading a file of an incident that isn't a video with filetype as its filetype
+// they are sent as they are, without the processing of videos
+func (sdk VideraSDK) uploadIncidentFile(location string, filetype string) (UploadResult, error) {
+	sdk.deadline = sdk.uploadDeadline()
+	sdk = sdk.withRoute()
+	source, err := sdk.newSource(location)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	size, err := source.Size()
+	if err != nil {
+		return UploadResult{}, err
+	}
+	sources := map[string]Source{filetype: source}
+	checksum, _, err := sourcesChecksums(sources, []string{filetype})
+	if err != nil {
+		return UploadResult{}, err
+	}
+	result := UploadResult{Checksum: checksum}
+	headers := map[string]string{"Filesize": fmt.Sprintf("%v", size)}
+
+	start := time.Now()
+	sdk.chunkStats = newChunkStats()
+	err = sdk.retryUpload(func(trial int) error {
+		result.Retries = trial
+
+		if err := sdk.routeUpload(checksum, trial); err != nil {
+			log.Println("Can't contact master")
+			return err
+		}
+		id, err := sdk.sendInitialRequest(source, filetype, headers)
+		if err != nil {
+			log.Println("Can't connect to node")
+			return err
+		}
+
+		bytesSent, err := sdk.withReinit(&id, func(extraHeaders map[string]string) (string, error) {
+			return sdk.sendInitialRequest(source, filetype, mergeHeaders(headers, extraHeaders))
+		}).uploadFiles(id, sources, []string{filetype}, nil)
+		result.BytesSent += bytesSent
+		if err != nil {
+			return err
+		}
+
+		result.ID, result.DataNode = id, sdk.uploadURL()
+		result.Duration = time.Since(start)
+		return nil
+	})
+	sdk.emitUploadOutcome(filetype, start, err)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	sdk.chunkStats.summarize(sdk, filetype, &result)
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package viderasdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// protocolVersion Version of the data node protocol the SDK speaks, offered in the Protocol-Version header of init requests
+// version 2 adds init requests carrying a JSON body with structured metadata
+const protocolVersion = 2
+
+// dataNodeVersions Protocol version each data node answered init requests with, keyed by data node URL
+var dataNodeVersions sync.Map
+
+// initBody Describes the JSON body of init requests to data nodes speaking protocol version 2
+type initBody struct {
+	Filename string                 `json:"filename"` //Sanitized file name, UTF-8 unlike the Filename header may safely be
+	Metadata map[string]interface{} `json:"metadata"` //Metadata of the upload, values may be nested objects and lists
+}
+
+// recordProtocolVersion is a function responsible for remembering the protocol version a data node speaks, 1 when it doesn't say
+func recordProtocolVersion(dataNode string, res *http.Response) {
+	version, err := strconv.Atoi(res.Header.Get("Protocol-Version"))
+	if err != nil {
+		version = 1
+	}
+	dataNodeVersions.Store(dataNode, version)
+}
+
+// acceptsInitBody is a function to check whether a data node is known to accept the JSON init body
+// data nodes not contacted yet get the metadata in headers, their answer decides the following init requests
+func acceptsInitBody(dataNode string) bool {
+	version, ok := dataNodeVersions.Load(dataNode)
+	return ok && version.(int) >= 2
+}
+
+// initMetadata is a function to get the body and headers carrying ClientOptions.Metadata in an init request
+// data nodes accepting it get the metadata as a JSON body, the others a Metadata header holding it flattened and URL encoded
+func (sdk VideraSDK) initMetadata(filename string) ([]byte, map[string]string, error) {
+	if len(sdk.options.Metadata) == 0 {
+		return nil, nil, nil
+	}
+
+	if acceptsInitBody(sdk.uploadURL()) {
+		body, err := json.Marshal(initBody{Filename: filename, Metadata: sdk.options.Metadata})
+		if err != nil {
+			return nil, nil, fmt.Errorf("Invalid metadata: %v", err)
+		}
+		return body, map[string]string{"Init-Body": "json"}, nil
+	}
+
+	values := url.Values{}
+	keys := make([]string, 0, len(sdk.options.Metadata))
+	for key := range sdk.options.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		flattenMetadata(key, sdk.options.Metadata[key], values)
+	}
+	return nil, map[string]string{"Metadata": values.Encode()}, nil
+}
+
+// flattenMetadata is a function responsible for adding a metadata value to values under dotted keys
+// e.g. {"title": {"en": "Intro"}} becomes title.en=Intro and list items are keyed by their index
+func flattenMetadata(key string, value interface{}, values url.Values) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for name, nested := range value {
+			flattenMetadata(key+"."+name, nested, values)
+		}
+	case map[string]string:
+		for name, nested := range value {
+			values.Set(key+"."+name, nested)
+		}
+	case []interface{}:
+		for idx, nested := range value {
+			flattenMetadata(key+"."+strconv.Itoa(idx), nested, values)
+		}
+	case []string:
+		for idx, nested := range value {
+			values.Set(key+"."+strconv.Itoa(idx), nested)
+		}
+	case nil:
+	default:
+		values.Set(key, fmt.Sprint(value))
+	}
+}
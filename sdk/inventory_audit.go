@@ -0,0 +1,153 @@
+package viderasdk
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// Statuses of the items of an inventory audit
+const (
+	InventoryMatch      = "match"      //Stored under the name of the local file with the same size and checksum
+	InventoryMissing    = "missing"    //Local file the cluster stores nothing under the name of
+	InventoryExtra      = "extra"      //Asset of the cluster no local file has the name of
+	InventoryMismatch   = "mismatch"   //Stored under the name of the local file with another size or checksum
+	InventoryUnverified = "unverified" //Stored with the size of the local file but the data node doesn't report checksums
+)
+
+// InventoryItem Describes how a local file or an asset of the cluster compares in an inventory audit
+type InventoryItem struct {
+	Status        string `json:"status"`                   //match, missing, extra, mismatch or unverified
+	Name          string `json:"name"`                     //Sanitized file name the local file and the asset are paired by
+	Path          string `json:"path,omitempty"`           //Path of the local file, empty for extra assets
+	LocalSize     int64  `json:"local_size,omitempty"`     //Size of the local file
+	LocalChecksum string `json:"local_checksum,omitempty"` //Hex encoded sha256 digest of the local file, computed only when an asset of the same size exists
+	ID            string `json:"id,omitempty"`             //ID of the asset, empty for missing local files
+	Size          int64  `json:"size,omitempty"`           //Size of the asset
+	Checksum      string `json:"checksum,omitempty"`       //Hex encoded sha256 digest of the asset as reported by the data node
+}
+
+// InventoryReport Describes how a local directory compares to the inventory of the cluster
+type InventoryReport struct {
+	Dir    string          `json:"dir"`    //Directory audited
+	Items  []InventoryItem `json:"items"`  //Local files and assets ordered by name
+	Counts map[string]int  `json:"counts"` //Number of items of each status
+}
+
+// Consistent returns whether every local file is stored identically and the cluster stores nothing else
+// unverified items count as consistent as nothing shows they differ
+func (report InventoryReport) Consistent() bool {
+	return report.Counts[InventoryMissing] == 0 && report.Counts[InventoryExtra] == 0 && report.Counts[InventoryMismatch] == 0
+}
+
+// AuditDirectory is a function responsible for comparing the files under dir to the assets of the cluster matching filter
+// local files and assets are paired by sanitized file name, sizes are compared first and checksums only for equal sizes,
+// from the chunk digests of the data node, so no payload is transferred
+func (sdk VideraSDK) AuditDirectory(dir string, filter ListFilter) (InventoryReport, error) {
+	sdk = sdk.withRoute()
+	report := InventoryReport{Dir: dir, Counts: make(map[string]int)}
+
+	local := make(map[string][]InventoryItem)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		name := utils.SanitizeFilename(utils.NormalizeFilename(entry.Name()), sdk.filenameLength)
+		local[name] = append(local[name], InventoryItem{Name: name, Path: path, LocalSize: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	stored := make(map[string][]Asset)
+	assets := sdk.ListAssets(filter)
+	for assets.Next() {
+		asset := assets.Asset()
+		stored[asset.Name] = append(stored[asset.Name], asset)
+	}
+	if err = assets.Err(); err != nil {
+		return report, err
+	}
+	if err = sdk.updateUploadURL(); err != nil {
+		return report, err
+	}
+
+	for name, items := range local {
+		for _, item := range items {
+			item, err = sdk.auditItem(item, stored[name])
+			if err != nil {
+				return report, err
+			}
+			report.Items = append(report.Items, item)
+		}
+	}
+	for name, assets := range stored {
+		if _, ok := local[name]; ok {
+			continue
+		}
+		for _, asset := range assets {
+			report.Items = append(report.Items, InventoryItem{Status: InventoryExtra, Name: name, ID: asset.ID, Size: asset.Size})
+		}
+	}
+
+	sort.Slice(report.Items, func(i, j int) bool {
+		if report.Items[i].Name != report.Items[j].Name {
+			return report.Items[i].Name < report.Items[j].Name
+		}
+		return report.Items[i].Path < report.Items[j].Path
+	})
+	for _, item := range report.Items {
+		report.Counts[item.Status]++
+	}
+	return report, nil
+}
+
+// auditItem is a function to compare a local file to the assets stored under its name
+// the first asset found identical is the one reported, otherwise the first one stored
+func (sdk VideraSDK) auditItem(item InventoryItem, assets []Asset) (InventoryItem, error) {
+	if len(assets) == 0 {
+		item.Status = InventoryMissing
+		return item, nil
+	}
+
+	item.Status, item.ID, item.Size = InventoryMismatch, assets[0].ID, assets[0].Size
+	for _, asset := range assets {
+		if asset.Size != item.LocalSize {
+			continue
+		}
+
+		if item.LocalChecksum == "" {
+			source, err := sdk.newSource(item.Path)
+			if err != nil {
+				return item, err
+			}
+			if item.LocalChecksum, _, err = sourcesChecksums(map[string]Source{"file": source}, []string{"file"}); err != nil {
+				return item, fmt.Errorf("Unable to hash %s: %v", item.Path, err)
+			}
+		}
+		digests, err := sdk.storedDigests(sdk.uploadURL(), asset.ID)
+		if err != nil {
+			return item, err
+		}
+
+		switch {
+		case digests != nil && digests.Checksum == item.LocalChecksum:
+			item.Status, item.ID, item.Checksum = InventoryMatch, asset.ID, digests.Checksum
+			return item, nil
+		case digests == nil || digests.Checksum == "":
+			item.Status, item.ID, item.Checksum = InventoryUnverified, asset.ID, ""
+		case item.Status == InventoryMismatch:
+			item.ID, item.Checksum = asset.ID, digests.Checksum
+		}
+		item.Size = asset.Size
+	}
+	return item, nil
+}
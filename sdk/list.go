@@ -0,0 +1,150 @@
+package viderasdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Asset Describes an upload stored by the cluster
+type Asset struct {
+	ID        string            `json:"id"`                //ID assigned to the upload by the data node
+	Type      string            `json:"type"`              //Type of the upload, model or video
+	Name      string            `json:"name"`              //Filename of the upload
+	Size      int64             `json:"size"`              //Size of the upload in bytes
+	Project   string            `json:"project,omitempty"` //Project the upload belongs to
+	Tags      map[string]string `json:"tags,omitempty"`    //Tags of the upload
+	CreatedAt time.Time         `json:"created_at"`        //Time at which the upload was created
+}
+
+// ListFilter Server side filters and ordering of an asset listing, zero values don't filter
+type ListFilter struct {
+	Type          string            //Only list assets of this type, model or video
+	NamePrefix    string            //Only list assets whose name starts with this prefix
+	Project       string            //Only list assets of this project
+	Tags          map[string]string //Only list assets carrying all of these tags
+	CreatedAfter  time.Time         //Only list assets created at or after this time
+	CreatedBefore time.Time         //Only list assets created before this time
+	Sort          string            //Field the assets are ordered by, prefixed with - for descending order, e.g. -created_at
+	PageSize      int               //Number of assets fetched per request, 0 lets the master decide
+}
+
+// assetsPage A page of an asset listing as returned by the master
+type assetsPage struct {
+	Assets        []Asset `json:"assets"`          //Assets of the page
+	NextPageToken string  `json:"next_page_token"` //Token of the next page, empty on the last page
+}
+
+// AssetIterator Iterates over the assets of a listing, fetching pages from the master as needed
+type AssetIterator struct {
+	sdk       VideraSDK  //SDK sending the requests
+	filter    ListFilter //Filters of the listing
+	page      []Asset    //Assets of the current page
+	idx       int        //Position of the current asset in the page
+	pageToken string     //Token of the next page
+	lastPage  bool       //Whether the current page is the last one
+	err       error      //Error that stopped the iteration
+}
+
+// ListAssets is a function to list the assets stored by the cluster matching a filter
+// iterate with Next and Asset, then check Err
+func (sdk VideraSDK) ListAssets(filter ListFilter) *AssetIterator {
+	return &AssetIterator{sdk: sdk, filter: filter, idx: -1}
+}
+
+// Next advances to the next asset, returns false when there are no more assets or an error occurred
+func (iterator *AssetIterator) Next() bool {
+	if iterator.err != nil {
+		return false
+	}
+
+	iterator.idx++
+	for iterator.idx >= len(iterator.page) {
+		if iterator.lastPage {
+			return false
+		}
+		if iterator.err = iterator.fetchPage(); iterator.err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Asset returns the current asset
+func (iterator *AssetIterator) Asset() Asset {
+	return iterator.page[iterator.idx]
+}
+
+// Err returns the error that stopped the iteration, nil when all assets were listed
+func (iterator *AssetIterator) Err() error {
+	return iterator.err
+}
+
+// fetchPage is a function responsible for fetching the next page of the listing from the master
+func (iterator *AssetIterator) fetchPage() error {
+	listURL, err := url.Parse(iterator.sdk.master())
+	if err != nil {
+		return err
+	}
+	listURL.RawQuery = iterator.filter.query(iterator.pageToken).Encode()
+
+	client := iterator.sdk.newClient()
+	req, _ := http.NewRequest(http.MethodGet, listURL.String(), nil)
+	req.Header.Set("Request-Type", "LIST")
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("Unable to list assets: %s", res.Status)
+	}
+
+	var page assetsPage
+	if err = json.NewDecoder(res.Body).Decode(&page); err != nil {
+		return err
+	}
+	iterator.page, iterator.idx = page.Assets, 0
+	iterator.pageToken, iterator.lastPage = page.NextPageToken, page.NextPageToken == ""
+	return nil
+}
+
+// query is a function to encode the filter as the query of a listing request
+func (filter ListFilter) query(pageToken string) url.Values {
+	query := url.Values{}
+	setQuery := func(key string, val string) {
+		if val != "" {
+			query.Set(key, val)
+		}
+	}
+
+	setQuery("type", filter.Type)
+	setQuery("name_prefix", filter.NamePrefix)
+	setQuery("project", filter.Project)
+	setQuery("sort", filter.Sort)
+	setQuery("page_token", pageToken)
+	if filter.PageSize > 0 {
+		query.Set("page_size", strconv.Itoa(filter.PageSize))
+	}
+	if !filter.CreatedAfter.IsZero() {
+		query.Set("created_after", filter.CreatedAfter.UTC().Format(time.RFC3339))
+	}
+	if !filter.CreatedBefore.IsZero() {
+		query.Set("created_before", filter.CreatedBefore.UTC().Format(time.RFC3339))
+	}
+
+	var tags []string
+	for key, val := range filter.Tags {
+		tags = append(tags, key+"="+val)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		query.Add("tag", tag)
+	}
+	return query
+}
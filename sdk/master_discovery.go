@@ -0,0 +1,196 @@
+package viderasdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/config"
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// ErrNoMaster Returned when none of the configured masters answers as leader
+var ErrNoMaster = errors.New("No master answered as leader")
+
+// discoveryMutex Serializes discoveries of the leader among the configured masters
+var discoveryMutex sync.Mutex
+
+// leaderMutex Guards leaderURL
+var leaderMutex sync.Mutex
+
+// leaderURL Master that last answered as leader among the configured masters, empty until discovered
+var leaderURL string
+
+// masterProbe Describes the answer of a master to a discovery probe
+type masterProbe struct {
+	master string         //Upload endpoint of the probed master
+	node   string         //Upload URL of the data node the master routed to
+	res    *http.Response //Response of the master, its body is closed
+	err    error          //Why the master didn't answer as leader
+}
+
+// masterEndpoints is a function to list the masters of the config, name_node_endpoint first and those of master_endpoints without duplicates
+func masterEndpoints(configObj config.SDKConfig) []string {
+	masters := []string{configObj.NameNodeEndpoint}
+	for _, master := range configObj.MasterEndpoints {
+		duplicate := false
+		for _, listed := range masters {
+			duplicate = duplicate || listed == master
+		}
+		if !duplicate && master != "" {
+			masters = append(masters, master)
+		}
+	}
+	return masters
+}
+
+// master is a function that returns the upload endpoint requests to the master go to
+// that is the leader among the configured masters once discovered, name_node_endpoint otherwise
+func (sdk VideraSDK) master() string {
+	if len(sdk.masters) < 2 {
+		return sdk.masterURL
+	}
+	if leader := currentLeader(); leader != "" {
+		return leader
+	}
+
+	discoveryMutex.Lock()
+	defer discoveryMutex.Unlock()
+	// another discovery may have found the leader meanwhile
+	if leader := currentLeader(); leader != "" {
+		return leader
+	}
+	probe, err := sdk.probeMasters()
+	if err != nil {
+		utils.Warnln(err.Error())
+		return sdk.masterURL
+	}
+	setLeader(probe.master)
+	return probe.master
+}
+
+// knownMaster is a function that returns the leader among the configured masters when already discovered, name_node_endpoint otherwise
+// unlike master it never probes, so requests sent while discovering may use it
+func (sdk VideraSDK) knownMaster() string {
+	if leader := currentLeader(); leader != "" && len(sdk.masters) > 1 {
+		return leader
+	}
+	return sdk.masterURL
+}
+
+// currentLeader is a function that returns the last discovered leader, empty when none was
+func currentLeader() string {
+	leaderMutex.Lock()
+	defer leaderMutex.Unlock()
+	return leaderURL
+}
+
+// setLeader is a function responsible for remembering the leader among the configured masters, logging changes
+func setLeader(master string) {
+	leaderMutex.Lock()
+	defer leaderMutex.Unlock()
+	if master != "" && master != leaderURL {
+		log.Println(fmt.Sprintf("Using master %s", master))
+	}
+	leaderURL = master
+}
+
+// updateUploadURLFromMasters is a function responsible for asking the leader among the configured masters for the data node upload url
+// the last leader is asked first, every master is probed at once when it fails
+func (sdk VideraSDK) updateUploadURLFromMasters() error {
+	discoveryMutex.Lock()
+	defer discoveryMutex.Unlock()
+
+	if leader := currentLeader(); leader != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), sdk.discoveryTimeout)
+		probe := sdk.probeMaster(ctx, leader)
+		cancel()
+		if probe.err == nil || errors.Is(probe.err, ErrMissingCapability) {
+			return sdk.applyMasterProbe(probe)
+		}
+		log.Println(fmt.Sprintf("Master %s failed, probing every master: %v", leader, probe.err))
+	}
+
+	probe, err := sdk.probeMasters()
+	if err != nil {
+		setLeader("")
+		return err
+	}
+	return sdk.applyMasterProbe(probe)
+}
+
+// applyMasterProbe is a function responsible for routing uploads to the data node a master answered with, remembering the master as leader
+func (sdk VideraSDK) applyMasterProbe(probe masterProbe) error {
+	if probe.err != nil {
+		return probe.err
+	}
+	setLeader(probe.master)
+	sdk.updateMaxFileSize(probe.res)
+	sdk.setUploadURL(probe.node, "Master "+probe.master)
+	return nil
+}
+
+// probeMasters is a function responsible for probing every configured master at once, each given discovery_timeout without retries
+// the first to answer as leader wins and the other probes are cancelled, a denied permission is returned as is as retrying other masters won't help
+func (sdk VideraSDK) probeMasters() (masterProbe, error) {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), sdk.discoveryTimeout)
+	defer cancel()
+
+	probes := make(chan masterProbe, len(sdk.masters))
+	for _, master := range sdk.masters {
+		go func(master string) {
+			probes <- sdk.probeMaster(ctx, master)
+		}(master)
+	}
+
+	var failures []string
+	for range sdk.masters {
+		probe := <-probes
+		if probe.err == nil || errors.Is(probe.err, ErrMissingCapability) {
+			utils.Debugln(fmt.Sprintf("Master %s answered as leader in %v", probe.master, time.Since(start)))
+			return probe, probe.err
+		}
+		failures = append(failures, fmt.Sprintf("%s: %v", probe.master, probe.err))
+	}
+	return masterProbe{}, fmt.Errorf("%w among %v masters: %s", ErrNoMaster, len(sdk.masters), strings.Join(failures, "; "))
+}
+
+// probeMaster is a function responsible for asking a master for the data node upload url once, a master answers as leader with 200
+func (sdk VideraSDK) probeMaster(ctx context.Context, master string) masterProbe {
+	probe := masterProbe{master: master}
+	if probe.err = sdk.checkTransport(master); probe.err != nil {
+		return probe
+	}
+
+	client := sdk.newClientWithPolicy(RetryPolicy{})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, master, nil)
+	probe.res, probe.err = client.Do(req)
+	if probe.err != nil {
+		return probe
+	}
+	defer probe.res.Body.Close()
+
+	body, err := ioutil.ReadAll(probe.res.Body)
+	if err != nil {
+		probe.err = err
+		return probe
+	}
+	if probe.err = permissionError(probe.res); probe.err != nil {
+		return probe
+	}
+	if probe.res.StatusCode != http.StatusOK {
+		probe.err = fmt.Errorf("%s: %s", probe.res.Status, strings.TrimSpace(string(body)))
+		return probe
+	}
+
+	probe.node = string(body)
+	probe.err = sdk.checkTransport(probe.node)
+	return probe
+}
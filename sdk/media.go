@@ -0,0 +1,273 @@
+package viderasdk
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// mediaInput is a function to get the path or URL ffmpeg reads a source from
+func mediaInput(source Source) (string, error) {
+	switch source := source.(type) {
+	case localSource:
+		if _, ok := source.fileSystem.(utils.OSFileSystem); ok {
+			return source.path, nil
+		}
+	case httpSource:
+		return source.url, nil
+	}
+	return "", errors.New("Video processing needs a local file or an http(s) URL")
+}
+
+// processesVideo is a function to check whether videos are processed with ffmpeg before upload
+func (sdk VideraSDK) processesVideo() bool {
+	return sdk.transformsVideo() || sdk.options.SegmentDuration > 0
+}
+
+// transformsVideo is a function to check whether videos are rewritten before upload, see prepareVideo
+func (sdk VideraSDK) transformsVideo() bool {
+	return sdk.options.Trim != nil || sdk.remuxesAudio() || sdk.options.ScrubMetadata
+}
+
+// remuxesAudio is a function to check whether audio tracks are dropped before upload
+func (sdk VideraSDK) remuxesAudio() bool {
+	return sdk.options.StripAudio || sdk.options.AudioTrack > 0
+}
+
+// requireMediaFeatures is a function to fail before anything is staged or uploaded when the processing options need
+// ffmpeg or ffprobe and the binary was built without them or they aren't installed
+func (sdk VideraSDK) requireMediaFeatures() error {
+	if err := utils.RequireFeature(utils.FeatureFFmpeg); err != nil {
+		return err
+	}
+	if sdk.options.ScrubMetadata {
+		return utils.RequireFeature(utils.FeatureFFprobe)
+	}
+	return nil
+}
+
+// prepareVideo is a function responsible for applying the processing options to a video inside dir
+// returns the processed video and the init headers describing the original, or the video itself when it needs no processing
+func (sdk VideraSDK) prepareVideo(video Source, dir string) (Source, map[string]string, error) {
+	if !sdk.transformsVideo() {
+		return video, nil, nil
+	}
+
+	input, err := mediaInput(video)
+	if err != nil {
+		return nil, nil, err
+	}
+	checksum, _, err := sourcesChecksums(map[string]Source{"video": video}, []string{"video"})
+	if err != nil {
+		return nil, nil, err
+	}
+	headers := map[string]string{
+		"Source-Filename": utils.NormalizeFilename(video.Name()),
+		"Source-Checksum": checksum,
+	}
+
+	// every step reads the output of the previous one
+	if sdk.options.Trim != nil {
+		output := filepath.Join(dir, "trimmed."+video.Name())
+		if err = trimVideo(input, output, *sdk.options.Trim); err != nil {
+			return nil, nil, err
+		}
+		headers["Trim-Start"] = utils.FormatTimecode(sdk.options.Trim.Start)
+		headers["Trim-End"] = utils.FormatTimecode(sdk.options.Trim.End)
+		log.Println(fmt.Sprintf("Trimmed video to %s-%s", headers["Trim-Start"], headers["Trim-End"]))
+		input = output
+	}
+
+	if sdk.remuxesAudio() {
+		output := filepath.Join(dir, "remuxed."+video.Name())
+		if err = remuxAudio(input, output, sdk.options.StripAudio, sdk.options.AudioTrack); err != nil {
+			return nil, nil, err
+		}
+		if sdk.options.StripAudio {
+			headers["Audio-Tracks"] = "stripped"
+			log.Println("Stripped audio tracks")
+		} else {
+			headers["Audio-Tracks"] = fmt.Sprintf("%v", sdk.options.AudioTrack)
+			log.Println(fmt.Sprintf("Kept only audio track %v", sdk.options.AudioTrack))
+		}
+		input = output
+	}
+
+	if sdk.options.ScrubMetadata {
+		output := filepath.Join(dir, "scrubbed."+video.Name())
+		removed, err := scrubMetadata(input, output, sdk.options.KeepMetadata)
+		if err != nil {
+			return nil, nil, err
+		}
+		headers["Scrubbed-Metadata"] = strings.Join(removed, ",")
+		log.Println(fmt.Sprintf("Removed %v metadata tags", len(removed)))
+		for _, key := range removed {
+			utils.Debugln(fmt.Sprintf("Removed metadata tag %s", key))
+		}
+		input = output
+	}
+
+	// the processed file is uploaded under the name of the original
+	output := filepath.Join(dir, video.Name())
+	if err = os.Rename(input, output); err != nil {
+		return nil, nil, err
+	}
+	return localSource{fileSystem: utils.OSFileSystem{}, path: output}, headers, nil
+}
+
+// scrubMetadata is a function responsible for removing the container and stream metadata of a video without re-encoding
+// tags named in keep, e.g. title or creation_time, are kept on every stream, returns the removed tags with their prefix
+func scrubMetadata(input string, output string, keep []string) ([]string, error) {
+	tags, err := utils.ProbeMetadata(input)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := make(map[string]bool)
+	for _, key := range keep {
+		kept[strings.ToLower(key)] = true
+	}
+
+	args := []string{"-i", input, "-map", "0", "-map_metadata", "-1", "-fflags", "+bitexact"}
+	var removed []string
+	for prefixedKey, val := range tags {
+		separator := strings.Index(prefixedKey, ":")
+		scope, key := prefixedKey[:separator], prefixedKey[separator+1:]
+		if !kept[strings.ToLower(key)] {
+			removed = append(removed, prefixedKey)
+			continue
+		}
+
+		if scope == "format" {
+			args = append(args, "-metadata", key+"="+val)
+		} else {
+			args = append(args, "-metadata:s:"+strings.TrimPrefix(scope, "stream"), key+"="+val)
+		}
+	}
+	sort.Strings(removed)
+
+	return removed, utils.RunFFmpeg(append(args, "-c", "copy", output)...)
+}
+
+// scrubbedMetadata is a function to get the metadata tags removed from a video from its init headers
+func scrubbedMetadata(headers map[string]string) []string {
+	if headers["Scrubbed-Metadata"] == "" {
+		return nil
+	}
+	return strings.Split(headers["Scrubbed-Metadata"], ",")
+}
+
+// remuxAudio is a function responsible for dropping every audio track, or all but one counted from 1, without re-encoding
+func remuxAudio(input string, output string, stripAudio bool, audioTrack int) error {
+	args := []string{"-i", input, "-map", "0", "-map", "-0:a"}
+	if !stripAudio {
+		args = append(args, "-map", fmt.Sprintf("0:a:%v", audioTrack-1))
+	}
+	return utils.RunFFmpeg(append(args, "-c", "copy", output)...)
+}
+
+// trimVideo is a function responsible for extracting a clip of a video
+// streams are copied when the clip starts on a keyframe, otherwise the video is re-encoded to start on the exact frame
+func trimVideo(input string, output string, clip TimeRange) error {
+	copyStreams, err := utils.KeyframeAt(input, clip.Start)
+	if err != nil {
+		utils.Debugln(fmt.Sprintf("Unable to find keyframes, re-encoding the clip: %v", err))
+	}
+
+	args := []string{"-ss", utils.FormatTimecode(clip.Start), "-i", input,
+		"-t", utils.FormatTimecode(clip.End - clip.Start), "-map", "0"}
+	if copyStreams {
+		args = append(args, "-c", "copy")
+	} else {
+		args = append(args, "-c:v", "libx264", "-preset", "veryfast", "-crf", "18", "-c:a", "copy")
+	}
+	return utils.RunFFmpeg(append(args, output)...)
+}
+
+// segmentVideo is a function responsible for splitting a video into segments of about duration inside dir
+// streams are copied so segments are cut on the keyframe following each boundary, returned in playback order
+func segmentVideo(video Source, duration time.Duration, dir string) ([]Source, error) {
+	input, err := mediaInput(video)
+	if err != nil {
+		return nil, err
+	}
+
+	name := video.Name()
+	ext := filepath.Ext(name)
+	pattern := filepath.Join(dir, strings.TrimSuffix(name, ext)+".segment%04d"+ext)
+	err = utils.RunFFmpeg("-i", input, "-map", "0", "-c", "copy", "-f", "segment",
+		"-segment_time", fmt.Sprintf("%.3f", duration.Seconds()), "-reset_timestamps", "1", pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, filepath.Join(dir, file.Name()))
+	}
+	sort.Strings(paths)
+	if len(paths) == 0 {
+		return nil, errors.New("ffmpeg produced no segments")
+	}
+
+	var segments []Source
+	for _, path := range paths {
+		segments = append(segments, localSource{fileSystem: utils.OSFileSystem{}, path: path})
+	}
+	return segments, nil
+}
+
+// uploadSegments is a function responsible for uploading video segments in order as a linked series
+// the ID of the first segment is the parent ID of the series, the others are initialized with it
+// extraHeaders describing the original video are sent with every segment
+func (sdk VideraSDK) uploadSegments(segments []Source, associatedModelID string, extraHeaders map[string]string) ([]UploadResult, error) {
+	var results []UploadResult
+	parentID := ""
+	for idx, segment := range segments {
+		start := time.Now()
+		checksum, _, err := sourcesChecksums(map[string]Source{"video": segment}, []string{"video"})
+		if err != nil {
+			return results, err
+		}
+
+		headers := map[string]string{
+			"Segment-Index": fmt.Sprintf("%v", idx),
+			"Segment-Count": fmt.Sprintf("%v", len(segments)),
+		}
+		for key, val := range extraHeaders {
+			headers[key] = val
+		}
+		if parentID != "" {
+			headers["Parent-ID"] = parentID
+		}
+		id, err := sdk.sendVideoInitialRequest(segment, associatedModelID, headers)
+		if err != nil {
+			return results, err
+		}
+
+		bytesSent, err := sdk.withReinit(&id, func(extraHeaders map[string]string) (string, error) {
+			return sdk.sendVideoInitialRequest(segment, associatedModelID, mergeHeaders(headers, extraHeaders))
+		}).uploadFiles(id, map[string]Source{"video": segment}, []string{"video"}, nil)
+		if parentID == "" {
+			parentID = id
+		}
+		results = append(results, UploadResult{ID: id, ProposedID: sdk.proposedID("video", headers), BytesSent: bytesSent, DataNode: sdk.uploadURL(), Checksum: checksum})
+		if err != nil {
+			return results, err
+		}
+		results[idx].Duration = time.Since(start)
+	}
+	return results, nil
+}
@@ -0,0 +1,144 @@
+package viderasdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"unicode/utf8"
+)
+
+// companionMaxSize Largest companion document accepted, companions describe a model and are never data
+const companionMaxSize = 1 << 20
+
+// ErrInvalidCompanion Returned before upload when a companion document of the model can't be read or is malformed
+var ErrInvalidCompanion = errors.New("Invalid companion document")
+
+// companion Describes a small document attached to a model once its artifacts are uploaded
+type companion struct {
+	kind        string //Companion header naming the document, e.g. metrics
+	contentType string //Content-Type the document is sent with
+	body        []byte //The document
+}
+
+// readCompanions is a function responsible for reading the companion documents given in the client options
+// they are read and validated before the model is uploaded so a broken document doesn't waste the upload
+func (sdk VideraSDK) readCompanions() ([]companion, error) {
+	var companions []companion
+	if sdk.options.MetricsFile != "" {
+		body, err := sdk.readCompanionFile(sdk.options.MetricsFile)
+		if err != nil {
+			return nil, err
+		}
+		var metrics map[string]interface{}
+		if err = json.Unmarshal(body, &metrics); err != nil {
+			return nil, fmt.Errorf("%w: metrics file %s must hold a JSON object: %v", ErrInvalidCompanion, sdk.options.MetricsFile, err)
+		}
+		companions = append(companions, companion{kind: "metrics", contentType: "application/json", body: body})
+	}
+	if sdk.options.ModelCard != "" {
+		body, err := sdk.readCompanionFile(sdk.options.ModelCard)
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(body) {
+			return nil, fmt.Errorf("%w: model card %s isn't UTF-8 text", ErrInvalidCompanion, sdk.options.ModelCard)
+		}
+		companions = append(companions, companion{kind: "model-card", contentType: "text/markdown; charset=utf-8", body: body})
+	}
+
+	return companions, nil
+}
+
+// readCompanionFile is a function to read a companion document from a local path or URL
+func (sdk VideraSDK) readCompanionFile(location string) ([]byte, error) {
+	source, err := sdk.newSource(location)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCompanion, err)
+	}
+	reader, err := source.Open(0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCompanion, err)
+	}
+	defer reader.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(reader, companionMaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCompanion, err)
+	}
+	if len(body) > companionMaxSize {
+		return nil, fmt.Errorf("%w: %s is larger than the %v bytes companion documents may take", ErrInvalidCompanion, location, companionMaxSize)
+	}
+	return body, nil
+}
+
+// attachCompanions is a function responsible for attaching companion documents to a stored model
+func (sdk VideraSDK) attachCompanions(id string, companions []companion) error {
+	for _, document := range companions {
+		if err := sdk.attachCompanion(id, document); err != nil {
+			return err
+		}
+		log.Println(fmt.Sprintf("Attached %s to model %s", document.kind, id))
+	}
+
+	return nil
+}
+
+// attachCompanion is a function responsible for storing a companion document of a model on the master
+// the document replaces any previous one of its kind so attaching again after a retry is harmless
+func (sdk VideraSDK) attachCompanion(id string, document companion) error {
+	client := sdk.newClient()
+	req, _ := http.NewRequest(http.MethodPut, sdk.master(), bytes.NewReader(document.body))
+	req.Header.Set("Request-Type", "COMPANION")
+	req.Header.Set("ID", id)
+	req.Header.Set("Companion", document.kind)
+	req.Header.Set("Content-Type", document.contentType)
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if err = permissionError(res); err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("Unable to attach %s to model %s: %s", document.kind, id, res.Status)
+	}
+	return nil
+}
+
+// ModelCard is a function responsible for getting the model card attached to a stored model version with ClientOptions.ModelCard
+func (sdk VideraSDK) ModelCard(id string) (string, error) {
+	body, err := sdk.fetchCompanion(id, "model-card")
+	return string(body), err
+}
+
+// fetchCompanion is a function responsible for getting a companion document of a stored model from the master
+func (sdk VideraSDK) fetchCompanion(id string, kind string) ([]byte, error) {
+	client := sdk.newClient()
+	req, _ := http.NewRequest(http.MethodGet, sdk.master(), nil)
+	req.Header.Set("Request-Type", "COMPANION")
+	req.Header.Set("ID", id)
+	req.Header.Set("Companion", kind)
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if err = permissionError(res); err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("Model %s has no %s attached", id, kind)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unable to get the %s of model %s: %s", kind, id, res.Status)
+	}
+	return ioutil.ReadAll(io.LimitReader(res.Body, companionMaxSize))
+}
@@ -0,0 +1,136 @@
+package viderasdk
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ErrConfigUnchanged Returned when a config update carries the config the model already has
+var ErrConfigUnchanged = errors.New("Config is unchanged")
+
+// UpdateModelConfig is a function responsible for uploading only the config of a stored model as a new minor version of it
+// the new version references the model and code artifacts already stored, identified by the digests of the manifest,
+// so thresholds or class lists change without uploading the model again
+func (sdk VideraSDK) UpdateModelConfig(id string, configPath string) (UploadResult, error) {
+	sdk.deadline = sdk.uploadDeadline()
+	sdk = sdk.withRoute()
+	manifest, err := sdk.ModelManifest(id)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	reused := make(map[string]string)
+	modelName := ""
+	for _, artifact := range manifest.Artifacts {
+		reused[artifact.Name] = artifact.Checksum
+		if artifact.Name == "model" {
+			modelName = artifact.Filename
+		}
+	}
+	if reused["model"] == "" || reused["code"] == "" {
+		return UploadResult{}, fmt.Errorf("Manifest of model %s lacks the model or code artifact", id)
+	}
+
+	sources, err := sdk.newSources(map[string]string{"config": configPath})
+	if err != nil {
+		return UploadResult{}, err
+	}
+	sources, cleanup, err := sdk.stageSources(sources)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	defer cleanup()
+
+	checksum, checksums, err := sourcesChecksums(sources, []string{"config"})
+	if err != nil {
+		return UploadResult{}, err
+	}
+	if checksum == reused["config"] {
+		return UploadResult{}, fmt.Errorf("%w: model %s already has config %s", ErrConfigUnchanged, id, checksum)
+	}
+	if err = sdk.validateModelConfig(modelName, sources["config"]); err != nil {
+		return UploadResult{}, err
+	}
+	companions, err := sdk.readCompanions()
+	if err != nil {
+		return UploadResult{}, err
+	}
+	result := UploadResult{Checksum: checksum}
+
+	files := map[string]string{"config": configPath}
+	if err = sdk.runHooks(HookEvent{Stage: "pre_upload", Filetype: "model", Files: files}); err != nil {
+		return UploadResult{}, err
+	}
+
+	start := time.Now()
+	sdk.chunkStats = newChunkStats()
+	err = sdk.retryUpload(func(trial int) error {
+		result.Retries = trial
+
+		err := sdk.updateUploadURL()
+		if err != nil {
+			log.Println("Can't contact master")
+			log.Println(err)
+			return err
+		}
+
+		versionID, err := sdk.sendConfigUpdateInitialRequest(id, sources, checksums, reused, nil)
+		if err != nil {
+			log.Println("Can't connect to node")
+			log.Println(err)
+			return err
+		}
+
+		log.Println(fmt.Sprintf("Sent inital request for config of model %s with ID = %s", id, versionID))
+		bytesSent, err := sdk.withReinit(&versionID, func(extraHeaders map[string]string) (string, error) {
+			return sdk.sendConfigUpdateInitialRequest(id, sources, checksums, reused, extraHeaders)
+		}).uploadFiles(versionID, sources, []string{"config"}, modelChecksumHeaders(checksums))
+		result.BytesSent += bytesSent
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+
+		log.Println("Upload successful")
+		result.ID, result.DataNode = versionID, sdk.uploadURL()
+		result.Duration = time.Since(start)
+		return nil
+	})
+	sdk.emitUploadOutcome("model", start, err)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	sdk.chunkStats.summarize(sdk, "model", &result)
+	if err = sdk.attachCompanions(result.ID, companions); err != nil {
+		return result, err
+	}
+
+	results := map[string]UploadResult{"model": result}
+	sdk.auditUpload("model", files, results)
+	sdk.accountUpload(results)
+	sdk.catalogUpload(files, results)
+	return result, sdk.runHooks(HookEvent{Stage: "post_upload", Filetype: "model", Files: files, Results: results})
+}
+
+// sendConfigUpdateInitialRequest is a function responsible for sending the initial request of a config only model version
+// reused holds the digests of the stored artifacts of the model the data node links into the new version
+func (sdk VideraSDK) sendConfigUpdateInitialRequest(id string, sources map[string]Source, checksums map[string]string,
+	reused map[string]string, extraHeaders map[string]string) (string, error) {
+	configSize, err := sources["config"].Size()
+	if err != nil {
+		return "", err
+	}
+
+	headers := map[string]string{
+		"Filesize":        fmt.Sprintf("%v", configSize),
+		"Config-Size":     fmt.Sprintf("%v", configSize),
+		"Config-Checksum": checksums["config"],
+		"Model-Checksum":  reused["model"],
+		"Code-Checksum":   reused["code"],
+		"Reuse-Artifacts": "model,code",
+		"Version-Of":      id,
+		"Version-Bump":    "minor",
+	}
+	return sdk.sendInitialRequest(sources["config"], "model", mergeHeaders(headers, extraHeaders))
+}
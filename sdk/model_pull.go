@@ -0,0 +1,222 @@
+package viderasdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// ModelManifest Describes the artifacts of a model stored by the cluster
+type ModelManifest struct {
+	ID        string          `json:"id"`        //ID of the model
+	Artifacts []ModelArtifact `json:"artifacts"` //Artifacts of the model in upload order
+}
+
+// ModelArtifact Describes a single artifact of a stored model
+type ModelArtifact struct {
+	Name     string `json:"name"`     //Artifact name (model, config or code)
+	Filename string `json:"filename"` //Name the artifact is stored under
+	Size     int64  `json:"size"`     //Size of the artifact in bytes
+	Checksum string `json:"checksum"` //Hex encoded sha256 digest of the artifact
+}
+
+// ModelManifest is a function responsible for asking the data node for the manifest of a stored model
+func (sdk VideraSDK) ModelManifest(id string) (ModelManifest, error) {
+	sdk = sdk.withRoute()
+	var manifest ModelManifest
+	if err := sdk.updateUploadURL(); err != nil {
+		return manifest, err
+	}
+
+	client := sdk.newClient()
+	req, _ := http.NewRequest(http.MethodGet, sdk.uploadURL(), nil)
+	req.Header.Set("Request-Type", "MANIFEST")
+	req.Header.Set("ID", id)
+	res, err := client.Do(req)
+	if err != nil {
+		return manifest, err
+	}
+	defer res.Body.Close()
+
+	if err = permissionError(res); err != nil {
+		return manifest, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return manifest, fmt.Errorf("Unable to get the manifest of model %s: %s", id, res.Status)
+	}
+	err = json.NewDecoder(res.Body).Decode(&manifest)
+	return manifest, err
+}
+
+// PullModel is a function responsible for downloading the artifacts of a stored model into dir
+// artifacts are downloaded in ranges fetched concurrently, downloads resume the ranges missing from partial files
+// left by an interrupted pull, every artifact is verified against
+// the manifest before it is moved into place so dir never holds a partial or corrupt artifact
+func (sdk VideraSDK) PullModel(id string, dir string) (ModelManifest, error) {
+	sdk = sdk.withRoute()
+	manifest, err := sdk.ModelManifest(id)
+	if err != nil {
+		return manifest, err
+	}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return manifest, err
+	}
+
+	for _, artifact := range manifest.Artifacts {
+		filename := filepath.Base(artifact.Filename)
+		if filename == "." || filename == string(filepath.Separator) {
+			return manifest, fmt.Errorf("Invalid filename %q for artifact %s", artifact.Filename, artifact.Name)
+		}
+
+		partPath := filepath.Join(dir, "."+filename+".part")
+		err = sdk.retryUpload(func(trial int) error {
+			return sdk.downloadArtifact(id, artifact, partPath)
+		})
+		if err != nil {
+			return manifest, err
+		}
+
+		checksum, err := utils.GetFileChecksum(partPath)
+		if err != nil {
+			return manifest, err
+		}
+		if checksum != artifact.Checksum {
+			os.Remove(partPath)
+			os.Remove(rangesPath(partPath))
+			return manifest, fmt.Errorf("Checksum mismatch for %s: expected %s, got %s", artifact.Name, artifact.Checksum, checksum)
+		}
+
+		if err = os.Rename(partPath, filepath.Join(dir, filename)); err != nil {
+			return manifest, err
+		}
+		os.Remove(rangesPath(partPath))
+		log.Println(fmt.Sprintf("Pulled %s into %s", artifact.Name, filepath.Join(dir, filename)))
+	}
+	return manifest, nil
+}
+
+// downloadArtifact is a function responsible for downloading the ranges of an artifact missing from its partial file,
+// as many at once as download_ranges allows, written in place and recorded in the range map of the partial file as they complete
+func (sdk VideraSDK) downloadArtifact(id string, artifact ModelArtifact, partPath string) error {
+	partFile, err := os.OpenFile(partPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer partFile.Close()
+
+	ranges, err := loadArtifactRanges(partFile, partPath, artifact)
+	if err != nil {
+		return err
+	}
+	// the range map exists before any range is written so a partial file with holes is never read as a prefix
+	if err = ranges.save(partPath); err != nil {
+		return err
+	}
+
+	missing := ranges.missing(sdk.chunkSize)
+	workers := sdk.downloadRanges
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(missing) {
+		workers = len(missing)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pending := make(chan [2]int64, len(missing))
+	for _, span := range missing {
+		pending <- span
+	}
+	close(pending)
+
+	client := sdk.newClient()
+	progress := sdk.newDownloadProgress(artifact.Name, artifact.Size)
+	var mutex sync.Mutex
+	var firstErr error
+	var wait sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for span := range pending {
+				err := sdk.downloadRange(ctx, client, id, artifact, partFile, span[0], span[1])
+				if err == nil {
+					// the range reaches the disk before the range map says it was written
+					err = partFile.Sync()
+				}
+
+				mutex.Lock()
+				if err == nil {
+					ranges.add(span[0], span[1])
+					err = ranges.save(partPath)
+					progress.update(ranges.written())
+				}
+				if err != nil && firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mutex.Unlock()
+				if err != nil {
+					return
+				}
+			}
+		}()
+	}
+	wait.Wait()
+	return firstErr
+}
+
+// downloadRange is a function responsible for downloading the inclusive range start-end of an artifact into its partial file
+// requesting the rest of the range again as long as the data node answers with less than asked
+func (sdk VideraSDK) downloadRange(ctx context.Context, client *http.Client, id string, artifact ModelArtifact, partFile *os.File, start int64, end int64) error {
+	for start <= end {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, sdk.uploadURL(), nil)
+		req.Header.Set("Request-Type", "DOWNLOAD")
+		req.Header.Set("ID", id)
+		req.Header.Set("Artifact", artifact.Name)
+		req.Header.Set("Range", fmt.Sprintf("bytes=%v-%v", start, end))
+		res, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+
+		if res.StatusCode != http.StatusPartialContent {
+			res.Body.Close()
+			return fmt.Errorf("Unable to download %s: %s", artifact.Name, res.Status)
+		}
+		// a data node sending more than the range must not write past it, a shorter range is continued
+		written, err := io.CopyN(&offsetWriter{file: partFile, offset: start}, res.Body, end-start+1)
+		res.Body.Close()
+		start += written
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if written == 0 {
+			return errors.New("Data node returned an empty range")
+		}
+	}
+	return nil
+}
+
+// offsetWriter Writes to a file at consecutive positions starting at offset, leaving the file offset untouched
+type offsetWriter struct {
+	file   *os.File //File written to
+	offset int64    //Position of the next write
+}
+
+// Write is a function responsible for writing p at the position of the writer and moving it past p
+func (writer *offsetWriter) Write(p []byte) (int, error) {
+	written, err := writer.file.WriteAt(p, writer.offset)
+	writer.offset += int64(written)
+	return written, err
+}
@@ -0,0 +1,131 @@
+package viderasdk
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestDownloadArtifactRanges checks an artifact downloads to exactly its bytes whether the data node answers a range
+// with the range, with more than it or with less than it
+func TestDownloadArtifactRanges(t *testing.T) {
+	artifact := bytes.Repeat([]byte("0123456789"), 1000)
+	tests := []struct {
+		name  string
+		serve func(start int64, end int64) []byte
+	}{
+		{"exact ranges", func(start int64, end int64) []byte { return artifact[start : end+1] }},
+		{"ranges past their end", func(start int64, end int64) []byte {
+			return append(append([]byte{}, artifact[start:end+1]...), "trailing"...)
+		}},
+		{"short ranges", func(start int64, end int64) []byte { return artifact[start : start+(end-start+1)/2+1] }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var start, end int64
+				fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end)
+				w.WriteHeader(http.StatusPartialContent)
+				w.Write(test.serve(start, end))
+			}))
+			defer server.Close()
+
+			sdk := VideraSDK{chunkSize: 1024}.withRoute()
+			sdk.routeTo(server.URL)
+			partPath := filepath.Join(t.TempDir(), ".model.bin.part")
+			err := sdk.downloadArtifact("1", ModelArtifact{Name: "model", Size: int64(len(artifact))}, partPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			downloaded, _ := ioutil.ReadFile(partPath)
+			if !bytes.Equal(downloaded, artifact) {
+				t.Errorf("downloaded %v bytes, expected the %v bytes of the artifact", len(downloaded), len(artifact))
+			}
+		})
+	}
+}
+
+// TestDownloadArtifactParallelResume checks the ranges of an artifact are downloaded concurrently and a pull resumes
+// only the ranges its range map is missing, or the prefix left by a sequential pull
+func TestDownloadArtifactParallelResume(t *testing.T) {
+	artifact := bytes.Repeat([]byte("0123456789"), 1000)
+	tests := []struct {
+		name      string
+		prepare   func(partPath string)
+		requested int64
+	}{
+		{"fresh", func(partPath string) {}, 10000},
+		{"range map", func(partPath string) {
+			partial := make([]byte, 6000)
+			copy(partial[:2048], artifact)
+			copy(partial[4096:], artifact[4096:6000])
+			ioutil.WriteFile(partPath, partial, 0644)
+			ranges := artifactRanges{Size: 10000, Checksum: "digest", Done: [][2]int64{{0, 2047}, {4096, 5999}}}
+			ranges.save(partPath)
+		}, 10000 - 2048 - 1904},
+		{"sequential prefix", func(partPath string) {
+			ioutil.WriteFile(partPath, artifact[:3000], 0644)
+		}, 7000},
+		{"other version", func(partPath string) {
+			ioutil.WriteFile(partPath, bytes.Repeat([]byte("x"), 3000), 0644)
+			ranges := artifactRanges{Size: 10000, Checksum: "older", Done: [][2]int64{{0, 2999}}}
+			ranges.save(partPath)
+		}, 10000},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var mutex sync.Mutex
+			inFlight, peak, requested := 0, 0, int64(0)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var start, end int64
+				fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end)
+				mutex.Lock()
+				inFlight++
+				if inFlight > peak {
+					peak = inFlight
+				}
+				requested += end - start + 1
+				mutex.Unlock()
+				time.Sleep(10 * time.Millisecond)
+				mutex.Lock()
+				inFlight--
+				mutex.Unlock()
+				w.WriteHeader(http.StatusPartialContent)
+				w.Write(artifact[start : end+1])
+			}))
+			defer server.Close()
+
+			sdk := VideraSDK{chunkSize: 1024, downloadRanges: 4}.withRoute()
+			sdk.routeTo(server.URL)
+			partPath := filepath.Join(t.TempDir(), ".model.bin.part")
+			test.prepare(partPath)
+			err := sdk.downloadArtifact("1", ModelArtifact{Name: "model", Size: int64(len(artifact)), Checksum: "digest"}, partPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			downloaded, _ := ioutil.ReadFile(partPath)
+			if !bytes.Equal(downloaded, artifact) {
+				t.Errorf("downloaded %v bytes differing from the %v bytes of the artifact", len(downloaded), len(artifact))
+			}
+			if requested != test.requested {
+				t.Errorf("requested %v bytes, expected %v", requested, test.requested)
+			}
+			if peak < 2 {
+				t.Errorf("%v ranges in flight at once, expected them downloaded concurrently", peak)
+			}
+			if peak > sdk.downloadRanges {
+				t.Errorf("%v ranges in flight at once, download_ranges is %v", peak, sdk.downloadRanges)
+			}
+		})
+	}
+}
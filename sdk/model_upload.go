@@ -1,25 +1,23 @@
 package viderasdk
 
 import (
-	"errors"
 	"fmt"
 	"log"
 	"time"
-
-	"github.com/SayedAlesawy/Videra-SDK/utils"
 )
 
 // sendModelInitialRequest is a function responsible for sending initial upload request for model
-func (sdk VideraSDK) sendModelInitialRequest(modelPath string, configPath string, codePath string) (string, error) {
-	modelSize, err := utils.GetFileSize(modelPath)
+// checksums holds the digest of each artifact so the data node can validate them at reassembly
+func (sdk VideraSDK) sendModelInitialRequest(sources map[string]Source, checksums map[string]string, extraHeaders map[string]string) (string, error) {
+	modelSize, err := sources["model"].Size()
 	if err != nil {
 		return "", err
 	}
-	configSize, err := utils.GetFileSize(configPath)
+	configSize, err := sources["config"].Size()
 	if err != nil {
 		return "", err
 	}
-	codeSize, err := utils.GetFileSize(codePath)
+	codeSize, err := sources["code"].Size()
 	if err != nil {
 		return "", err
 	}
@@ -30,43 +28,128 @@ func (sdk VideraSDK) sendModelInitialRequest(modelPath string, configPath string
 		"Config-Size": fmt.Sprintf("%v", configSize),
 		"Code-Size":   fmt.Sprintf("%v", codeSize),
 	}
+	// data nodes supporting it accept the artifacts as concurrent streams, re-initialized sessions continue as a single one
+	if extraHeaders["Resume-From"] == "" {
+		headers["Parallel-Artifacts"] = "offered"
+	}
+	if sdk.options.VersionOf != "" {
+		headers["Version-Of"] = sdk.options.VersionOf
+	}
+	for header, digest := range modelChecksumHeaders(checksums) {
+		headers[header] = digest
+	}
+	for key, val := range extraHeaders {
+		headers[key] = val
+	}
 
-	return sdk.sendInitialRequest(modelPath, "model", headers)
+	return sdk.sendInitialRequest(sources["model"], "model", headers)
 }
 
 // UploadModel is a function responsible for uploading model
-func (sdk VideraSDK) uploadModel(modelPath string, configPath string, codePath string) error {
-	ticker := time.NewTicker(time.Duration(sdk.defaultWaitingTime) * time.Second)
+func (sdk VideraSDK) UploadModel(modelPath string, configPath string, codePath string) (UploadResult, error) {
+	sdk.deadline = sdk.uploadDeadline()
+	sdk = sdk.withRoute()
+	sources, err := sdk.newSources(map[string]string{
+		"model":  modelPath,
+		"config": configPath,
+		"code":   codePath,
+	})
+	if err != nil {
+		return UploadResult{}, err
+	}
+	fingerprint := sourcesFingerprint(sources, modelUploadOrder)
+	sources, cleanup, err := sdk.stageSources(sources)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	defer cleanup()
+
+	checksum, checksums, err := sourcesChecksums(sources, modelUploadOrder)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	result := UploadResult{Checksum: checksum, ProposedID: sdk.proposedID("model", nil)}
+	if err = sdk.validateModelConfig(sources["model"].Name(), sources["config"]); err != nil {
+		return UploadResult{}, err
+	}
+	companions, err := sdk.readCompanions()
+	if err != nil {
+		return UploadResult{}, err
+	}
 
-	for trial := 0; trial <= sdk.defaultMaxRetries; trial, _ = trial+1, <-ticker.C {
-		err := sdk.updateUploadURL()
+	if sdk.options.SkipUploaded {
+		if cachedID := sdk.lookupUploadCache("model", fingerprint, checksum, ""); cachedID != "" {
+			log.Println("Model was already uploaded with ID =", cachedID)
+			result.ID = cachedID
+			return result, nil
+		}
+	}
+
+	files := map[string]string{"model": modelPath, "config": configPath, "code": codePath}
+	if err = sdk.runHooks(HookEvent{Stage: "pre_upload", Filetype: "model", Files: files}); err != nil {
+		return UploadResult{}, err
+	}
+
+	start := time.Now()
+	sdk.chunkStats = newChunkStats()
+	err = sdk.retryUpload(func(trial int) error {
+		result.Retries = trial
+
+		err := sdk.routeUpload(checksum, trial)
 		if err != nil {
 			log.Println("Can't contact master")
 			log.Println(err)
-			continue
+			return err
 		}
 
-		modelID, err := sdk.sendModelInitialRequest(modelPath, configPath, codePath)
+		modelID, err := sdk.sendModelInitialRequest(sources, checksums, nil)
 		if err != nil {
 			log.Println("Can't connect to node")
 			log.Println(err)
-			continue
+			return err
 		}
 
 		log.Println("Sent inital request for model with ID =", modelID)
-		uploadFilesPaths := map[string]string{
-			"model":  modelPath,
-			"config": configPath,
-			"code":   codePath,
-		}
-		err = sdk.uploadFiles(modelID, uploadFilesPaths, modelUploadOrder)
+		bytesSent, err := sdk.withReinit(&modelID, func(extraHeaders map[string]string) (string, error) {
+			return sdk.sendModelInitialRequest(sources, checksums, extraHeaders)
+		}).uploadModelFiles(modelID, sources, modelChecksumHeaders(checksums))
+		result.BytesSent += bytesSent
 		if err != nil {
 			log.Println(err)
-			continue
+			return err
 		}
 
 		log.Println("Upload successful")
+		result.ID, result.DataNode = modelID, sdk.uploadURL()
+		result.Duration = time.Since(start)
 		return nil
+	})
+	sdk.emitUploadOutcome("model", start, err)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	sdk.chunkStats.summarize(sdk, "model", &result)
+	if err = sdk.attachCompanions(result.ID, companions); err != nil {
+		return result, err
 	}
-	return errors.New("An error has occurred")
+
+	if err = sdk.recordUploadCache("model", fingerprint, result, ""); err != nil {
+		log.Println("Unable to record upload in cache:", err)
+	}
+
+	results := map[string]UploadResult{"model": result}
+	sdk.auditUpload("model", files, results)
+	sdk.accountUpload(results)
+	sdk.catalogUpload(files, results)
+	return result, sdk.runHooks(HookEvent{Stage: "post_upload", Filetype: "model", Files: files, Results: results})
+}
+
+// modelChecksumHeaders is a function to map model artifacts digests to their header names
+func modelChecksumHeaders(checksums map[string]string) map[string]string {
+	headers := make(map[string]string)
+	for name, digest := range checksums {
+		headers[checksumHeaders[name]] = digest
+	}
+
+	return headers
 }
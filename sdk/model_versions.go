@@ -0,0 +1,43 @@
+package viderasdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ModelVersion Describes a version of a model stored by the cluster
+type ModelVersion struct {
+	ID         string                 `json:"id"`                   //ID of the model version
+	Version    string                 `json:"version"`              //Version the master numbered it with, e.g. 3 or 3.1
+	VersionOf  string                 `json:"version_of,omitempty"` //ID of the version it was uploaded as a new version of
+	Name       string                 `json:"name"`                 //Filename of the model artifact
+	UploadedAt time.Time              `json:"uploaded_at"`          //Time at which the version was uploaded
+	Metrics    map[string]interface{} `json:"metrics,omitempty"`    //Evaluation metrics attached with ClientOptions.MetricsFile
+}
+
+// ModelVersions is a function responsible for asking the master for every version of the model the given ID is a version of
+// versions are returned oldest first with their evaluation metrics so they can be compared
+func (sdk VideraSDK) ModelVersions(id string) ([]ModelVersion, error) {
+	client := sdk.newClient()
+	req, _ := http.NewRequest(http.MethodGet, sdk.master(), nil)
+	req.Header.Set("Request-Type", "VERSIONS")
+	req.Header.Set("ID", id)
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if err = permissionError(res); err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unable to get the versions of model %s: %s", id, res.Status)
+	}
+
+	var versions []ModelVersion
+	err = json.NewDecoder(res.Body).Decode(&versions)
+	return versions, err
+}
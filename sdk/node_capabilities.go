@@ -0,0 +1,141 @@
+package viderasdk
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/httpx"
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// nodeCapabilitiesFile Name of the file caching the capabilities advertised by each data node inside the state directory
+const nodeCapabilitiesFile = "node_capabilities.json"
+
+// nodeCapabilitiesTTL Time after which the capabilities of a data node are probed again
+const nodeCapabilitiesTTL = time.Hour
+
+// nodeProbeTimeout Time a data node is given to answer a capabilities probe
+const nodeProbeTimeout = 5 * time.Second
+
+// nodeCapabilitiesMutex Serializes probes and updates of the node capabilities file
+var nodeCapabilitiesMutex sync.Mutex
+
+// NodeCapabilities Describes the limits and features a data node advertised, empty fields weren't advertised
+type NodeCapabilities struct {
+	Advertised     bool      `json:"advertised"`                 //Whether the data node answered the probe, older data nodes don't
+	MaxRequestSize int64     `json:"max_request_size,omitempty"` //Largest chunk request accepted, from Max-Request-Size
+	MaxFileSize    int64     `json:"max_file_size,omitempty"`    //Largest upload accepted, from Max-File-Size
+	Checksums      []string  `json:"checksums,omitempty"`        //Chunk checksum algorithms supported, from Checksum-Algorithms
+	Encodings      []string  `json:"encodings,omitempty"`        //Chunk encodings supported, from Chunk-Encodings
+	ProbedAt       time.Time `json:"probed_at"`                  //Time at which the data node was probed
+}
+
+// nodeCapabilities is a function responsible for getting the capabilities of a data node, probed with an OPTIONS request
+// and cached per node in the state directory for nodeCapabilitiesTTL. Data nodes that can't be reached aren't cached
+// and advertise nothing, the init request then fails or learns the limits as before
+func (sdk VideraSDK) nodeCapabilities(node string) NodeCapabilities {
+	if !sdk.probeNodeCapabilities || node == "" {
+		return NodeCapabilities{}
+	}
+
+	nodeCapabilitiesMutex.Lock()
+	defer nodeCapabilitiesMutex.Unlock()
+
+	cached := make(map[string]NodeCapabilities)
+	if err := sdk.loadStateFile(nodeCapabilitiesFile, &cached); err != nil {
+		log.Println("Ignoring cached node capabilities:", err)
+		cached = make(map[string]NodeCapabilities)
+	}
+	if capabilities, ok := cached[node]; ok && time.Since(capabilities.ProbedAt) < nodeCapabilitiesTTL {
+		return capabilities
+	}
+
+	capabilities, err := sdk.probeNode(node)
+	if err != nil {
+		utils.Debugln(fmt.Sprintf("Unable to probe the capabilities of data node %s: %v", node, err))
+		return NodeCapabilities{}
+	}
+	if capabilities.Advertised {
+		log.Println(fmt.Sprintf("Data node %s advertises max request size %v, max file size %v, checksums [%s] and encodings [%s]", node,
+			capabilities.MaxRequestSize, capabilities.MaxFileSize, strings.Join(capabilities.Checksums, ", "), strings.Join(capabilities.Encodings, ", ")))
+	}
+	cached[node] = capabilities
+	if err = sdk.saveStateFile(nodeCapabilitiesFile, cached); err != nil {
+		log.Println("Unable to cache node capabilities:", err)
+	}
+	return capabilities
+}
+
+// probeNode is a function responsible for asking a data node for its limits and features once
+// data nodes answering with other than 2xx, such as 404 or 405 of older ones, advertise nothing
+func (sdk VideraSDK) probeNode(node string) (NodeCapabilities, error) {
+	capabilities := NodeCapabilities{ProbedAt: time.Now().UTC()}
+	if err := sdk.checkTransport(node); err != nil {
+		return capabilities, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), nodeProbeTimeout)
+	defer cancel()
+	client := sdk.newClientWithPolicy(RetryPolicy{})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodOptions, node, nil)
+	req.Header.Set("Request-Type", "capabilities")
+	req.Header.Set("Protocol-Version", strconv.Itoa(protocolVersion))
+	res, err := client.Do(req)
+	if err != nil {
+		return capabilities, err
+	}
+	res.Body.Close()
+	recordProtocolVersion(node, res)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		utils.Debugln(fmt.Sprintf("Data node %s doesn't advertise capabilities: %s", node, res.Status))
+		return capabilities, nil
+	}
+	capabilities.Advertised = true
+	capabilities.MaxRequestSize, _ = httpx.MaxRequestSize(res)
+	if size, ok := httpx.MaxFileSize(res); ok && size > 0 {
+		capabilities.MaxFileSize = size
+	}
+	capabilities.Checksums = headerList(res.Header.Get("Checksum-Algorithms"))
+	capabilities.Encodings = headerList(res.Header.Get("Chunk-Encodings"))
+	return capabilities, nil
+}
+
+// headerList is a function to split a comma separated header into its trimmed values, nil when it is empty
+func headerList(value string) []string {
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// supportedOffer is a function to keep the values of a comma separated offer a data node supports, in the order of the offer
+// the offer is kept as is when the data node didn't advertise the values it supports
+func supportedOffer(offer string, supported []string) string {
+	if supported == nil || offer == "" {
+		return offer
+	}
+
+	var kept []string
+	for _, name := range headerList(offer) {
+		for _, candidate := range supported {
+			if strings.EqualFold(name, candidate) {
+				kept = append(kept, name)
+				break
+			}
+		}
+	}
+	if len(kept) == 0 {
+		utils.Debugln(fmt.Sprintf("Data node supports none of %s", offer))
+	}
+	return strings.Join(kept, ", ")
+}
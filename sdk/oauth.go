@@ -0,0 +1,182 @@
+package viderasdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// deviceCodeGrantType Grant type used to poll the token endpoint during the device flow
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// tokenRefreshMargin How long before expiry an access token is refreshed
+const tokenRefreshMargin = 30 * time.Second
+
+// oauthRequestTimeout Time a request to the OpenID Connect issuer may take, requests of the SDK wait for a token refresh
+const oauthRequestTimeout = 30 * time.Second
+
+// DeviceAuthorization Describes the code the user enters to approve a device login
+type DeviceAuthorization struct {
+	UserCode                string `json:"user_code"`                 //Code the user enters on the verification page
+	VerificationURI         string `json:"verification_uri"`          //Page where the user approves the login
+	VerificationURIComplete string `json:"verification_uri_complete"` //Verification page with the code already filled in, may be empty
+	DeviceCode              string `json:"device_code"`               //Code the SDK polls the token endpoint with
+	ExpiresIn               int    `json:"expires_in"`                //Seconds until the codes expire
+	Interval                int    `json:"interval"`                  //Seconds between polls of the token endpoint
+}
+
+// oidcDiscovery Endpoints advertised by an OpenID Connect issuer
+type oidcDiscovery struct {
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"` //Endpoint issuing device codes
+	TokenEndpoint               string `json:"token_endpoint"`                //Endpoint issuing tokens
+}
+
+// tokenResponse Response of the token endpoint, errors are reported in the error field
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`  //Bearer token attached to requests
+	RefreshToken string `json:"refresh_token"` //Token used to get a new access token
+	ExpiresIn    int    `json:"expires_in"`    //Seconds until the access token expires
+	Error        string `json:"error"`         //OAuth2 error code
+}
+
+// DeviceLogin is a function responsible for the OAuth2 device authorization flow against an OpenID Connect issuer
+// prompt is called with the code the user must approve, the returned credentials are refreshed automatically once stored
+func (sdk VideraSDK) DeviceLogin(issuer string, clientID string, scope string, prompt func(DeviceAuthorization)) (Credentials, error) {
+	client := sdk.oauthClient()
+	var discovery oidcDiscovery
+	res, err := client.Get(strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration")
+	if err != nil {
+		return Credentials{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return Credentials{}, fmt.Errorf("OpenID configuration request failed: %s", res.Status)
+	}
+	if err = json.NewDecoder(res.Body).Decode(&discovery); err != nil {
+		return Credentials{}, err
+	}
+	if discovery.DeviceAuthorizationEndpoint == "" || discovery.TokenEndpoint == "" {
+		return Credentials{}, errors.New("Issuer doesn't support the device authorization flow")
+	}
+
+	var authorization DeviceAuthorization
+	res, err = client.PostForm(discovery.DeviceAuthorizationEndpoint, url.Values{"client_id": {clientID}, "scope": {scope}})
+	if err != nil {
+		return Credentials{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return Credentials{}, fmt.Errorf("Device authorization request failed: %s", res.Status)
+	}
+	if err = json.NewDecoder(res.Body).Decode(&authorization); err != nil {
+		return Credentials{}, err
+	}
+	prompt(authorization)
+
+	interval := time.Duration(authorization.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	deadline := time.Now().Add(time.Duration(authorization.ExpiresIn) * time.Second)
+	for time.Now().Before(deadline) {
+		time.Sleep(interval)
+
+		token, err := requestToken(context.Background(), client, discovery.TokenEndpoint, url.Values{
+			"grant_type":  {deviceCodeGrantType},
+			"device_code": {authorization.DeviceCode},
+			"client_id":   {clientID},
+		})
+		if err != nil {
+			return Credentials{}, err
+		}
+
+		switch token.Error {
+		case "":
+			credentials := Credentials{TokenURL: discovery.TokenEndpoint, ClientID: clientID}
+			credentials.setToken(token)
+			return credentials, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		default:
+			return Credentials{}, fmt.Errorf("Device login failed: %s", token.Error)
+		}
+	}
+	return Credentials{}, errors.New("Device login failed: code expired before it was approved")
+}
+
+// refreshToken is a function responsible for getting a new access token with the refresh token, given up on once ctx is done
+func (credentials *Credentials) refreshToken(ctx context.Context, client *http.Client) error {
+	token, err := requestToken(ctx, client, credentials.TokenURL, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {credentials.RefreshToken},
+		"client_id":     {credentials.ClientID},
+	})
+	if err != nil {
+		return err
+	}
+	if token.Error != "" {
+		return fmt.Errorf("Token refresh failed: %s, run login again", token.Error)
+	}
+
+	credentials.setToken(token)
+	return nil
+}
+
+// expired is a function to check whether the access token is expired or about to expire
+func (credentials Credentials) expired() bool {
+	return !credentials.ExpiresAt.IsZero() && time.Now().Add(tokenRefreshMargin).After(credentials.ExpiresAt)
+}
+
+// setToken is a function to store a token response in the credentials
+// the previous refresh token is kept when the response doesn't rotate it
+func (credentials *Credentials) setToken(token tokenResponse) {
+	credentials.Token = token.AccessToken
+	if token.RefreshToken != "" {
+		credentials.RefreshToken = token.RefreshToken
+	}
+	credentials.ExpiresAt = time.Time{}
+	if token.ExpiresIn > 0 {
+		credentials.ExpiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second).UTC()
+	}
+}
+
+// requestToken is a function responsible for posting a token request, OAuth2 errors are returned in the response
+func requestToken(ctx context.Context, client *http.Client, tokenURL string, form url.Values) (tokenResponse, error) {
+	var token tokenResponse
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return token, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := client.Do(req)
+	if err != nil {
+		return token, err
+	}
+	defer res.Body.Close()
+
+	if err = json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return token, fmt.Errorf("Invalid token response: %s", res.Status)
+	}
+	if token.Error == "" && token.AccessToken == "" {
+		return token, fmt.Errorf("Token response is missing the access token: %s", res.Status)
+	}
+	return token, nil
+}
+
+// oauthClient is a function that returns the client of requests to the OpenID Connect issuer, each given up on after oauthRequestTimeout
+// it refuses plain http when TLS is required like the other clients of the SDK but carries no credentials, signature or relay,
+// nor retries token requests as a refresh token may be rotated by the first attempt
+func (sdk VideraSDK) oauthClient() *http.Client {
+	sdk.credentials = nil
+	sdk.relays = nil
+	sdk.options.Signer = nil
+	client := sdk.newClientWithPolicy(RetryPolicy{})
+	client.Timeout = oauthRequestTimeout
+	return client
+}
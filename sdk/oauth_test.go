@@ -0,0 +1,59 @@
+package viderasdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestTokenRefresh checks token refreshes go through the client of the SDK, refusing plain http when TLS is required,
+// carrying no signature and giving up once their context is done
+func TestTokenRefresh(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Signature") != "" || r.Header.Get("Authorization") != "" {
+			t.Errorf("token request carries Signature %q and Authorization %q", r.Header.Get("Signature"), r.Header.Get("Authorization"))
+		}
+		if r.URL.Path == "/slow" {
+			<-release
+		}
+		w.Write([]byte(`{"access_token": "new", "expires_in": 3600}`))
+	}))
+	defer server.Close()
+	// the slow handler is released before the server waits for it to close
+	defer close(release)
+
+	tests := []struct {
+		name       string
+		options    ClientOptions
+		path       string
+		timeout    time.Duration
+		wantErr    error
+		wantFailed bool
+	}{
+		{"refreshed", ClientOptions{}, "/token", time.Second, nil, false},
+		{"signed client", ClientOptions{Signer: HMACSigner{KeyID: "key", Secret: "secret"}}, "/token", time.Second, nil, false},
+		{"plain http with TLS required", ClientOptions{RequireTLS: true}, "/token", time.Second, ErrInsecureTransport, true},
+		{"slow issuer", ClientOptions{}, "/slow", 50 * time.Millisecond, context.DeadlineExceeded, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sdk := VideraSDK{options: test.options}
+			credentials := Credentials{Token: "old", RefreshToken: "refresh", TokenURL: server.URL + test.path}
+			ctx, cancel := context.WithTimeout(context.Background(), test.timeout)
+			defer cancel()
+
+			err := credentials.refreshToken(ctx, sdk.oauthClient())
+			if (err != nil) != test.wantFailed || (test.wantErr != nil && !errors.Is(err, test.wantErr)) {
+				t.Fatalf("refresh failed with %v, expected %v", err, test.wantErr)
+			}
+			if !test.wantFailed && credentials.Token != "new" {
+				t.Errorf("token %q after the refresh, expected new", credentials.Token)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package viderasdk
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// errArtifactUploaded Returned by uploadFiles when every chunk of an artifact of a parallel session was acknowledged
+// while other artifacts are still being uploaded, i.e. without the data node completing the session
+var errArtifactUploaded = errors.New("Artifact uploaded")
+
+// recordSessionParallel is a function responsible for remembering whether the data node accepted parallel artifacts for a session
+// model init requests offer them and the data node answers Parallel-Artifacts: true when chunks may carry an Artifact header
+// with offsets within that artifact
+func (sdk VideraSDK) recordSessionParallel(id string, res *http.Response) {
+	if res.Header.Get("Parallel-Artifacts") == "true" {
+		sdk.updateSession(id, func(state *sessionState) { state.parallel = true })
+	}
+}
+
+// uploadModelFiles is a function responsible for uploading the model, config and code of a model session
+// they are uploaded concurrently when the data node accepted parallel artifacts and as a single stream otherwise,
+// as many at once as max_goroutines allows
+func (sdk VideraSDK) uploadModelFiles(id string, sources map[string]Source, expectedDigests map[string]string) (int64, error) {
+	if !sdk.loadSession(id).parallel {
+		return sdk.uploadFiles(id, sources, modelUploadOrder, expectedDigests)
+	}
+
+	var wait sync.WaitGroup
+	var mutex sync.Mutex
+	var firstErr error
+	completed := false
+	bytesSent := int64(0)
+	for _, name := range modelUploadOrder {
+		wait.Add(1)
+		go func(name string) {
+			defer wait.Done()
+			acquireWorker()
+			defer releaseWorker()
+			artifactSDK := sdk
+			artifactSDK.artifact = name
+			sent, err := artifactSDK.uploadFiles(id, map[string]Source{name: sources[name]}, []string{name}, expectedDigests)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			bytesSent += sent
+			if err == nil {
+				completed = true
+			} else if err != errArtifactUploaded && firstErr == nil {
+				firstErr = err
+			}
+		}(name)
+	}
+	wait.Wait()
+
+	if firstErr != nil {
+		return bytesSent, firstErr
+	}
+	if !completed {
+		return bytesSent, errors.New("Data node didn't complete the model once every artifact was uploaded")
+	}
+	return bytesSent, nil
+}
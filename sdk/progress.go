@@ -0,0 +1,73 @@
+package viderasdk
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// uploadProgress Periodically logs a summary of an upload instead of a line per chunk
+// a summary is logged every interval and whenever the upload crosses a percentStep boundary
+type uploadProgress struct {
+	action       string                                     //Uploading or Downloading, starts log lines
+	name         string                                     //Name of the upload in log lines
+	total        int64                                      //Total size of the upload in bytes
+	interval     time.Duration                              //Time between summaries, 0 disables time based summaries
+	percentStep  int                                        //Percentage between summaries, 0 disables percent based summaries
+	start        time.Time                                  //Time at which the upload started
+	lastLoggedAt time.Time                                  //Time of the last summary
+	lastPercent  int                                        //Percentage reported by the last summary
+	onProgress   func(name string, done int64, total int64) //ClientOptions.OnProgress, called on every update
+	ahead        int64                                      //Bytes uploaded out of order past those done, e.g. the tail of tail first uploads
+}
+
+// newUploadProgress is a function that returns a progress reporter for an upload of total bytes
+func (sdk VideraSDK) newUploadProgress(name string, total int64) *uploadProgress {
+	now := time.Now()
+	return &uploadProgress{
+		action:       "Uploading",
+		name:         name,
+		total:        total,
+		interval:     time.Duration(sdk.progressInterval) * time.Second,
+		percentStep:  sdk.progressPercent,
+		start:        now,
+		lastLoggedAt: now,
+		onProgress:   sdk.options.OnProgress,
+	}
+}
+
+// newDownloadProgress is a function that returns a progress reporter for a download of total bytes
+func (sdk VideraSDK) newDownloadProgress(name string, total int64) *uploadProgress {
+	progress := sdk.newUploadProgress(name, total)
+	progress.action = "Downloading"
+	return progress
+}
+
+// update records that done bytes were uploaded, logging a summary when one is due
+// bytes uploaded ahead of them count as done too, up to the total
+func (progress *uploadProgress) update(done int64) {
+	if done += progress.ahead; done > progress.total {
+		done = progress.total
+	}
+	if progress.onProgress != nil {
+		progress.onProgress(progress.name, done, progress.total)
+	}
+	percent := 100
+	if progress.total > 0 {
+		percent = int(done * 100 / progress.total)
+	}
+	now := time.Now()
+
+	timeDue := progress.interval > 0 && now.Sub(progress.lastLoggedAt) >= progress.interval
+	percentDue := progress.percentStep > 0 && percent/progress.percentStep > progress.lastPercent/progress.percentStep
+	if !timeDue && !percentDue {
+		return
+	}
+
+	rate := float64(0)
+	if elapsed := now.Sub(progress.start).Seconds(); elapsed > 0 {
+		rate = float64(done) / elapsed / (1 << 20)
+	}
+	log.Println(fmt.Sprintf("%s %s: %v%% (%v of %v bytes, %.1f MiB/s)", progress.action, progress.name, percent, done, progress.total, rate))
+	progress.lastLoggedAt, progress.lastPercent = now, percent
+}
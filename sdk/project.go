@@ -0,0 +1,94 @@
+package viderasdk
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ErrInsecureTransport Returned when TLS is required and the master, a data node or a relay is reached over plain http
+var ErrInsecureTransport = errors.New("Refusing to upload over plain http")
+
+// UseProject is a function to apply the defaults of a project of the config to the SDK, see projects
+// options set afterwards, e.g. from CLI flags, take precedence over them
+func (sdk *VideraSDK) UseProject(name string) error {
+	project, ok := sdkConfig.Projects[name]
+	if !ok {
+		return fmt.Errorf("Unknown project %s, expected one of the projects of the config", name)
+	}
+
+	sdk.project = name
+	if project.ChunkSize > 0 {
+		sdk.chunkSize = int64(project.ChunkSize)
+	}
+	if len(project.Tags) > 0 {
+		tags := make(map[string]string, len(sdk.options.Tags)+len(project.Tags))
+		for key, val := range sdk.options.Tags {
+			tags[key] = val
+		}
+		for key, val := range project.Tags {
+			tags[key] = val
+		}
+		sdk.options.Tags = tags
+	}
+	sdk.options.RequireTLS = sdk.options.RequireTLS || project.RequireTLS
+	if project.RateLimit > 0 {
+		sdk.options.RateLimit = int64(project.RateLimit)
+	}
+	if project.Retention > 0 {
+		sdk.options.Retention = time.Duration(project.Retention)
+	}
+	return nil
+}
+
+// projectHeaders is a function to get the headers init requests carry for the project and its defaults
+func (sdk VideraSDK) projectHeaders() map[string]string {
+	headers := make(map[string]string)
+	if sdk.project != "" {
+		headers["Project"] = sdk.project
+	}
+	if len(sdk.options.Tags) > 0 {
+		tags := url.Values{}
+		for key, val := range sdk.options.Tags {
+			tags.Set(key, val)
+		}
+		headers["Tags"] = tags.Encode()
+	}
+	if sdk.options.Priority != "" {
+		headers["Priority"] = sdk.options.Priority
+	}
+	if sdk.options.Retention > 0 {
+		headers["Retention-Seconds"] = strconv.FormatInt(int64(sdk.options.Retention/time.Second), 10)
+	}
+	return headers
+}
+
+// checkTransport is a function to fail when TLS is required and endpoint isn't an https URL
+func (sdk VideraSDK) checkTransport(endpoint string) error {
+	if !sdk.options.RequireTLS {
+		return nil
+	}
+
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Scheme != "https" {
+		return fmt.Errorf("%w: %s", ErrInsecureTransport, endpoint)
+	}
+	return nil
+}
+
+// tlsTransport Refuses requests that aren't sent over https, whichever URL they were built from
+// it sits below the transports rewriting URLs, so relays, signed URLs and redirects are checked too
+type tlsTransport struct {
+	base http.RoundTripper //Transport sending the requests
+}
+
+// RoundTrip sends the request when its URL is an https URL and fails with ErrInsecureTransport otherwise
+func (transport tlsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "https" {
+		return nil, fmt.Errorf("%w: %s", ErrInsecureTransport, req.URL.Redacted())
+	}
+	return transport.base.RoundTrip(req)
+}
@@ -0,0 +1,36 @@
+package viderasdk
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// proposedID is a function to get the ID proposed in the Proposed-ID header of an init request, empty when none is proposed
+// the uploads of a job propose the ID suffixed by their filetype and segments after the first by their index,
+// re-initialized sessions don't propose one as the ID was already taken by the expired session
+func (sdk VideraSDK) proposedID(filetype string, extraHeaders map[string]string) string {
+	if sdk.options.ProposedID == "" || extraHeaders["Resume-From"] != "" {
+		return ""
+	}
+
+	id := sdk.options.ProposedID
+	if sdk.options.IDNamespace != "" {
+		id = sdk.options.IDNamespace + "/" + id
+	}
+	if sdk.jobProposal {
+		id += "-" + filetype
+	}
+	if index := extraHeaders["Segment-Index"]; index != "" && index != "0" {
+		id += "-" + index
+	}
+	return id
+}
+
+// logAssignedID is a function responsible for telling the proposed ID apart from the one assigned by the data node
+// which is authoritative, clusters not supporting proposals assign their own
+func logAssignedID(proposed string, res *http.Response) {
+	if proposed != "" && res.Header.Get("ID") != proposed {
+		log.Println(fmt.Sprintf("Data node assigned ID %s instead of the proposed %s", res.Header.Get("ID"), proposed))
+	}
+}
@@ -0,0 +1,125 @@
+package viderasdk
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// relayProbeTimeout Time after which a relay that didn't answer its probe is considered unreachable
+const relayProbeTimeout = 2 * time.Second
+
+// relayTargetHeader Header telling a relay which data node to forward a request to
+const relayTargetHeader = "Relay-Target"
+
+// relaysMutex Guards relayRoutes
+var relaysMutex sync.Mutex
+
+// relayRoutes Relay the traffic of each data node the relays were probed against is routed through, empty when uploading directly
+// uploads routed to different data nodes each go through the relay fastest for theirs
+var relayRoutes = map[string]string{}
+
+// selectRelay is a function responsible for probing the relays and the data node and routing the traffic of node through the fastest
+// keeps uploading directly when the data node answers faster than every relay or no relay answers, each data node is probed once
+func (sdk VideraSDK) selectRelay(node string) {
+	if len(sdk.relays) == 0 {
+		return
+	}
+	relaysMutex.Lock()
+	_, probed := relayRoutes[node]
+	relaysMutex.Unlock()
+	if probed {
+		return
+	}
+
+	active := ""
+	fastest, err := probeLatency(node)
+	if err != nil {
+		fastest = relayProbeTimeout
+	}
+	for _, relay := range sdk.relays {
+		if err := sdk.checkTransport(relay); err != nil {
+			utils.Warnln(fmt.Sprintf("Skipping relay: %v", err))
+			continue
+		}
+		latency, err := probeLatency(relay)
+		if err != nil {
+			utils.Debugln(fmt.Sprintf("Relay %s is unreachable: %v", relay, err))
+			continue
+		}
+		utils.Debugln(fmt.Sprintf("Relay %s answered in %v", relay, latency))
+		if latency < fastest {
+			fastest, active = latency, relay
+		}
+	}
+
+	relaysMutex.Lock()
+	relayRoutes[node] = active
+	relaysMutex.Unlock()
+	if active != "" {
+		log.Println(fmt.Sprintf("Routing traffic of data node %s through relay %s", node, active))
+	}
+}
+
+// probeLatency is a function to measure how long an endpoint takes to answer, any response counts as an answer
+func probeLatency(url string) (time.Duration, error) {
+	client := http.Client{Timeout: relayProbeTimeout}
+	req, err := http.NewRequest(http.MethodHead, url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	res.Body.Close()
+	return time.Since(start), nil
+}
+
+// relayTransport Sends data node requests through the relay selected for their data node, falling back to the data node when it fails
+type relayTransport struct {
+	base http.RoundTripper //Transport sending the requests
+}
+
+// RoundTrip sends a data node request through the relay of its data node, or directly once the relay failed
+func (transport relayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	node := req.URL.String()
+	relaysMutex.Lock()
+	relay := relayRoutes[node]
+	relaysMutex.Unlock()
+	if relay == "" {
+		return transport.base.RoundTrip(req)
+	}
+
+	relayReq, err := http.NewRequestWithContext(req.Context(), req.Method, relay, req.Body)
+	if err != nil {
+		return nil, err
+	}
+	relayReq.Header = req.Header.Clone()
+	relayReq.Header.Set(relayTargetHeader, node)
+	relayReq.ContentLength = req.ContentLength
+
+	res, err := transport.base.RoundTrip(relayReq)
+	if err == nil {
+		return res, nil
+	}
+
+	utils.Warnln(fmt.Sprintf("Relay %s failed, uploading directly: %v", relay, err))
+	relaysMutex.Lock()
+	if relayRoutes[node] == relay {
+		relayRoutes[node] = ""
+	}
+	relaysMutex.Unlock()
+	if req.GetBody != nil {
+		if req.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return transport.base.RoundTrip(req)
+}
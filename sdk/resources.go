@@ -0,0 +1,146 @@
+package viderasdk
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/config"
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// resourceReportInterval Time between reports of resource usage to metrics
+const resourceReportInterval = 10 * time.Second
+
+// bufferBudget Memory the chunk and read ahead buffers of every running upload share, nil when unlimited
+var bufferBudget *utils.MemoryBudget
+
+// workerSlots Goroutines the upload pipeline may start at once, e.g. for parallel artifacts or read ahead, nil when unlimited
+var workerSlots chan struct{}
+
+// lastResourceReport Unix nanoseconds of the last report of resource usage
+var lastResourceReport int64
+
+// applyResourceLimits is a function responsible for applying the resource ceilings of the config to the process
+// with cgroup_aware, GOMAXPROCS follows the CPU quota of the cgroup unless max_procs is set
+// and buffers may use a quarter of its memory limit unless max_buffer_memory is set
+func applyResourceLimits(configObj config.SDKConfig) {
+	maxProcs, maxBufferMemory := configObj.MaxProcs, int64(configObj.MaxBufferMemory)
+	if configObj.CgroupAware {
+		cpus, memory := utils.CgroupLimits()
+		if maxProcs == 0 && cpus > 0 {
+			maxProcs = int(math.Ceil(cpus))
+		}
+		if maxBufferMemory == 0 && memory > 0 {
+			maxBufferMemory = memory / 4
+		}
+	}
+
+	if maxProcs > 0 {
+		runtime.GOMAXPROCS(maxProcs)
+	}
+	bufferBudget = utils.NewMemoryBudget(maxBufferMemory)
+	if configObj.MaxGoroutines > 0 {
+		workerSlots = make(chan struct{}, configObj.MaxGoroutines)
+	}
+	if maxProcs > 0 || maxBufferMemory > 0 || configObj.MaxGoroutines > 0 {
+		log.Println(fmt.Sprintf("%s Resource limits: %v procs, %v bytes of buffers, %v goroutines", logPrefix, maxProcs, maxBufferMemory, configObj.MaxGoroutines))
+	}
+}
+
+// acquireWorker is a function responsible for waiting for a goroutine slot of the pipeline, releaseWorker frees it
+func acquireWorker() {
+	if workerSlots != nil {
+		workerSlots <- struct{}{}
+	}
+}
+
+// tryAcquireWorker is a function to take a goroutine slot of the pipeline if one is free
+func tryAcquireWorker() bool {
+	if workerSlots == nil {
+		return true
+	}
+	select {
+	case workerSlots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// releaseWorker is a function responsible for freeing a goroutine slot of the pipeline
+func releaseWorker() {
+	if workerSlots != nil {
+		<-workerSlots
+	}
+}
+
+// acquireBuffer is a function that returns a buffer of size bytes once the buffer budget fits it, releaseBuffer returns it
+func (sdk VideraSDK) acquireBuffer(size int64) []byte {
+	bufferBudget.Acquire(size)
+	sdk.metrics().Gauge("buffer_memory", float64(bufferBudget.InUse()), nil)
+	return make([]byte, size)
+}
+
+// releaseBuffer is a function responsible for returning a buffer from acquireBuffer to the budget
+func (sdk VideraSDK) releaseBuffer(buffer []byte) {
+	bufferBudget.Release(int64(cap(buffer)))
+	sdk.metrics().Gauge("buffer_memory", float64(bufferBudget.InUse()), nil)
+}
+
+// reportResourceUsage is a function responsible for reporting the memory and goroutines in use to metrics,
+// at most once every resourceReportInterval
+func (sdk VideraSDK) reportResourceUsage(tags map[string]string) {
+	now := time.Now().UnixNano()
+	last := atomic.LoadInt64(&lastResourceReport)
+	if now-last < int64(resourceReportInterval) || !atomic.CompareAndSwapInt64(&lastResourceReport, last, now) {
+		return
+	}
+
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	sdk.metrics().Gauge("heap_in_use", float64(memStats.HeapInuse), tags)
+	sdk.metrics().Gauge("goroutines", float64(runtime.NumGoroutine()), tags)
+	sdk.metrics().Gauge("buffer_memory", float64(bufferBudget.InUse()), tags)
+}
+
+// readAheadSource is a function responsible for reading source ahead of the upload in blocks blocks of blockSize bytes,
+// fewer when the buffer budget is short of them, source is returned as is when no block fits or no goroutine slot is free
+func (sdk VideraSDK) readAheadSource(source io.ReadCloser, blockSize int64, blocks int, onDepth func(depth int)) io.ReadCloser {
+	if !tryAcquireWorker() {
+		utils.Debugln("No goroutine slot free, reading without read ahead")
+		return source
+	}
+	held := bufferBudget.TryAcquire(blockSize * int64(blocks))
+	if held < blockSize {
+		bufferBudget.Release(held)
+		releaseWorker()
+		utils.Debugln("Buffer memory exhausted, reading without read ahead")
+		return source
+	}
+
+	reader := utils.NewBoundedReader(source, int(blockSize), int(held/blockSize), onDepth)
+	return &budgetedReader{ReadCloser: reader, held: held}
+}
+
+// budgetedReader A read ahead reader holding part of the buffer budget and a goroutine slot until closed
+type budgetedReader struct {
+	io.ReadCloser
+	held      int64     //Bytes of the budget held
+	closeOnce sync.Once //Guards releasing
+}
+
+// Close closes the read ahead reader and releases what it held
+func (reader *budgetedReader) Close() error {
+	err := reader.ReadCloser.Close()
+	reader.closeOnce.Do(func() {
+		bufferBudget.Release(reader.held)
+		releaseWorker()
+	})
+	return err
+}
@@ -0,0 +1,109 @@
+package viderasdk
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// newClient is a function responsible for creating the http client used for requests to master and data nodes
+// retries of the client are reported to the OnRetry callback
+// data node requests go through the active relay and the bearer token of the active profile is attached to every request
+func (sdk VideraSDK) newClient() *http.Client {
+	return sdk.newClientWithPolicy(RetryPolicy{MaxRetries: sdk.defaultMaxRetries, WaitingTime: sdk.defaultWaitingTime})
+}
+
+// newClientWithPolicy is a function responsible for creating the http client of newClient retrying according to policy
+func (sdk VideraSDK) newClientWithPolicy(policy RetryPolicy) *http.Client {
+	client := utils.NewClientWithRetryHook(policy.MaxRetries, policy.WaitingTime, func(attempt int, err error, nextDelay time.Duration) {
+		sdk.metrics().Count("request_retries", 1, nil)
+		if sdk.options.OnRetry != nil {
+			sdk.options.OnRetry(attempt, err, nextDelay)
+		}
+	})
+
+	if sdk.options.RequireTLS {
+		client.Transport = tlsTransport{base: client.Transport}
+	}
+	// signing comes first so that the signature covers the request as the outer transports changed it
+	if sdk.options.Signer != nil {
+		client.Transport = signingTransport{base: client.Transport, signer: sdk.options.Signer}
+	}
+	// quirks are applied before signing so the signature covers the Request-Type the server receives
+	client.Transport = quirksTransport{base: client.Transport, sdk: sdk}
+	if !sdk.deadline.IsZero() {
+		client.Transport = deadlineTransport{base: client.Transport, deadline: sdk.deadline}
+	}
+	if len(sdk.relays) > 0 {
+		client.Transport = relayTransport{base: client.Transport}
+	}
+	if sdk.credentials != nil {
+		client.Transport = credentialsTransport{base: client.Transport, credentials: sdk.credentials}
+	}
+	return client
+}
+
+// initRetryPolicy is a function that returns the retry policy of init requests
+// a retried init may create a second session, so by default it is retried once and carries an idempotency key
+func (sdk VideraSDK) initRetryPolicy() RetryPolicy {
+	if sdk.options.InitRetry != nil {
+		return *sdk.options.InitRetry
+	}
+	return RetryPolicy{MaxRetries: minInt(sdk.defaultMaxRetries, 1), WaitingTime: sdk.defaultWaitingTime}
+}
+
+// appendRetryPolicy is a function that returns the retry policy of chunk requests
+// chunks are sent at an explicit offset which the data node checks, so by default they are retried more
+func (sdk VideraSDK) appendRetryPolicy() RetryPolicy {
+	if sdk.options.AppendRetry != nil {
+		return *sdk.options.AppendRetry
+	}
+	return RetryPolicy{MaxRetries: 2 * sdk.defaultMaxRetries, WaitingTime: sdk.defaultWaitingTime}
+}
+
+// minInt is a function that returns the smaller of two ints
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// retryUpload is a function responsible for running upload attempts until one succeeds or retries are exhausted
+// attempts failing with ErrFileTooLarge, ErrFiletypeMismatch, ErrFilenameExists, ErrMissingCapability, ErrInsecureTransport
+// or past the deadline are not retried, nor are those failing with ErrSourceChanged unless AllowChanged is set
+// other failures are reported to the OnRetry callback, each attempt holds one of the upload slots of the SDK
+func (sdk VideraSDK) retryUpload(attempt func(trial int) error) error {
+	waitingTime := time.Duration(sdk.defaultWaitingTime) * time.Second
+	ticker := time.NewTicker(waitingTime)
+	defer ticker.Stop()
+
+	for trial := 0; trial <= sdk.defaultMaxRetries; trial, _ = trial+1, <-ticker.C {
+		if sdk.deadlineExceeded() {
+			return ErrDeadlineExceeded
+		}
+		sdk.uploadSlots.acquire()
+		err := attempt(trial)
+		sdk.uploadSlots.release()
+		if err == nil {
+			return nil
+		}
+		sdk.metrics().Count("upload_attempts_failed", 1, nil)
+		if errors.Is(err, ErrFileTooLarge) || errors.Is(err, ErrDeadlineExceeded) || errors.Is(err, ErrFiletypeMismatch) ||
+			errors.Is(err, ErrFilenameExists) || errors.Is(err, ErrMissingCapability) || errors.Is(err, ErrInsecureTransport) ||
+			(errors.Is(err, ErrSourceChanged) && !sdk.options.AllowChanged) {
+			return err
+		}
+		if sdk.deadlineExceeded() {
+			return fmt.Errorf("%w: %v", ErrDeadlineExceeded, err)
+		}
+
+		if trial < sdk.defaultMaxRetries && sdk.options.OnRetry != nil {
+			sdk.options.OnRetry(trial+1, err, waitingTime)
+		}
+	}
+	return errors.New("An error has occurred")
+}
@@ -1,17 +1,23 @@
 package viderasdk
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io/fs"
 	"io/ioutil"
 	"log"
 	"net/http"
-	"path"
+	"os"
+	"path/filepath"
 	"strconv"
 	"sync"
 	"time"
 
 	"github.com/SayedAlesawy/Videra-SDK/config"
+	"github.com/SayedAlesawy/Videra-SDK/httpx"
+	"github.com/SayedAlesawy/Videra-SDK/metrics"
 	"github.com/SayedAlesawy/Videra-SDK/utils"
 )
 
@@ -24,36 +30,219 @@ var sdkOnce sync.Once
 // sdkInstance A singleton instance of the server object
 var sdkInstance *VideraSDK
 
+// sdkConfig The config the singleton instance was created from, environment overrides included
+var sdkConfig config.SDKConfig
+
 // SDKInstance A function to return a singleton server instance
 func SDKInstance() *VideraSDK {
 
 	sdkOnce.Do(func() {
-		configManager := config.ConfigurationManagerInstance("config/config_files")
-		configObj := configManager.SDKConfig("sdk_config.yaml")
+		configObj := config.SDKConfigFile.Get()
+		sdkConfig = configObj
+
+		applyResourceLimits(configObj)
+		utils.SetPoolOptions(poolOptions(configObj))
+		if err := utils.SetInterfaces(configObj.BindInterfaces, configObj.InterfaceFailover); err != nil {
+			log.Println(fmt.Sprintf("%s Unable to bind connections to bind_interfaces: %v", logPrefix, err))
+		}
 
 		sdk := VideraSDK{
-			masterURL:          configObj.NameNodeEndpoint,
-			chunkSize:          configObj.ChunkSize,
-			defaultMaxRetries:  configObj.MaxRetries,
-			defaultWaitingTime: configObj.WaitingTime,
+			masterURL:             configObj.NameNodeEndpoint,
+			masters:               masterEndpoints(configObj),
+			discoveryTimeout:      time.Duration(configObj.DiscoveryTimeout),
+			probeNodeCapabilities: configObj.ProbeNodeCapabilities,
+			chunkSize:             int64(configObj.ChunkSize),
+			defaultMaxRetries:     configObj.MaxRetries,
+			defaultWaitingTime:    configObj.WaitingTime,
+			sessionReinits:        configObj.SessionReinits,
+			expiryMargin:          time.Duration(configObj.ExpiryMargin) * time.Second,
+			dedupChunks:           configObj.DedupChunks,
+			sparseUpload:          configObj.SparseUpload,
+			readAhead:             configObj.ReadAhead << 20,
+			dropPageCache:         configObj.DropPageCache,
+			fileSystem:            utils.OSFileSystem{},
+			stateDir:              os.ExpandEnv(configObj.StateDir),
+			stagingDir:            os.ExpandEnv(configObj.StagingDir),
+			auditLog:              configObj.AuditLog,
+			usageAccounting:       configObj.UsageAccounting,
+			catalog:               configObj.Catalog,
+			progressInterval:      configObj.ProgressInterval,
+			progressPercent:       configObj.ProgressPercent,
+			relays:                configObj.Relays,
+			filenameLength:        configObj.FilenameMaxLength,
+			watchdogRate:          int64(configObj.WatchdogMinRate),
+			watchdogWindow:        time.Duration(configObj.WatchdogWindow) * time.Second,
+			watchdogAction:        configObj.WatchdogAction,
+			serverVersionPin:      configObj.ServerVersion,
+			uploadSlots:           newSlots(configObj.MaxUploads),
+			chunkSlots:            newChunkScheduler(configObj.MaxChunks),
+			priorityWeights:       configObj.PriorityWeights,
+			downloadRanges:        configObj.DownloadRanges,
+		}
+		shaper, err := newTrafficShaper(configObj.TrafficShaping, configObj.NameNodeEndpoint)
+		if err != nil {
+			log.Println(fmt.Sprintf("%s Ignoring traffic_shaping: %v", logPrefix, err))
+		}
+		sdk.shaper = shaper
+		if configObj.StatsDAddr != "" {
+			emitter, err := metrics.NewStatsDEmitter(configObj.StatsDAddr, configObj.StatsDPrefix)
+			if err != nil {
+				log.Println(fmt.Sprintf("%s Unable to set up statsd metrics: %v", logPrefix, err))
+			} else {
+				sdk.options.Metrics = emitter
+			}
+		}
+
+		if configObj.PreUpload != "" || configObj.PostUpload != "" {
+			sdk.options.Hooks = []Hook{CommandHook{PreCommand: configObj.PreUpload, PostCommand: configObj.PostUpload}}
+		}
+		sdk.options.HookFailurePolicy = configObj.HookFailure
+		sdk.options.FilenameCollision = configObj.FilenameCollision
+		sdk.options.SourceIsolation = configObj.SourceIsolation
+		sdk.options.VideoPrecheck = configObj.VideoPrecheck
+		sdk.options.Routing = configObj.Routing
+		sdk.options.RequireTLS = configObj.Security.RequireTLS
+		if configObj.SigningKeyID != "" {
+			sdk.options.Signer = HMACSigner{KeyID: configObj.SigningKeyID, Secret: configObj.SigningSecret}
+		}
+		sdk.options.Compression = configObj.Compression
+		sdk.options.Checksums = configObj.Checksums
+
+		if configObj.Project != "" {
+			if err := sdk.UseProject(configObj.Project); err != nil {
+				log.Println(fmt.Sprintf("%s %v", logPrefix, err))
+			}
+		}
+
+		if configObj.Profile != "" {
+			if err := sdk.UseProfile(configObj.Profile); err != nil {
+				log.Println(fmt.Sprintf("%s Unable to use profile %s: %v", logPrefix, configObj.Profile, err))
+			}
+		}
+
+		if configObj.FFmpegPath != "" {
+			utils.FFmpegPath = configObj.FFmpegPath
+		}
+		utils.SetDebugLogging(configObj.LogLevel == "debug")
+		if configObj.LogFile != "" || configObj.LogFormat == "json" {
+			err := utils.SetupLogging(logOptions(configObj))
+			if err != nil {
+				log.Println(fmt.Sprintf("%s Unable to set up logging: %v", logPrefix, err))
+			}
 		}
 
 		sdkInstance = &sdk
+		utils.SetConnectionObserver(sdkInstance.reportConnection)
+		utils.SetRouteObserver(sdkInstance.reportRouteFailover)
 	})
 
 	return sdkInstance
 }
 
-var uploadURL string
+// LogOptions is a function to get the logging options of the config the SDK runs with, e.g. to override some of them
+func LogOptions() utils.LogOptions {
+	SDKInstance()
+	return logOptions(sdkConfig)
+}
+
+// NewStatsDEmitter is a function that returns an emitter sending metrics to the StatsD agent at addr under the statsd_prefix of the config,
+// e.g. for an agent given on the command line
+func NewStatsDEmitter(addr string) (*metrics.StatsDEmitter, error) {
+	SDKInstance()
+	return metrics.NewStatsDEmitter(addr, sdkConfig.StatsDPrefix)
+}
+
+// logOptions is a function to get the logging options described by the SDK config
+func logOptions(configObj config.SDKConfig) utils.LogOptions {
+	return utils.LogOptions{
+		File:       os.ExpandEnv(configObj.LogFile),
+		MaxSize:    configObj.LogMaxSize << 20,
+		MaxBackups: configObj.LogMaxBackups,
+		MaxAge:     time.Duration(configObj.LogMaxAge) * 24 * time.Hour,
+		JSON:       configObj.LogFormat == "json",
+	}
+}
+
+// SetFileSystem is a function to set the filesystem local upload paths are read from, e.g. an in-memory one in tests
+func (sdk *VideraSDK) SetFileSystem(fileSystem fs.FS) {
+	sdk.fileSystem = fileSystem
+}
+
+// EffectiveConfig is a function to list the config the SDK runs with, after environment overrides, with the source of each value
+func EffectiveConfig() []config.Setting {
+	SDKInstance()
+	return config.Settings(&sdkConfig)
+}
+
+// SetMasterURL is a function to set the upload endpoint of the cluster master, overriding name_node_endpoint of the config
+// the masters of master_endpoints are no longer probed
+func (sdk *VideraSDK) SetMasterURL(url string) {
+	sdk.masterURL, sdk.masters = url, nil
+}
+
+// SetStateDir is a function to set the directory holding local state such as the upload cache, overriding state_dir of the config
+func (sdk *VideraSDK) SetStateDir(dir string) {
+	sdk.stateDir = dir
+}
+
+// SetChunkSize is a function to set the size of uploaded chunks, overriding chunk_size of the config
+func (sdk *VideraSDK) SetChunkSize(size int64) {
+	sdk.chunkSize = size
+}
+
+// SetClientOptions is a function to set the optional settings and callbacks of the SDK
+func (sdk *VideraSDK) SetClientOptions(options ClientOptions) {
+	sdk.options = options
+}
+
+// ClientOptions is a function to get the optional settings and callbacks of the SDK, e.g. to change one of them
+func (sdk VideraSDK) ClientOptions() ClientOptions {
+	return sdk.options
+}
+
+// metrics is a function to get the emitter metrics are reported to
+func (sdk VideraSDK) metrics() metrics.Emitter {
+	if sdk.options.Metrics == nil {
+		return metrics.NoopEmitter{}
+	}
+	return sdk.options.Metrics
+}
+
+// emitUploadOutcome is a function responsible for reporting whether an upload succeeded and how long it took
+func (sdk VideraSDK) emitUploadOutcome(filetype string, start time.Time, err error) {
+	tags := map[string]string{"filetype": filetype}
+	if err != nil {
+		sdk.metrics().Count("uploads_failed", 1, tags)
+		return
+	}
+	sdk.metrics().Count("uploads_succeeded", 1, tags)
+	sdk.metrics().Timing("upload_duration", time.Since(start), tags)
+}
+
+// ErrFileTooLarge Returned when an upload exceeds the cluster max file size, such uploads are never retried
+var ErrFileTooLarge = errors.New("File exceeds the cluster max file size")
 
 var modelUploadOrder = []string{"model", "config", "code"}
 
+// checksumHeaders Header carrying the digest of each model artifact in init and completion responses
+var checksumHeaders = map[string]string{
+	"model":  "Model-Checksum",
+	"config": "Config-Checksum",
+	"code":   "Code-Checksum",
+}
+
 // updateUploadURL is a function responsible for asking master node for data node upload url
 func (sdk VideraSDK) updateUploadURL() error {
+	if len(sdk.masters) > 1 {
+		return sdk.updateUploadURLFromMasters()
+	}
 	// send request to master node to get data node upload ip
 	// if success, set the new upload URL
 	// if fail, return error
-	client := utils.NewClient(sdk.defaultMaxRetries, sdk.defaultWaitingTime)
+	if err := sdk.checkTransport(sdk.masterURL); err != nil {
+		return err
+	}
+	client := sdk.newClient()
 	req, _ := http.NewRequest(http.MethodGet, sdk.masterURL, nil)
 	res, err := client.Do(req)
 	if err != nil {
@@ -70,27 +259,129 @@ func (sdk VideraSDK) updateUploadURL() error {
 
 	body := string(bodyBytes)
 
+	if err = permissionError(res); err != nil {
+		return err
+	}
 	if res.StatusCode != http.StatusOK {
 		log.Println(body)
 		return errors.New(body)
 	}
 
-	uploadURL = body
-	log.Println(fmt.Sprintf("Updated upload url to %s", uploadURL))
+	if err = sdk.checkTransport(body); err != nil {
+		return err
+	}
+	sdk.updateMaxFileSize(res)
+	sdk.setUploadURL(body, "Master")
+	return nil
+}
+
+// setUploadURL is a function responsible for sending the next requests of uploads to a data node, by names who routed them there
+// moving to a different data node is reported as a failover
+func (sdk VideraSDK) setUploadURL(node string, by string) {
+	if previous := sdk.routeTo(node); previous != "" && previous != node {
+		sdk.warn(Warning{Kind: WarningNodeFailover, DataNode: previous, Old: previous, New: node,
+			Message: fmt.Sprintf("%s routed the upload from data node %s to %s", by, previous, node)})
+		if sdk.options.OnNodeFailover != nil {
+			sdk.options.OnNodeFailover(previous, node)
+		}
+	}
+	log.Println(fmt.Sprintf("Updated upload url to %s", node))
+	sdk.selectRelay(node)
+}
+
+// checkSourcesSize is a function to fail fast when the sources uploaded as one file exceed the cluster max file size
+func (sdk VideraSDK) checkSourcesSize(sources map[string]Source, uploadOrder []string) error {
+	totalSize := int64(0)
+	for _, name := range uploadOrder {
+		size, err := sources[name].Size()
+		if err != nil {
+			return err
+		}
+		totalSize += size
+	}
+
+	return sdk.checkMaxFileSize(sources[uploadOrder[0]].Name(), totalSize)
+}
+
+// updateMaxFileSize is a function responsible for recording the Max-File-Size advertised in a response
+func (sdk VideraSDK) updateMaxFileSize(res *http.Response) {
+	if res.Header.Get("Max-File-Size") == "" {
+		return
+	}
+
+	size, ok := httpx.MaxFileSize(res)
+	if !ok {
+		log.Println(fmt.Sprintf("Ignoring invalid Max-File-Size %s", res.Header.Get("Max-File-Size")))
+		return
+	}
+	sdk.setMaxFileSize(size, false)
+}
+
+// checkMaxFileSize is a function to fail fast when a file is larger than the cluster accepts
+func (sdk VideraSDK) checkMaxFileSize(filename string, size int64) error {
+	if maxFileSize := sdk.maxFileSize(); maxFileSize > 0 && size > maxFileSize {
+		return fmt.Errorf("%w: %s is %v bytes, max is %v bytes", ErrFileTooLarge, filename, size, maxFileSize)
+	}
 	return nil
 }
 
 // sendInitialRequest is a function responsible for starting upload process with data node
-// default set headers are filename and filetype
-func (sdk VideraSDK) sendInitialRequest(filepath string, filetype string, extraHeaders map[string]string) (string, error) {
-	filename := path.Base(filepath)
+// default set headers are the sanitized filename, filetype and the content type sniffed from the source
+func (sdk VideraSDK) sendInitialRequest(source Source, filetype string, extraHeaders map[string]string) (string, error) {
+	filename := utils.SanitizeFilename(utils.NormalizeFilename(source.Name()), sdk.filenameLength)
+
+	// limits advertised by the data node apply before init instead of failing the upload midway
+	capabilities := sdk.nodeCapabilities(sdk.uploadURL())
+	if capabilities.MaxFileSize > 0 {
+		sdk.setMaxFileSize(capabilities.MaxFileSize, true)
+	}
+	sdk.recordMaxRequestSize(capabilities.MaxRequestSize)
 
-	client := utils.NewClient(sdk.defaultMaxRetries, sdk.defaultWaitingTime)
-	req, _ := http.NewRequest(http.MethodPost, uploadURL, nil)
+	fileSize, _ := strconv.ParseInt(extraHeaders["Filesize"], 10, 64)
+	if err := sdk.checkMaxFileSize(filename, fileSize); err != nil {
+		return "", err
+	}
+	contentType, err := sdk.checkFiletype(source, filetype)
+	if err != nil {
+		return "", err
+	}
+
+	body, metadataHeaders, err := sdk.initMetadata(filename)
+	if err != nil {
+		return "", err
+	}
+
+	client := sdk.newClientWithPolicy(sdk.initRetryPolicy())
+	req, _ := httpx.NewRewindableRequest(http.MethodPost, sdk.uploadURL(), body)
 	req.Header.Set("Request-Type", "init")
+	req.Header.Set("Protocol-Version", strconv.Itoa(protocolVersion))
+	// retries of the request carry the same key so the data node can return the session it already created
+	req.Header.Set("Idempotency-Key", newIdempotencyKey())
+	offered := supportedOffer(sdk.offeredEncodings(), capabilities.Encodings)
+	if offered != "" {
+		req.Header.Set("Chunk-Encodings", offered)
+	}
+	offeredChecksums := supportedOffer(sdk.offeredChecksums(), capabilities.Checksums)
+	if offeredChecksums != "" {
+		req.Header.Set("Checksum-Algorithms", offeredChecksums)
+	}
 	req.Header.Set("Filename", filename)
 	req.Header.Set("Filetype", filetype)
+	req.Header.Set("Content-Type", contentType)
+	if sdk.options.FilenameCollision != "" {
+		req.Header.Set("Filename-Collision", sdk.options.FilenameCollision)
+	}
+	proposed := sdk.proposedID(filetype, extraHeaders)
+	if proposed != "" {
+		req.Header.Set("Proposed-ID", proposed)
+	}
 
+	for key, val := range sdk.projectHeaders() {
+		req.Header.Set(key, val)
+	}
+	for key, val := range metadataHeaders {
+		req.Header.Set(key, val)
+	}
 	for key, val := range extraHeaders {
 		req.Header.Set(key, val)
 	}
@@ -99,72 +390,287 @@ func (sdk VideraSDK) sendInitialRequest(filepath string, filetype string, extraH
 		log.Println(err)
 		return "", err
 	}
+	recordProtocolVersion(sdk.uploadURL(), res)
 
-	if res.StatusCode != http.StatusCreated {
-		return "", errors.New("An error has occurred")
+	sdk.updateMaxFileSize(res)
+	if res.StatusCode == http.StatusRequestEntityTooLarge {
+		if err := sdk.checkMaxFileSize(filename, fileSize); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("%w: %s is %v bytes", ErrFileTooLarge, filename, fileSize)
+	}
+	if err := permissionError(res); err != nil {
+		return "", err
+	}
+	adopted := sessionConflict(res)
+	if !adopted {
+		if err := checkFilenameCollision(filename, res); err != nil {
+			return "", err
+		}
+		if res.StatusCode != http.StatusCreated {
+			return "", errors.New("An error has occurred")
+		}
 	}
 
 	id := res.Header.Get("ID")
-	if res.Header.Get("Max-Request-Size") != "" {
-		sdk.chunkSize, _ = strconv.ParseInt(res.Header.Get("Max-Request-Size"), 10, 64)
-		log.Println(fmt.Sprintf("Chunk size %v", sdk.chunkSize))
+	if adopted {
+		id = sdk.adoptSession(filename, res)
+	}
+	logAssignedID(proposed, res)
+	sdk.recordSessionEncoding(id, offered, res)
+	sdk.recordSessionChecksum(id, offeredChecksums, res)
+	if extraHeaders["Resume-From"] != "" {
+		sdk.recordResumedOffset(id, res)
+	}
+	// an adopted session continues as the single in order stream it may have been uploaded as
+	if !adopted {
+		sdk.recordSessionParallel(id, res)
+		sdk.recordSessionOrder(id, res)
+	}
+	sdk.recordSessionExpiry(id, res)
+	record := SessionRecord{ID: id, Filetype: filetype, Filename: filename, DataNode: sdk.uploadURL(), StartedAt: time.Now().UTC(), Size: fileSize}
+	if expiry := sdk.sessionExpiry(id); !expiry.IsZero() {
+		expiry = expiry.UTC()
+		record.ExpiresAt = &expiry
+	}
+	sdk.journalSession(record)
+	if maxRequestSize, ok := httpx.MaxRequestSize(res); ok {
+		log.Println(fmt.Sprintf("Chunk size %v", maxRequestSize))
+		// chunks of the session are sized from the recorded limit
+		sdk.recordMaxRequestSize(maxRequestSize)
 	}
 	return id, nil
 }
 
+// newIdempotencyKey is a function that returns a random key identifying one init request across its retries
+func newIdempotencyKey() string {
+	key := make([]byte, 16)
+	rand.Read(key)
+	return hex.EncodeToString(key)
+}
+
 // UploadJob is a function responsible for uploading a model and a video into videra system
-func (sdk VideraSDK) UploadJob(videoPath string, modelPath string, configPath string, codePath string) error {
-	ticker := time.NewTicker(time.Duration(sdk.defaultWaitingTime) * time.Second)
+// paths may also be http(s)://, s3:// or gs:// URLs, see NewSource
+// a failing post upload hook is returned along with the result of the completed upload
+func (sdk VideraSDK) UploadJob(videoPath string, modelPath string, configPath string, codePath string) (JobResult, error) {
+	sdk.deadline = sdk.uploadDeadline()
+	sdk = sdk.withRoute()
+	sources, err := sdk.newSources(map[string]string{
+		"video":  videoPath,
+		"model":  modelPath,
+		"config": configPath,
+		"code":   codePath,
+	})
+	if err != nil {
+		return JobResult{}, err
+	}
+	// a mismatching or corrupt video fails the job before the model is uploaded
+	if sdk.options.StrictFiletype {
+		if _, err = sdk.checkFiletype(sources["video"], "video"); err != nil {
+			return JobResult{}, err
+		}
+	}
+	if err = sdk.precheckVideo(sources["video"]); err != nil {
+		return JobResult{}, err
+	}
 
-	for trial := 0; trial <= sdk.defaultMaxRetries; trial, _ = trial+1, <-ticker.C {
-		err := sdk.updateUploadURL()
+	modelFiles := sourcesFingerprint(sources, modelUploadOrder)
+	videoFiles := sourcesFingerprint(sources, []string{"video"})
+	sources, cleanup, err := sdk.stageSources(sources)
+	if err != nil {
+		return JobResult{}, err
+	}
+	defer cleanup()
+
+	var videoHeaders map[string]string
+	var segments []Source
+	if sdk.processesVideo() {
+		// a processed video isn't interchangeable with an upload of the same file
+		videoFiles = nil
+		if err = sdk.requireMediaFeatures(); err != nil {
+			return JobResult{}, err
+		}
+
+		workDir, err := ioutil.TempDir("", "videra-video-")
 		if err != nil {
-			log.Println("Can't contact master")
-			log.Println(err)
-			continue
+			return JobResult{}, err
 		}
+		defer os.RemoveAll(workDir)
 
-		modelID, err := sdk.sendModelInitialRequest(modelPath, configPath, codePath)
+		sources["video"], videoHeaders, err = sdk.prepareVideo(sources["video"], workDir)
 		if err != nil {
-			log.Println("Can't connect to node")
-			log.Println(err)
-			continue
+			return JobResult{}, err
 		}
 
-		log.Println("Sent inital request for model with ID =", modelID)
-		uploadFilesPaths := map[string]string{
-			"model":  modelPath,
-			"config": configPath,
-			"code":   codePath,
+		if sdk.options.SegmentDuration > 0 {
+			segmentsDir := filepath.Join(workDir, "segments")
+			if err = os.Mkdir(segmentsDir, 0700); err != nil {
+				return JobResult{}, err
+			}
+			segments, err = segmentVideo(sources["video"], sdk.options.SegmentDuration, segmentsDir)
+			if err != nil {
+				return JobResult{}, err
+			}
+			log.Println(fmt.Sprintf("Split video into %v segments", len(segments)))
 		}
-		err = sdk.uploadFiles(modelID, uploadFilesPaths, modelUploadOrder)
+	}
+
+	// the model and video of the job can't both take the proposed ID
+	sdk.jobProposal = true
+	var result JobResult
+	result.Model.ProposedID = sdk.proposedID("model", nil)
+	if segments == nil {
+		result.Video.ProposedID = sdk.proposedID("video", videoHeaders)
+	}
+	var modelChecksums map[string]string
+	result.Model.Checksum, modelChecksums, err = sourcesChecksums(sources, modelUploadOrder)
+	if err != nil {
+		return JobResult{}, err
+	}
+	result.Video.Checksum, _, err = sourcesChecksums(sources, []string{"video"})
+	if err != nil {
+		return JobResult{}, err
+	}
+	result.Video.ScrubbedMetadata = scrubbedMetadata(videoHeaders)
+	if err = sdk.validateModelConfig(sources["model"].Name(), sources["config"]); err != nil {
+		return JobResult{}, err
+	}
+	companions, err := sdk.readCompanions()
+	if err != nil {
+		return JobResult{}, err
+	}
+
+	cachedModelID := ""
+	if sdk.options.SkipUploaded {
+		cachedModelID = sdk.lookupUploadCache("model", modelFiles, result.Model.Checksum, "")
+		cachedVideoID := sdk.lookupUploadCache("video", videoFiles, result.Video.Checksum, cachedModelID)
+		if cachedModelID != "" && cachedVideoID != "" {
+			log.Println("Job was already uploaded with model ID =", cachedModelID, "and video ID =", cachedVideoID)
+			result.Model.ID, result.Video.ID = cachedModelID, cachedVideoID
+			return result, nil
+		}
+	}
+
+	files := map[string]string{"video": videoPath, "model": modelPath, "config": configPath, "code": codePath}
+	if err = sdk.runHooks(HookEvent{Stage: "pre_upload", Filetype: "job", Files: files}); err != nil {
+		return JobResult{}, err
+	}
+
+	start := time.Now()
+	sdk.chunkStats = newChunkStats()
+	err = sdk.retryUpload(func(trial int) error {
+		result.Model.Retries, result.Video.Retries = trial, trial
+
+		err := sdk.routeUpload(result.Video.Checksum, trial)
 		if err != nil {
+			log.Println("Can't contact master")
 			log.Println(err)
-			continue
+			return err
+		}
+
+		// check both uploads before sending anything, the video is rejected only after the model otherwise
+		if segments == nil {
+			err = sdk.checkSourcesSize(sources, []string{"video"})
+		}
+		for idx := 0; err == nil && idx < len(segments); idx++ {
+			err = sdk.checkSourcesSize(map[string]Source{"video": segments[idx]}, []string{"video"})
+		}
+		if err == nil {
+			err = sdk.checkSourcesSize(sources, modelUploadOrder)
+		}
+		if errors.Is(err, ErrFileTooLarge) {
+			return err
 		}
 
-		log.Println("Upload Model successful")
+		modelID := cachedModelID
+		if modelID == "" {
+			modelID, err = sdk.sendModelInitialRequest(sources, modelChecksums, nil)
+			if err != nil {
+				log.Println("Can't connect to node")
+				log.Println(err)
+				return err
+			}
+
+			log.Println("Sent inital request for model with ID =", modelID)
+			bytesSent, err := sdk.withReinit(&modelID, func(extraHeaders map[string]string) (string, error) {
+				return sdk.sendModelInitialRequest(sources, modelChecksums, extraHeaders)
+			}).uploadModelFiles(modelID, sources, modelChecksumHeaders(modelChecksums))
+			result.Model.BytesSent += bytesSent
+			if err != nil {
+				log.Println(err)
+				return err
+			}
+
+			log.Println("Upload Model successful")
+			result.Model.DataNode = sdk.uploadURL()
+			result.Model.Duration = time.Since(start)
+		} else {
+			log.Println("Model was already uploaded with ID =", modelID)
+		}
+		result.Model.ID = modelID
+		videoStart := time.Now()
+
+		if segments != nil {
+			result.Segments, err = sdk.uploadSegments(segments, modelID, videoHeaders)
+			for _, segment := range result.Segments {
+				result.Video.BytesSent += segment.BytesSent
+			}
+			if err != nil {
+				log.Println(err)
+				return err
+			}
+
+			log.Println("Video segments were uploaded successfully")
+			result.Video.ID, result.Video.DataNode = result.Segments[0].ID, sdk.uploadURL()
+			result.Video.Duration = time.Since(videoStart)
+			return nil
+		}
 
-		videoID, err := sdk.sendVideoInitialRequest(videoPath, modelID)
+		videoID, err := sdk.sendVideoInitialRequest(sources["video"], modelID, videoHeaders)
 		if err != nil {
 			log.Println("Can't connect to node")
 			log.Println(err)
-			continue
+			return err
 		}
 
 		log.Println("Sent inital request with ID =", videoID)
-		videoPathMap := map[string]string{
-			"video": videoPath,
-		}
-
-		err = sdk.uploadFiles(videoID, videoPathMap, []string{"video"})
+		bytesSent, err := sdk.withReinit(&videoID, func(extraHeaders map[string]string) (string, error) {
+			return sdk.sendVideoInitialRequest(sources["video"], modelID, mergeHeaders(videoHeaders, extraHeaders))
+		}).uploadFiles(videoID, sources, []string{"video"}, nil)
+		result.Video.BytesSent += bytesSent
 		if err != nil {
 			log.Println(err)
-			continue
+			return err
 		}
 
 		log.Println("Video was upload successfully")
+		result.Video.ID, result.Video.DataNode = videoID, sdk.uploadURL()
+		result.Video.Duration = time.Since(videoStart)
 		return nil
+	})
+	sdk.emitUploadOutcome("job", start, err)
+	if err != nil {
+		return JobResult{}, err
+	}
+	sdk.chunkStats.summarize(sdk, "model", &result.Model)
+	sdk.chunkStats.summarize(sdk, "video", &result.Video)
+	if err = sdk.attachCompanions(result.Model.ID, companions); err != nil {
+		return result, err
+	}
+
+	if cachedModelID == "" {
+		err = sdk.recordUploadCache("model", modelFiles, result.Model, "")
 	}
-	return errors.New("An error has occurred")
+	if err == nil {
+		err = sdk.recordUploadCache("video", videoFiles, result.Video, result.Model.ID)
+	}
+	if err != nil {
+		log.Println("Unable to record upload in cache:", err)
+	}
+
+	results := map[string]UploadResult{"model": result.Model, "video": result.Video}
+	sdk.auditUpload("job", files, results)
+	sdk.accountUpload(results)
+	sdk.catalogUpload(files, results)
+	return result, sdk.runHooks(HookEvent{Stage: "post_upload", Filetype: "job", Files: files, Results: results})
 }
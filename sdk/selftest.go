@@ -0,0 +1,159 @@
+package viderasdk
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"time"
+)
+
+// errSelftestInterrupted Returned by the source of a resume self-test once the interruption offset is reached
+var errSelftestInterrupted = errors.New("Upload interrupted by the self-test")
+
+// selftestChunks Chunks the file of a resume self-test spans when no size is given
+const selftestChunks = 4
+
+// ResumeReport Describes the outcome of a resumability self-test
+type ResumeReport struct {
+	ID            string        `json:"id"`             //ID of the test upload
+	DataNode      string        `json:"data_node"`      //Data node the test upload went to
+	Size          int64         `json:"size"`           //Size of the random file uploaded
+	InterruptedAt int64         `json:"interrupted_at"` //Offset at which the first attempt was cut
+	StoredAt      int64         `json:"stored_at"`      //Bytes the data node held after the interruption, -1 when it doesn't report them
+	BytesResent   int64         `json:"bytes_resent"`   //Bytes sent by the resumed attempt
+	Checksum      string        `json:"checksum"`       //Hex encoded sha256 digest of the random file
+	Verify        VerifyReport  `json:"verify"`         //Comparison of the stored upload against the random file
+	Duration      time.Duration `json:"duration"`       //Time the self-test took
+	Problems      []string      `json:"problems"`       //Why the self-test failed, empty when it passed
+}
+
+// Passed returns whether the upload resumed where it was interrupted and the stored upload matches the file
+func (report ResumeReport) Passed() bool {
+	return len(report.Problems) == 0
+}
+
+// interruptedSource A source whose readers fail once they reach an offset, simulating a connection dropped mid upload
+type interruptedSource struct {
+	Source
+	at int64 //Offset at which reads fail
+}
+
+// Open returns a reader of the source failing with errSelftestInterrupted at the interruption offset
+func (source interruptedSource) Open(offset int64) (io.ReadCloser, error) {
+	reader, err := source.Source.Open(offset)
+	if err != nil {
+		return nil, err
+	}
+	return &interruptedReader{ReadCloser: reader, remaining: source.at - offset}, nil
+}
+
+// interruptedReader Reads until the interruption offset of its source
+type interruptedReader struct {
+	io.ReadCloser
+	remaining int64 //Bytes readable before the interruption
+}
+
+// Read reads from the underlying reader, failing with errSelftestInterrupted once the interruption offset is reached
+func (reader *interruptedReader) Read(p []byte) (int, error) {
+	if reader.remaining <= 0 {
+		return 0, errSelftestInterrupted
+	}
+	if int64(len(p)) > reader.remaining {
+		p = p[:reader.remaining]
+	}
+	n, err := reader.ReadCloser.Read(p)
+	reader.remaining -= int64(n)
+	return n, err
+}
+
+// SelfTestResume is a function responsible for checking that uploads to the cluster resume where they were interrupted
+// a random file of size bytes, selftestChunks chunks when 0, is uploaded as a video for the given model, cut at a random offset,
+// resumed in the same session and compared against the stored upload. The test upload stays on the cluster under the returned ID
+func (sdk VideraSDK) SelfTestResume(size int64, associatedModelID string) (ResumeReport, error) {
+	sdk = sdk.withRoute()
+	start := time.Now()
+	if size <= 0 {
+		size = selftestChunks * sdk.chunkSize
+	}
+	if size < 2 {
+		return ResumeReport{}, fmt.Errorf("Self-test file of %v bytes can't be interrupted", size)
+	}
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	report := ResumeReport{Size: size, InterruptedAt: 1 + random.Int63n(size-1), StoredAt: -1}
+
+	file, err := os.CreateTemp("", "videra-selftest-*.bin")
+	if err != nil {
+		return report, err
+	}
+	defer os.Remove(file.Name())
+	_, err = io.CopyN(file, random, size)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return report, err
+	}
+	source, err := sdk.newSource(file.Name())
+	if err != nil {
+		return report, err
+	}
+	if report.Checksum, _, err = sourcesChecksums(map[string]Source{"video": source}, []string{"video"}); err != nil {
+		return report, err
+	}
+
+	if err = sdk.routeUpload(report.Checksum, 0); err != nil {
+		return report, err
+	}
+	if report.ID, err = sdk.sendVideoInitialRequest(source, associatedModelID, nil); err != nil {
+		return report, err
+	}
+	report.DataNode = sdk.uploadURL()
+	log.Println(fmt.Sprintf("Self-test upload %s of %v bytes interrupted at offset %v", report.ID, size, report.InterruptedAt))
+
+	_, err = sdk.uploadFiles(report.ID, map[string]Source{"video": interruptedSource{Source: source, at: report.InterruptedAt}}, []string{"video"}, nil)
+	if !errors.Is(err, errSelftestInterrupted) {
+		if err == nil {
+			err = errors.New("upload completed before the interruption")
+		}
+		return report, fmt.Errorf("Unable to interrupt the self-test upload: %v", err)
+	}
+	if digests, err := sdk.storedDigests(report.DataNode, report.ID); err == nil && digests != nil {
+		report.StoredAt = digests.Size
+		if report.StoredAt > report.InterruptedAt {
+			report.Problems = append(report.Problems, fmt.Sprintf("data node holds %v bytes of the %v sent before the interruption", report.StoredAt, report.InterruptedAt))
+		}
+	}
+
+	log.Println(fmt.Sprintf("Resuming self-test upload %s", report.ID))
+	sdk.chunkStats = newChunkStats()
+	err = sdk.retryUpload(func(trial int) error {
+		bytesSent, err := sdk.uploadFiles(report.ID, map[string]Source{"video": source}, []string{"video"}, nil)
+		report.BytesResent += bytesSent
+		return err
+	})
+	if err != nil {
+		report.Problems = append(report.Problems, fmt.Sprintf("resumed upload failed: %v", err))
+		report.Duration = time.Since(start)
+		return report, nil
+	}
+
+	// the chunk cut by the interruption is sent again, more than that means the upload started over
+	remaining := size - report.InterruptedAt + sdk.chunkSize
+	if report.StoredAt >= 0 {
+		remaining = size - report.StoredAt
+	}
+	if report.BytesResent > remaining {
+		report.Problems = append(report.Problems, fmt.Sprintf("resumed upload sent %v bytes instead of at most the %v remaining", report.BytesResent, remaining))
+	}
+
+	if report.Verify, err = sdk.Verify(report.ID, file.Name()); err != nil {
+		report.Problems = append(report.Problems, fmt.Sprintf("stored upload can't be verified: %v", err))
+	} else if !report.Verify.Match() {
+		report.Problems = append(report.Problems, fmt.Sprintf("stored upload differs from the file in %v ranges", len(report.Verify.Mismatches)))
+	}
+	report.Duration = time.Since(start)
+	return report, nil
+}
@@ -0,0 +1,145 @@
+package viderasdk
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// serverVersions Version each master and data node reported in its Server-Version header, keyed by host
+var serverVersions sync.Map
+
+// serverQuirks Describes how a server version deviates from the protocol the SDK speaks
+type serverQuirks struct {
+	requestTypeCase string //lower or title to rewrite Request-Type values the server matches case sensitively, empty sends them as is
+	sessionIDHeader string //Header the server returns session IDs in instead of ID, empty when it uses ID
+	initStatus      int    //Status the server answers created sessions with instead of 201, 0 when it answers 201
+}
+
+// knownQuirks Quirks of server versions older than each version, the first entry the version is older than applies
+var knownQuirks = []struct {
+	below  string
+	quirks serverQuirks
+}{
+	{below: "1.0", quirks: serverQuirks{requestTypeCase: "lower", sessionIDHeader: "Session-ID", initStatus: http.StatusOK}},
+	{below: "1.4", quirks: serverQuirks{requestTypeCase: "title", initStatus: http.StatusOK}},
+}
+
+// quirksFor is a function that returns the quirks of a server version, none for empty or unknown versions
+func quirksFor(version string) serverQuirks {
+	if version == "" {
+		return serverQuirks{}
+	}
+	for _, known := range knownQuirks {
+		if compareVersions(version, known.below) < 0 {
+			return known.quirks
+		}
+	}
+	return serverQuirks{}
+}
+
+// compareVersions is a function that compares dotted versions such as 1.3.2 numerically, returning -1, 0 or 1
+// a leading v and suffixes such as -rc1 are ignored, missing components count as 0
+func compareVersions(a string, b string) int {
+	partsA, partsB := versionParts(a), versionParts(b)
+	for idx := 0; idx < len(partsA) || idx < len(partsB); idx++ {
+		var partA, partB int
+		if idx < len(partsA) {
+			partA = partsA[idx]
+		}
+		if idx < len(partsB) {
+			partB = partsB[idx]
+		}
+		if partA != partB {
+			if partA < partB {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionParts is a function to get the numeric components of a dotted version
+func versionParts(version string) []int {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if idx := strings.IndexAny(version, "-+ "); idx >= 0 {
+		version = version[:idx]
+	}
+
+	var parts []int
+	for _, part := range strings.Split(version, ".") {
+		number, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+		parts = append(parts, number)
+	}
+	return parts
+}
+
+// serverVersion is a function to get the version of the server at host
+// hosts that haven't answered yet are assumed to run the version the master reported, data nodes are as old as their cluster
+func (sdk VideraSDK) serverVersion(host string) string {
+	if sdk.serverVersionPin != "" {
+		return sdk.serverVersionPin
+	}
+	if version, ok := serverVersions.Load(host); ok {
+		return version.(string)
+	}
+	if req, err := http.NewRequest(http.MethodGet, sdk.knownMaster(), nil); err == nil {
+		if version, ok := serverVersions.Load(req.URL.Host); ok {
+			return version.(string)
+		}
+	}
+	return ""
+}
+
+// quirksTransport Adapts requests and responses to the quirks of the version of the server they are sent to
+type quirksTransport struct {
+	base http.RoundTripper //Transport sending the requests
+	sdk  VideraSDK         //SDK whose servers versions are looked up
+}
+
+// RoundTrip sends the request as the server version expects it and normalizes the response to the current protocol
+// the Server-Version header of every response is recorded so later requests to the host adapt to it
+func (transport quirksTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	quirks := quirksFor(transport.sdk.serverVersion(req.URL.Host))
+	requestType := req.Header.Get("Request-Type")
+	if requestType != "" && quirks.requestTypeCase != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Request-Type", requestTypeCase(requestType, quirks.requestTypeCase))
+	}
+
+	res, err := transport.base.RoundTrip(req)
+	if err != nil {
+		return res, err
+	}
+	if version := res.Header.Get("Server-Version"); version != "" {
+		serverVersions.Store(req.URL.Host, version)
+	}
+
+	if quirks.sessionIDHeader != "" && res.Header.Get("ID") == "" && res.Header.Get(quirks.sessionIDHeader) != "" {
+		res.Header.Set("ID", res.Header.Get(quirks.sessionIDHeader))
+	}
+	if strings.EqualFold(requestType, "init") && quirks.initStatus != 0 && res.StatusCode == quirks.initStatus && res.Header.Get("ID") != "" {
+		res.StatusCode, res.Status = http.StatusCreated, "201 Created"
+	}
+	return res, nil
+}
+
+// requestTypeCase is a function to get a Request-Type value in the case a server expects, e.g. APPEND as append or Append
+func requestTypeCase(requestType string, letterCase string) string {
+	lower := strings.ToLower(requestType)
+	if letterCase == "title" {
+		words := strings.Split(lower, "-")
+		for idx, word := range words {
+			if word != "" {
+				words[idx] = strings.ToUpper(word[:1]) + word[1:]
+			}
+		}
+		return strings.Join(words, "-")
+	}
+	return lower
+}
@@ -0,0 +1,264 @@
+package viderasdk
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrOffsetMismatch Returned by AppendAt when the data node expects another offset, Session.Offset is the one to continue from
+var ErrOffsetMismatch = errors.New("Offset mismatch")
+
+// ErrSessionIncomplete Returned by Finalize when the data node didn't complete the session
+var ErrSessionIncomplete = errors.New("Session incomplete")
+
+// ErrSessionCompleted Returned by AppendAt once the data node completed the session
+var ErrSessionCompleted = errors.New("Session already completed")
+
+// Session An upload session whose chunks the integrator sends, e.g. from custom sources or in their own order, see OpenSession
+// appends go through the chunk path of every upload: they are sent with the append retry policy, fail over to the data node the
+// master routes to when theirs is unreachable, re-initialize the session when the data node expires it, renegotiate the chunk size
+// and are remediated by the throughput watchdog, everything else of the pipeline of uploads, such as processing, dedup and ordering,
+// is left to the integrator
+type Session struct {
+	sdk       VideraSDK           //SDK the session was opened with, re-running init when the data node expires the session
+	client    *http.Client        //Client appends are sent with
+	mutex     sync.Mutex          //Serializes appends
+	id        string              //ID of the session, changed by re-initializations
+	dataNode  string              //Data node the session was opened on, changed by failovers
+	filetype  string              //Filetype the session was initialized with
+	size      int64               //Size of the upload in bytes
+	offset    int64               //Offset the data node expects next, as far as known
+	chunkSize int64               //Size of the chunk requests appends are split in
+	compress  bool                //Whether chunks are compressed with the codec of the session, only when the source compresses well
+	reinits   int                 //Re-initializations so far
+	bytesSent int64               //Payload bytes transmitted
+	start     time.Time           //Time at which the session was opened
+	retries   int                 //Failed init attempts before the session was opened
+	completed bool                //Whether the data node completed the session
+	watchdog  *throughputWatchdog //Throughput watchdog of the appends, nil when disabled
+}
+
+// OpenSession is a function responsible for initializing an upload session of source, routed like every upload and retried as set
+// by the retry policies, source gives the name, size and leading bytes of the upload the init request is built from
+// headers are added to the init request, e.g. Associated-Model-ID for videos
+func (sdk VideraSDK) OpenSession(source Source, filetype string, headers map[string]string) (*Session, error) {
+	sdk = sdk.withRoute()
+	size, err := source.Size()
+	if err != nil {
+		return nil, err
+	}
+	initHeaders := mergeHeaders(map[string]string{"Filesize": fmt.Sprintf("%v", size)}, headers)
+
+	session := &Session{filetype: filetype, size: size, start: time.Now()}
+	err = sdk.retryUpload(func(trial int) error {
+		session.retries = trial
+		if err := sdk.routeUpload("", trial); err != nil {
+			return err
+		}
+		session.id, err = sdk.sendInitialRequest(source, filetype, initHeaders)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	session.sdk = sdk.withReinit(&session.id, func(extraHeaders map[string]string) (string, error) {
+		return sdk.sendInitialRequest(source, filetype, mergeHeaders(initHeaders, extraHeaders))
+	})
+	session.client = sdk.newClientWithPolicy(sdk.appendRetryPolicy())
+	session.chunkSize = sdk.learnedChunkSize()
+	session.dataNode = sdk.uploadURL()
+	session.watchdog = sdk.newThroughputWatchdog()
+	if codec := sdk.sessionCodec(session.id); codec != nil {
+		session.compress = sourceCompressible(source, codec)
+	}
+	session.offset = sdk.adoptedOffset(session.id)
+	log.Println("Opened session", session.id)
+	return session, nil
+}
+
+// ID returns the ID of the session, which changes when the data node expires the session and it is re-initialized or fails over
+func (session *Session) ID() string {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+	return session.id
+}
+
+// Offset returns the offset the data node expects next as far as known, i.e. after the last acknowledged append or the offset it corrected to
+func (session *Session) Offset() int64 {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+	return session.offset
+}
+
+// AppendAt is a function responsible for sending n bytes read from r at offset, split in chunks of the negotiated chunk size
+// when the data node expects another offset, e.g. after a re-initialization kept fewer bytes, it fails with ErrOffsetMismatch
+// and Offset returns the one to continue from, the bytes of r after the chunk that failed aren't read
+func (session *Session) AppendAt(offset int64, r io.Reader, n int64) error {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	if session.completed {
+		return fmt.Errorf("%w: %s", ErrSessionCompleted, session.id)
+	}
+	if offset < 0 || n < 0 || offset+n > session.size {
+		return fmt.Errorf("Bytes %v to %v are outside the %v bytes of session %s", offset, offset+n, session.size, session.id)
+	}
+
+	buffer := session.sdk.acquireBuffer(session.chunkSize)
+	defer func() { session.sdk.releaseBuffer(buffer) }()
+	for n > 0 && !session.completed {
+		length := n
+		if length > int64(len(buffer)) {
+			length = int64(len(buffer))
+		}
+		if _, err := io.ReadFull(r, buffer[:length]); err != nil {
+			return err
+		}
+		if err := session.send(offset, buffer[:length]); err != nil {
+			return err
+		}
+		offset, n = offset+length, n-length
+	}
+	return nil
+}
+
+// send is a function responsible for sending a chunk at offset, split again when the data node lowers the chunk size
+func (session *Session) send(offset int64, chunk []byte) error {
+	sdk := session.sdk
+	tags := map[string]string{"filetype": session.filetype}
+	for len(chunk) > 0 && !session.completed {
+		// a session about to expire is extended or replaced before the chunk is sent to it
+		if id := session.id; sdk.sessionExpiring(id, offset) && session.reinits < sdk.sessionReinits {
+			newID, newOffset, err := sdk.renewSession(id, offset)
+			if err != nil {
+				return err
+			}
+			if newID != id {
+				session.reinits++
+				return session.continueFrom(id, offset, newID, newOffset)
+			}
+		}
+
+		length := int64(len(chunk))
+		if length > session.chunkSize {
+			length = session.chunkSize
+		}
+		var codec Codec
+		if session.compress {
+			codec = sdk.sessionCodec(session.id)
+		}
+		req := newChunkRequest(session.dataNode, session.id, offset, chunk[:length], nil, codec)
+		sdk.setChunkChecksum(req, session.id, chunk[:length])
+		response, err := sdk.sendChunk(session.client, session.id, req, offset, length, tags)
+		if err != nil {
+			// the data node is still unreachable after the append retries, the session continues on the one the master routes to
+			if session.reinits >= sdk.sessionReinits {
+				return err
+			}
+			return session.failover(offset, err)
+		}
+
+		switch response.outcome {
+		case chunkAccepted, chunkCompleted:
+			session.bytesSent += response.sent
+			offset += length
+			chunk = chunk[length:]
+			session.offset = offset
+			session.completed = response.outcome == chunkCompleted
+			if err = session.observe(offset, length); err != nil {
+				return err
+			}
+			continue
+		case chunkOffsetCorrected:
+			session.offset = response.offset
+			return fmt.Errorf("%w: data node expects offset %v of session %s, not %v", ErrOffsetMismatch, response.offset, session.id, offset)
+		case chunkTooLarge:
+			if response.chunkSize < length {
+				sdk.warn(Warning{Kind: WarningChunkSizeChange, Session: session.id, Old: strconv.FormatInt(session.chunkSize, 10), New: strconv.FormatInt(response.chunkSize, 10),
+					Message: fmt.Sprintf("Chunk size error: changing from %v to %v", session.chunkSize, response.chunkSize)})
+				session.chunkSize = response.chunkSize
+				sdk.recordMaxRequestSize(response.chunkSize)
+				continue
+			}
+		case chunkSessionExpired:
+			if id := session.id; session.reinits < sdk.sessionReinits {
+				session.reinits++
+				newID, newOffset, err := sdk.reinitSession(id, offset)
+				if err != nil {
+					return err
+				}
+				return session.continueFrom(id, offset, newID, newOffset)
+			}
+		}
+		if err = permissionError(response.res); err != nil {
+			return err
+		}
+		return fmt.Errorf("Unable to append to session %s at offset %v: %s", session.id, offset, response.res.Status)
+	}
+	return nil
+}
+
+// observe is a function responsible for feeding the throughput watchdog with an acknowledged append of length bytes ending at offset
+// and applying its remediation, a failover fails the append with ErrOffsetMismatch like a re-initialization
+func (session *Session) observe(offset int64, length int64) error {
+	rate, slow := session.watchdog.observe(length)
+	if !slow || session.completed {
+		return nil
+	}
+	session.watchdog.reset()
+	sdk := session.sdk
+	sdk.chunkSize = session.chunkSize
+	if action := sdk.slowThroughput(session.id, rate, session.reinits); action == WatchdogRenegotiate {
+		session.chunkSize /= 2
+	} else if action == WatchdogFailover {
+		sdk.metrics().Count("watchdog_failovers", 1, map[string]string{"filetype": session.filetype})
+		return session.failover(offset, nil)
+	}
+	return nil
+}
+
+// failover is a function responsible for continuing the session at offset on the data node the master routes to, cause is the
+// error that made the data node of the session unusable, nil when the watchdog found it too slow
+func (session *Session) failover(offset int64, cause error) error {
+	id := session.id
+	session.reinits++
+	newID, newOffset, err := session.sdk.failover(id, offset)
+	if err != nil && cause != nil {
+		return fmt.Errorf("Unable to fail over session %s after %v: %w", id, cause, err)
+	}
+	if err != nil {
+		return err
+	}
+	return session.continueFrom(id, offset, newID, newOffset)
+}
+
+// continueFrom is a function responsible for moving appends at offset to the session that replaced the session id, which continues
+// from newOffset on the data node the SDK is now routed to, the append fails with ErrOffsetMismatch so the integrator sends again from there
+func (session *Session) continueFrom(id string, offset int64, newID string, newOffset int64) error {
+	session.id, session.offset = newID, newOffset
+	session.dataNode = session.sdk.uploadURL()
+	return fmt.Errorf("%w: session %s was replaced by %s continuing from offset %v, not %v", ErrOffsetMismatch, id, newID, newOffset, offset)
+}
+
+// Finalize is a function responsible for closing a session the data node completed, removing it from the session journal
+// it fails with ErrSessionIncomplete while bytes are missing, the Checksum of the result is left empty as the integrator sent the bytes
+func (session *Session) Finalize() (UploadResult, error) {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	if !session.completed {
+		return UploadResult{}, fmt.Errorf("%w: %s reached offset %v of %v bytes", ErrSessionIncomplete, session.id, session.offset, session.size)
+	}
+	session.sdk.completeSession(session.id, session.dataNode)
+	session.sdk.emitUploadOutcome(session.filetype, session.start, nil)
+	log.Println("Session", session.id, "completed")
+	return UploadResult{ID: session.id, BytesSent: session.bytesSent, Duration: time.Since(session.start), DataNode: session.dataNode,
+		Retries: session.retries + session.reinits}, nil
+}
@@ -0,0 +1,37 @@
+package viderasdk
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/SayedAlesawy/Videra-SDK/httpx"
+)
+
+// sessionConflict is a function to check whether an init response is a conflict with a session the data node already has for the file
+// such responses are 409 carrying the ID of the existing session and the Offset it reached, unlike filename collisions which carry no ID
+func sessionConflict(res *http.Response) bool {
+	return res.StatusCode == http.StatusConflict && res.Header.Get("ID") != ""
+}
+
+// adoptSession is a function responsible for continuing the existing session of a conflicting init response
+// the session replaces any journal record of it and its first transfer starts at the offset it reached
+func (sdk VideraSDK) adoptSession(filename string, res *http.Response) string {
+	id := res.Header.Get("ID")
+	offset, _ := httpx.OffsetCorrection(res)
+
+	sdk.completeSession(id, sdk.uploadURL())
+	sdk.updateSession(id, func(state *sessionState) { state.adoptedOffset = offset })
+	sdk.warn(Warning{Kind: WarningSessionAdopted, Session: id, DataNode: sdk.uploadURL(), New: strconv.FormatInt(offset, 10),
+		Message: fmt.Sprintf("Data node already has session %s for %s, continuing it from offset %v", id, filename, offset)})
+	return id
+}
+
+// adoptedOffset is a function that returns the offset an adopted session reached, 0 for other sessions or once it was read
+func (sdk VideraSDK) adoptedOffset(id string) int64 {
+	offset := int64(0)
+	sdk.updateSession(id, func(state *sessionState) {
+		offset, state.adoptedOffset = state.adoptedOffset, 0
+	})
+	return offset
+}
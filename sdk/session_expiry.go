@@ -0,0 +1,76 @@
+package viderasdk
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/httpx"
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// recordSessionExpiry is a function responsible for remembering when the data node expires a session
+// data nodes announce it in a Session-TTL header holding seconds or a Session-Expires header holding a time,
+// on init and on chunks when activity extends it
+func (sdk VideraSDK) recordSessionExpiry(id string, res *http.Response) {
+	expiry := httpx.SessionExpiry(res, time.Now())
+	if expiry.IsZero() {
+		return
+	}
+
+	sdk.updateSession(id, func(state *sessionState) {
+		if expiry.After(state.expiry) {
+			state.expiryWarned = false
+		}
+		state.expiry = expiry
+	})
+}
+
+// sessionExpiry is a function that returns the time a session expires at, zero when the data node didn't say
+func (sdk VideraSDK) sessionExpiry(id string) time.Time {
+	return sdk.loadSession(id).expiry
+}
+
+// sessionExpiring is a function to check whether a session expires within the expiry margin, warning about it once
+func (sdk VideraSDK) sessionExpiring(id string, offset int64) bool {
+	expiry := sdk.sessionExpiry(id)
+	if expiry.IsZero() || time.Until(expiry) > sdk.expiryMargin {
+		return false
+	}
+
+	warned := false
+	sdk.updateSession(id, func(state *sessionState) { warned, state.expiryWarned = state.expiryWarned, true })
+	if !warned {
+		sdk.warn(Warning{Kind: WarningSessionExpiring, Session: id, New: expiry.UTC().Format(time.RFC3339),
+			Message: fmt.Sprintf("Session %s expires in %v at offset %v", id, time.Until(expiry).Round(time.Second), offset)})
+	}
+	return true
+}
+
+// renewSession is a function responsible for keeping an expiring session alive before the data node expires it
+// the data node is asked to extend it with a REFRESH request, data nodes that can't are given a new session
+// continuing from offset, the ID and offset the upload continues with are returned
+func (sdk VideraSDK) renewSession(id string, offset int64) (string, int64, error) {
+	client := sdk.newClient()
+	req, _ := http.NewRequest(http.MethodPost, sdk.uploadURL(), nil)
+	req.Header.Set("Request-Type", "REFRESH")
+	req.Header.Set("ID", id)
+	res, err := client.Do(req)
+	if err == nil {
+		res.Body.Close()
+		if res.StatusCode == http.StatusOK {
+			sdk.recordSessionExpiry(id, res)
+			if time.Until(sdk.sessionExpiry(id)) > sdk.expiryMargin {
+				utils.Debugln(fmt.Sprintf("Session %s extended until %v", id, sdk.sessionExpiry(id).UTC().Format(time.RFC3339)))
+				return id, offset, nil
+			}
+		}
+	}
+
+	if sdk.reinit == nil || sdk.artifact != "" {
+		// nothing else keeps the session alive, the upload races its expiry without asking again for every chunk
+		sdk.updateSession(id, func(state *sessionState) { state.expiry = time.Time{} })
+		return id, offset, nil
+	}
+	return sdk.reinitSession(id, offset)
+}
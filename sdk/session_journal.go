@@ -0,0 +1,100 @@
+package viderasdk
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// sessionJournalFile Name of the file recording upload sessions that didn't complete yet inside the state directory
+const sessionJournalFile = "sessions.json"
+
+// sessionJournalMutex Serializes updates of the session journal, e.g. by concurrent uploads
+var sessionJournalMutex sync.Mutex
+
+// SessionRecord Describes an upload session initialized with a data node that didn't complete yet
+type SessionRecord struct {
+	ID        string     `json:"id"`                   //ID assigned to the session by the data node
+	Filetype  string     `json:"filetype"`             //Type of the upload, model or video
+	Filename  string     `json:"filename"`             //Filename of the upload
+	DataNode  string     `json:"data_node"`            //Upload URL of the data node holding the session
+	StartedAt time.Time  `json:"started_at"`           //Time at which the session was initialized
+	Size      int64      `json:"size,omitempty"`       //Size of the upload in bytes
+	Offset    int64      `json:"offset,omitempty"`     //Bytes acknowledged by the data node when the upload was interrupted
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` //Time the data node expires the session at, nil when it didn't say
+}
+
+// Sessions is a function to list the upload sessions recorded in the session journal
+func (sdk VideraSDK) Sessions() ([]SessionRecord, error) {
+	var records []SessionRecord
+	if err := sdk.loadStateFile(sessionJournalFile, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// saveSessions is a function responsible for writing the session journal, replacing the file atomically
+func (sdk VideraSDK) saveSessions(records []SessionRecord) error {
+	return sdk.saveStateFile(sessionJournalFile, records)
+}
+
+// journalSession is a function responsible for recording an initialized session, failures are only logged
+func (sdk VideraSDK) journalSession(record SessionRecord) {
+	sessionJournalMutex.Lock()
+	defer sessionJournalMutex.Unlock()
+
+	records, err := sdk.Sessions()
+	if err == nil {
+		err = sdk.saveSessions(append(records, record))
+	}
+	if err != nil {
+		log.Println("Unable to record session in journal:", err)
+	}
+}
+
+// completeSession is a function responsible for removing a completed session from the journal along with its state, failures are only logged
+func (sdk VideraSDK) completeSession(id string, dataNode string) {
+	sdk.forgetSession(id, dataNode)
+	sessionJournalMutex.Lock()
+	defer sessionJournalMutex.Unlock()
+
+	records, err := sdk.Sessions()
+	if err != nil {
+		log.Println("Unable to update session journal:", err)
+		return
+	}
+
+	remaining := []SessionRecord{}
+	for _, record := range records {
+		if record.ID != id || record.DataNode != dataNode {
+			remaining = append(remaining, record)
+		}
+	}
+	if len(remaining) == len(records) {
+		return
+	}
+	if err = sdk.saveSessions(remaining); err != nil {
+		log.Println("Unable to update session journal:", err)
+	}
+}
+
+// recordSessionOffset is a function responsible for recording the offset an interrupted session reached, failures are only logged
+func (sdk VideraSDK) recordSessionOffset(id string, dataNode string, offset int64) {
+	sessionJournalMutex.Lock()
+	defer sessionJournalMutex.Unlock()
+
+	records, err := sdk.Sessions()
+	if err != nil {
+		log.Println("Unable to update session journal:", err)
+		return
+	}
+
+	for idx := range records {
+		if records[idx].ID == id && records[idx].DataNode == dataNode {
+			records[idx].Offset = offset
+		}
+	}
+	if err = sdk.saveSessions(records); err != nil {
+		log.Println("Unable to update session journal:", err)
+	}
+}
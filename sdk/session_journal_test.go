@@ -0,0 +1,40 @@
+package viderasdk
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentJournalUpdates checks sessions journaled and completed by concurrent uploads all reach the journal
+func TestConcurrentJournalUpdates(t *testing.T) {
+	sdk := VideraSDK{stateDir: t.TempDir()}
+	const uploads = 16
+	var wg sync.WaitGroup
+	for idx := 0; idx < uploads; idx++ {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			id := fmt.Sprint(idx)
+			sdk.journalSession(SessionRecord{ID: id, DataNode: "node"})
+			sdk.recordSessionOffset(id, "node", int64(idx))
+			if idx%2 == 0 {
+				sdk.completeSession(id, "node")
+			}
+		}(idx)
+	}
+	wg.Wait()
+
+	records, err := sdk.Sessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != uploads/2 {
+		t.Fatalf("%v sessions in the journal, expected %v", len(records), uploads/2)
+	}
+	for _, record := range records {
+		if fmt.Sprint(record.Offset) != record.ID {
+			t.Errorf("session %s recorded offset %v", record.ID, record.Offset)
+		}
+	}
+}
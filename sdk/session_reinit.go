@@ -0,0 +1,71 @@
+package viderasdk
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/SayedAlesawy/Videra-SDK/httpx"
+)
+
+// sessionReinit Re-runs the init request of an upload, hinting the data node at the offset the expired session reached
+type sessionReinit func(resumeFrom int64) (string, error)
+
+// withReinit is a function that returns a copy of the SDK whose uploads re-run init when the data node expires the session
+// init is given the Resume-From and Previous-ID headers to add to the init request, *id is updated to the new session
+func (sdk VideraSDK) withReinit(id *string, init func(extraHeaders map[string]string) (string, error)) VideraSDK {
+	sdk.reinit = func(resumeFrom int64) (string, error) {
+		newID, err := init(map[string]string{
+			"Resume-From": strconv.FormatInt(resumeFrom, 10),
+			"Previous-ID": *id,
+		})
+		if err == nil {
+			*id = newID
+		}
+		return newID, err
+	}
+	return sdk
+}
+
+// sessionExpired is a function to check whether a chunk response says the data node expired the session
+func sessionExpired(res *http.Response) bool {
+	return res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusGone
+}
+
+// reinitSession is a function responsible for replacing an expired session by a new one
+// the data node may keep the bytes of the expired session up to the Resume-From hint, the upload continues
+// from the offset it answers with and restarts otherwise
+func (sdk VideraSDK) reinitSession(id string, offset int64) (string, int64, error) {
+	newID, err := sdk.reinit(offset)
+	if err != nil {
+		return "", 0, fmt.Errorf("Unable to re-initialize expired session %s: %v", id, err)
+	}
+	sdk.completeSession(id, sdk.uploadURL())
+
+	resumeFrom := int64(0)
+	if kept := sdk.loadSession(newID); kept.resumed && kept.resumedOffset <= offset {
+		resumeFrom = kept.resumedOffset
+	}
+	sdk.warn(Warning{Kind: WarningSessionReinit, Session: newID, Old: id, New: newID,
+		Message: fmt.Sprintf("Session %s expired at offset %v, continuing from %v as session %s", id, offset, resumeFrom, newID)})
+	return newID, resumeFrom, nil
+}
+
+// recordResumedOffset is a function responsible for remembering the offset a re-initialized session continues from
+func (sdk VideraSDK) recordResumedOffset(id string, res *http.Response) {
+	if offset, ok := httpx.OffsetCorrection(res); ok {
+		sdk.updateSession(id, func(state *sessionState) { state.resumedOffset, state.resumed = offset, true })
+	}
+}
+
+// mergeHeaders is a function that returns the headers of both maps, those of extra replacing those of headers
+func mergeHeaders(headers map[string]string, extra map[string]string) map[string]string {
+	merged := make(map[string]string, len(headers)+len(extra))
+	for key, val := range headers {
+		merged[key] = val
+	}
+	for key, val := range extra {
+		merged[key] = val
+	}
+	return merged
+}
@@ -0,0 +1,61 @@
+package viderasdk
+
+import "time"
+
+// sessionState Describes what the data node negotiated for a session of the call, recorded from its init and chunk responses
+type sessionState struct {
+	encoding      string    //Chunk encoding the data node accepted, empty when chunks are sent as is
+	checksum      string    //Chunk checksum algorithm the data node picked, empty when chunks carry no checksum
+	parallel      bool      //Whether the data node accepted the artifacts of the session uploaded in parallel
+	tailFirst     bool      //Whether the data node accepted the chunks of the session in tail first order
+	adoptedOffset int64     //Offset an adopted session reached, read once by its first transfer
+	resumedOffset int64     //Offset a re-initialized session continues from, the bytes the data node kept of the expired session
+	resumed       bool      //Whether the data node answered the re-initialization with the offset it kept
+	expiry        time.Time //Time the data node expires the session at, zero when it didn't say
+	expiryWarned  bool      //Whether the approaching expiry was already warned about
+}
+
+// updateSession is a function responsible for changing the state of session id on the data node the call is routed to
+// sessions are told apart by data node as IDs are only unique per data node, calls that aren't routed keep no state
+func (sdk VideraSDK) updateSession(id string, update func(state *sessionState)) {
+	if sdk.route == nil {
+		return
+	}
+	sdk.route.mutex.Lock()
+	defer sdk.route.mutex.Unlock()
+
+	flow := chunkFlow(sdk.route.dataNode, id)
+	state, ok := sdk.route.sessions[flow]
+	if !ok {
+		if sdk.route.sessions == nil {
+			sdk.route.sessions = make(map[string]*sessionState)
+		}
+		state = &sessionState{}
+		sdk.route.sessions[flow] = state
+	}
+	update(state)
+}
+
+// loadSession is a function that returns the state of session id on the data node the call is routed to, empty for unknown sessions
+func (sdk VideraSDK) loadSession(id string) sessionState {
+	if sdk.route == nil {
+		return sessionState{}
+	}
+	sdk.route.mutex.Lock()
+	defer sdk.route.mutex.Unlock()
+
+	if state, ok := sdk.route.sessions[chunkFlow(sdk.route.dataNode, id)]; ok {
+		return *state
+	}
+	return sessionState{}
+}
+
+// forgetSession is a function responsible for dropping the state of session id on dataNode once the session ended
+func (sdk VideraSDK) forgetSession(id string, dataNode string) {
+	if sdk.route == nil {
+		return
+	}
+	sdk.route.mutex.Lock()
+	defer sdk.route.mutex.Unlock()
+	delete(sdk.route.sessions, chunkFlow(dataNode, id))
+}
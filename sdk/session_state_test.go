@@ -0,0 +1,34 @@
+package viderasdk
+
+import "testing"
+
+// TestSessionStatePerDataNode checks sessions of the same ID on different data nodes keep their own state,
+// that completing a session drops its state and that calls don't share the state of their sessions
+func TestSessionStatePerDataNode(t *testing.T) {
+	sdk := VideraSDK{stateDir: t.TempDir()}.withRoute()
+	sdk.routeTo("http://node-a")
+	sdk.updateSession("1", func(state *sessionState) { state.encoding, state.adoptedOffset = "zstd", 42 })
+	sdk.routeTo("http://node-b")
+	if state := sdk.loadSession("1"); state.encoding != "" {
+		t.Errorf("session 1 of node-b has encoding %q of session 1 of node-a", state.encoding)
+	}
+	if other := (VideraSDK{}).withRoute(); other.loadSession("1").encoding != "" {
+		t.Errorf("another call sees the state of session 1")
+	}
+
+	sdk.routeTo("http://node-a")
+	if offset := sdk.adoptedOffset("1"); offset != 42 {
+		t.Errorf("adopted offset %v, expected 42", offset)
+	}
+	if offset := sdk.adoptedOffset("1"); offset != 0 {
+		t.Errorf("adopted offset %v read twice, expected 0", offset)
+	}
+
+	sdk.completeSession("1", "http://node-a")
+	if state := sdk.loadSession("1"); state.encoding != "" {
+		t.Errorf("completed session kept encoding %q", state.encoding)
+	}
+	if len(sdk.route.sessions) != 0 {
+		t.Errorf("%v sessions left in the state of the call, expected none", len(sdk.route.sessions))
+	}
+}
@@ -0,0 +1,156 @@
+package viderasdk
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// ErrSessionMismatch Returned when importing a session for a local file that isn't the one being uploaded
+var ErrSessionMismatch = errors.New("Local file doesn't match the exported session")
+
+// SessionToken Describes an interrupted upload session so that another machine holding the same file can continue it
+type SessionToken struct {
+	ID        string   `json:"id"`                   //ID assigned to the session by the data node
+	Filetype  string   `json:"filetype"`             //Type of the upload, only video sessions can be imported
+	Filename  string   `json:"filename"`             //Filename of the upload
+	DataNode  string   `json:"data_node"`            //Upload URL of the data node holding the session
+	Size      int64    `json:"size"`                 //Size of the whole upload in bytes
+	Offset    int64    `json:"offset"`               //Bytes stored by the data node when the session was exported
+	ChunkSize int64    `json:"chunk_size,omitempty"` //Size of the chunks digested in Chunks
+	Chunks    []string `json:"chunks,omitempty"`     //Hex encoded sha256 digest of each stored chunk, empty when the data node didn't report them
+}
+
+// ExportSession is a function responsible for producing a portable token for a session of the session journal
+// the bytes stored so far and their digests are asked to the data node, the journal offset is used when it doesn't report them
+func (sdk VideraSDK) ExportSession(id string) (string, error) {
+	records, err := sdk.Sessions()
+	if err != nil {
+		return "", err
+	}
+
+	var token *SessionToken
+	for _, record := range records {
+		if record.ID == id {
+			token = &SessionToken{ID: record.ID, Filetype: record.Filetype, Filename: record.Filename,
+				DataNode: record.DataNode, Size: record.Size, Offset: record.Offset}
+			if record.ExpiresAt != nil && time.Now().After(*record.ExpiresAt) {
+				utils.Warnln(fmt.Sprintf("Session %s expired at %v, the data node may have discarded it", id, record.ExpiresAt.Format(time.RFC3339)))
+			}
+		}
+	}
+	if token == nil {
+		return "", fmt.Errorf("Session %s is not in the session journal", id)
+	}
+
+	digests, err := sdk.storedDigests(token.DataNode, id)
+	if err != nil {
+		return "", err
+	}
+	if digests != nil {
+		token.Offset, token.ChunkSize, token.Chunks = digests.Size, digests.ChunkSize, digests.Chunks
+	} else {
+		utils.Warnln("Data node doesn't report chunk digests, the importing file is only checked by size")
+	}
+
+	content, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(content), nil
+}
+
+// ParseSessionToken is a function to decode a token produced by ExportSession
+func ParseSessionToken(encoded string) (SessionToken, error) {
+	var token SessionToken
+	content, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return token, fmt.Errorf("Invalid session token: %v", err)
+	}
+	if err = json.Unmarshal(content, &token); err != nil {
+		return token, fmt.Errorf("Invalid session token: %v", err)
+	}
+	if token.ID == "" || token.DataNode == "" {
+		return token, errors.New("Invalid session token: missing session ID or data node")
+	}
+	return token, nil
+}
+
+// ImportSession is a function responsible for continuing an exported session from a local copy of its file
+// the local file must have the size of the upload and, when the token carries digests, match every stored chunk
+func (sdk VideraSDK) ImportSession(encoded string, location string) (UploadResult, error) {
+	sdk.deadline = sdk.uploadDeadline()
+	sdk = sdk.withRoute()
+	token, err := ParseSessionToken(encoded)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	if token.Filetype != "video" {
+		return UploadResult{}, fmt.Errorf("Only video sessions can be imported, %s is a %s session", token.ID, token.Filetype)
+	}
+
+	source, err := sdk.newSource(location)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	if err = verifySessionSource(token, source); err != nil {
+		return UploadResult{}, err
+	}
+
+	checksum, _, err := sourcesChecksums(map[string]Source{"video": source}, []string{"video"})
+	if err != nil {
+		return UploadResult{}, err
+	}
+	result := UploadResult{ID: token.ID, DataNode: token.DataNode, Checksum: checksum}
+
+	sdk.routeTo(token.DataNode)
+	sdk.journalSession(SessionRecord{ID: token.ID, Filetype: token.Filetype, Filename: token.Filename,
+		DataNode: token.DataNode, StartedAt: time.Now().UTC(), Size: token.Size, Offset: token.Offset})
+
+	// the data node answers the first chunk with the offset it reached, the upload continues from there
+	start := time.Now()
+	sdk.chunkStats = newChunkStats()
+	err = sdk.retryUpload(func(trial int) error {
+		result.Retries = trial
+		bytesSent, err := sdk.uploadFiles(token.ID, map[string]Source{"video": source}, []string{"video"}, nil)
+		result.BytesSent += bytesSent
+		return err
+	})
+	sdk.emitUploadOutcome("video", start, err)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	sdk.chunkStats.summarize(sdk, "video", &result)
+
+	log.Println("Imported session", token.ID, "completed")
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// verifySessionSource is a function to check that a local source is the file of an exported session
+func verifySessionSource(token SessionToken, source Source) error {
+	size, err := source.Size()
+	if err != nil {
+		return err
+	}
+	if size != token.Size {
+		return fmt.Errorf("%w: %s is %v bytes, the session uploads %v bytes", ErrSessionMismatch, source.Name(), size, token.Size)
+	}
+	if len(token.Chunks) == 0 {
+		return nil
+	}
+
+	mismatches, err := compareDigests(source, token.Offset, storedDigests{Size: token.Offset, ChunkSize: token.ChunkSize, Chunks: token.Chunks})
+	if err != nil {
+		return err
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("%w: %s differs from the stored bytes at %v-%v", ErrSessionMismatch, source.Name(), mismatches[0].Start, mismatches[0].End)
+	}
+	return nil
+}
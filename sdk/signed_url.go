@@ -0,0 +1,93 @@
+package viderasdk
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"time"
+)
+
+// signedURLSessionParam Query parameter of a signed URL carrying the ID of the upload session
+const signedURLSessionParam = "id"
+
+// UploadToSignedURL is a function responsible for uploading a video to a session created out of band
+// the presigned data node URL carries the session ID in its id query parameter, master discovery and init are skipped
+// the video is processed like by UploadVideo but, without an init request, the original isn't described to the data node
+func (sdk VideraSDK) UploadToSignedURL(videoPath string, signedURL string) (UploadResult, error) {
+	sdk.deadline = sdk.uploadDeadline()
+	sdk = sdk.withRoute()
+	parsedURL, err := url.Parse(signedURL)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	id := parsedURL.Query().Get(signedURLSessionParam)
+	if id == "" {
+		return UploadResult{}, errors.New("Signed URL is missing the session ID")
+	}
+
+	video, err := sdk.newSource(videoPath)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	video, cleanup, err := sdk.stageSource(video)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	defer cleanup()
+	var videoHeaders map[string]string
+	if sdk.transformsVideo() {
+		workDir, err := ioutil.TempDir("", "videra-video-")
+		if err != nil {
+			return UploadResult{}, err
+		}
+		defer os.RemoveAll(workDir)
+
+		if video, videoHeaders, err = sdk.prepareVideo(video, workDir); err != nil {
+			return UploadResult{}, err
+		}
+	}
+	videoSources := map[string]Source{
+		"video": video,
+	}
+
+	checksum, _, err := sourcesChecksums(videoSources, []string{"video"})
+	if err != nil {
+		return UploadResult{}, err
+	}
+	result := UploadResult{ID: id, Checksum: checksum, ScrubbedMetadata: scrubbedMetadata(videoHeaders)}
+
+	files := map[string]string{"video": videoPath}
+	if err = sdk.runHooks(HookEvent{Stage: "pre_upload", Filetype: "video", Files: files}); err != nil {
+		return UploadResult{}, err
+	}
+
+	sdk.routeTo(signedURL)
+	start := time.Now()
+	sdk.chunkStats = newChunkStats()
+	err = sdk.retryUpload(func(trial int) error {
+		result.Retries = trial
+
+		bytesSent, err := sdk.uploadFiles(id, videoSources, []string{"video"}, nil)
+		result.BytesSent += bytesSent
+		return err
+	})
+	sdk.emitUploadOutcome("video", start, err)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	sdk.chunkStats.summarize(sdk, "video", &result)
+
+	log.Println("Upload successful")
+	// the signature of the URL isn't reported
+	parsedURL.RawQuery = ""
+	result.DataNode = parsedURL.String()
+	result.Duration = time.Since(start)
+
+	results := map[string]UploadResult{"video": result}
+	sdk.auditUpload("video", files, results)
+	sdk.accountUpload(results)
+	sdk.catalogUpload(files, results)
+	return result, sdk.runHooks(HookEvent{Stage: "post_upload", Filetype: "video", Files: files, Results: results})
+}
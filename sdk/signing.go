@@ -0,0 +1,130 @@
+package viderasdk
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// signingDateHeader Header carrying the time a request was signed at, covered by the signature so gateways can reject replays
+const signingDateHeader = "X-Videra-Date"
+
+// signingTimeFormat Format of signingDateHeader, the basic ISO 8601 format of SigV4
+const signingTimeFormat = "20060102T150405Z"
+
+// defaultSignedHeaders Headers the HMAC signer covers when present, besides Host and signingDateHeader
+var defaultSignedHeaders = []string{"Request-Type", "ID", "Offset", "Filesize", "Content-Encoding", "Chunk-Size", "Chunk-Hash", "Artifact"}
+
+// RequestSigner Signs requests before they are sent, e.g. for gateways authenticating requests in front of data nodes
+type RequestSigner interface {
+	// Sign adds the signature of req to its headers, bodyHash is the hex encoded sha256 digest of its body
+	Sign(req *http.Request, bodyHash string) error
+}
+
+// HMACSigner A RequestSigner computing an HMAC-SHA256 over the method, path, query, headers and body hash of requests
+// in the manner of AWS SigV4, the signature is sent in the Signature header along with the key ID and the signed headers:
+// Signature: VIDERA-HMAC-SHA256 KeyId=<key id>, SignedHeaders=host;x-videra-date, Signature=<hex>
+type HMACSigner struct {
+	KeyID         string   //Identifies the secret to the gateway
+	Secret        string   //Secret shared with the gateway
+	SignedHeaders []string //Headers covered when present besides Host and X-Videra-Date, nil covers the headers of the upload protocol
+}
+
+// Sign adds the date, body hash and signature headers to req
+func (signer HMACSigner) Sign(req *http.Request, bodyHash string) error {
+	req.Header.Set(signingDateHeader, time.Now().UTC().Format(signingTimeFormat))
+	req.Header.Set("X-Content-SHA256", bodyHash)
+
+	headers := signer.SignedHeaders
+	if headers == nil {
+		headers = defaultSignedHeaders
+	}
+	names := []string{"host", strings.ToLower(signingDateHeader), "x-content-sha256"}
+	for _, header := range headers {
+		if req.Header.Get(header) != "" {
+			names = append(names, strings.ToLower(header))
+		}
+	}
+	sort.Strings(names)
+
+	mac := hmac.New(sha256.New, []byte(signer.Secret))
+	io.WriteString(mac, canonicalRequest(req, names, bodyHash))
+	req.Header.Set("Signature", fmt.Sprintf("VIDERA-HMAC-SHA256 KeyId=%s, SignedHeaders=%s, Signature=%s",
+		signer.KeyID, strings.Join(names, ";"), hex.EncodeToString(mac.Sum(nil))))
+	return nil
+}
+
+// canonicalRequest is a function to get the string an HMACSigner signs, lines of the method, path, sorted query,
+// each signed header as name:value in the order of names, the names joined by ; and the body hash
+func canonicalRequest(req *http.Request, names []string, bodyHash string) string {
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	lines := []string{req.Method, path, req.URL.Query().Encode()}
+	for _, name := range names {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		lines = append(lines, name+":"+strings.TrimSpace(value))
+	}
+	return strings.Join(append(lines, strings.Join(names, ";"), bodyHash), "\n")
+}
+
+// signingTransport Signs every request with the signer of the client options
+type signingTransport struct {
+	base   http.RoundTripper //Transport sending the requests
+	signer RequestSigner     //Signer adding the signature headers
+}
+
+// RoundTrip sends a signed copy of the request, the body is hashed through GetBody when the request can rewind it
+func (transport signingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	bodyHash, err := requestBodyHash(req)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	if err = transport.signer.Sign(req, bodyHash); err != nil {
+		return nil, fmt.Errorf("Unable to sign request: %v", err)
+	}
+	return transport.base.RoundTrip(req)
+}
+
+// requestBodyHash is a function to get the hex encoded sha256 digest of the body of a request
+// bodies that can't be rewound are read into memory and replaced so the request can still be sent
+func requestBodyHash(req *http.Request) (string, error) {
+	hash := sha256.New()
+	if req.Body == nil || req.Body == http.NoBody {
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+		defer body.Close()
+		if _, err = io.Copy(hash, body); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	}
+
+	content, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	hash.Write(content)
+	req.Body = ioutil.NopCloser(strings.NewReader(string(content)))
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
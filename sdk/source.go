@@ -0,0 +1,299 @@
+package viderasdk
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// Source An interface for upload inputs, regardless of where their bytes are stored
+type Source interface {
+	// Name returns the file name reported to the data node
+	Name() string
+	// Size returns the total size of the source in bytes
+	Size() (int64, error)
+	// Open returns a reader positioned at the given offset from the start of the source
+	Open(offset int64) (io.ReadCloser, error)
+}
+
+// sparseSource An optional interface for sources that can report holes, i.e. ranges that read back as zeros
+type sparseSource interface {
+	Holes() ([]utils.ByteRange, error)
+}
+
+// sourceHoles is a function to get the holes of a source, returns nil when the source isn't sparse aware
+func sourceHoles(source Source) []utils.ByteRange {
+	sparse, ok := source.(sparseSource)
+	if !ok {
+		return nil
+	}
+
+	holes, err := sparse.Holes()
+	if err != nil {
+		log.Println("Unable to detect holes, uploading as a regular file:", err)
+		return nil
+	}
+
+	return holes
+}
+
+// NewSource is a function responsible for resolving an upload location into a source
+// supported locations are local paths, http(s):// URLs, s3://bucket/key and gs://bucket/key
+// s3:// and gs:// locations are read anonymously from their public HTTPS endpoints so only publicly readable objects work,
+// private objects are read through a presigned https:// URL instead
+// URLs are fetched with default client settings, locations passed to the upload methods of an SDK are fetched with its client
+func NewSource(location string) (Source, error) {
+	return VideraSDK{fileSystem: utils.OSFileSystem{}}.newSource(location)
+}
+
+// newSource is a function responsible for resolving an upload location into a source
+// local paths are read from the filesystem of the SDK and URLs fetched with its source client
+func (sdk VideraSDK) newSource(location string) (Source, error) {
+	parsedURL, err := url.Parse(location)
+	// windows drive letters parse as single letter schemes, treat them as local paths
+	if err != nil || len(parsedURL.Scheme) <= 1 {
+		return localSource{fileSystem: sdk.fileSystem, path: location}, nil
+	}
+
+	objectKey := strings.TrimPrefix(parsedURL.Path, "/")
+	switch parsedURL.Scheme {
+	case "file":
+		return localSource{fileSystem: sdk.fileSystem, path: fileURLPath(parsedURL)}, nil
+	case "http", "https":
+		return newHTTPSource(location, "", sdk.sourceClient()), nil
+	case "s3":
+		return newHTTPSource(fmt.Sprintf("https://%s.s3.amazonaws.com/%s", parsedURL.Host, objectKey), location, sdk.sourceClient()), nil
+	case "gs":
+		return newHTTPSource(fmt.Sprintf("https://storage.googleapis.com/%s/%s", parsedURL.Host, objectKey), location, sdk.sourceClient()), nil
+	}
+
+	return nil, fmt.Errorf("Unsupported source scheme %s", parsedURL.Scheme)
+}
+
+// sourceClient is a function that returns the client URL sources are fetched with, the client of the SDK without cluster credentials
+// so require_tls and the connection pool apply while bearer tokens and request signatures of the cluster aren't sent to other servers
+func (sdk VideraSDK) sourceClient() *http.Client {
+	sdk.credentials = nil
+	sdk.relays = nil
+	sdk.options.Signer = nil
+	return sdk.newClient()
+}
+
+// fileURLPath is a function to get the local path of a file:// URL
+// file:///C:/videos/cam.mp4 maps to C:/videos/cam.mp4 and file://server/share/cam.mp4 to a UNC path
+func fileURLPath(fileURL *url.URL) string {
+	urlPath := fileURL.Path
+	if fileURL.Host != "" && fileURL.Host != "localhost" {
+		return filepath.FromSlash(fmt.Sprintf("//%s%s", fileURL.Host, urlPath))
+	}
+	if len(urlPath) >= 3 && urlPath[0] == '/' && urlPath[2] == ':' {
+		urlPath = urlPath[1:]
+	}
+
+	return filepath.FromSlash(urlPath)
+}
+
+// newSources is a function responsible for resolving a set of upload locations keyed by artifact name
+func (sdk VideraSDK) newSources(locations map[string]string) (map[string]Source, error) {
+	sources := make(map[string]Source)
+	for name, location := range locations {
+		source, err := sdk.newSource(location)
+		if err != nil {
+			return nil, err
+		}
+		sources[name] = source
+	}
+
+	return sources, nil
+}
+
+// sourcesChecksums is a function to get the hex encoded sha256 digests of sources in a single read pass
+// returns the digest of the sources concatenated in the given order and the digest of each source by name
+func sourcesChecksums(sources map[string]Source, order []string) (string, map[string]string, error) {
+	combinedHash := sha256.New()
+	checksums := make(map[string]string)
+	for _, name := range order {
+		reader, err := sources[name].Open(0)
+		if err != nil {
+			return "", nil, err
+		}
+
+		hash := sha256.New()
+		_, err = io.Copy(io.MultiWriter(combinedHash, hash), reader)
+		reader.Close()
+		if err != nil {
+			return "", nil, err
+		}
+		checksums[name] = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	return hex.EncodeToString(combinedHash.Sum(nil)), checksums, nil
+}
+
+// localSource A source backed by a file on a local filesystem
+type localSource struct {
+	fileSystem fs.FS  //Filesystem the file is read from
+	path       string //Path of the file
+}
+
+// Name returns the base name of the local file
+func (source localSource) Name() string {
+	return utils.NormalizeFilename(source.path)
+}
+
+// Size returns the size of the local file
+func (source localSource) Size() (int64, error) {
+	fileInfo, err := fs.Stat(source.fileSystem, source.path)
+	if err != nil {
+		return 0, err
+	}
+
+	return fileInfo.Size(), nil
+}
+
+// Open opens the local file and seeks to the given offset
+// files that can't seek are read and discarded up to the offset
+func (source localSource) Open(offset int64) (io.ReadCloser, error) {
+	file, err := source.fileSystem.Open(source.path)
+	if err != nil {
+		return nil, err
+	}
+
+	if seeker, ok := file.(io.Seeker); ok {
+		_, err = seeker.Seek(offset, io.SeekStart)
+	} else {
+		_, err = io.CopyN(io.Discard, file, offset)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// Holes returns the holes of the local file, only files on the OS filesystem can report holes
+func (source localSource) Holes() ([]utils.ByteRange, error) {
+	if _, ok := source.fileSystem.(utils.OSFileSystem); !ok {
+		return nil, nil
+	}
+
+	return utils.GetFileHoles(source.path)
+}
+
+// sourceRequestTimeout Time a server is given to answer a request for a URL source before it is abandoned,
+// reading an answered source isn't bounded as it is streamed for as long as the upload takes
+const sourceRequestTimeout = 30 * time.Second
+
+// httpSource A source streamed from an HTTP(S) URL using ranged requests
+// object storage URLs are mapped to their public HTTPS endpoints
+type httpSource struct {
+	url      string       //URL of the object
+	location string       //s3:// or gs:// location the URL was mapped from, empty for http(s):// locations
+	client   *http.Client //Client used to fetch the object
+}
+
+// newHTTPSource is a function responsible for creating a source for an HTTP(S) URL fetched with client
+func newHTTPSource(url string, location string, client *http.Client) httpSource {
+	return httpSource{url: url, location: location, client: client}
+}
+
+// sourceError is a function that returns the error of an unexpected response to a request for the source
+// object storage refusing anonymous reads is pointed at presigned URLs
+func (source httpSource) sourceError(action string, res *http.Response) error {
+	if source.location != "" && (res.StatusCode == http.StatusForbidden || res.StatusCode == http.StatusUnauthorized) {
+		return fmt.Errorf("Unable to %s %s: %s, only publicly readable objects can be read from %s, use a presigned https URL for private ones",
+			action, source.url, res.Status, source.location)
+	}
+	return fmt.Errorf("Unable to %s %s: %s", action, source.url, res.Status)
+}
+
+// Name returns the last path segment of the URL
+func (source httpSource) Name() string {
+	parsedURL, err := url.Parse(source.url)
+	if err != nil {
+		return path.Base(source.url)
+	}
+
+	return path.Base(parsedURL.Path)
+}
+
+// Size returns the content length advertised by the server
+func (source httpSource) Size() (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sourceRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, source.url, nil)
+	if err != nil {
+		return 0, err
+	}
+	res, err := source.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return 0, source.sourceError("stat", res)
+	}
+	if res.ContentLength < 0 {
+		return 0, fmt.Errorf("Unable to stat %s: unknown content length", source.url)
+	}
+
+	return res.ContentLength, nil
+}
+
+// Open requests the object starting at the given offset, abandoning the request when the server doesn't answer in time
+func (source httpSource) Open(offset int64) (io.ReadCloser, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.url, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	expectedStatus := http.StatusOK
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%v-", offset))
+		expectedStatus = http.StatusPartialContent
+	}
+
+	timeout := time.AfterFunc(sourceRequestTimeout, cancel)
+	res, err := source.client.Do(req)
+	timeout.Stop()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if res.StatusCode != expectedStatus {
+		res.Body.Close()
+		cancel()
+		return nil, source.sourceError(fmt.Sprintf("read at offset %v", offset), res)
+	}
+
+	return cancelingBody{ReadCloser: res.Body, cancel: cancel}, nil
+}
+
+// cancelingBody Releases the context of the request of a body when it is closed
+type cancelingBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc //Cancels the context of the request
+}
+
+// Close is a function responsible for closing the body and releasing the context of its request
+func (body cancelingBody) Close() error {
+	err := body.ReadCloser.Close()
+	body.cancel()
+	return err
+}
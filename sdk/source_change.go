@@ -0,0 +1,67 @@
+package viderasdk
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// ErrSourceChanged Returned when a file is modified, truncated or replaced while it is uploaded
+// uploads failing with it are restarted from scratch when AllowChanged is set and fail otherwise
+var ErrSourceChanged = errors.New("Source changed during upload")
+
+// statSource An optional interface for sources that can describe their current state, used to detect changes during uploads
+type statSource interface {
+	Stat() (fs.FileInfo, error)
+}
+
+// Stat returns the file info of the local file
+func (source localSource) Stat() (fs.FileInfo, error) {
+	return fs.Stat(source.fileSystem, source.path)
+}
+
+// sourceSnapshot The state of a source when its upload started
+type sourceSnapshot struct {
+	source statSource  //Source the snapshot was taken of, nil when it can't describe its state
+	name   string      //Name of the source in errors
+	info   fs.FileInfo //State of the source when the upload started
+}
+
+// snapshotSources is a function to take a snapshot of each source that can describe its state
+func snapshotSources(sources map[string]Source) map[string]sourceSnapshot {
+	snapshots := make(map[string]sourceSnapshot, len(sources))
+	for name, source := range sources {
+		stat, ok := source.(statSource)
+		if !ok {
+			continue
+		}
+		if info, err := stat.Stat(); err == nil {
+			snapshots[name] = sourceSnapshot{source: stat, name: source.Name(), info: info}
+		}
+	}
+	return snapshots
+}
+
+// check is a function to fail with ErrSourceChanged when the size, modification time or identity of the source
+// differ from when the snapshot was taken, the identity is the inode on unix and the file index on windows
+func (snapshot sourceSnapshot) check() error {
+	if snapshot.source == nil {
+		return nil
+	}
+
+	info, err := snapshot.source.Stat()
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrSourceChanged, snapshot.name, err)
+	}
+	if info.Size() != snapshot.info.Size() {
+		return fmt.Errorf("%w: %s: size changed from %v to %v bytes", ErrSourceChanged, snapshot.name, snapshot.info.Size(), info.Size())
+	}
+	if !info.ModTime().Equal(snapshot.info.ModTime()) {
+		return fmt.Errorf("%w: %s: modified at %v", ErrSourceChanged, snapshot.name, info.ModTime())
+	}
+	if info.Sys() != nil && snapshot.info.Sys() != nil && !os.SameFile(info, snapshot.info) {
+		return fmt.Errorf("%w: %s: replaced by another file", ErrSourceChanged, snapshot.name)
+	}
+	return nil
+}
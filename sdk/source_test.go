@@ -0,0 +1,77 @@
+package viderasdk
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestHTTPSourceClient checks URL sources are fetched with the client settings of the SDK without its cluster credentials or signatures
+func TestHTTPSourceClient(t *testing.T) {
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		w.Write([]byte("video"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		options ClientOptions
+		check   func(t *testing.T, err error)
+	}{
+		{"signing client", ClientOptions{Signer: HMACSigner{KeyID: "key", Secret: "secret"}}, func(t *testing.T, err error) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			if headers.Get("Signature") != "" || headers.Get("X-Videra-Date") != "" {
+				t.Errorf("cluster request signature sent to the source: %v", headers)
+			}
+		}},
+		{"require tls", ClientOptions{RequireTLS: true}, func(t *testing.T, err error) {
+			if !errors.Is(err, ErrInsecureTransport) {
+				t.Errorf("plain http source read with require_tls: %v", err)
+			}
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			headers = nil
+			sdk := VideraSDK{options: test.options, credentials: &profileCredentials{credentials: Credentials{Token: "cluster-token"}}}
+			source, err := sdk.newSource(server.URL + "/clip.mp4")
+			if err != nil {
+				t.Fatal(err)
+			}
+			reader, err := source.Open(0)
+			if err == nil {
+				ioutil.ReadAll(reader)
+				reader.Close()
+				if headers.Get("Authorization") != "" {
+					t.Errorf("cluster credentials sent to the source: %s", headers.Get("Authorization"))
+				}
+			}
+			test.check(t, err)
+		})
+	}
+}
+
+// TestObjectStorageSourceErrors checks refused reads of object storage locations point at presigned URLs
+func TestObjectStorageSourceErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	source := newHTTPSource(server.URL+"/cam1.mp4", "s3://bucket/cam1.mp4", http.DefaultClient)
+	if _, err := source.Size(); err == nil || !strings.Contains(err.Error(), "presigned") {
+		t.Errorf("refused read of s3 object answered with %v", err)
+	}
+	source.location = ""
+	if _, err := source.Size(); err == nil || strings.Contains(err.Error(), "presigned") {
+		t.Errorf("refused read of https URL answered with %v", err)
+	}
+}
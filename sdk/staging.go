@@ -0,0 +1,93 @@
+package viderasdk
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// Source isolation modes, see ClientOptions.SourceIsolation
+const (
+	IsolationHardlink = "hardlink" //Hard link local files into a staging directory, isolating uploads from files renamed or rotated away
+	IsolationReflink  = "reflink"  //Clone local files copy on write, also isolating uploads from writes, falls back to hard links
+)
+
+// stagingDirPrefix Prefix of the staging directories of uploads in the staging directory, gc removes stale ones in the state directory
+const stagingDirPrefix = "staging.tmp-"
+
+// stageSources is a function responsible for isolating the local files of an upload from their producer as set by SourceIsolation
+// it returns the sources to upload and a function removing the staging directory, sources that can't be staged,
+// e.g. remote ones or files on another filesystem than the staging directory, are uploaded in place
+func (sdk VideraSDK) stageSources(sources map[string]Source) (map[string]Source, func(), error) {
+	isolation := sdk.options.SourceIsolation
+	if isolation == "" {
+		return sources, func() {}, nil
+	}
+	if isolation != IsolationHardlink && isolation != IsolationReflink {
+		return nil, nil, fmt.Errorf("Unknown source isolation %s, expected %s or %s", isolation, IsolationHardlink, IsolationReflink)
+	}
+
+	stagingDir := sdk.stagingDir
+	if stagingDir == "" {
+		stagingDir = sdk.stateDir
+	}
+	if err := os.MkdirAll(stagingDir, 0700); err != nil {
+		return nil, nil, err
+	}
+	workDir, err := ioutil.TempDir(stagingDir, stagingDirPrefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { os.RemoveAll(workDir) }
+
+	staged := make(map[string]Source, len(sources))
+	for name, source := range sources {
+		staged[name] = source
+		local, ok := source.(localSource)
+		if !ok {
+			continue
+		}
+		if _, ok = local.fileSystem.(utils.OSFileSystem); !ok {
+			continue
+		}
+
+		// each file gets its own directory so the staged file keeps its name
+		fileDir := filepath.Join(workDir, name)
+		if err = os.Mkdir(fileDir, 0700); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		stagedPath := filepath.Join(fileDir, filepath.Base(local.path))
+		if err = stageFile(isolation, local.path, stagedPath); err != nil {
+			utils.Warnln(fmt.Sprintf("Unable to stage %s, uploading it in place: %v", local.path, err))
+			continue
+		}
+		staged[name] = localSource{fileSystem: local.fileSystem, path: stagedPath}
+	}
+	return staged, cleanup, nil
+}
+
+// stageFile is a function responsible for linking src at dst, reflinks fall back to hard links when unsupported
+func stageFile(isolation string, src string, dst string) error {
+	if isolation == IsolationReflink {
+		err := utils.CloneFile(src, dst)
+		if err == nil {
+			return nil
+		}
+		utils.Debugln(fmt.Sprintf("Unable to reflink %s, hard linking it: %v", src, err))
+	}
+
+	return os.Link(src, dst)
+}
+
+// stageSource is a function responsible for isolating a single source, see stageSources
+func (sdk VideraSDK) stageSource(source Source) (Source, func(), error) {
+	staged, cleanup, err := sdk.stageSources(map[string]Source{"source": source})
+	if err != nil {
+		return nil, nil, err
+	}
+	return staged["source"], cleanup, nil
+}
@@ -0,0 +1,197 @@
+package viderasdk
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// stateFormat Format name of the envelope state files are written in
+const stateFormat = "videra-state"
+
+// ErrStateCorrupt Returned when a state file fails its integrity checksum or can't be decoded
+var ErrStateCorrupt = errors.New("State file is corrupt")
+
+// ErrStateTooNew Returned when a state file was written by a newer SDK with a schema this one doesn't know
+var ErrStateTooNew = errors.New("State file was written by a newer SDK")
+
+// stateMigration Rewrites the data of a state file from one schema version to the next
+type stateMigration func(data json.RawMessage) (json.RawMessage, error)
+
+// stateSchema Describes the schema of a state file, migrations[v] upgrades data of version v to version v+1
+type stateSchema struct {
+	migrations []stateMigration
+}
+
+// version is a function that returns the schema version the SDK writes the state file in
+func (schema stateSchema) version() int {
+	return len(schema.migrations)
+}
+
+// legacyStateMigration Migrates the bare JSON written before state files were versioned, their data is kept as is
+func legacyStateMigration(data json.RawMessage) (json.RawMessage, error) {
+	return data, nil
+}
+
+// stateSchemas Schemas of the versioned state files keyed by their name inside the state directory,
+// append-only logs such as the audit log and the usage ledger aren't versioned
+var stateSchemas = map[string]stateSchema{
+	sessionJournalFile:   {migrations: []stateMigration{legacyStateMigration}},
+	uploadCacheFile:      {migrations: []stateMigration{legacyStateMigration}},
+	clusterLimitsFile:    {migrations: []stateMigration{legacyStateMigration}},
+	nodeCapabilitiesFile: {migrations: []stateMigration{legacyStateMigration}},
+}
+
+// versionedStateFiles Names of the versioned state files in the order they are described
+var versionedStateFiles = []string{sessionJournalFile, uploadCacheFile, clusterLimitsFile, nodeCapabilitiesFile}
+
+// stateEnvelope Describes the layout state files are written in
+type stateEnvelope struct {
+	Format   string          `json:"format"`   //Always videra-state
+	Version  int             `json:"version"`  //Schema version of the data
+	Checksum string          `json:"checksum"` //Hex encoded sha256 digest of the compacted data
+	Data     json.RawMessage `json:"data"`     //Content of the state file
+}
+
+// StateFileInfo Describes a state file of the state directory
+type StateFileInfo struct {
+	Name           string `json:"name"`               //Name of the file inside the state directory
+	Path           string `json:"path"`               //Path of the file
+	Exists         bool   `json:"exists"`             //Whether the file exists, the fields below are empty otherwise
+	Size           int64  `json:"size,omitempty"`     //Size of the file in bytes
+	Version        int    `json:"version"`            //Schema version the file was written in, 0 for files written before versioning
+	CurrentVersion int    `json:"current_version"`    //Schema version the SDK writes, older files are migrated when loaded
+	Checksum       string `json:"checksum,omitempty"` //Hex encoded sha256 digest of the data, empty for files written before versioning
+	Valid          bool   `json:"valid"`              //Whether the file decodes and matches its checksum
+	Error          string `json:"error,omitempty"`    //Why the file isn't valid
+}
+
+// decodeStateFile is a function to unwrap the content of a state file and migrate its data to the current schema version
+// bare JSON written before state files were versioned is read as version 0
+func decodeStateFile(name string, content []byte, info *StateFileInfo) (json.RawMessage, error) {
+	schema := stateSchemas[name]
+	info.CurrentVersion = schema.version()
+
+	var envelope stateEnvelope
+	if err := json.Unmarshal(content, &envelope); err != nil || envelope.Format != stateFormat {
+		envelope = stateEnvelope{Data: content}
+	} else {
+		info.Version = envelope.Version
+		if stateChecksum(envelope.Data) != envelope.Checksum {
+			return nil, fmt.Errorf("%w: %s doesn't match its checksum", ErrStateCorrupt, name)
+		}
+		info.Checksum = envelope.Checksum
+	}
+
+	if envelope.Version > schema.version() {
+		return nil, fmt.Errorf("%w: %s is version %v, this SDK reads up to version %v", ErrStateTooNew, name, envelope.Version, schema.version())
+	}
+	data := envelope.Data
+	for version := envelope.Version; version < schema.version(); version++ {
+		var err error
+		if data, err = schema.migrations[version](data); err != nil {
+			return nil, fmt.Errorf("%w: %s can't be migrated from version %v: %v", ErrStateCorrupt, name, version, err)
+		}
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("%w: %s isn't JSON", ErrStateCorrupt, name)
+	}
+	return data, nil
+}
+
+// loadStateFile is a function to read a state file into value, migrated to the current schema version
+// value is left untouched when the file doesn't exist
+func (sdk VideraSDK) loadStateFile(name string, value interface{}) error {
+	content, err := ioutil.ReadFile(filepath.Join(sdk.stateDir, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	data, err := decodeStateFile(name, content, &StateFileInfo{})
+	if errors.Is(err, ErrStateCorrupt) {
+		return fmt.Errorf("%w, run state repair", err)
+	}
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("%w: %s: %v, run state repair", ErrStateCorrupt, name, err)
+	}
+	return nil
+}
+
+// saveStateFile is a function responsible for writing a state file in the current schema version with its checksum, replacing the file atomically
+func (sdk VideraSDK) saveStateFile(name string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(stateEnvelope{Format: stateFormat, Version: stateSchemas[name].version(),
+		Checksum: stateChecksum(data), Data: data}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(filepath.Join(sdk.stateDir, name), content)
+}
+
+// stateChecksum is a function to get the checksum of the data of a state file, computed on its compacted form
+// as the data is indented along with the rest of the file
+func stateChecksum(data json.RawMessage) string {
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, data); err != nil {
+		return ""
+	}
+	digest := sha256.Sum256(compacted.Bytes())
+	return hex.EncodeToString(digest[:])
+}
+
+// StateFiles is a function to describe the versioned state files of the state directory, checking their integrity
+func (sdk VideraSDK) StateFiles() []StateFileInfo {
+	var files []StateFileInfo
+	for _, name := range versionedStateFiles {
+		info, _, _ := sdk.StateFile(name)
+		files = append(files, info)
+	}
+	return files
+}
+
+// StateFile is a function to read a versioned state file of the state directory, returning its data migrated to the current schema version
+// the data is nil when the file doesn't exist or isn't valid
+func (sdk VideraSDK) StateFile(name string) (StateFileInfo, json.RawMessage, error) {
+	info := StateFileInfo{Name: name, Path: filepath.Join(sdk.stateDir, name)}
+	if _, ok := stateSchemas[name]; !ok {
+		return info, nil, fmt.Errorf("Unknown state file %s, expected one of %s", name, strings.Join(versionedStateFiles, ", "))
+	}
+	info.CurrentVersion = stateSchemas[name].version()
+
+	content, err := ioutil.ReadFile(info.Path)
+	if os.IsNotExist(err) {
+		return info, nil, nil
+	}
+	if err != nil {
+		info.Error = err.Error()
+		return info, nil, err
+	}
+	info.Exists, info.Size = true, int64(len(content))
+
+	data, err := decodeStateFile(name, content, &info)
+	if err != nil {
+		info.Error = err.Error()
+		return info, nil, err
+	}
+	info.Valid = true
+	return info, data, nil
+}
@@ -0,0 +1,120 @@
+package viderasdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// stateRepairer Describes how a versioned state file is repaired
+type stateRepairer struct {
+	mutex *sync.Mutex        //Serializes updates of the file, held while it is repaired
+	value func() interface{} //Returns a pointer to an empty value of the data of the file
+}
+
+// stateRepairers Repairers of the versioned state files keyed by their name inside the state directory
+var stateRepairers = map[string]stateRepairer{
+	sessionJournalFile:   {&sessionJournalMutex, func() interface{} { return &[]SessionRecord{} }},
+	uploadCacheFile:      {&uploadCacheMutex, func() interface{} { return &[]CacheEntry{} }},
+	clusterLimitsFile:    {&clusterLimitsMutex, func() interface{} { return &map[string]ClusterLimits{} }},
+	nodeCapabilitiesFile: {&nodeCapabilitiesMutex, func() interface{} { return &map[string]NodeCapabilities{} }},
+}
+
+// StateRepair Describes what repairing a state file did
+type StateRepair struct {
+	Name          string `json:"name"`                     //Name of the file inside the state directory
+	Path          string `json:"path"`                     //Path of the file
+	Exists        bool   `json:"exists"`                   //Whether the file existed, missing files need no repair
+	Repaired      bool   `json:"repaired"`                 //Whether the file was corrupt and was rebuilt
+	Salvaged      bool   `json:"salvaged"`                 //Whether the rebuilt file kept the data of the corrupt one, it is empty otherwise
+	QuarantinedAs string `json:"quarantined_as,omitempty"` //Path the corrupt file was moved to
+	Error         string `json:"error,omitempty"`          //Why the file is corrupt, or why it couldn't be repaired
+}
+
+// RepairStateFiles is a function responsible for repairing the corrupt versioned state files of the state directory
+// valid and missing files are left untouched, see RepairStateFile
+func (sdk VideraSDK) RepairStateFiles() []StateRepair {
+	var repairs []StateRepair
+	for _, name := range versionedStateFiles {
+		repair, _ := sdk.RepairStateFile(name)
+		repairs = append(repairs, repair)
+	}
+	return repairs
+}
+
+// RepairStateFile is a function responsible for repairing a versioned state file that fails its checksum or can't be decoded
+// the corrupt file is moved aside next to it and rebuilt from its data when that still decodes, or empty otherwise
+// files written by a newer SDK aren't touched
+func (sdk VideraSDK) RepairStateFile(name string) (StateRepair, error) {
+	repair := StateRepair{Name: name, Path: filepath.Join(sdk.stateDir, name)}
+	repairer, ok := stateRepairers[name]
+	if !ok {
+		_, _, err := sdk.StateFile(name)
+		repair.Error = err.Error()
+		return repair, err
+	}
+	repairer.mutex.Lock()
+	defer repairer.mutex.Unlock()
+
+	content, err := ioutil.ReadFile(repair.Path)
+	if os.IsNotExist(err) {
+		return repair, nil
+	}
+	if err != nil {
+		repair.Error = err.Error()
+		return repair, err
+	}
+	repair.Exists = true
+
+	value := repairer.value()
+	data, err := decodeStateFile(name, content, &StateFileInfo{})
+	if err == nil {
+		if err = json.Unmarshal(data, value); err == nil {
+			return repair, nil
+		}
+		err = fmt.Errorf("%w: %s: %v", ErrStateCorrupt, name, err)
+	}
+	if !errors.Is(err, ErrStateCorrupt) {
+		repair.Error = err.Error()
+		return repair, err
+	}
+	repair.Error = err.Error()
+
+	quarantine := fmt.Sprintf("%s.corrupt-%s", repair.Path, time.Now().UTC().Format("20060102T150405Z"))
+	if err = os.Rename(repair.Path, quarantine); err != nil {
+		repair.Error = fmt.Sprintf("Unable to move aside corrupt %s: %v", name, err)
+		return repair, err
+	}
+	repair.QuarantinedAs = quarantine
+
+	value = repairer.value()
+	repair.Salvaged = salvageStateData(name, content, value)
+	if !repair.Salvaged {
+		value = repairer.value()
+	}
+	if err = sdk.saveStateFile(name, value); err != nil {
+		repair.Error = fmt.Sprintf("Unable to rebuild %s: %v", name, err)
+		return repair, err
+	}
+	repair.Repaired = true
+	return repair, nil
+}
+
+// salvageStateData is a function to decode the data of a corrupt state file into value ignoring its checksum
+// returns false when the data doesn't decode either, e.g. as the file was cut short, or is of a schema version this SDK doesn't know
+func salvageStateData(name string, content []byte, value interface{}) bool {
+	var envelope stateEnvelope
+	data := json.RawMessage(content)
+	if err := json.Unmarshal(content, &envelope); err == nil && envelope.Format == stateFormat {
+		if envelope.Version != stateSchemas[name].version() {
+			return false
+		}
+		data = envelope.Data
+	}
+	return json.Unmarshal(data, value) == nil
+}
@@ -0,0 +1,64 @@
+package viderasdk
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRepairStateFile checks corrupt state files are moved aside and rebuilt, and valid and missing ones left untouched
+func TestRepairStateFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		corrupt  func(content string) string
+		repaired bool
+		sessions int
+	}{
+		{"valid", nil, false, 1},
+		{"missing", func(content string) string { return "" }, false, 0},
+		{"checksum mismatch", func(content string) string { return strings.Replace(content, `"clip.mp4"`, `"clap.mp4"`, 1) }, true, 1},
+		{"cut short", func(content string) string { return content[:len(content)/2] }, true, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sdk := VideraSDK{stateDir: t.TempDir()}
+			sdk.journalSession(SessionRecord{ID: "1", Filename: "clip.mp4", DataNode: "node"})
+			path := filepath.Join(sdk.stateDir, sessionJournalFile)
+			if test.corrupt != nil {
+				content, _ := ioutil.ReadFile(path)
+				if corrupted := test.corrupt(string(content)); corrupted == "" {
+					os.Remove(path)
+				} else if err := ioutil.WriteFile(path, []byte(corrupted), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := sdk.Sessions(); test.repaired && err == nil {
+				t.Fatalf("corrupt journal loaded")
+			}
+
+			repair, err := sdk.RepairStateFile(sessionJournalFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if repair.Repaired != test.repaired {
+				t.Fatalf("repaired %v, expected %v: %s", repair.Repaired, test.repaired, repair.Error)
+			}
+			if test.repaired {
+				if _, err := os.Stat(repair.QuarantinedAs); err != nil {
+					t.Errorf("corrupt journal wasn't kept: %v", err)
+				}
+			}
+
+			sessions, err := sdk.Sessions()
+			if err != nil {
+				t.Fatalf("repaired journal doesn't load: %v", err)
+			}
+			if len(sessions) != test.sessions {
+				t.Errorf("%v sessions in the repaired journal, expected %v", len(sessions), test.sessions)
+			}
+		})
+	}
+}
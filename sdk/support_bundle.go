@@ -0,0 +1,211 @@
+package viderasdk
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// supportFileMaxSize Bytes of a log or state file included in support bundles at most, the end of larger files is kept
+const supportFileMaxSize = 4 << 20
+
+// supportStateFiles Files of the state directory included in support bundles, credentials and chunk samples are left out
+var supportStateFiles = []string{sessionJournalFile, uploadCacheFile, clusterLimitsFile, nodeCapabilitiesFile, auditLogFile}
+
+// supportRedactions Patterns of secrets replaced in every file of support bundles, the first group of each is kept
+var supportRedactions = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]+`),
+	regexp.MustCompile(`(?i)((?:token|secret|password|signature|sig|api_?key|access_?key)["']?\s*[:=]\s*["']?)[^\s"'&,}]+`),
+	regexp.MustCompile(`(://)[^/@\s]+@`),
+}
+
+// SupportManifest Describes the contents of a support bundle
+type SupportManifest struct {
+	CreatedAt time.Time `json:"created_at"` //Time at which the bundle was created
+	Files     []string  `json:"files"`      //Files of the bundle
+	Notes     []string  `json:"notes"`      //What couldn't be collected and why
+}
+
+// supportVersion Describes the build of the SDK and the host it runs on
+type supportVersion struct {
+	Module          string          `json:"module"`           //Version of the main module, (devel) for local builds
+	Revision        string          `json:"revision"`         //VCS revision the binary was built from, empty when unknown
+	GoVersion       string          `json:"go_version"`       //Go release the binary was built with
+	Platform        string          `json:"platform"`         //OS and architecture of the host
+	ProtocolVersion int             `json:"protocol_version"` //Version of the data node protocol the SDK speaks
+	Features        []utils.Feature `json:"features"`         //Optional integrations and whether they are available
+}
+
+// supportProbe Describes the results of probing the cluster for a support bundle, failures are recorded as errors
+type supportProbe struct {
+	Master          string                      `json:"master"`                      //Upload endpoint of the master
+	MasterLatency   string                      `json:"master_latency,omitempty"`    //Time the master took to answer
+	MasterError     string                      `json:"master_error,omitempty"`      //Why the master couldn't be reached
+	DataNode        string                      `json:"data_node,omitempty"`         //Data node the master routes uploads to
+	DataNodeLatency string                      `json:"data_node_latency,omitempty"` //Time the data node took to answer
+	DataNodeError   string                      `json:"data_node_error,omitempty"`   //Why no data node could be reached
+	Capabilities    []string                    `json:"capabilities"`                //Capabilities granted to the credentials in use, nil when not served
+	ServerVersions  map[string]string           `json:"server_versions"`             //Versions the master and data nodes reported, keyed by host
+	Connections     []utils.HostConnectionStats `json:"connections"`                 //Statistics of the connections the probes opened
+}
+
+// SupportBundle is a function responsible for writing a gzipped tar archive describing the SDK and its environment for bug reports
+// it holds version info, the effective config, results of probing the cluster, the end of the log file and the local state files,
+// secrets are redacted from every file and credentials are left out
+func (sdk VideraSDK) SupportBundle(bundlePath string) (SupportManifest, error) {
+	manifest := SupportManifest{CreatedAt: time.Now().UTC()}
+	bundleFile, err := os.Create(bundlePath)
+	if err != nil {
+		return manifest, err
+	}
+	defer bundleFile.Close()
+	compressor := gzip.NewWriter(bundleFile)
+	writer := tar.NewWriter(compressor)
+
+	entries := []struct {
+		name    string
+		collect func() ([]byte, error)
+	}{
+		{"version.json", func() ([]byte, error) { return json.MarshalIndent(supportVersionInfo(), "", "  ") }},
+		{"config.json", func() ([]byte, error) { return json.MarshalIndent(EffectiveConfig(), "", "  ") }},
+		{"probe.json", func() ([]byte, error) { return json.MarshalIndent(sdk.supportProbe(), "", "  ") }},
+	}
+	for _, entry := range entries {
+		content, err := entry.collect()
+		if err == nil {
+			err = writeSupportEntry(writer, entry.name, content)
+		}
+		if err != nil {
+			return manifest, err
+		}
+		manifest.Files = append(manifest.Files, entry.name)
+	}
+
+	var files [][2]string
+	if sdkConfig.LogFile != "" {
+		files = append(files, [2]string{"logs/" + filepath.Base(sdkConfig.LogFile), os.ExpandEnv(sdkConfig.LogFile)})
+	} else {
+		manifest.Notes = append(manifest.Notes, "No log_file is set, logs went to stderr")
+	}
+	for _, name := range supportStateFiles {
+		files = append(files, [2]string{"state/" + name, filepath.Join(sdk.stateDir, name)})
+	}
+	for _, file := range files {
+		name := file[0]
+		content, err := readFileTail(file[1], supportFileMaxSize)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err == nil {
+			err = writeSupportEntry(writer, name, content)
+		}
+		if err != nil {
+			manifest.Notes = append(manifest.Notes, fmt.Sprintf("%s not collected: %v", name, err))
+			continue
+		}
+		manifest.Files = append(manifest.Files, name)
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err == nil {
+		err = writeSupportEntry(writer, "manifest.json", content)
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err == nil {
+		err = compressor.Close()
+	}
+	if err != nil {
+		return manifest, err
+	}
+	manifest.Files = append(manifest.Files, "manifest.json")
+	return manifest, bundleFile.Close()
+}
+
+// supportVersionInfo is a function to describe the build of the SDK from the build info embedded in the binary
+func supportVersionInfo() supportVersion {
+	version := supportVersion{GoVersion: runtime.Version(), Platform: runtime.GOOS + "/" + runtime.GOARCH,
+		ProtocolVersion: protocolVersion, Features: utils.Features()}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		version.Module = info.Main.Path + "@" + info.Main.Version
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				version.Revision = setting.Value
+			}
+		}
+	}
+	return version
+}
+
+// supportProbe is a function responsible for probing the master and the data node it routes uploads to
+func (sdk VideraSDK) supportProbe() supportProbe {
+	sdk = sdk.withRoute()
+	probe := supportProbe{Master: sdk.master(), ServerVersions: map[string]string{}}
+	if latency, err := probeLatency(sdk.master()); err != nil {
+		probe.MasterError = err.Error()
+	} else {
+		probe.MasterLatency = latency.String()
+	}
+	probe.Capabilities, _ = sdk.FetchCapabilities()
+
+	if err := sdk.updateUploadURL(); err != nil {
+		probe.DataNodeError = err.Error()
+	} else if latency, err := probeLatency(sdk.uploadURL()); err != nil {
+		probe.DataNode, probe.DataNodeError = sdk.uploadURL(), err.Error()
+	} else {
+		probe.DataNode, probe.DataNodeLatency = sdk.uploadURL(), latency.String()
+	}
+
+	serverVersions.Range(func(host, version interface{}) bool {
+		probe.ServerVersions[host.(string)] = version.(string)
+		return true
+	})
+	probe.Connections = utils.ConnectionStats()
+	return probe
+}
+
+// writeSupportEntry is a function responsible for adding a file to a support bundle with its secrets redacted
+func writeSupportEntry(writer *tar.Writer, name string, content []byte) error {
+	for _, pattern := range supportRedactions {
+		content = pattern.ReplaceAll(content, []byte("${1}********"))
+	}
+
+	err := writer.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), ModTime: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(content)
+	return err
+}
+
+// readFileTail is a function to read the last max bytes of a file
+func readFileTail(path string, max int64) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > max {
+		if _, err = file.Seek(info.Size()-max, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	return ioutil.ReadAll(file)
+}
@@ -0,0 +1,90 @@
+package viderasdk
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// recordSessionOrder is a function responsible for remembering whether the data node accepted a tail first order for a session
+// video init requests offer Upload-Order: tail-first when TailFirst is set, data nodes assembling files from chunks at any offset
+// answer Upload-Order: tail-first and others ignore the offer, so the video is uploaded in order
+func (sdk VideraSDK) recordSessionOrder(id string, res *http.Response) {
+	if res.Header.Get("Upload-Order") == "tail-first" {
+		sdk.updateSession(id, func(state *sessionState) { state.tailFirst = true })
+	}
+}
+
+// tailStart is a function that returns the offset the tail of a tail first session starts at, aligned to the chunk size
+// so the chunk before it ends exactly where the tail starts, ok is false when the upload is sent in order
+func (sdk VideraSDK) tailStart(id string, filesSizes []int64) (int64, bool) {
+	if !sdk.loadSession(id).tailFirst || len(filesSizes) != 1 || sdk.artifact != "" || sdk.options.TailFirst <= 0 {
+		return 0, false
+	}
+
+	start := (filesSizes[0] - sdk.options.TailFirst) / sdk.chunkSize * sdk.chunkSize
+	return start, start > 0
+}
+
+// uploadTail is a function responsible for sending the chunks of a tail first session from start to the end of the source
+// before uploadFiles sends the rest in order, so reviewers can preview the end of a video, e.g. the last minutes of an incident, first
+// the data node completes the session with the chunk filling the last gap, i.e. the one ending at start
+// a rejected chunk stops the tail, the video is then uploaded in order from the start. Returns the payload bytes sent
+// and whether the data node completed the session
+func (sdk VideraSDK) uploadTail(client *http.Client, id string, source Source, start int64, buffer []byte, progress *uploadProgress,
+	tags map[string]string, expectedDigests map[string]string) (int64, bool, error) {
+	reader, err := sdk.openSource(source, start, tags)
+	if err != nil {
+		return 0, false, err
+	}
+	defer reader.Close()
+
+	var codec Codec
+	if sessionCodec := sdk.sessionCodec(id); sessionCodec != nil && sourceCompressible(source, sessionCodec) {
+		codec = sessionCodec
+	}
+	log.Println(fmt.Sprintf("Uploading video %s from offset %v first", source.Name(), start))
+	bytesSent := int64(0)
+	for offset := start; ; {
+		bytesread, err := io.ReadFull(reader, buffer)
+		if err == io.EOF {
+			return bytesSent, false, nil
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return bytesSent, false, err
+		}
+		if sdk.deadlineExceeded() {
+			return bytesSent, false, ErrDeadlineExceeded
+		}
+
+		req := newChunkRequest(sdk.uploadURL(), id, offset, buffer[:bytesread], nil, codec)
+		sdk.setChunkChecksum(req, id, buffer[:bytesread])
+		response, err := sdk.sendChunk(client, id, req, offset, int64(bytesread), tags)
+		if err != nil {
+			return bytesSent, false, err
+		}
+		bytesSent += response.sent
+		if response.outcome != chunkAccepted && response.outcome != chunkCompleted {
+			log.Println(fmt.Sprintf("Tail chunk at offset %v rejected with %s, uploading video in order", offset, response.res.Status))
+			return bytesSent, false, nil
+		}
+		offset += int64(bytesread)
+		progress.ahead += int64(bytesread)
+		progress.update(0)
+		if response.outcome == chunkCompleted {
+			sdk.completeSession(id, sdk.uploadURL())
+			return bytesSent, true, verifyDigestsEcho(response.res, expectedDigests)
+		}
+	}
+}
+
+// tailFirstHeaders is a function that returns the headers offering a tail first order in the init request of a video
+// re-initialized sessions continue in order as the data node of the previous one kept only its leading bytes
+func (sdk VideraSDK) tailFirstHeaders(videoSize int64, extraHeaders map[string]string) map[string]string {
+	if sdk.options.TailFirst <= 0 || sdk.options.TailFirst >= videoSize || extraHeaders["Resume-From"] != "" {
+		return nil
+	}
+	return map[string]string{"Upload-Order": "tail-first", "Tail-Size": strconv.FormatInt(sdk.options.TailFirst, 10)}
+}
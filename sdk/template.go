@@ -0,0 +1,53 @@
+package viderasdk
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/config"
+)
+
+// UseTemplate is a function to apply the options of an upload template of the config to the SDK, see templates
+// the project of the template is applied first, options set afterwards, e.g. from CLI flags, take precedence over both,
+// the template is returned for its artifact layout
+func (sdk *VideraSDK) UseTemplate(name string) (config.TemplateConfig, error) {
+	template, ok := sdkConfig.Templates[name]
+	if !ok {
+		return template, fmt.Errorf("Unknown template %s, expected one of the templates of the config", name)
+	}
+
+	if template.Project != "" {
+		if err := sdk.UseProject(template.Project); err != nil {
+			return template, fmt.Errorf("Template %s: %v", name, err)
+		}
+	}
+	if template.ChunkSize > 0 {
+		sdk.chunkSize = int64(template.ChunkSize)
+	}
+	if len(template.Tags) > 0 {
+		tags := make(map[string]string, len(sdk.options.Tags)+len(template.Tags))
+		for key, val := range sdk.options.Tags {
+			tags[key] = val
+		}
+		for key, val := range template.Tags {
+			tags[key] = val
+		}
+		sdk.options.Tags = tags
+	}
+	if len(template.Metadata) > 0 {
+		sdk.options.Metadata = template.Metadata
+	}
+	if template.Priority != "" {
+		sdk.options.Priority = template.Priority
+	}
+	if len(template.Compression) > 0 {
+		sdk.options.Compression = template.Compression
+	}
+	if template.SegmentDuration > 0 {
+		sdk.options.SegmentDuration = time.Duration(template.SegmentDuration)
+	}
+	sdk.options.RequireTLS = sdk.options.RequireTLS || template.RequireTLS
+	sdk.options.StripAudio = sdk.options.StripAudio || template.StripAudio
+	sdk.options.ScrubMetadata = sdk.options.ScrubMetadata || template.ScrubMetadata
+	return template, nil
+}
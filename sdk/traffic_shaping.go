@@ -0,0 +1,188 @@
+package viderasdk
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/config"
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// shapingInterval Time between evaluations of the traffic shaping rules while uploading
+const shapingInterval = 10 * time.Second
+
+// shapingDays Days of the week shaping rules may name, by their three letter abbreviation
+var shapingDays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// shapingRule Describes a traffic shaping rule with its days and hours parsed
+type shapingRule struct {
+	config.ShapingRule
+	days     map[time.Weekday]bool //Days the rule applies on, nil for every day
+	from, to time.Duration         //Window since midnight the rule applies in, from == to for all day
+}
+
+// trafficShaper Evaluates the traffic shaping rules against the network uploads go through and the time of day
+type trafficShaper struct {
+	rules       []shapingRule //Rules in order of precedence
+	host        string        //Host of the master, its route picks the interface when connections aren't bound
+	mutex       sync.Mutex    //Guards the fields below
+	evaluatedAt time.Time     //Time the rules were last evaluated
+	rate        int64         //Bytes per second of the matching rule, 0 when unlimited
+	matched     int           //Index of the matching rule, -1 when none matches
+}
+
+// newTrafficShaper is a function to parse traffic shaping rules, returns nil when there are none
+func newTrafficShaper(rules config.Shaping, masterURL string) (*trafficShaper, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	shaper := &trafficShaper{matched: -1}
+	if parsed, err := url.Parse(masterURL); err == nil {
+		shaper.host = parsed.Host
+	}
+	for idx, rule := range rules {
+		parsed := shapingRule{ShapingRule: rule}
+		switch rule.Network {
+		case "", utils.NetworkEthernet, utils.NetworkWifi, utils.NetworkCellular, utils.NetworkLoopback:
+		default:
+			return nil, fmt.Errorf("Traffic shaping rule %v has unknown network %s, expected ethernet, wifi, cellular or loopback", idx+1, rule.Network)
+		}
+		for _, day := range rule.Days {
+			weekday, ok := shapingDays[strings.ToLower(day)]
+			if !ok {
+				return nil, fmt.Errorf("Traffic shaping rule %v has unknown day %s, expected mon through sun", idx+1, day)
+			}
+			if parsed.days == nil {
+				parsed.days = make(map[time.Weekday]bool)
+			}
+			parsed.days[weekday] = true
+		}
+		if rule.Hours != "" {
+			var err error
+			parsed.from, parsed.to, err = parseHours(rule.Hours)
+			if err != nil {
+				return nil, fmt.Errorf("Traffic shaping rule %v: %v", idx+1, err)
+			}
+		}
+		shaper.rules = append(shaper.rules, parsed)
+	}
+
+	return shaper, nil
+}
+
+// parseHours is a function to parse a window of local time such as 08:00-20:00 into its bounds since midnight
+func parseHours(hours string) (time.Duration, time.Duration, error) {
+	bounds := strings.Split(hours, "-")
+	if len(bounds) != 2 {
+		return 0, 0, fmt.Errorf("invalid hours %q, expected a window such as 08:00-20:00", hours)
+	}
+
+	var parsed [2]time.Duration
+	for idx, bound := range bounds {
+		clock, err := time.Parse("15:04", strings.TrimSpace(bound))
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid hours %q, expected a window such as 08:00-20:00", hours)
+		}
+		parsed[idx] = time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute
+	}
+	return parsed[0], parsed[1], nil
+}
+
+// currentRate is a function that returns the bytes per second the matching traffic shaping rule limits uploads to, 0 when unlimited
+// the rules are evaluated at most every shapingInterval, changes of the limit are logged
+func (shaper *trafficShaper) currentRate() int64 {
+	if shaper == nil {
+		return 0
+	}
+	shaper.mutex.Lock()
+	defer shaper.mutex.Unlock()
+
+	if time.Since(shaper.evaluatedAt) < shapingInterval {
+		return shaper.rate
+	}
+	shaper.evaluatedAt = time.Now()
+
+	name, err := utils.RouteInterface(shaper.host)
+	if err != nil {
+		utils.Debugln(fmt.Sprintf("Unable to find the interface uploads go through: %v", err))
+	}
+	network := ""
+	if name != "" {
+		network = utils.NetworkType(name)
+	}
+
+	matched, rate := -1, int64(0)
+	for idx, rule := range shaper.rules {
+		if rule.matches(name, network, shaper.evaluatedAt) {
+			matched, rate = idx, int64(rule.RateLimit)
+			break
+		}
+	}
+	if matched != shaper.matched || rate != shaper.rate {
+		limit := "unlimited"
+		if rate > 0 {
+			limit = (*utils.Rate)(&rate).String()
+		}
+		if matched < 0 {
+			log.Println(fmt.Sprintf("No traffic shaping rule matches %s network on %s, uploads are %s", network, name, limit))
+		} else {
+			log.Println(fmt.Sprintf("Traffic shaping rule %v matches %s network on %s, uploads are %s", matched+1, network, name, limit))
+		}
+	}
+	shaper.matched, shaper.rate = matched, rate
+	return rate
+}
+
+// matches is a function to check whether the rule applies to uploads through an interface of a network type at a time
+func (rule shapingRule) matches(name string, network string, now time.Time) bool {
+	if rule.Network != "" && rule.Network != network {
+		return false
+	}
+	if len(rule.Interfaces) > 0 && !matchesInterface(rule.Interfaces, name) {
+		return false
+	}
+	if rule.days != nil && !rule.days[now.Weekday()] {
+		return false
+	}
+	if rule.Hours == "" || rule.from == rule.to {
+		return true
+	}
+
+	sinceMidnight := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute
+	if rule.from < rule.to {
+		return sinceMidnight >= rule.from && sinceMidnight < rule.to
+	}
+	// the window wraps past midnight, e.g. 22:00-06:00
+	return sinceMidnight >= rule.from || sinceMidnight < rule.to
+}
+
+// matchesInterface is a function to check whether an interface name matches one of the given names, a trailing * matches any suffix
+func matchesInterface(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if prefix := strings.TrimSuffix(pattern, "*"); prefix != pattern && strings.HasPrefix(name, prefix) {
+			return true
+		}
+		if pattern == name {
+			return true
+		}
+	}
+	return false
+}
+
+// uploadRate is a function that returns the bytes per second files are read for upload at most, 0 when unlimited
+// the lower of RateLimit and the limit of the matching traffic shaping rule applies
+func (sdk VideraSDK) uploadRate() int64 {
+	rate, shaped := sdk.options.RateLimit, sdk.shaper.currentRate()
+	if rate == 0 || (shaped > 0 && shaped < rate) {
+		rate = shaped
+	}
+	return rate
+}
@@ -1,9 +1,200 @@
 package viderasdk
 
+import (
+	"io/fs"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/metrics"
+)
+
 // VideraSDK Handles communication between clients and videra system
 type VideraSDK struct {
-	masterURL          string //IP of master
-	chunkSize          int64  //Upload chunk size
-	defaultMaxRetries  int    //Max number of request retrials
-	defaultWaitingTime int    //waiting time between failed request and new one
+	masterURL             string              //IP of master
+	masters               []string            //Upload endpoints of every configured master, name_node_endpoint first, probed for the leader when more than one
+	discoveryTimeout      time.Duration       //Time each master is given to answer a discovery probe
+	probeNodeCapabilities bool                //Probe data nodes for their limits and features before init, cached per node
+	chunkSize             int64               //Upload chunk size
+	defaultMaxRetries     int                 //Max number of request retrials
+	defaultWaitingTime    int                 //waiting time between failed request and new one
+	dedupChunks           bool                //Skip transmitting chunks already stored by the data node
+	sparseUpload          bool                //Send holes of sparse files as zero fill requests
+	readAhead             int64               //Bytes of a source read ahead of the upload in the background, 0 disables it
+	dropPageCache         bool                //Drop the pages of local files from the page cache once uploaded
+	fileSystem            fs.FS               //Filesystem local upload paths are read from
+	stateDir              string              //Directory holding local state such as the upload cache
+	stagingDir            string              //Directory local files are staged in under source isolation, empty stages them in stateDir
+	auditLog              bool                //Append completed uploads to the audit log of the state directory
+	catalog               bool                //Append every completed upload to the local catalog in the state directory
+	usageAccounting       bool                //Append completed uploads to the usage ledger of the state directory
+	progressInterval      int                 //Seconds between progress summaries
+	progressPercent       int                 //Percentage between progress summaries
+	relays                []string            //Relays data node traffic may be routed through
+	filenameLength        int                 //Bytes after which sanitized file names are shortened, 0 keeps any length
+	artifact              string              //Artifact of a parallel session uploadFiles is uploading, empty for single stream uploads
+	deadline              time.Time           //Time by which the running upload must complete, zero when unbounded
+	reinit                sessionReinit       //Re-runs init of the running upload when the data node expires its session, nil when it can't be
+	sessionReinits        int                 //Times a single upload re-initializes expired sessions at most
+	chunkStats            *chunkStats         //Records the latency and throughput of chunks of the running upload, nil when not recorded
+	route                 *uploadRoute        //Data node and max file size of the running call, nil until the call is routed, see withRoute
+	expiryMargin          time.Duration       //Time before the announced expiry of a session at which it is extended or replaced
+	credentials           *profileCredentials //Credentials of the active profile, nil when no profile is used
+	project               string              //Project whose defaults were applied, empty when none was
+	uploadSlots           uploadSlots         //Upload attempts running at once, nil when unbounded
+	chunkSlots            *chunkScheduler     //Chunk requests in flight at once shared fairly between uploads, nil when unbounded
+	priorityWeights       map[string]float64  //Weight the chunks of uploads are scheduled with by priority
+	downloadRanges        int                 //Ranges of an artifact model pulls download at once
+	watchdogRate          int64               //Bytes per second below which the throughput watchdog remediates, 0 disables it
+	watchdogWindow        time.Duration       //Time over which the throughput watchdog measures throughput
+	jobProposal           bool                //Suffix proposed IDs by filetype, set while uploading a job
+	serverVersionPin      string              //Server version whose quirks requests adapt to, empty probes it from Server-Version headers
+	watchdogAction        string              //Remediation of the throughput watchdog, alert, renegotiate or failover
+	shaper                *trafficShaper      //Rate limits of the traffic shaping rules, nil when none are set
+	options               ClientOptions
+}
+
+// Credentials Describes how to reach and authenticate against a cluster
+type Credentials struct {
+	Endpoint string `json:"endpoint"` //Upload endpoint of the cluster master, empty keeps the configured one
+	Token    string `json:"token"`    //Bearer token attached to every request
+
+	RefreshToken string    `json:"refresh_token,omitempty"` //OAuth2 refresh token used to renew the bearer token
+	ExpiresAt    time.Time `json:"expires_at,omitempty"`    //Time at which the bearer token expires, zero if it doesn't
+	TokenURL     string    `json:"token_url,omitempty"`     //OAuth2 token endpoint tokens are refreshed at
+	ClientID     string    `json:"client_id,omitempty"`     //OAuth2 client the tokens were issued to
+
+	Capabilities []string `json:"capabilities,omitempty"` //Capabilities the master granted the credentials, nil when unknown
+}
+
+// ClientOptions Optional settings and callbacks host applications can set on the SDK
+type ClientOptions struct {
+	SkipUploaded      bool                                                  //Reuse the ID of files found unchanged in the upload cache instead of uploading them
+	OnRetry           func(attempt int, err error, nextDelay time.Duration) //Called before a failed request or upload attempt is retried
+	OnNodeFailover    func(oldNode string, newNode string)                  //Called when the master routes an attempt to a different data node
+	OnWarning         func(warning Warning)                                 //Called with every non-fatal anomaly such as offset corrections, chunk size changes and failovers
+	OnProgress        func(name string, done int64, total int64)            //Called as chunks of an upload or download complete with the bytes done so far, name is the first artifact, e.g. video or model
+	Signer            RequestSigner                                         //Signs every request, e.g. an HMACSigner for gateways in front of data nodes, nil sends them unsigned
+	Metrics           metrics.Emitter                                       //Receives upload counters and timings, nil disables metrics
+	Hooks             []Hook                                                //Run in order before and after every upload
+	SegmentDuration   time.Duration                                         //Split job videos into segments of about this duration uploaded as a linked series, 0 disables segmenting
+	Trim              *TimeRange                                            //Upload only this clip of videos, nil uploads them whole
+	StripAudio        bool                                                  //Drop every audio track of videos before upload
+	AudioTrack        int                                                   //Keep only this audio track of videos, counted from 1, 0 keeps every track
+	ScrubMetadata     bool                                                  //Remove container and stream metadata such as GPS location and device identifiers from videos before upload
+	KeepMetadata      []string                                              //Metadata tags kept when scrubbing, e.g. title or creation_time
+	HookFailurePolicy string                                                //abort (default) fails the upload when a hook fails, warn only logs it
+	Metadata          map[string]interface{}                                //Metadata sent with every upload, values may be nested objects and lists of any language, flattened for data nodes predating the JSON init body
+	Tags              map[string]string                                     //Tags sent with every upload
+	Priority          string                                                //Priority the cluster is asked to process uploads with, sent in the Priority header of init requests, empty leaves it to the cluster
+	RequireTLS        bool                                                  //Refuse to talk to master or data nodes over plain http
+	RateLimit         int64                                                 //Max bytes per second files are read for upload, 0 leaves it unlimited
+	Retention         time.Duration                                         //How long the cluster is asked to keep uploads, 0 leaves it to the cluster
+	FilenameCollision string                                                //rename-suffix, overwrite or fail when the data node already stores a file of the same name, empty leaves it to the data node
+	InitRetry         *RetryPolicy                                          //Retries of init requests, nil retries them once as they may create a session
+	AppendRetry       *RetryPolicy                                          //Retries of chunk requests, nil retries them twice max_retries times as they are idempotent
+	Compression       []string                                              //Codecs offered to data nodes in preference order, e.g. zstd, gzip or lz4, only compressible files are compressed
+	Checksums         []string                                              //Chunk checksum algorithms offered to data nodes in preference order, crc32c, xxh64 or sha256, empty offers all from the fastest on the CPU, none offers none
+	MaxBufferedChunks int                                                   //Chunks read ahead of the network at most, reading pauses while they wait to be sent, 0 reads each chunk when it is sent
+	VideoPrecheck     string                                                //warn or refuse to parse the container and decode a sample of frames of videos before init, warning about or refusing unreadable ones, empty skips the pre-check
+	StrictFiletype    bool                                                  //Fail uploads of videos that don't look like a video container instead of warning
+	AllowChanged      bool                                                  //Restart uploads of files modified while uploaded instead of failing them with ErrSourceChanged
+	TailFirst         int64                                                 //Bytes at the end of videos uploaded before the rest for quick previews, e.g. the last minutes of an incident, when the data node assembles chunks in any order, 0 uploads them in order
+	Weight            float64                                               //Share of max_concurrent_chunks the uploads get relative to concurrent ones, 0 uses the weight of their Priority in priority_weights of the config or 1
+	SampleChunks      float64                                               //Fraction of chunks whose hash, headers and response are recorded in a bundle of the state directory for debugging corrupt uploads, 0 records none
+	SampleChunkData   bool                                                  //Also store raw copies of sampled chunks in the bundle
+	ProposedID        string                                                //ID proposed to clusters letting clients pick upload IDs for easier tracing, e.g. a UUIDv7, the ID the data node assigns is authoritative
+	IDNamespace       string                                                //Namespace the proposed ID is prefixed with as namespace/id
+	MetricsFile       string                                                //Path or URL of a JSON object of evaluation metrics, e.g. accuracy and latency benchmarks, attached to uploaded models
+	ModelCard         string                                                //Path or URL of a markdown document describing uploaded models, e.g. MODEL_CARD.md, attached to them and read back with ModelCard
+	ModelFramework    string                                                //Framework of uploaded models whose config schema the master serves, e.g. pytorch or onnx, empty guesses it from the model extension
+	SkipValidation    bool                                                  //Upload model configs without validating them against the schema of their framework
+	VersionOf         string                                                //ID of a stored model uploaded models are a new version of, listed together by ModelVersions
+	SourceIsolation   string                                                //hardlink or reflink to stage local files before upload so producers rotating or rewriting them don't affect it, empty uploads them in place
+	Deadline          time.Duration                                         //Bound on a whole upload including retries and failovers, 0 disables it
+	FileTimeout       time.Duration                                         //Bound on the transfer of each file or set of model files, 0 disables it
+	Routing           string                                                //master asks the master for the data node of every attempt, content computes it from the checksum of the content and the shard map of the master
+	ContentRouter     ContentRouter                                         //Computes data nodes under content routing, nil uses the HashRouter
+}
+
+// RetryPolicy Describes how a class of requests is retried
+type RetryPolicy struct {
+	MaxRetries  int //Max number of request retrials
+	WaitingTime int //Seconds waited between a failed request and the next one
+}
+
+// TimeRange A range of a video such as the clip kept by a trim
+type TimeRange struct {
+	Start time.Duration //Offset of the first kept frame
+	End   time.Duration //Offset at which the clip ends
+}
+
+// CacheEntry Describes a completed upload remembered in the local upload cache
+type CacheEntry struct {
+	ID                string       `json:"id"`                            //ID assigned to the upload by the data node
+	Filetype          string       `json:"filetype"`                      //Type of the upload, model or video
+	Files             []CachedFile `json:"files"`                         //Fingerprints of the uploaded files in upload order
+	Checksum          string       `json:"checksum"`                      //Hex encoded sha256 digest of the uploaded content
+	AssociatedModelID string       `json:"associated_model_id,omitempty"` //Model a video was uploaded for
+	UploadedAt        time.Time    `json:"uploaded_at"`                   //Time at which the upload completed
+}
+
+// CachedFile Fingerprint of a local file used to detect whether it changed since it was uploaded
+type CachedFile struct {
+	Path    string    `json:"path"`     //Absolute path of the file
+	Size    int64     `json:"size"`     //Size of the file in bytes
+	ModTime time.Time `json:"mod_time"` //Last modification time of the file
+}
+
+// bundleManifest Describes the contents of an upload bundle
+type bundleManifest struct {
+	Version   int              `json:"version"`    //Bundle layout version
+	CreatedAt time.Time        `json:"created_at"` //Time at which the bundle was created
+	Artifacts []bundleArtifact `json:"artifacts"`  //Artifacts packaged in the bundle
+}
+
+// bundleArtifact Describes a single artifact stored in an upload bundle
+type bundleArtifact struct {
+	Name     string `json:"name"`     //Artifact name (video, model, config or code)
+	Path     string `json:"path"`     //Path of the artifact entry inside the bundle
+	Size     int64  `json:"size"`     //Size of the artifact in bytes
+	Checksum string `json:"checksum"` //Hex encoded sha256 digest of the artifact
+}
+
+// UploadResult Describes a completed upload
+type UploadResult struct {
+	ID        string        `json:"id"`          //ID assigned to the upload by the data node, authoritative even when another was proposed
+	BytesSent int64         `json:"bytes_sent"`  //Payload bytes transmitted, including failed attempts
+	Duration  time.Duration `json:"duration_ns"` //Time from the first attempt until completion
+	DataNode  string        `json:"data_node"`   //Upload URL of the data node that accepted the upload
+	Checksum  string        `json:"checksum"`    //Hex encoded sha256 digest of the uploaded content
+	Retries   int           `json:"retries"`     //Number of failed attempts before the successful one
+
+	ScrubbedMetadata []string `json:"scrubbed_metadata,omitempty"` //Metadata tags removed from the video before upload
+	ProposedID       string   `json:"proposed_id,omitempty"`       //ID proposed on init, see ClientOptions.ProposedID
+
+	ChunkLatency    *Distribution `json:"chunk_latency_ms,omitempty"`     //Milliseconds from sending each chunk to its response, including failed attempts
+	ChunkThroughput *Distribution `json:"chunk_throughput_bps,omitempty"` //Bytes per second each chunk was sent at
+}
+
+// JobResult Describes a completed job upload
+type JobResult struct {
+	Model    UploadResult   `json:"model"`              //Result of the model upload
+	Video    UploadResult   `json:"video"`              //Result of the video upload, its ID is the parent ID of the series when the video was segmented
+	Segments []UploadResult `json:"segments,omitempty"` //Result of each segment in playback order when the video was segmented
+}
+
+// IncidentFiles Describes the files of an incident uploaded together by UploadIncident
+type IncidentFiles struct {
+	ID         string //ID of the incident linking the uploads, empty generates a UUIDv7
+	Video      string //Path or URL of the video of the incident
+	Detections string //Path or URL of the JSON output of the model for the video, empty when there is none
+	Logs       string //Path or URL of the logs of the device, empty when there are none
+	ModelID    string //ID of the model the video is uploaded for, empty when it isn't for one
+}
+
+// IncidentResult Describes a completed incident upload
+type IncidentResult struct {
+	ID         string        `json:"id"`                   //ID of the incident, tagged on each of its uploads as incident
+	Video      UploadResult  `json:"video"`                //Result of the video upload
+	Detections *UploadResult `json:"detections,omitempty"` //Result of the detections upload, nil when there were none
+	Logs       *UploadResult `json:"logs,omitempty"`       //Result of the logs upload, nil when there were none
 }
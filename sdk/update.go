@@ -0,0 +1,50 @@
+package viderasdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// MetadataChanges Describes changes to the metadata of a stored asset, zero values are left unchanged
+type MetadataChanges struct {
+	Name      string            `json:"name,omitempty"`       //New filename of the asset
+	SetTags   map[string]string `json:"set_tags,omitempty"`   //Tags added to the asset or overwritten
+	UnsetTags []string          `json:"unset_tags,omitempty"` //Keys of tags removed from the asset
+}
+
+// UpdateMetadata is a function responsible for renaming or retagging a stored asset without uploading it again
+// returns the asset as updated by the master
+func (sdk VideraSDK) UpdateMetadata(id string, changes MetadataChanges) (Asset, error) {
+	var asset Asset
+	if changes.Name == "" && len(changes.SetTags) == 0 && len(changes.UnsetTags) == 0 {
+		return asset, errors.New("No metadata changes given")
+	}
+
+	body, err := json.Marshal(changes)
+	if err != nil {
+		return asset, err
+	}
+
+	client := sdk.newClient()
+	req, _ := http.NewRequest(http.MethodPatch, sdk.master(), bytes.NewReader(body))
+	req.Header.Set("Request-Type", "METADATA")
+	req.Header.Set("ID", id)
+	req.Header.Set("Content-Type", "application/json")
+	res, err := client.Do(req)
+	if err != nil {
+		return asset, err
+	}
+	defer res.Body.Close()
+
+	if err = permissionError(res); err != nil {
+		return asset, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return asset, fmt.Errorf("Unable to update asset %s: %s", id, res.Status)
+	}
+	err = json.NewDecoder(res.Body).Decode(&asset)
+	return asset, err
+}
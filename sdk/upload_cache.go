@@ -0,0 +1,130 @@
+package viderasdk
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// uploadCacheFile Name of the upload cache file inside the state directory
+const uploadCacheFile = "upload_cache.json"
+
+// uploadCacheMutex Serializes updates of the upload cache, e.g. by concurrent uploads completing
+var uploadCacheMutex sync.Mutex
+
+// UploadCache is a function to list the uploads remembered in the local upload cache
+func (sdk VideraSDK) UploadCache() ([]CacheEntry, error) {
+	var entries []CacheEntry
+	if err := sdk.loadStateFile(uploadCacheFile, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ClearUploadCache is a function to forget every upload remembered in the local upload cache
+func (sdk VideraSDK) ClearUploadCache() error {
+	uploadCacheMutex.Lock()
+	defer uploadCacheMutex.Unlock()
+	err := os.Remove(filepath.Join(sdk.stateDir, uploadCacheFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// saveUploadCache is a function responsible for writing the upload cache, replacing the file atomically
+func (sdk VideraSDK) saveUploadCache(entries []CacheEntry) error {
+	return sdk.saveStateFile(uploadCacheFile, entries)
+}
+
+// lookupUploadCache is a function to find a cached upload of the same files with the same content
+// returns the cached upload ID, or an empty string when the files were never uploaded or changed since
+func (sdk VideraSDK) lookupUploadCache(filetype string, files []CachedFile, checksum string, associatedModelID string) string {
+	if files == nil {
+		return ""
+	}
+
+	entries, err := sdk.UploadCache()
+	if err != nil {
+		return ""
+	}
+
+	for _, entry := range entries {
+		if entry.Filetype == filetype && entry.Checksum == checksum &&
+			entry.AssociatedModelID == associatedModelID && sameCachedFiles(entry.Files, files) {
+			return entry.ID
+		}
+	}
+	return ""
+}
+
+// recordUploadCache is a function responsible for remembering a completed upload in the local upload cache
+// uploads of non local sources have no fingerprint and aren't cached
+func (sdk VideraSDK) recordUploadCache(filetype string, files []CachedFile, result UploadResult, associatedModelID string) error {
+	if files == nil {
+		return nil
+	}
+
+	uploadCacheMutex.Lock()
+	defer uploadCacheMutex.Unlock()
+	entries, err := sdk.UploadCache()
+	if err != nil {
+		return err
+	}
+
+	entries = append(entries, CacheEntry{
+		ID:                result.ID,
+		Filetype:          filetype,
+		Files:             files,
+		Checksum:          result.Checksum,
+		AssociatedModelID: associatedModelID,
+		UploadedAt:        time.Now().UTC(),
+	})
+
+	return sdk.saveUploadCache(entries)
+}
+
+// sourcesFingerprint is a function to get the fingerprints of sources in upload order
+// returns nil if any of the sources isn't a local file
+func sourcesFingerprint(sources map[string]Source, uploadOrder []string) []CachedFile {
+	var files []CachedFile
+	for _, name := range uploadOrder {
+		source, ok := sources[name].(localSource)
+		if !ok {
+			return nil
+		}
+
+		fileInfo, err := fs.Stat(source.fileSystem, source.path)
+		if err != nil {
+			return nil
+		}
+
+		filePath := source.path
+		if _, ok := source.fileSystem.(utils.OSFileSystem); ok {
+			filePath, _ = filepath.Abs(source.path)
+		}
+
+		files = append(files, CachedFile{Path: filePath, Size: fileInfo.Size(), ModTime: fileInfo.ModTime().UTC()})
+	}
+
+	return files
+}
+
+// sameCachedFiles is a function to check whether two lists of fingerprints describe the same files
+func sameCachedFiles(cachedFiles []CachedFile, files []CachedFile) bool {
+	if len(cachedFiles) != len(files) {
+		return false
+	}
+
+	for idx := range files {
+		if cachedFiles[idx].Path != files[idx].Path || cachedFiles[idx].Size != files[idx].Size ||
+			!cachedFiles[idx].ModTime.Equal(files[idx].ModTime) {
+			return false
+		}
+	}
+	return true
+}
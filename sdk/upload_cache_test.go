@@ -0,0 +1,33 @@
+package viderasdk
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentUploadCacheUpdates checks uploads completing concurrently are all remembered in the upload cache
+func TestConcurrentUploadCacheUpdates(t *testing.T) {
+	sdk := VideraSDK{stateDir: t.TempDir()}
+	const uploads = 16
+	var wg sync.WaitGroup
+	for idx := 0; idx < uploads; idx++ {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			files := []CachedFile{{Path: fmt.Sprintf("/videos/%v.mp4", idx)}}
+			if err := sdk.recordUploadCache("video", files, UploadResult{ID: fmt.Sprint(idx)}, ""); err != nil {
+				t.Error(err)
+			}
+		}(idx)
+	}
+	wg.Wait()
+
+	entries, err := sdk.UploadCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != uploads {
+		t.Errorf("%v uploads in the cache, expected %v", len(entries), uploads)
+	}
+}
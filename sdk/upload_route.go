@@ -0,0 +1,59 @@
+package viderasdk
+
+import "sync"
+
+// uploadRoute Describes where the data node requests of one call to the SDK go, shared by the copies of the SDK the call makes
+// so concurrent uploads through the same SDK each keep their own data node, max file size and sessions
+type uploadRoute struct {
+	mutex       sync.Mutex               //Guards the fields below
+	dataNode    string                   //URL of the data node the requests of the call are sent to, empty until routed
+	maxFileSize int64                    //Largest upload accepted by the cluster as advertised by the master or data node, 0 when unknown
+	sessions    map[string]*sessionState //State of the sessions the call initialized keyed by their data node and ID, see updateSession
+}
+
+// withRoute is a function that returns the SDK with a route of its own, calls made by a routed call keep its route
+func (sdk VideraSDK) withRoute() VideraSDK {
+	if sdk.route == nil {
+		sdk.route = &uploadRoute{}
+	}
+	return sdk
+}
+
+// uploadURL is a function that returns the data node the call is routed to, empty before it is
+func (sdk VideraSDK) uploadURL() string {
+	if sdk.route == nil {
+		return ""
+	}
+	sdk.route.mutex.Lock()
+	defer sdk.route.mutex.Unlock()
+	return sdk.route.dataNode
+}
+
+// routeTo is a function responsible for sending the next data node requests of the call to node, returns the data node it replaced
+func (sdk VideraSDK) routeTo(node string) string {
+	sdk.route.mutex.Lock()
+	defer sdk.route.mutex.Unlock()
+	previous := sdk.route.dataNode
+	sdk.route.dataNode = node
+	return previous
+}
+
+// maxFileSize is a function that returns the largest upload the cluster accepts as far as the call knows, 0 when unknown
+func (sdk VideraSDK) maxFileSize() int64 {
+	if sdk.route == nil {
+		return 0
+	}
+	sdk.route.mutex.Lock()
+	defer sdk.route.mutex.Unlock()
+	return sdk.route.maxFileSize
+}
+
+// setMaxFileSize is a function responsible for recording the max file size advertised to the call, lowering only keeps
+// it when it is smaller than the one already known
+func (sdk VideraSDK) setMaxFileSize(size int64, lowering bool) {
+	sdk.route.mutex.Lock()
+	defer sdk.route.mutex.Unlock()
+	if !lowering || sdk.route.maxFileSize == 0 || size < sdk.route.maxFileSize {
+		sdk.route.maxFileSize = size
+	}
+}
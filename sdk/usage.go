@@ -0,0 +1,182 @@
+package viderasdk
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// usageLedgerFile Name of the usage ledger inside the state directory, one JSON record per line
+const usageLedgerFile = "usage.jsonl"
+
+// usageMutex Serializes appends to the usage ledger
+var usageMutex sync.Mutex
+
+// UsageRecord Describes the accounting of a completed upload in the usage ledger, jobs record their model and video separately
+type UsageRecord struct {
+	Time      time.Time         `json:"time"`              //Time at which the upload completed
+	Filetype  string            `json:"filetype"`          //Type of the upload, model or video
+	ID        string            `json:"id"`                //ID assigned to the upload by the data node
+	Project   string            `json:"project,omitempty"` //Project whose defaults applied to the upload
+	Profile   string            `json:"profile,omitempty"` //Profile whose credentials authenticated the upload
+	Tags      map[string]string `json:"tags,omitempty"`    //Tags sent with the upload
+	BytesSent int64             `json:"bytes_sent"`        //Payload bytes transmitted, including failed attempts
+	Duration  time.Duration     `json:"duration_ns"`       //Time from the first attempt until completion
+}
+
+// UsageRollup Describes the uploads of a group of the usage ledger
+type UsageRollup struct {
+	Group     map[string]string `json:"group"`       //Value of each grouping of the group, e.g. tag:camera, empty when ungrouped
+	Uploads   int               `json:"uploads"`     //Uploads of the group
+	BytesSent int64             `json:"bytes_sent"`  //Payload bytes the uploads transmitted
+	Duration  time.Duration     `json:"duration_ns"` //Time the uploads took together
+	First     time.Time         `json:"first"`       //Time the first upload of the group completed
+	Last      time.Time         `json:"last"`        //Time the last upload of the group completed
+}
+
+// accountUpload is a function responsible for appending completed uploads to the usage ledger when usage_accounting is set
+// results reused from the upload cache took no time and aren't accounted, failures are only logged as the upload already completed
+func (sdk VideraSDK) accountUpload(results map[string]UploadResult) {
+	if !sdk.usageAccounting {
+		return
+	}
+
+	var lines []byte
+	for _, filetype := range []string{"model", "video"} {
+		result, ok := results[filetype]
+		if !ok || result.Duration == 0 {
+			continue
+		}
+		record := UsageRecord{Time: time.Now().UTC(), Filetype: filetype, ID: result.ID, Project: sdk.project,
+			Tags: sdk.options.Tags, BytesSent: result.BytesSent, Duration: result.Duration}
+		if sdk.credentials != nil {
+			record.Profile = sdk.credentials.profile
+		}
+		line, err := json.Marshal(record)
+		if err != nil {
+			log.Println("Unable to record upload in usage ledger:", err)
+			return
+		}
+		lines = append(append(lines, line...), '\n')
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	usageMutex.Lock()
+	defer usageMutex.Unlock()
+	err := os.MkdirAll(sdk.stateDir, 0700)
+	if err == nil {
+		var file *os.File
+		file, err = os.OpenFile(filepath.Join(sdk.stateDir, usageLedgerFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err == nil {
+			_, err = file.Write(lines)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+	}
+	if err != nil {
+		log.Println("Unable to record upload in usage ledger:", err)
+	}
+}
+
+// UsageRecords is a function to read the records of the usage ledger of uploads completed since the given time, in order
+func (sdk VideraSDK) UsageRecords(since time.Time) ([]UsageRecord, error) {
+	var records []UsageRecord
+	file, err := os.Open(filepath.Join(sdk.stateDir, usageLedgerFile))
+	if os.IsNotExist(err) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		var record UsageRecord
+		if err = json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			// a line cut short by a crash mid append is skipped
+			log.Println(fmt.Sprintf("Skipping line %v of the usage ledger: %v", line, err))
+			continue
+		}
+		if !record.Time.Before(since) {
+			records = append(records, record)
+		}
+	}
+	return records, scanner.Err()
+}
+
+// RollupUsage is a function to sum usage records by the given groupings, in order of the bytes the groups sent
+// groupings are tag:<name>, project, profile, filetype and day, uploads lacking a tag are grouped under an empty value
+func RollupUsage(records []UsageRecord, groupBy []string) ([]UsageRollup, error) {
+	for _, grouping := range groupBy {
+		switch {
+		case strings.HasPrefix(grouping, "tag:") && len(grouping) > len("tag:"):
+		case grouping == "project", grouping == "profile", grouping == "filetype", grouping == "day":
+		default:
+			return nil, fmt.Errorf("Unknown usage grouping %s, expected tag:<name>, project, profile, filetype or day", grouping)
+		}
+	}
+
+	rollups := make(map[string]*UsageRollup)
+	for _, record := range records {
+		group := make(map[string]string, len(groupBy))
+		keys := make([]string, 0, len(groupBy))
+		for _, grouping := range groupBy {
+			group[grouping] = usageGroupValue(record, grouping)
+			keys = append(keys, group[grouping])
+		}
+		key := strings.Join(keys, "\x00")
+
+		rollup, ok := rollups[key]
+		if !ok {
+			rollup = &UsageRollup{Group: group, First: record.Time}
+			rollups[key] = rollup
+		}
+		rollup.Uploads++
+		rollup.BytesSent += record.BytesSent
+		rollup.Duration += record.Duration
+		if record.Time.Before(rollup.First) {
+			rollup.First = record.Time
+		}
+		if record.Time.After(rollup.Last) {
+			rollup.Last = record.Time
+		}
+	}
+
+	sorted := make([]UsageRollup, 0, len(rollups))
+	for _, rollup := range rollups {
+		sorted = append(sorted, *rollup)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].BytesSent != sorted[j].BytesSent {
+			return sorted[i].BytesSent > sorted[j].BytesSent
+		}
+		return fmt.Sprint(sorted[i].Group) < fmt.Sprint(sorted[j].Group)
+	})
+	return sorted, nil
+}
+
+// usageGroupValue is a function to get the value a usage record takes for a grouping
+func usageGroupValue(record UsageRecord, grouping string) string {
+	switch grouping {
+	case "project":
+		return record.Project
+	case "profile":
+		return record.Profile
+	case "filetype":
+		return record.Filetype
+	case "day":
+		return record.Time.Local().Format("2006-01-02")
+	}
+	return record.Tags[strings.TrimPrefix(grouping, "tag:")]
+}
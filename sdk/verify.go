@@ -0,0 +1,201 @@
+package viderasdk
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// VerifyReport Describes how a stored upload compares to a local source
+type VerifyReport struct {
+	ID            string            `json:"id"`             //ID of the stored upload
+	Size          int64             `json:"size"`           //Size of the stored upload
+	LocalSize     int64             `json:"local_size"`     //Size of the local source
+	Checksum      string            `json:"checksum"`       //Hex encoded sha256 digest of the stored upload, empty when the data node didn't report it
+	LocalChecksum string            `json:"local_checksum"` //Hex encoded sha256 digest of the local source
+	Method        string            `json:"method"`         //digests when the data node reported chunk digests, download when ranges were downloaded
+	Mismatches    []utils.ByteRange `json:"mismatches"`     //Ranges of the local source that differ from the stored upload
+}
+
+// Match returns whether the stored upload is identical to the local source
+func (report VerifyReport) Match() bool {
+	return report.Size == report.LocalSize && len(report.Mismatches) == 0
+}
+
+// storedDigests Digests of a stored upload as reported by the data node
+type storedDigests struct {
+	Size      int64    `json:"size"`       //Size of the upload
+	Checksum  string   `json:"checksum"`   //Hex encoded sha256 digest of the upload
+	ChunkSize int64    `json:"chunk_size"` //Size of the chunks digested, the last one may be shorter
+	Chunks    []string `json:"chunks"`     //Hex encoded sha256 digest of each chunk
+}
+
+// Verify is a function responsible for comparing a stored upload against a local source
+// chunk digests reported by the data node are compared when supported, otherwise every range is downloaded
+func (sdk VideraSDK) Verify(id string, location string) (VerifyReport, error) {
+	sdk = sdk.withRoute()
+	report := VerifyReport{ID: id}
+	source, err := sdk.newSource(location)
+	if err != nil {
+		return report, err
+	}
+	if report.LocalSize, err = source.Size(); err != nil {
+		return report, err
+	}
+	if report.LocalChecksum, _, err = sourcesChecksums(map[string]Source{"video": source}, []string{"video"}); err != nil {
+		return report, err
+	}
+	if err = sdk.updateUploadURL(); err != nil {
+		return report, err
+	}
+
+	digests, err := sdk.storedDigests(sdk.uploadURL(), id)
+	if err != nil {
+		return report, err
+	}
+	if digests != nil {
+		report.Method, report.Size, report.Checksum = "digests", digests.Size, digests.Checksum
+		report.Mismatches, err = compareDigests(source, report.LocalSize, *digests)
+		return report, err
+	}
+
+	report.Method, report.Size = "download", report.LocalSize
+	report.Mismatches, err = sdk.compareDownload(id, source, report.LocalSize)
+	return report, err
+}
+
+// storedDigests is a function responsible for asking the data node for the chunk digests of an upload
+// returns nil without error when the data node doesn't support digest queries
+func (sdk VideraSDK) storedDigests(dataNode string, id string) (*storedDigests, error) {
+	client := sdk.newClient()
+	req, _ := http.NewRequest(http.MethodGet, dataNode, nil)
+	req.Header.Set("Request-Type", "DIGESTS")
+	req.Header.Set("ID", id)
+	req.Header.Set("Chunk-Size", fmt.Sprintf("%v", sdk.chunkSize))
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotImplemented, http.StatusBadRequest:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("Unable to get the digests of %s: %s", id, res.Status)
+	}
+
+	var digests storedDigests
+	if err = json.NewDecoder(res.Body).Decode(&digests); err != nil {
+		return nil, err
+	}
+	if digests.ChunkSize <= 0 {
+		return nil, errors.New("Data node reported digests without a chunk size")
+	}
+	return &digests, nil
+}
+
+// compareDigests is a function to find the chunks of a local source whose digest differs from the stored one
+// the local bytes past the stored size, or stored chunks past the local size, are reported as mismatches too
+func compareDigests(source Source, localSize int64, digests storedDigests) ([]utils.ByteRange, error) {
+	file, err := source.Open(0)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	// a prefix of the source may be compared, e.g. against a partial upload
+	reader := io.LimitReader(file, localSize)
+
+	var mismatches []utils.ByteRange
+	chunk := make([]byte, digests.ChunkSize)
+	for idx, offset := 0, int64(0); offset < localSize || idx < len(digests.Chunks); idx, offset = idx+1, offset+digests.ChunkSize {
+		read, err := io.ReadFull(reader, chunk)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+
+		sum := sha256.Sum256(chunk[:read])
+		if idx >= len(digests.Chunks) || read == 0 || digests.Chunks[idx] != hex.EncodeToString(sum[:]) {
+			mismatches = appendRange(mismatches, offset, offset+digests.ChunkSize)
+		}
+	}
+	return clipRanges(mismatches, maxInt64(localSize, digests.Size)), nil
+}
+
+// compareDownload is a function responsible for downloading a stored upload chunk by chunk and comparing it to a local source
+func (sdk VideraSDK) compareDownload(id string, source Source, localSize int64) ([]utils.ByteRange, error) {
+	reader, err := source.Open(0)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	client := sdk.newClient()
+	var mismatches []utils.ByteRange
+	local := make([]byte, sdk.chunkSize)
+	for offset := int64(0); offset < localSize; offset += sdk.chunkSize {
+		read, err := io.ReadFull(reader, local)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+
+		req, _ := http.NewRequest(http.MethodGet, sdk.uploadURL(), nil)
+		req.Header.Set("Request-Type", "DOWNLOAD")
+		req.Header.Set("ID", id)
+		req.Header.Set("Artifact", "video")
+		req.Header.Set("Range", fmt.Sprintf("bytes=%v-%v", offset, offset+int64(read)-1))
+		res, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		stored, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode != http.StatusPartialContent {
+			return nil, fmt.Errorf("Data node supports neither digests nor downloads of %s: %s", id, res.Status)
+		}
+
+		if !bytes.Equal(stored, local[:read]) {
+			mismatches = appendRange(mismatches, offset, offset+int64(read))
+		}
+	}
+	return mismatches, nil
+}
+
+// appendRange is a function to add a range to a sorted list of ranges, merging it with the last one when adjacent
+func appendRange(ranges []utils.ByteRange, start int64, end int64) []utils.ByteRange {
+	if len(ranges) > 0 && ranges[len(ranges)-1].End == start {
+		ranges[len(ranges)-1].End = end
+		return ranges
+	}
+	return append(ranges, utils.ByteRange{Start: start, End: end})
+}
+
+// clipRanges is a function to clip ranges to a size
+func clipRanges(ranges []utils.ByteRange, size int64) []utils.ByteRange {
+	for idx := range ranges {
+		if ranges[idx].End > size {
+			ranges[idx].End = size
+		}
+	}
+	return ranges
+}
+
+// maxInt64 is a function to get the larger of two sizes
+func maxInt64(a int64, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,143 @@
+package viderasdk
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// Values of ClientOptions.VideoPrecheck
+const (
+	PrecheckWarn   = "warn"   //Upload videos failing the pre-check after warning about them
+	PrecheckRefuse = "refuse" //Fail uploads of videos failing the pre-check with ErrCorruptVideo
+)
+
+// ErrCorruptVideo Returned before init under the refuse pre-check when a video can't be parsed or decoded
+var ErrCorruptVideo = errors.New("Video is corrupt or truncated")
+
+// precheckSamples Fractions of the duration of a video at which frames are decoded by the pre-check,
+// truncated recordings usually fail near their end
+var precheckSamples = []float64{0, 0.5, 0.95}
+
+// precheckFrames Frames decoded at each sample
+const precheckFrames = 5
+
+// maxContainerBoxes Top level boxes walked at most, videos have a handful so more is a sign of garbage
+const maxContainerBoxes = 100000
+
+// precheckVideo is a function responsible for checking a video is readable before its session is created
+// the container is parsed and a sample of frames decoded, failures are warned about or refused as set by ClientOptions.VideoPrecheck
+func (sdk VideraSDK) precheckVideo(video Source) error {
+	if sdk.options.VideoPrecheck == "" {
+		return nil
+	}
+	if sdk.options.VideoPrecheck != PrecheckWarn && sdk.options.VideoPrecheck != PrecheckRefuse {
+		return fmt.Errorf("Unknown video pre-check %s, expected warn or refuse", sdk.options.VideoPrecheck)
+	}
+
+	problem := videoProblem(video)
+	if problem == nil {
+		utils.Debugln(fmt.Sprintf("Video %s passed the pre-check", video.Name()))
+		return nil
+	}
+	if sdk.options.VideoPrecheck == PrecheckRefuse {
+		return fmt.Errorf("%w: %s: %v", ErrCorruptVideo, video.Name(), problem)
+	}
+	sdk.warn(Warning{Kind: WarningCorruptVideo, Message: fmt.Sprintf("%s may be corrupt: %v", video.Name(), problem)})
+	return nil
+}
+
+// videoProblem is a function to get why a video is unreadable, nil when it looks fine
+// the boxes of MP4 and QuickTime videos are walked without dependencies, decoding needs ffmpeg and is skipped without it
+func videoProblem(video Source) error {
+	contentType, err := sniffSource(video)
+	if err != nil {
+		return err
+	}
+	if contentType == "video/mp4" || contentType == "video/quicktime" || contentType == "video/3gpp" {
+		if err = checkContainerBoxes(video); err != nil {
+			return err
+		}
+	}
+
+	input, err := mediaInput(video)
+	if err == nil {
+		err = utils.RequireFeature(utils.FeatureFFprobe)
+	}
+	if err == nil {
+		err = utils.RequireFeature(utils.FeatureFFmpeg)
+	}
+	if err != nil {
+		utils.Debugln(fmt.Sprintf("Frames of %s not decoded by the pre-check: %v", video.Name(), err))
+		return nil
+	}
+
+	duration, err := utils.ProbeDuration(input)
+	if err != nil {
+		return fmt.Errorf("container can't be parsed: %v", err)
+	}
+	for _, fraction := range precheckSamples {
+		offset := time.Duration(float64(duration) * fraction)
+		if err = utils.DecodeSample(input, offset, precheckFrames); err != nil {
+			return fmt.Errorf("frames at %s don't decode: %v", utils.FormatTimecode(offset), err)
+		}
+	}
+	return nil
+}
+
+// checkContainerBoxes is a function to walk the top level boxes of an MP4 or QuickTime video, failing when one runs past
+// the end of the file or the moov box indexing the samples is missing, the signs of a recording cut short
+func checkContainerBoxes(video Source) error {
+	size, err := video.Size()
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, 16)
+	moov := false
+	offset := int64(0)
+	for boxes := 0; offset < size && boxes < maxContainerBoxes; boxes++ {
+		reader, err := video.Open(offset)
+		if err != nil {
+			return err
+		}
+		n, err := io.ReadFull(reader, header)
+		reader.Close()
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		if n < 8 {
+			return fmt.Errorf("box header at offset %v is cut short", offset)
+		}
+
+		boxSize, kind := int64(binary.BigEndian.Uint32(header[:4])), string(header[4:8])
+		switch boxSize {
+		case 0:
+			// the last box may extend to the end of the file
+			boxSize = size - offset
+		case 1:
+			if n < 16 {
+				return fmt.Errorf("%s box header at offset %v is cut short", kind, offset)
+			}
+			boxSize = int64(binary.BigEndian.Uint64(header[8:16]))
+		}
+		if boxSize < 8 {
+			return fmt.Errorf("%s box at offset %v has an invalid size of %v bytes", kind, offset, boxSize)
+		}
+		if offset+boxSize > size {
+			return fmt.Errorf("%s box at offset %v ends %v bytes past the end of the file", kind, offset, offset+boxSize-size)
+		}
+
+		moov = moov || kind == "moov"
+		offset += boxSize
+	}
+
+	if !moov {
+		return errors.New("no moov box, the recording wasn't finalized")
+	}
+	return nil
+}
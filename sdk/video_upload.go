@@ -1,17 +1,17 @@
 package viderasdk
 
 import (
-	"errors"
 	"fmt"
+	"io/ioutil"
 	"log"
+	"os"
 	"time"
-
-	"github.com/SayedAlesawy/Videra-SDK/utils"
 )
 
 // sendVideoInitialRequest is a function responsible for sending initial upload request for video
-func (sdk VideraSDK) sendVideoInitialRequest(videoPath string, associatedModelID string) (string, error) {
-	videoSize, err := utils.GetFileSize(videoPath)
+// extraHeaders are added to the request, e.g. to link a segment to its series
+func (sdk VideraSDK) sendVideoInitialRequest(video Source, associatedModelID string, extraHeaders map[string]string) (string, error) {
+	videoSize, err := video.Size()
 	if err != nil {
 		return "", err
 	}
@@ -20,38 +20,119 @@ func (sdk VideraSDK) sendVideoInitialRequest(videoPath string, associatedModelID
 		"Filesize":            fmt.Sprintf("%v", videoSize),
 		"Associated-Model-ID": associatedModelID,
 	}
-	return sdk.sendInitialRequest(videoPath, "video", headers)
+	for key, val := range sdk.tailFirstHeaders(videoSize, extraHeaders) {
+		headers[key] = val
+	}
+	for key, val := range extraHeaders {
+		headers[key] = val
+	}
+	return sdk.sendInitialRequest(video, "video", headers)
 }
 
-// uploadVideo is a function responsible for uploading video
-func (sdk VideraSDK) uploadVideo(videoPath string, associatedModelID string) error {
-	ticker := time.NewTicker(time.Duration(sdk.defaultWaitingTime) * time.Second)
+// UploadVideo is a function responsible for uploading video
+// videos are processed before upload like job videos, except that they aren't segmented
+func (sdk VideraSDK) UploadVideo(videoPath string, associatedModelID string) (UploadResult, error) {
+	sdk.deadline = sdk.uploadDeadline()
+	sdk = sdk.withRoute()
+	video, err := sdk.newSource(videoPath)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	fingerprint := sourcesFingerprint(map[string]Source{"video": video}, []string{"video"})
+	video, cleanup, err := sdk.stageSource(video)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	defer cleanup()
+	if err = sdk.precheckVideo(video); err != nil {
+		return UploadResult{}, err
+	}
+
+	var videoHeaders map[string]string
+	if sdk.transformsVideo() {
+		// a processed video isn't interchangeable with an upload of the same file
+		fingerprint = nil
+
+		workDir, err := ioutil.TempDir("", "videra-video-")
+		if err != nil {
+			return UploadResult{}, err
+		}
+		defer os.RemoveAll(workDir)
+
+		video, videoHeaders, err = sdk.prepareVideo(video, workDir)
+		if err != nil {
+			return UploadResult{}, err
+		}
+	}
+	videoSources := map[string]Source{
+		"video": video,
+	}
+
+	checksum, _, err := sourcesChecksums(videoSources, []string{"video"})
+	if err != nil {
+		return UploadResult{}, err
+	}
+	result := UploadResult{Checksum: checksum, ProposedID: sdk.proposedID("video", videoHeaders), ScrubbedMetadata: scrubbedMetadata(videoHeaders)}
+
+	if sdk.options.SkipUploaded {
+		if cachedID := sdk.lookupUploadCache("video", fingerprint, checksum, associatedModelID); cachedID != "" {
+			log.Println("Video was already uploaded with ID =", cachedID)
+			result.ID = cachedID
+			return result, nil
+		}
+	}
+
+	files := map[string]string{"video": videoPath}
+	if err = sdk.runHooks(HookEvent{Stage: "pre_upload", Filetype: "video", Files: files}); err != nil {
+		return UploadResult{}, err
+	}
+
+	start := time.Now()
+	sdk.chunkStats = newChunkStats()
+	err = sdk.retryUpload(func(trial int) error {
+		result.Retries = trial
 
-	for trial := 0; trial <= sdk.defaultMaxRetries; trial, _ = trial+1, <-ticker.C {
-		err := sdk.updateUploadURL()
+		err := sdk.routeUpload(checksum, trial)
 		if err != nil {
 			log.Println("Can't contact master")
 			log.Println(err)
-			continue
+			return err
 		}
 
-		id, err := sdk.sendVideoInitialRequest(videoPath, associatedModelID)
+		id, err := sdk.sendVideoInitialRequest(video, associatedModelID, videoHeaders)
 		if err != nil {
 			log.Println("Can't connect to node")
 			log.Println(err)
-			continue
+			return err
 		}
 
 		log.Println("Sent inital request with ID =", id)
-		videoPathMap := map[string]string{
-			"video": videoPath,
+		bytesSent, err := sdk.withReinit(&id, func(extraHeaders map[string]string) (string, error) {
+			return sdk.sendVideoInitialRequest(video, associatedModelID, mergeHeaders(videoHeaders, extraHeaders))
+		}).uploadFiles(id, videoSources, []string{"video"}, nil)
+		result.BytesSent += bytesSent
+		if err != nil {
+			return err
 		}
 
-		err = sdk.uploadFiles(id, videoPathMap, []string{"video"})
-		if err == nil {
-			log.Println("Upload successful")
-			return nil
-		}
+		log.Println("Upload successful")
+		result.ID, result.DataNode = id, sdk.uploadURL()
+		result.Duration = time.Since(start)
+		return nil
+	})
+	sdk.emitUploadOutcome("video", start, err)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	sdk.chunkStats.summarize(sdk, "video", &result)
+
+	if err = sdk.recordUploadCache("video", fingerprint, result, associatedModelID); err != nil {
+		log.Println("Unable to record upload in cache:", err)
 	}
-	return errors.New("An error has occurred")
+
+	results := map[string]UploadResult{"video": result}
+	sdk.auditUpload("video", files, results)
+	sdk.accountUpload(results)
+	sdk.catalogUpload(files, results)
+	return result, sdk.runHooks(HookEvent{Stage: "post_upload", Filetype: "video", Files: files, Results: results})
 }
@@ -0,0 +1,47 @@
+package viderasdk
+
+import (
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// Kinds of warnings passed to ClientOptions.OnWarning
+const (
+	WarningOffsetCorrection    = "offset_correction"    //The data node asked the upload to continue from another offset
+	WarningChunkSizeChange     = "chunk_size_change"    //The data node pushed a smaller max request size
+	WarningSessionReinit       = "session_reinit"       //The data node expired the session and it was replaced by a new one
+	WarningNodeFailover        = "node_failover"        //The master routed an attempt to a different data node
+	WarningFiletypeMismatch    = "filetype_mismatch"    //A video doesn't look like a video container
+	WarningHookFailed          = "hook_failed"          //A hook failed under the warn policy
+	WarningUnsupportedEncoding = "unsupported_encoding" //A compression codec is unknown or the data node picked one that wasn't offered
+	WarningSlowThroughput      = "slow_throughput"      //The upload ran below watchdog_min_rate for a whole watchdog window
+	WarningSessionAdopted      = "session_adopted"      //An init collided with a session the data node already had for the file, which is continued
+	WarningSessionExpiring     = "session_expiring"     //The session expires within session_expiry_margin, it is extended or replaced
+	WarningCorruptVideo        = "corrupt_video"        //A video failed the pre-check under the warn policy
+	WarningRouteFailover       = "route_failover"       //Requests kept failing through a network interface and connections moved to the next one
+)
+
+// Warning Describes a non-fatal anomaly of an upload, the upload goes on in a degraded condition
+type Warning struct {
+	Kind     string    //Kind of the warning, one of the Warning constants
+	Message  string    //Human readable description, the line logged for the warning
+	Session  string    //ID of the affected session, empty when the warning isn't tied to one
+	DataNode string    //Data node the upload was talking to
+	Old      string    //Value before the change, e.g. the offset or chunk size the SDK had, empty when nothing changed
+	New      string    //Value after the change, e.g. the offset or chunk size the data node asked for
+	Time     time.Time //Time at which the warning was raised
+}
+
+// warn is a function responsible for logging a warning and passing it to ClientOptions.OnWarning when set
+func (sdk VideraSDK) warn(warning Warning) {
+	warning.Time = time.Now().UTC()
+	if warning.DataNode == "" {
+		warning.DataNode = sdk.uploadURL()
+	}
+
+	utils.Warnln(warning.Message)
+	if sdk.options.OnWarning != nil {
+		sdk.options.OnWarning(warning)
+	}
+}
@@ -0,0 +1,86 @@
+package viderasdk
+
+import (
+	"fmt"
+	"time"
+)
+
+// Remediations of the throughput watchdog
+const (
+	WatchdogAlert       = "alert"       //Only emit a slow_throughput warning
+	WatchdogRenegotiate = "renegotiate" //Halve the chunk size, down to minWatchdogChunkSize
+	WatchdogFailover    = "failover"    //Ask the master for a data node again and continue the upload in a new session on it
+)
+
+// minWatchdogChunkSize Chunk size the watchdog doesn't shrink chunks below
+const minWatchdogChunkSize = 256 << 10
+
+// throughputWatchdog Detects uploads running slower than a minimum throughput over a sliding window of time
+type throughputWatchdog struct {
+	minRate     int64         //Bytes per second below which the upload is slow
+	window      time.Duration //Time over which the throughput is measured
+	windowStart time.Time     //Time at which the current window started
+	windowBytes int64         //Bytes acknowledged during the current window
+}
+
+// newThroughputWatchdog is a function that returns the watchdog of an upload, nil when watchdog_min_rate is unset
+func (sdk VideraSDK) newThroughputWatchdog() *throughputWatchdog {
+	if sdk.watchdogRate <= 0 || sdk.watchdogWindow <= 0 {
+		return nil
+	}
+	return &throughputWatchdog{minRate: sdk.watchdogRate, window: sdk.watchdogWindow, windowStart: time.Now()}
+}
+
+// observe records that bytes were acknowledged, once the window elapsed it returns the throughput over the window
+// and whether it was below the minimum, starting a new window
+func (watchdog *throughputWatchdog) observe(bytes int64) (int64, bool) {
+	if watchdog == nil {
+		return 0, false
+	}
+
+	watchdog.windowBytes += bytes
+	elapsed := time.Since(watchdog.windowStart)
+	if elapsed < watchdog.window {
+		return 0, false
+	}
+
+	rate := int64(float64(watchdog.windowBytes) / elapsed.Seconds())
+	watchdog.windowStart, watchdog.windowBytes = time.Now(), 0
+	return rate, rate < watchdog.minRate
+}
+
+// reset is a function responsible for starting a new window, e.g. after a remediation changed the transfer
+func (watchdog *throughputWatchdog) reset() {
+	if watchdog == nil {
+		return
+	}
+	watchdog.windowStart, watchdog.windowBytes = time.Now(), 0
+}
+
+// slowThroughput is a function responsible for warning that the upload of session id runs at rate bytes per second,
+// it returns the remediation to apply, failing over falls back to an alert when the upload can't continue in a new session
+// or already replaced its session session_reinits times as failovers counts
+func (sdk VideraSDK) slowThroughput(id string, rate int64, failovers int) string {
+	action := sdk.watchdogAction
+	if action == WatchdogFailover && (sdk.reinit == nil || sdk.artifact != "" || failovers >= sdk.sessionReinits) {
+		action = WatchdogAlert
+	}
+	if action == WatchdogRenegotiate && sdk.chunkSize/2 < minWatchdogChunkSize {
+		action = WatchdogAlert
+	}
+
+	sdk.warn(Warning{Kind: WarningSlowThroughput, Session: id, DataNode: sdk.uploadURL(), Old: fmt.Sprint(sdk.watchdogRate), New: fmt.Sprint(rate),
+		Message: fmt.Sprintf("Upload running at %v bytes/s for %v, below %v bytes/s, remediation: %s", rate, sdk.watchdogWindow, sdk.watchdogRate, action)})
+	return action
+}
+
+// failover is a function responsible for continuing the upload of session id from offset on the data node the master routes to
+// the session is removed from the journal under the data node it was initialized on
+func (sdk VideraSDK) failover(id string, offset int64) (string, int64, error) {
+	previous := sdk.uploadURL()
+	if err := sdk.updateUploadURL(); err != nil {
+		return "", 0, err
+	}
+	sdk.completeSession(id, previous)
+	return sdk.reinitSession(id, offset)
+}
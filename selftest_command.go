@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// selftestCommand is a function responsible for the selftest subcommands, checks of the cluster run before relying on it
+func selftestCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("Missing selftest subcommand, expected resume")
+	}
+
+	switch args[0] {
+	case "resume":
+		return selftestResumeCommand(args[1:])
+	}
+	return errors.New("Unknown selftest subcommand, expected resume")
+}
+
+// selftestResumeCommand is a function responsible for interrupting a test upload, resuming it and printing whether it resumed intact
+func selftestResumeCommand(args []string) error {
+	flags := flag.NewFlagSet("selftest resume", flag.ExitOnError)
+	modelID := flags.String("model-id", "", "ID of the model the test video is uploaded for")
+	var size utils.Size
+	flags.Var(&size, "size", "Size of the random test file, e.g. 64MiB, 4 chunks by default")
+	output := flags.String("output", "human", "Format of the printed report, human or json")
+	flags.Usage = func() {
+		log.Println("Usage: selftest resume -model-id <id> [-size <size>] [-output human|json]")
+		flags.PrintDefaults()
+	}
+	parseFlags(flags, args)
+
+	if *modelID == "" {
+		flags.Usage()
+		return errors.New("Missing model ID")
+	}
+	if err := validateOutputFormat(*output); err != nil {
+		return err
+	}
+
+	report, err := viderasdk.SDKInstance().SelfTestResume(int64(size), *modelID)
+	if err != nil {
+		return err
+	}
+
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		result := "PASS"
+		if !report.Passed() {
+			result = "FAIL"
+		}
+		fmt.Printf("Resume self-test: %s\n", result)
+		fmt.Printf("  Upload ID:    %s\n", report.ID)
+		fmt.Printf("  Data node:    %s\n", report.DataNode)
+		fmt.Printf("  Size:         %v\n", report.Size)
+		fmt.Printf("  Interrupted:  at offset %v\n", report.InterruptedAt)
+		if report.StoredAt >= 0 {
+			fmt.Printf("  Stored:       %v bytes at the interruption\n", report.StoredAt)
+		} else {
+			fmt.Printf("  Stored:       not reported by the data node\n")
+		}
+		fmt.Printf("  Resent:       %v bytes\n", report.BytesResent)
+		fmt.Printf("  Verified by:  %s\n", report.Verify.Method)
+		fmt.Printf("  Duration:     %v\n", report.Duration)
+		for _, problem := range report.Problems {
+			fmt.Printf("  Problem:      %s\n", problem)
+		}
+	}
+
+	if !report.Passed() {
+		return errors.New("Upload didn't resume intact")
+	}
+	log.Println("Upload resumed intact")
+	return nil
+}
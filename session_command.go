@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+)
+
+// sessionCommand is a function responsible for the session subcommands
+// session export prints a portable token for an open session, session import continues it from another machine
+func sessionCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("Missing session subcommand, expected export or import")
+	}
+
+	switch args[0] {
+	case "export":
+		return sessionExportCommand(args[1:])
+	case "import":
+		return sessionImportCommand(args[1:])
+	}
+	return errors.New("Unknown session subcommand, expected export or import")
+}
+
+// sessionExportCommand is a function responsible for printing the token of a session of the session journal
+func sessionExportCommand(args []string) error {
+	if len(args) != 1 || strings.HasPrefix(args[0], "-") {
+		log.Println("Usage: session export <session id>")
+		return errors.New("Missing session ID")
+	}
+
+	token, err := viderasdk.SDKInstance().ExportSession(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Println(token)
+	return nil
+}
+
+// sessionImportCommand is a function responsible for continuing an exported session from a local file
+func sessionImportCommand(args []string) error {
+	flags := flag.NewFlagSet("session import", flag.ExitOnError)
+	token := flags.String("token", "", "Token printed by session export")
+	file := flags.String("file", "", "Path or URL of the local copy of the file being uploaded")
+	output := flags.String("output", "human", "Format of the printed result, human or json")
+	flags.Usage = func() {
+		log.Println("Usage: session import -token <token> -file <local file> [-output human|json]")
+		flags.PrintDefaults()
+	}
+	parseFlags(flags, args)
+
+	if *token == "" || *file == "" {
+		flags.Usage()
+		return errors.New("Missing token or local file")
+	}
+	if err := validateOutputFormat(*output); err != nil {
+		return err
+	}
+
+	result, err := viderasdk.SDKInstance().ImportSession(*token, *file)
+	if err != nil {
+		return err
+	}
+
+	log.Println("Session completed successfully!")
+	return printSingleResult(*output, "Video", result)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+	"github.com/SayedAlesawy/Videra-SDK/videratest"
+)
+
+// startLocalSink is a function responsible for pointing the SDK at an in-process fake cluster instead of the configured one
+// local state such as the session journal goes to a temporary directory, the returned function stops the sink and removes it
+func startLocalSink(vSDK *viderasdk.VideraSDK) (*videratest.Server, func(), error) {
+	stateDir, err := ioutil.TempDir("", "videra-sink")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sink := videratest.NewServer()
+	// the sink speaks the latest protocol so the report shows what current data nodes receive
+	sink.ProtocolVersion = 2
+	vSDK.SetMasterURL(sink.URL())
+	vSDK.SetStateDir(stateDir)
+	return sink, func() {
+		sink.Close()
+		os.RemoveAll(stateDir)
+	}, nil
+}
+
+// validateSink is a function to check that a -sink flag value is supported
+func validateSink(sink string) error {
+	if sink != "cluster" && sink != "local" {
+		return errors.New("Unknown sink, expected cluster or local")
+	}
+	return nil
+}
+
+// printSinkReport is a function responsible for printing the requests a local sink received to stdout in the given format
+func printSinkReport(format string, requests []videratest.Request) error {
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(requests)
+	}
+
+	chunks, bytesSent := 0, int64(0)
+	for idx, request := range requests {
+		fmt.Printf("#%v %s -> %v\n", idx+1, strings.TrimSpace(request.Node+" "+request.Method+" "+request.Type), request.Status)
+		if request.Type == "APPEND" {
+			chunks++
+			bytesSent += request.Length
+			fmt.Printf("  Chunk:      session %s, offset %v, %v bytes\n", request.Session, request.Offset, request.Length)
+		}
+
+		names := make([]string, 0, len(request.Header))
+		for name := range request.Header {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("  %s: %s\n", name, strings.Join(request.Header[name], ", "))
+		}
+		if request.Body != "" {
+			fmt.Printf("  Body: %s\n", request.Body)
+		}
+	}
+	fmt.Printf("%v requests, %v chunks, %v bytes sent\n", len(requests), chunks, bytesSent)
+	return nil
+}
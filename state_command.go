@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+)
+
+// stateCommand is a function responsible for the state subcommands
+// state show describes the versioned state files of the state directory or prints the content of one of them,
+// state repair rebuilds the corrupt ones
+func stateCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("Missing state subcommand, expected show or repair")
+	}
+
+	switch args[0] {
+	case "show":
+		return stateShowCommand(args[1:])
+	case "repair":
+		return stateRepairCommand(args[1:])
+	}
+
+	return errors.New("Unknown state subcommand, expected show or repair")
+}
+
+// stateShowCommand is a function responsible for describing the versioned state files or printing the content of one of them
+func stateShowCommand(args []string) error {
+	flags := flag.NewFlagSet("state show", flag.ExitOnError)
+	output := flags.String("output", "human", "Format of the printed state files, human or json")
+	flags.Usage = func() {
+		log.Println("Usage: state show [-output human|json] [sessions.json|upload_cache.json|cluster_limits.json|node_capabilities.json]")
+		flags.PrintDefaults()
+	}
+	parseFlags(flags, args)
+
+	if err := validateOutputFormat(*output); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return errors.New("Expected at most one state file")
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if flags.NArg() == 1 {
+		info, data, err := viderasdk.SDKInstance().StateFile(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		if !info.Exists {
+			return fmt.Errorf("State file %s doesn't exist", info.Path)
+		}
+		if *output == "json" {
+			return encoder.Encode(struct {
+				viderasdk.StateFileInfo
+				Data json.RawMessage `json:"data"`
+			}{info, data})
+		}
+		log.Println(fmt.Sprintf("%s version %v, migrated to version %v", info.Path, info.Version, info.CurrentVersion))
+		return encoder.Encode(data)
+	}
+
+	files := viderasdk.SDKInstance().StateFiles()
+	if *output == "json" {
+		return encoder.Encode(files)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "FILE\tSIZE\tVERSION\tCURRENT\tSTATUS")
+	for _, file := range files {
+		status, size, version := "ok", fmt.Sprint(file.Size), fmt.Sprint(file.Version)
+		switch {
+		case !file.Exists:
+			status, size, version = "missing", "-", "-"
+		case !file.Valid:
+			status = file.Error
+		case file.Version < file.CurrentVersion:
+			status = "migrated when loaded"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%v\t%s\n", file.Name, size, version, file.CurrentVersion, status)
+	}
+	return writer.Flush()
+}
+
+// stateRepairCommand is a function responsible for repairing the corrupt versioned state files, or one of them, and printing what was done
+// corrupt files are kept next to the rebuilt ones with a .corrupt- suffix
+func stateRepairCommand(args []string) error {
+	flags := flag.NewFlagSet("state repair", flag.ExitOnError)
+	output := flags.String("output", "human", "Format of the printed repairs, human or json")
+	flags.Usage = func() {
+		log.Println("Usage: state repair [-output human|json] [sessions.json|upload_cache.json|cluster_limits.json|node_capabilities.json]")
+		flags.PrintDefaults()
+	}
+	parseFlags(flags, args)
+
+	if err := validateOutputFormat(*output); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return errors.New("Expected at most one state file")
+	}
+
+	var repairs []viderasdk.StateRepair
+	if flags.NArg() == 1 {
+		repair, err := viderasdk.SDKInstance().RepairStateFile(flags.Arg(0))
+		if err != nil && repair.QuarantinedAs == "" {
+			return err
+		}
+		repairs = append(repairs, repair)
+	} else {
+		repairs = viderasdk.SDKInstance().RepairStateFiles()
+	}
+
+	failed := 0
+	for _, repair := range repairs {
+		if repair.Error != "" && !repair.Repaired {
+			failed++
+		}
+	}
+
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(repairs); err != nil {
+			return err
+		}
+	} else {
+		writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(writer, "FILE\tSTATUS\tCORRUPT FILE KEPT AT")
+		for _, repair := range repairs {
+			status, kept := "ok", "-"
+			switch {
+			case !repair.Exists:
+				status = "missing"
+			case repair.Repaired && repair.Salvaged:
+				status = "rebuilt from its data: " + repair.Error
+			case repair.Repaired:
+				status = "rebuilt empty: " + repair.Error
+			case repair.Error != "":
+				status = "not repaired: " + repair.Error
+			}
+			if repair.QuarantinedAs != "" {
+				kept = repair.QuarantinedAs
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\n", repair.Name, status, kept)
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%v state files couldn't be repaired", failed)
+	}
+	return nil
+}
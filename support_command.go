@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+)
+
+// supportBundleCommand is a function responsible for writing a support bundle to attach to bug reports
+func supportBundleCommand(args []string) error {
+	flags := flag.NewFlagSet("support-bundle", flag.ExitOnError)
+	bundlePath := flags.String("out", "", "Path of the archive to create, videra-support-<time>.tar.gz in the working directory by default")
+	output := flags.String("output", "human", "Format of the printed manifest, human or json")
+	flags.Usage = func() {
+		log.Println("Usage: support-bundle [-out <archive>] [-output human|json]")
+		log.Println("Secrets are redacted and credentials left out, review the archive before sharing it")
+		flags.PrintDefaults()
+	}
+	parseFlags(flags, args)
+
+	if err := validateOutputFormat(*output); err != nil {
+		return err
+	}
+	if *bundlePath == "" {
+		*bundlePath = fmt.Sprintf("videra-support-%s.tar.gz", time.Now().Format("20060102-150405"))
+	}
+
+	manifest, err := viderasdk.SDKInstance().SupportBundle(*bundlePath)
+	if err != nil {
+		return err
+	}
+
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(manifest)
+	}
+	fmt.Printf("Support bundle: %s\n", *bundlePath)
+	for _, file := range manifest.Files {
+		fmt.Printf("  %s\n", file)
+	}
+	for _, note := range manifest.Notes {
+		fmt.Printf("  Note: %s\n", note)
+	}
+	return nil
+}
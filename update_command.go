@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+)
+
+// stringsFlag A flag collecting values, it can be repeated
+type stringsFlag []string
+
+// String returns the values separated by commas
+func (values *stringsFlag) String() string {
+	return strings.Join(*values, ",")
+}
+
+// Set adds a value
+func (values *stringsFlag) Set(value string) error {
+	*values = append(*values, value)
+	return nil
+}
+
+// updateCommand is a function responsible for renaming or retagging a stored asset
+func updateCommand(args []string) error {
+	flags := flag.NewFlagSet("update", flag.ExitOnError)
+	name := flags.String("name", "", "New filename of the asset")
+	output := flags.String("output", "human", "Format of the printed asset, human or json")
+	setTags := tagsFlag{}
+	flags.Var(setTags, "set-tag", "Add or overwrite a key=value tag, can be repeated")
+	var unsetTags stringsFlag
+	flags.Var(&unsetTags, "unset-tag", "Remove the tag with this key, can be repeated")
+	flags.Usage = func() {
+		log.Println("Usage: update [-name name] [-set-tag k=v] [-unset-tag k] [-output human|json] <asset id>")
+	}
+
+	// the ID comes first on the command line, flags follow it
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		flags.Usage()
+		return errors.New("Missing asset ID")
+	}
+	parseFlags(flags, args[1:])
+	if err := validateOutputFormat(*output); err != nil {
+		return err
+	}
+
+	asset, err := viderasdk.SDKInstance().UpdateMetadata(args[0], viderasdk.MetadataChanges{
+		Name:      *name,
+		SetTags:   setTags,
+		UnsetTags: unsetTags,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(asset)
+	}
+	fmt.Printf("Updated %s %s: %s %s\n", asset.Type, asset.ID, asset.Name, tagsFlag(asset.Tags).String())
+	return nil
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// uploadCommand is a function responsible for the upload subcommands
+// upload video uploads a single video, either for a model or to a presigned session,
+// upload incident uploads the video, detections and logs of an incident as linked uploads,
+// upload -template uploads a file in the shape of a template of the config
+func uploadCommand(args []string) error {
+	if len(args) > 0 && args[0] == "incident" {
+		return uploadIncidentCommand(args[1:])
+	}
+	if len(args) > 0 && args[0] != "video" {
+		return uploadTemplateCommand(args)
+	}
+	if len(args) == 0 {
+		return errors.New("Missing or unknown upload subcommand, expected video, incident or -template")
+	}
+
+	flags := flag.NewFlagSet("upload video", flag.ExitOnError)
+	signedURL := flags.String("signed-url", "", "Presigned data node URL of a session created out of band")
+	modelID := flags.String("model-id", "", "ID of the model the video is uploaded for")
+	output := flags.String("output", "human", "Format of the printed result, human or json")
+	project := flags.String("project", "", "Apply the defaults of this project of the config, overrides project of the config")
+	trim := flags.String("trim", "", "Upload only this clip of the video, e.g. 00:05:00-00:07:30")
+	stripAudio := flags.Bool("strip-audio", false, "Drop every audio track of the video before upload")
+	audioTrack := flags.Int("audio-track", 0, "Keep only this audio track of the video, counted from 1")
+	scrubMetadata := flags.Bool("scrub-metadata", false, "Remove metadata such as GPS location and device identifiers from the video")
+	keepMetadata := flags.String("keep-metadata", "", "Comma separated metadata tags kept when scrubbing, e.g. title,creation_time")
+	var deadline, fileTimeout utils.Duration
+	var chunkSize utils.Size
+	flags.Var(&chunkSize, "chunk-size", "Size of uploaded chunks, e.g. 4MiB, overrides chunk_size of the config")
+	var tailFirst utils.Size
+	flags.Var(&tailFirst, "tail-first", "Upload this much of the end of the video first for quick previews, e.g. 256MiB, when the data node supports it, in order otherwise")
+	flags.Var(&deadline, "deadline", "Give up the upload after this duration including retries, e.g. 2h")
+	flags.Var(&fileTimeout, "file-timeout", "Give up the upload when transferring the video takes longer than this duration")
+	compression := flags.String("compression", "", "Comma separated codecs offered for chunks in preference order, e.g. zstd,gzip")
+	metadata := flags.String("metadata", "", "JSON object of metadata sent with the upload, e.g. '{\"title\": {\"en\": \"Intro\"}}', or @file to read it from a file")
+	onCollision := flags.String("on-collision", "", "rename-suffix, overwrite or fail when the cluster already has a file of the same name, overrides filename_collision of the config")
+	bindInterface := flags.String("bind-interface", "", "Comma separated network interfaces connections are bound to in preference order, e.g. eth0,wwan0, failing over to the next when requests keep failing")
+	precheck := flags.String("precheck", "", "warn or refuse to parse the container and decode a sample of frames of the video before upload, overrides video_precheck of the config")
+	strictFiletype := flags.Bool("strict-filetype", false, "Fail when the video doesn't look like a video container instead of warning")
+	isolation := flags.String("isolation", "", "hardlink or reflink to stage local files before upload so rotating or rewriting them doesn't affect it, overrides source_isolation of the config")
+	requireTLS := flags.Bool("require-tls", false, "Refuse to talk to any master, data node or relay over plain http, see security.require_tls of the config")
+	proposedID := flags.String("id", "", "ID proposed for the upload to clusters letting clients pick it, auto generates a UUIDv7, the cluster has the final say")
+	idNamespace := flags.String("id-namespace", "", "Namespace the proposed ID is prefixed with as namespace/id")
+	sampleChunks := flags.Float64("debug-sample-chunks", 0, "Fraction of chunks recorded with their hashes and responses in a bundle of the state directory for support, e.g. 0.01")
+	sampleChunkData := flags.Bool("debug-sample-data", false, "Also store raw copies of the chunks sampled by -debug-sample-chunks")
+	allowChanged := flags.Bool("allow-changed", false, "Restart the upload from scratch when a file is modified while uploaded instead of failing")
+	weight := flags.Float64("weight", 0, "Share of max_concurrent_chunks the upload gets relative to concurrent ones, overrides the weight of its priority in priority_weights of the config")
+	flags.Usage = func() {
+		log.Println("Usage: upload video (-signed-url <url> | -model-id <id>) [processing flags] [-output human|json] <video file>")
+		flags.PrintDefaults()
+	}
+	parseFlags(flags, args[1:])
+
+	if flags.NArg() != 1 || (*signedURL == "") == (*modelID == "") {
+		flags.Usage()
+		return errors.New("Expected a video file and either -signed-url or -model-id")
+	}
+	if err := validateOutputFormat(*output); err != nil {
+		return err
+	}
+
+	vSDK := viderasdk.SDKInstance()
+	if *project != "" {
+		if err := vSDK.UseProject(*project); err != nil {
+			return err
+		}
+	}
+	if *bindInterface != "" {
+		if err := vSDK.BindInterfaces(strings.Split(*bindInterface, ",")); err != nil {
+			return err
+		}
+	}
+	// only the flags given override the options of the config and project
+	options := vSDK.ClientOptions()
+	var err error
+	flags.Visit(func(set *flag.Flag) {
+		switch set.Name {
+		case "strip-audio":
+			options.StripAudio = *stripAudio
+		case "audio-track":
+			options.AudioTrack = *audioTrack
+		case "scrub-metadata":
+			options.ScrubMetadata = *scrubMetadata
+		case "deadline":
+			options.Deadline = time.Duration(deadline)
+		case "file-timeout":
+			options.FileTimeout = time.Duration(fileTimeout)
+		case "strict-filetype":
+			options.StrictFiletype = *strictFiletype
+		case "allow-changed":
+			options.AllowChanged = *allowChanged
+		case "tail-first":
+			options.TailFirst = int64(tailFirst)
+		case "weight":
+			options.Weight = *weight
+		case "debug-sample-chunks":
+			options.SampleChunks = *sampleChunks
+		case "debug-sample-data":
+			options.SampleChunkData = *sampleChunkData
+		case "id-namespace":
+			options.IDNamespace = *idNamespace
+		case "precheck":
+			options.VideoPrecheck = *precheck
+		case "isolation":
+			options.SourceIsolation = *isolation
+		case "on-collision":
+			options.FilenameCollision = *onCollision
+		case "compression":
+			options.Compression = strings.Split(*compression, ",")
+		case "keep-metadata":
+			options.KeepMetadata = strings.Split(*keepMetadata, ",")
+		}
+	})
+	options.RequireTLS = options.RequireTLS || *requireTLS
+	if *proposedID != "" {
+		if options.ProposedID, err = resolveProposedID(*proposedID); err != nil {
+			return err
+		}
+	}
+	if *metadata != "" {
+		if options.Metadata, err = parseMetadata(*metadata); err != nil {
+			return err
+		}
+	}
+	if *trim != "" {
+		start, end, err := utils.ParseTimeRange(*trim)
+		if err != nil {
+			return err
+		}
+		options.Trim = &viderasdk.TimeRange{Start: start, End: end}
+	}
+	vSDK.SetClientOptions(options)
+	if chunkSize > 0 {
+		vSDK.SetChunkSize(int64(chunkSize))
+	}
+
+	var result viderasdk.UploadResult
+	if *signedURL != "" {
+		result, err = vSDK.UploadToSignedURL(flags.Arg(0), *signedURL)
+	} else {
+		result, err = vSDK.UploadVideo(flags.Arg(0), *modelID)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Println("Video submitted successfully!")
+	return printSingleResult(*output, "Video", result)
+}
+
+// uploadIncidentCommand is a function responsible for uploading the video, detections and logs of an incident under one incident ID
+func uploadIncidentCommand(args []string) error {
+	flags := flag.NewFlagSet("upload incident", flag.ExitOnError)
+	video := flags.String("video", "", "Path or URL to the video of the incident")
+	detections := flags.String("detections", "", "Path or URL to the JSON output of the model for the video")
+	logs := flags.String("logs", "", "Path or URL to the logs of the device")
+	incidentID := flags.String("id", "", "ID of the incident, empty generates a UUIDv7")
+	modelID := flags.String("model-id", "", "ID of the model the video is uploaded for")
+	project := flags.String("project", "", "Apply the defaults of this project of the config, overrides project of the config")
+	output := flags.String("output", "human", "Format of the printed result, human or json")
+	tags := tagsFlag{}
+	flags.Var(tags, "tag", "key=value tag added to every upload of the incident, can be repeated")
+	flags.Usage = func() {
+		log.Println("Usage: upload incident -video <video file> [-detections <json file>] [-logs <log file>] [-id <incident id>] [-model-id <id>] [-output human|json]")
+		flags.PrintDefaults()
+	}
+	parseFlags(flags, args)
+
+	if *video == "" || flags.NArg() != 0 {
+		flags.Usage()
+		return errors.New("Expected -video")
+	}
+	if err := validateOutputFormat(*output); err != nil {
+		return err
+	}
+
+	vSDK := viderasdk.SDKInstance()
+	if *project != "" {
+		if err := vSDK.UseProject(*project); err != nil {
+			return err
+		}
+	}
+	if len(tags) > 0 {
+		options := vSDK.ClientOptions()
+		merged := make(map[string]string, len(options.Tags)+len(tags))
+		for key, val := range options.Tags {
+			merged[key] = val
+		}
+		for key, val := range tags {
+			merged[key] = val
+		}
+		options.Tags = merged
+		vSDK.SetClientOptions(options)
+	}
+
+	result, err := vSDK.UploadIncident(viderasdk.IncidentFiles{ID: *incidentID, Video: *video, Detections: *detections, Logs: *logs, ModelID: *modelID})
+	if err != nil && result.Video.ID == "" {
+		return err
+	}
+	if err != nil {
+		log.Println(err)
+	}
+
+	log.Println(fmt.Sprintf("Incident %s submitted successfully!", result.ID))
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+	printUploadResult("Video", result.Video)
+	if result.Detections != nil {
+		printUploadResult("Detections", *result.Detections)
+	}
+	if result.Logs != nil {
+		printUploadResult("Logs", *result.Logs)
+	}
+	return err
+}
+
+// uploadTemplateCommand is a function responsible for uploading a file in the shape of a template of the config
+// the file is the video of a job when the template has a model and a video for its model_id otherwise,
+// flags given on the command line override the fields of the template
+func uploadTemplateCommand(args []string) error {
+	flags := flag.NewFlagSet("upload", flag.ExitOnError)
+	templateName := flags.String("template", "", "Template of the config the upload is shaped by")
+	modelPath := flags.String("model", "", "Path or URL to model file, overrides model of the template")
+	configPath := flags.String("config", "", "Path or URL to config file, overrides config of the template")
+	codePath := flags.String("code", "", "Path or URL to code file, overrides code of the template")
+	modelID := flags.String("model-id", "", "ID of the model the video is uploaded for, overrides model_id of the template")
+	project := flags.String("project", "", "Apply the defaults of this project on top of the template")
+	priority := flags.String("priority", "", "Priority the cluster is asked to process the upload with, overrides priority of the template")
+	compression := flags.String("compression", "", "Comma separated codecs offered for chunks in preference order, overrides compression of the template")
+	stripAudio := flags.Bool("strip-audio", false, "Drop every audio track of the video, overrides strip_audio of the template")
+	scrubMetadata := flags.Bool("scrub-metadata", false, "Remove metadata from the video, overrides scrub_metadata of the template")
+	requireTLS := flags.Bool("require-tls", false, "Refuse to upload over plain http, overrides require_tls of the template")
+	output := flags.String("output", "human", "Format of the printed result, human or json")
+	tags := tagsFlag{}
+	flags.Var(tags, "tag", "key=value tag added to those of the template, can be repeated")
+	var chunkSize utils.Size
+	var segmentDuration utils.Duration
+	flags.Var(&chunkSize, "chunk-size", "Size of uploaded chunks, overrides chunk_size of the template")
+	flags.Var(&segmentDuration, "segment-duration", "Split the video of jobs into segments of about this duration, overrides segment_duration of the template")
+	flags.Usage = func() {
+		log.Println("Usage: upload -template <name> [template overrides] [-output human|json] <file>")
+		flags.PrintDefaults()
+	}
+	parseFlags(flags, args)
+
+	if *templateName == "" || flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("Expected -template and a file")
+	}
+	if err := validateOutputFormat(*output); err != nil {
+		return err
+	}
+
+	vSDK := viderasdk.SDKInstance()
+	template, err := vSDK.UseTemplate(*templateName)
+	if err != nil {
+		return err
+	}
+	if *project != "" {
+		if err = vSDK.UseProject(*project); err != nil {
+			return err
+		}
+	}
+
+	options := vSDK.ClientOptions()
+	if len(tags) > 0 {
+		merged := make(map[string]string, len(options.Tags)+len(tags))
+		for key, val := range options.Tags {
+			merged[key] = val
+		}
+		for key, val := range tags {
+			merged[key] = val
+		}
+		options.Tags = merged
+	}
+	flags.Visit(func(set *flag.Flag) {
+		switch set.Name {
+		case "model":
+			template.Model = *modelPath
+		case "config":
+			template.Config = *configPath
+		case "code":
+			template.Code = *codePath
+		case "model-id":
+			template.ModelID = *modelID
+		case "priority":
+			options.Priority = *priority
+		case "compression":
+			options.Compression = strings.Split(*compression, ",")
+		case "strip-audio":
+			options.StripAudio = *stripAudio
+		case "scrub-metadata":
+			options.ScrubMetadata = *scrubMetadata
+		case "require-tls":
+			options.RequireTLS = *requireTLS
+		case "segment-duration":
+			options.SegmentDuration = time.Duration(segmentDuration)
+		}
+	})
+	vSDK.SetClientOptions(options)
+	if chunkSize > 0 {
+		vSDK.SetChunkSize(int64(chunkSize))
+	}
+
+	if template.Model == "" {
+		if template.ModelID == "" {
+			return fmt.Errorf("Template %s has neither a model nor a model_id, give one with -model or -model-id", *templateName)
+		}
+		result, err := vSDK.UploadVideo(flags.Arg(0), template.ModelID)
+		if err != nil {
+			return err
+		}
+		log.Println("Video submitted successfully!")
+		return printSingleResult(*output, "Video", result)
+	}
+
+	result, err := vSDK.UploadJob(flags.Arg(0), template.Model, template.Config, template.Code)
+	if err != nil {
+		return err
+	}
+	log.Println("Job submitted successfully!")
+	return printJobResult(*output, result)
+}
+
+// parseMetadata is a function to parse the value of a -metadata flag, a JSON object or @ followed by the path of a file holding one
+func parseMetadata(value string) (map[string]interface{}, error) {
+	content := []byte(value)
+	if strings.HasPrefix(value, "@") {
+		var err error
+		if content, err = ioutil.ReadFile(value[1:]); err != nil {
+			return nil, err
+		}
+	}
+
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(content, &metadata); err != nil {
+		return nil, fmt.Errorf("Invalid metadata, expected a JSON object: %v", err)
+	}
+	return metadata, nil
+}
+
+// resolveProposedID is a function to get the ID proposed by an -id flag, auto stands for a new UUIDv7
+func resolveProposedID(value string) (string, error) {
+	if value != "auto" {
+		return value, nil
+	}
+	return utils.NewUUIDv7()
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+	"github.com/SayedAlesawy/Videra-SDK/utils"
+)
+
+// usageCommand is a function responsible for the usage subcommands
+// usage local sums the uploads of the usage ledger of the state directory without asking the cluster
+func usageCommand(args []string) error {
+	if len(args) == 0 || args[0] != "local" {
+		return errors.New("Missing or unknown usage subcommand, expected local")
+	}
+
+	flags := flag.NewFlagSet("usage local", flag.ExitOnError)
+	groupBy := flags.String("group-by", "", "Comma separated groupings, tag:<name>, project, profile, filetype or day, e.g. tag:camera,day")
+	var since utils.Duration
+	flags.Var(&since, "since", "Only sum uploads completed within this duration, e.g. 7d, all of them by default")
+	output := flags.String("output", "human", "Format of the printed rollups, human or json")
+	flags.Usage = func() {
+		log.Println("Usage: usage local [-group-by tag:<name>,project,profile,filetype,day] [-since <duration>] [-output human|json]")
+		flags.PrintDefaults()
+	}
+	parseFlags(flags, args[1:])
+
+	if err := validateOutputFormat(*output); err != nil {
+		return err
+	}
+	var groupings []string
+	if *groupBy != "" {
+		groupings = strings.Split(*groupBy, ",")
+	}
+	from := time.Time{}
+	if since > 0 {
+		from = time.Now().Add(-time.Duration(since))
+	}
+
+	records, err := viderasdk.SDKInstance().UsageRecords(from)
+	if err != nil {
+		return err
+	}
+	rollups, err := viderasdk.RollupUsage(records, groupings)
+	if err != nil {
+		return err
+	}
+
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(rollups)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, grouping := range groupings {
+		fmt.Fprintf(writer, "%s\t", strings.ToUpper(grouping))
+	}
+	fmt.Fprintln(writer, "UPLOADS\tBYTES SENT\tDURATION\tLAST")
+	for _, rollup := range rollups {
+		for _, grouping := range groupings {
+			value := rollup.Group[grouping]
+			if value == "" {
+				value = "-"
+			}
+			fmt.Fprintf(writer, "%s\t", value)
+		}
+		fmt.Fprintf(writer, "%v\t%v\t%v\t%s\n", rollup.Uploads, utils.FormatSize(rollup.BytesSent), rollup.Duration.Round(time.Second),
+			rollup.Last.Local().Format(time.RFC3339))
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,7 @@
+//go:build cgo
+// +build cgo
+
+package utils
+
+// cgoEnabled Whether this binary was built with cgo, no integration needs it so CGO_ENABLED=0 builds lose nothing
+const cgoEnabled = true
@@ -0,0 +1,56 @@
+//go:build linux
+// +build linux
+
+package utils
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// cgroupsSupported Whether cgroup limits are read on this platform
+const cgroupsSupported = true
+
+// CgroupLimits is a function that returns the CPUs and bytes of memory the cgroup of the process may use
+// either is 0 when the cgroup doesn't limit it or no cgroup v1 or v2 controller is mounted
+func CgroupLimits() (float64, int64) {
+	cpus := float64(0)
+	if fields := strings.Fields(readCgroupFile("/sys/fs/cgroup/cpu.max")); len(fields) == 2 {
+		cpus = cpuQuota(fields[0], fields[1])
+	} else {
+		cpus = cpuQuota(readCgroupFile("/sys/fs/cgroup/cpu/cpu.cfs_quota_us"), readCgroupFile("/sys/fs/cgroup/cpu/cpu.cfs_period_us"))
+	}
+
+	memory, err := strconv.ParseInt(readCgroupFile("/sys/fs/cgroup/memory.max"), 10, 64)
+	if err != nil {
+		memory, _ = strconv.ParseInt(readCgroupFile("/sys/fs/cgroup/memory/memory.limit_in_bytes"), 10, 64)
+	}
+	// cgroup v1 reports no limit as a huge page aligned number
+	if memory >= 1<<62 {
+		memory = 0
+	}
+	return cpus, memory
+}
+
+// cpuQuota is a function to get the CPUs a quota of microseconds per period allows, 0 when unlimited
+func cpuQuota(quota string, period string) float64 {
+	quotaValue, err := strconv.ParseFloat(quota, 64)
+	if err != nil || quotaValue <= 0 {
+		return 0
+	}
+	periodValue, err := strconv.ParseFloat(period, 64)
+	if err != nil || periodValue <= 0 {
+		return 0
+	}
+	return quotaValue / periodValue
+}
+
+// readCgroupFile is a function to read a cgroup control file, empty when it doesn't exist
+func readCgroupFile(path string) string {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package utils
+
+// cgroupsSupported Whether cgroup limits are read on this platform
+const cgroupsSupported = false
+
+// CgroupLimits is a function that returns no limits on platforms without cgroups
+func CgroupLimits() (float64, int64) {
+	return 0, 0
+}
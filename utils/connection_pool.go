@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
+)
+
+// PoolOptions Describes the keep-alive connections kept to each host, i.e. to the master, each data node and each relay
+type PoolOptions struct {
+	MaxIdlePerHost int           //Idle connections kept per host, 0 keeps GOMAXPROCS+1
+	MaxPerHost     int           //Connections per host at most, idle and active, 0 leaves them unbounded
+	IdleTimeout    time.Duration //Time after which idle connections are closed, 0 keeps 90s
+}
+
+// ConnectionEvent Describes the connection a request was sent on
+type ConnectionEvent struct {
+	Host          string        //host:port the request was sent to
+	Reused        bool          //Whether the connection was kept alive from an earlier request
+	DialTime      time.Duration //Time spent connecting, 0 for reused connections
+	HandshakeTime time.Duration //Time spent in the TLS handshake, 0 for reused and plain connections
+}
+
+// HostConnectionStats Connection statistics of a host since the process started
+type HostConnectionStats struct {
+	Host          string        `json:"host"`               //host:port of the master, data node or relay
+	New           int64         `json:"new"`                //Requests sent on new connections
+	Reused        int64         `json:"reused"`             //Requests sent on kept alive connections
+	Handshakes    int64         `json:"handshakes"`         //TLS handshakes made
+	DialTime      time.Duration `json:"dial_time"`          //Total time spent connecting
+	HandshakeTime time.Duration `json:"handshake_time"`     //Total time spent in TLS handshakes
+	MaxHandshake  time.Duration `json:"max_handshake_time"` //Longest TLS handshake
+	LastUsedAt    time.Time     `json:"last_used_at"`       //Time of the last request
+}
+
+// poolMutex Guards the variables below
+var poolMutex sync.Mutex
+
+// currentPoolOptions Options pooledTransport was created with
+var currentPoolOptions PoolOptions
+
+// pooledTransport Transport shared by every client so connections to a host are kept alive across uploads
+var pooledTransport = newPooledTransport(currentPoolOptions)
+
+// connectionStats Statistics of each host keyed by host:port
+var connectionStats = make(map[string]*HostConnectionStats)
+
+// connectionObserver Called with the connection of each request, nil when unset
+var connectionObserver func(event ConnectionEvent)
+
+// newPooledTransport is a function that returns a transport keeping connections alive as described by options
+// connections are bound to the active interface set by SetInterfaces, except in the browser where fetch manages them
+func newPooledTransport(options PoolOptions) *http.Transport {
+	transport := cleanhttp.DefaultPooledTransport()
+	dial := transport.DialContext
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return dialRoute(ctx, network, addr, dial)
+	}
+	if fetchTransport {
+		transport.DialContext = nil
+	}
+	if options.MaxIdlePerHost > 0 {
+		transport.MaxIdleConnsPerHost = options.MaxIdlePerHost
+	}
+	transport.MaxConnsPerHost = options.MaxPerHost
+	if options.IdleTimeout > 0 {
+		transport.IdleConnTimeout = options.IdleTimeout
+	}
+	return transport
+}
+
+// SetPoolOptions is a function to change the connections kept to each host, the idle connections of the previous pool are closed
+func SetPoolOptions(options PoolOptions) {
+	poolMutex.Lock()
+	previous := pooledTransport
+	currentPoolOptions, pooledTransport = options, newPooledTransport(options)
+	poolMutex.Unlock()
+
+	previous.CloseIdleConnections()
+}
+
+// closeIdleConnections is a function responsible for closing the idle connections of the pool, new requests dial again
+func closeIdleConnections() {
+	poolMutex.Lock()
+	transport := pooledTransport
+	poolMutex.Unlock()
+
+	transport.CloseIdleConnections()
+}
+
+// CurrentPoolOptions is a function that returns the options of the connection pool in use
+func CurrentPoolOptions() PoolOptions {
+	poolMutex.Lock()
+	defer poolMutex.Unlock()
+
+	return currentPoolOptions
+}
+
+// SetConnectionObserver is a function to set the function called with the connection of each request, e.g. to report metrics
+func SetConnectionObserver(observer func(event ConnectionEvent)) {
+	poolMutex.Lock()
+	defer poolMutex.Unlock()
+
+	connectionObserver = observer
+}
+
+// ConnectionStats is a function that returns the connection statistics of each host sorted by host
+func ConnectionStats() []HostConnectionStats {
+	poolMutex.Lock()
+	defer poolMutex.Unlock()
+
+	stats := make([]HostConnectionStats, 0, len(connectionStats))
+	for _, hostStats := range connectionStats {
+		stats = append(stats, *hostStats)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Host < stats[j].Host })
+	return stats
+}
+
+// poolTransport Sends requests through the shared pooled transport, recording the connection each one was sent on
+type poolTransport struct{}
+
+// RoundTrip sends the request with a trace of its connection, traces already attached to the request keep working
+func (poolTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var mutex sync.Mutex
+	event := ConnectionEvent{Host: req.URL.Host}
+	var dialStart, handshakeStart time.Time
+	trace := &httptrace.ClientTrace{
+		ConnectStart: func(network, addr string) {
+			mutex.Lock()
+			dialStart = time.Now()
+			mutex.Unlock()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			mutex.Lock()
+			event.DialTime = time.Since(dialStart)
+			mutex.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			mutex.Lock()
+			handshakeStart = time.Now()
+			mutex.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			mutex.Lock()
+			event.HandshakeTime = time.Since(handshakeStart)
+			mutex.Unlock()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			mutex.Lock()
+			event.Reused = info.Reused
+			mutex.Unlock()
+		},
+	}
+
+	poolMutex.Lock()
+	transport := pooledTransport
+	poolMutex.Unlock()
+
+	res, err := transport.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+	mutex.Lock()
+	recordConnection(event)
+	mutex.Unlock()
+	// requests abandoned by the client, e.g. at a deadline, say nothing of the route
+	if err == nil {
+		routeSucceeded()
+	} else if req.Context().Err() == nil {
+		routeFailed()
+	}
+	return res, err
+}
+
+// recordConnection is a function responsible for adding the connection of a request to the statistics of its host
+func recordConnection(event ConnectionEvent) {
+	poolMutex.Lock()
+	hostStats, ok := connectionStats[event.Host]
+	if !ok {
+		hostStats = &HostConnectionStats{Host: event.Host}
+		connectionStats[event.Host] = hostStats
+	}
+	if event.Reused {
+		hostStats.Reused++
+	} else {
+		hostStats.New++
+	}
+	if event.HandshakeTime > 0 {
+		hostStats.Handshakes++
+		hostStats.HandshakeTime += event.HandshakeTime
+		if event.HandshakeTime > hostStats.MaxHandshake {
+			hostStats.MaxHandshake = event.HandshakeTime
+		}
+	}
+	hostStats.DialTime += event.DialTime
+	hostStats.LastUsedAt = time.Now().UTC()
+	observer := connectionObserver
+	poolMutex.Unlock()
+
+	if observer != nil {
+		observer(event)
+	}
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Encrypt is a function to encrypt plaintext with AES-256-GCM, the random nonce is prepended to the result
+func Encrypt(key []byte, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt is a function to decrypt data produced by Encrypt with the same key
+func Decrypt(key []byte, data []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("Encrypted data is too short")
+	}
+	return aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+}
+
+// PassphraseKey is a function to derive a 256 bit key from a passphrase with scrypt and salt
+// the parameters are those recommended for interactive use, deriving a key takes about 100ms
+func PassphraseKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+}
+
+// UnsaltedPassphraseKey is a function to derive the 256 bit key of a passphrase as a plain sha256 digest
+// only used to read data encrypted before keys were derived with PassphraseKey
+func UnsaltedPassphraseKey(passphrase string) []byte {
+	key := sha256.Sum256([]byte(passphrase))
+	return key[:]
+}
+
+// newAEAD is a function to create the AES-GCM cipher of a key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,9 @@
+//go:build windows || js || plan9
+// +build windows js plan9
+
+package utils
+
+// syncDir is a no-op on platforms that can't sync directories, renames there are as durable as the filesystem makes them
+func syncDir(dir string) error {
+	return nil
+}
@@ -0,0 +1,16 @@
+//go:build !windows && !js && !plan9
+// +build !windows,!js,!plan9
+
+package utils
+
+import "os"
+
+// syncDir is a function responsible for flushing the entries of a directory to disk, so a file renamed into it survives a crash
+func syncDir(dir string) error {
+	dirFile, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer dirFile.Close()
+	return dirFile.Sync()
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// Optional integrations of the client, each is either built into the binary by build tags or platform and then checked at runtime
+const (
+	FeatureCgo       = "cgo"              //Built with cgo, the default build is pure Go and cross compiles with CGO_ENABLED=0
+	FeatureFFmpeg    = "ffmpeg"           //Trimming, remuxing, scrubbing and segmenting videos, dropped by the noffmpeg tag
+	FeatureFFprobe   = "ffprobe"          //Probing keyframes and metadata of videos, dropped by the noffmpeg tag
+	FeatureReflink   = "reflink"          //Copy on write staging of local files with source_isolation reflink
+	FeatureSparse    = "sparse-files"     //Skipping holes of sparse files with SEEK_DATA/SEEK_HOLE
+	FeaturePageCache = "page-cache-hints" //posix_fadvise hints for read ahead and dropping uploaded pages
+	FeatureCgroups   = "cgroup-limits"    //Sizing concurrency and buffers by the CPU and memory limits of the cgroup
+)
+
+// ErrFeatureUnavailable Returned when an action needs an integration the current binary or host doesn't support
+var ErrFeatureUnavailable = errors.New("Feature unavailable")
+
+// Feature Describes an optional integration and whether the current binary supports it
+type Feature struct {
+	Name      string `json:"name"`             //One of the Feature constants
+	Compiled  bool   `json:"compiled"`         //Built into the binary, false when dropped by a build tag or unsupported by the platform
+	Available bool   `json:"available"`        //Usable at runtime, e.g. the binary it runs was found
+	Detail    string `json:"detail,omitempty"` //Why the feature is unavailable or where it was found
+}
+
+// Features is a function to list the optional integrations with what the current binary and host support of them
+func Features() []Feature {
+	platform := fmt.Sprintf("not supported on %s/%s", runtime.GOOS, runtime.GOARCH)
+	features := []Feature{
+		{Name: FeatureCgo, Compiled: cgoEnabled, Available: cgoEnabled, Detail: "pure Go build"},
+		execFeature(FeatureFFmpeg, FFmpegPath),
+		execFeature(FeatureFFprobe, FFprobePath()),
+		platformFeature(FeatureReflink, reflinkSupported, platform),
+		platformFeature(FeatureSparse, holesSupported, platform),
+		platformFeature(FeaturePageCache, pageCacheHints, platform),
+		platformFeature(FeatureCgroups, cgroupsSupported, platform),
+	}
+	if cgoEnabled {
+		features[0].Detail = ""
+	}
+	return features
+}
+
+// RequireFeature is a function to check an action needing the named integration can run with the current binary and host
+// ffmpeg and ffprobe fail with ErrFFmpegNotFound, everything else with ErrFeatureUnavailable
+func RequireFeature(name string) error {
+	for _, feature := range Features() {
+		if feature.Name != name {
+			continue
+		}
+		if feature.Available {
+			return nil
+		}
+		if name == FeatureFFmpeg || name == FeatureFFprobe {
+			return fmt.Errorf("%w: %s", ErrFFmpegNotFound, feature.Detail)
+		}
+		return fmt.Errorf("%w: %s %s", ErrFeatureUnavailable, name, feature.Detail)
+	}
+	return fmt.Errorf("%w: unknown feature %s", ErrFeatureUnavailable, name)
+}
+
+// execFeature is a function to describe an integration running an external binary
+func execFeature(name string, binary string) Feature {
+	if !ffmpegCompiled {
+		return Feature{Name: name, Detail: "dropped by the noffmpeg build tag"}
+	}
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return Feature{Name: name, Compiled: true, Detail: fmt.Sprintf("%s not found", binary)}
+	}
+	return Feature{Name: name, Compiled: true, Available: true, Detail: path}
+}
+
+// platformFeature is a function to describe an integration only some platforms support
+func platformFeature(name string, supported bool, platform string) Feature {
+	if !supported {
+		return Feature{Name: name, Detail: platform}
+	}
+	return Feature{Name: name, Compiled: true, Available: true}
+}
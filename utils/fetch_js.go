@@ -0,0 +1,7 @@
+//go:build js && wasm
+// +build js,wasm
+
+package utils
+
+// fetchTransport Whether requests are sent with the fetch API of the browser, net/http only uses it for transports without dial functions
+const fetchTransport = true
@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// FFmpegPath Path of the ffmpeg binary used to process videos before upload
+var FFmpegPath = "ffmpeg"
+
+// ErrFFmpegNotFound Returned when a video must be processed but ffmpeg isn't installed
+var ErrFFmpegNotFound = errors.New("Video processing requires ffmpeg, install it or set ffmpeg_path in the config")
+
+// FFprobePath is a function to get the path of the ffprobe binary installed alongside ffmpeg
+func FFprobePath() string {
+	dir, file := filepath.Split(FFmpegPath)
+	return dir + strings.Replace(file, "ffmpeg", "ffprobe", 1)
+}
@@ -0,0 +1,40 @@
+//go:build noffmpeg
+// +build noffmpeg
+
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+// ffmpegCompiled Whether the ffmpeg integration is built into this binary, the noffmpeg build tag drops it
+const ffmpegCompiled = false
+
+// errFFmpegNotCompiled Returned by every ffmpeg and ffprobe call of binaries built with the noffmpeg tag
+var errFFmpegNotCompiled = fmt.Errorf("%w: this binary was built with the noffmpeg tag", ErrFFmpegNotFound)
+
+// RunFFmpeg is a function that fails as the ffmpeg integration isn't built into this binary
+func RunFFmpeg(args ...string) error {
+	return errFFmpegNotCompiled
+}
+
+// KeyframeAt is a function that fails as the ffprobe integration isn't built into this binary
+func KeyframeAt(input string, offset time.Duration) (bool, error) {
+	return false, errFFmpegNotCompiled
+}
+
+// ProbeMetadata is a function that fails as the ffprobe integration isn't built into this binary
+func ProbeMetadata(input string) (map[string]string, error) {
+	return nil, errFFmpegNotCompiled
+}
+
+// ProbeDuration is a function that fails as the ffprobe integration isn't built into this binary
+func ProbeDuration(input string) (time.Duration, error) {
+	return 0, errFFmpegNotCompiled
+}
+
+// DecodeSample is a function that fails as the ffmpeg integration isn't built into this binary
+func DecodeSample(input string, offset time.Duration, frames int) error {
+	return errFFmpegNotCompiled
+}
@@ -0,0 +1,103 @@
+//go:build !noffmpeg
+// +build !noffmpeg
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ffmpegCompiled Whether the ffmpeg integration is built into this binary, the noffmpeg build tag drops it
+const ffmpegCompiled = true
+
+// RunFFmpeg is a function responsible for running ffmpeg with the given arguments
+// the output of ffmpeg is included in the returned error when it fails
+func RunFFmpeg(args ...string) error {
+	if _, err := exec.LookPath(FFmpegPath); err != nil {
+		return ErrFFmpegNotFound
+	}
+
+	cmd := exec.Command(FFmpegPath, append([]string{"-hide_banner", "-loglevel", "error", "-y"}, args...)...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ffmpeg failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	Debugln(fmt.Sprintf("ffmpeg %s", strings.Join(args, " ")))
+	return nil
+}
+
+// KeyframeAt is a function to check whether the first video keyframe at or after offset is exactly at offset
+func KeyframeAt(input string, offset time.Duration) (bool, error) {
+	cmd := exec.Command(FFprobePath(), "-v", "error", "-select_streams", "v:0", "-skip_frame", "nokey",
+		"-read_intervals", FormatTimecode(offset)+"%+#1", "-show_entries", "frame=pts_time", "-of", "csv=p=0", input)
+	output, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("ffprobe failed: %v", err)
+	}
+
+	keyframe, err := strconv.ParseFloat(strings.TrimSpace(strings.Split(string(output), "\n")[0]), 64)
+	if err != nil {
+		return false, nil
+	}
+	return math.Abs(keyframe-offset.Seconds()) < 0.001, nil
+}
+
+// ProbeMetadata is a function to get the container and stream metadata tags of a video
+// keys are prefixed with format: for container tags and stream<index>: for stream tags
+func ProbeMetadata(input string) (map[string]string, error) {
+	cmd := exec.Command(FFprobePath(), "-v", "error", "-show_entries", "format_tags:stream_tags", "-of", "json", input)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %v", err)
+	}
+
+	var probe struct {
+		Format struct {
+			Tags map[string]string `json:"tags"`
+		} `json:"format"`
+		Streams []struct {
+			Tags map[string]string `json:"tags"`
+		} `json:"streams"`
+	}
+	if err = json.Unmarshal(output, &probe); err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string)
+	for key, val := range probe.Format.Tags {
+		tags["format:"+key] = val
+	}
+	for idx, stream := range probe.Streams {
+		for key, val := range stream.Tags {
+			tags[fmt.Sprintf("stream%v:%s", idx, key)] = val
+		}
+	}
+	return tags, nil
+}
+
+// ProbeDuration is a function to get the duration of a video as its container declares it, failing when ffprobe can't parse the container
+func ProbeDuration(input string) (time.Duration, error) {
+	cmd := exec.Command(FFprobePath(), "-v", "error", "-show_entries", "format=duration", "-of", "csv=p=0", input)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("ffprobe failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(string(output)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("ffprobe found no duration: %s", strings.TrimSpace(string(output)))
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// DecodeSample is a function to decode frames of the first video stream from offset on, failing at the first decoding error
+func DecodeSample(input string, offset time.Duration, frames int) error {
+	return RunFFmpeg("-xerror", "-ss", FormatTimecode(offset), "-i", input, "-map", "0:v:0",
+		"-frames:v", strconv.Itoa(frames), "-f", "null", "-")
+}
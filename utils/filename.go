@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// unnamedFile Name given to files whose name is empty once sanitized
+const unnamedFile = "unnamed"
+
+// SanitizeFilename is a function to make a file name safe to send in headers
+// the name is NFC normalized, invalid UTF-8, control and invisible formatting characters are dropped, surrounding
+// spaces and dots are trimmed and names longer than maxLength bytes are shortened keeping their extension, 0 keeps any length
+func SanitizeFilename(name string, maxLength int) string {
+	name = norm.NFC.String(strings.ToValidUTF8(name, ""))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		if r == '/' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.Trim(name, " .")
+
+	if maxLength > 0 && len(name) > maxLength {
+		ext := path.Ext(name)
+		if len(ext) >= maxLength {
+			ext = ""
+		}
+		name = truncateUTF8(strings.TrimSuffix(name, ext), maxLength-len(ext)) + ext
+	}
+	if strings.Trim(name, " .") == "" {
+		return unnamedFile
+	}
+	return name
+}
+
+// truncateUTF8 is a function to cut a string to at most length bytes without splitting a character
+func truncateUTF8(s string, length int) string {
+	if len(s) <= length {
+		return s
+	}
+	for length > 0 && !utf8.RuneStart(s[length]) {
+		length--
+	}
+	return s[:length]
+}
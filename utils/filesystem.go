@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"io/fs"
+	"os"
+)
+
+// OSFileSystem An fs.FS backed by the operating system filesystem
+// unlike os.DirFS it accepts any path os.Open does, including absolute and windows paths
+type OSFileSystem struct{}
+
+// Open opens the named file with os.Open
+func (OSFileSystem) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+// Stat returns the file info of the named file with os.Stat
+func (OSFileSystem) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// ReadFile reads the named file with os.ReadFile
+func (OSFileSystem) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrUnknownInterface Returned when a network interface connections are bound to doesn't exist on the host
+var ErrUnknownInterface = errors.New("Unknown network interface")
+
+// primaryRetryAfter Time after a failover at which new connections try the preferred interface again
+const primaryRetryAfter = 5 * time.Minute
+
+// routeMutex Guards the variables below
+var routeMutex sync.Mutex
+
+// routeInterfaces Interfaces connections are bound to in preference order, empty leaves the choice to the routing table
+var routeInterfaces []string
+
+// routeActive Index of the interface new connections are bound to
+var routeActive int
+
+// routeFailures Consecutive failures reported on the active interface
+var routeFailures int
+
+// routeFailoverAfter Consecutive failures after which connections move to the next interface
+var routeFailoverAfter = 1
+
+// routeFailedOverAt Time of the last failover
+var routeFailedOverAt time.Time
+
+// routeObserver Called on each failover, nil when unset
+var routeObserver func(from string, to string)
+
+// SetInterfaces is a function to bind new connections to the first of the given network interfaces, e.g. eth0 then wwan0
+// connections move to the next interface after failoverAfter consecutive requests failed without a response, idle connections are closed
+func SetInterfaces(interfaces []string, failoverAfter int) error {
+	for _, name := range interfaces {
+		if _, err := net.InterfaceByName(name); err != nil {
+			return fmt.Errorf("%w: %s", ErrUnknownInterface, name)
+		}
+	}
+
+	routeMutex.Lock()
+	routeInterfaces, routeActive, routeFailures = interfaces, 0, 0
+	routeFailoverAfter = failoverAfter
+	if routeFailoverAfter < 1 {
+		routeFailoverAfter = 1
+	}
+	routeMutex.Unlock()
+
+	closeIdleConnections()
+	return nil
+}
+
+// ActiveInterface is a function that returns the interface new connections are bound to, empty when they aren't bound
+// the preferred interface is tried again primaryRetryAfter a failover
+func ActiveInterface() string {
+	routeMutex.Lock()
+	defer routeMutex.Unlock()
+
+	if len(routeInterfaces) == 0 {
+		return ""
+	}
+	if routeActive != 0 && time.Since(routeFailedOverAt) > primaryRetryAfter {
+		Debugln(fmt.Sprintf("Trying preferred interface %s again", routeInterfaces[0]))
+		routeActive, routeFailures = 0, 0
+	}
+	return routeInterfaces[routeActive]
+}
+
+// routeFailed is a function responsible for counting a failed request towards failing over to the next interface
+// the route observer is called with the interfaces connections moved from and to when it caused a failover
+func routeFailed() {
+	routeMutex.Lock()
+	if len(routeInterfaces) < 2 {
+		routeMutex.Unlock()
+		return
+	}
+	routeFailures++
+	if routeFailures < routeFailoverAfter {
+		routeMutex.Unlock()
+		return
+	}
+
+	from := routeInterfaces[routeActive]
+	routeActive, routeFailures = (routeActive+1)%len(routeInterfaces), 0
+	routeFailedOverAt = time.Now()
+	to := routeInterfaces[routeActive]
+	observer := routeObserver
+	routeMutex.Unlock()
+
+	// connections kept alive on the failing interface must not be reused
+	closeIdleConnections()
+	if observer != nil {
+		observer(from, to)
+	}
+}
+
+// routeSucceeded is a function responsible for resetting the failures counted on the active interface
+func routeSucceeded() {
+	routeMutex.Lock()
+	routeFailures = 0
+	routeMutex.Unlock()
+}
+
+// SetRouteObserver is a function to set the function called with the interfaces connections moved from and to on each failover
+func SetRouteObserver(observer func(from string, to string)) {
+	routeMutex.Lock()
+	defer routeMutex.Unlock()
+
+	routeObserver = observer
+}
+
+// dialRoute is a function to connect to addr from an address of the active interface, or through dial when no interface is set
+func dialRoute(ctx context.Context, network string, addr string, dial func(ctx context.Context, network, addr string) (net.Conn, error)) (net.Conn, error) {
+	name := ActiveInterface()
+	if name == "" {
+		return dial(ctx, network, addr)
+	}
+
+	localAddr, err := interfaceAddr(name)
+	if err != nil {
+		return nil, err
+	}
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, LocalAddr: localAddr}
+	return dialer.DialContext(ctx, network, addr)
+}
+
+// interfaceAddr is a function to get the local address connections bound to an interface are made from
+// IPv4 is preferred, link local addresses are skipped as they can't reach the cluster
+func interfaceAddr(name string) (net.Addr, error) {
+	netInterface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownInterface, name)
+	}
+	addrs, err := netInterface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+
+	var fallback net.IP
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if ipNet.IP.To4() != nil {
+			return &net.TCPAddr{IP: ipNet.IP}, nil
+		}
+		if fallback == nil {
+			fallback = ipNet.IP
+		}
+	}
+	if fallback == nil {
+		return nil, fmt.Errorf("Interface %s has no usable address", name)
+	}
+	return &net.TCPAddr{IP: fallback}, nil
+}
@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// debugLogging Whether debug level messages are logged
+var debugLogging bool
+
+// defaultWarningSampler Sampler used by Warnln
+var defaultWarningSampler = NewWarningSampler(time.Minute)
+
+// SetDebugLogging is a function to enable or disable debug level messages
+func SetDebugLogging(enabled bool) {
+	debugLogging = enabled
+}
+
+// Debugln is a function to log its arguments like log.Println, only when debug logging is enabled
+func Debugln(v ...interface{}) {
+	if debugLogging {
+		log.Println(append([]interface{}{"[DEBUG]"}, v...)...)
+	}
+}
+
+// Warnln is a function to log a warning like log.Println, identical warnings repeated within a minute are sampled
+func Warnln(v ...interface{}) {
+	defaultWarningSampler.Println(append([]interface{}{"[WARN]"}, v...)...)
+}
+
+// LogOptions Describes where and how logs are written
+type LogOptions struct {
+	File       string        //Path of the log file, logs go to stderr when empty
+	MaxSize    int64         //Size in bytes after which the log file is rotated, 0 disables rotation
+	MaxBackups int           //Number of rotated log files to keep
+	MaxAge     time.Duration //Age after which rotated log files are removed, 0 keeps them regardless of age
+	JSON       bool          //Write every log line as a JSON object
+}
+
+// logFileMutex Guards logFile
+var logFileMutex sync.Mutex
+
+// logFile Log file the standard logger writes to, nil when it writes to stderr
+var logFile *RotatingFile
+
+// SetupLogging is a function to direct the standard logger to a rotating file and/or JSON formatting
+// the log file of a previous setup is closed once the logger moved to the new output
+func SetupLogging(options LogOptions) error {
+	logFileMutex.Lock()
+	defer logFileMutex.Unlock()
+
+	var output io.Writer = os.Stderr
+	var rotatingFile *RotatingFile
+	if options.File != "" {
+		var err error
+		if rotatingFile, err = OpenRotatingFile(options.File, options.MaxSize, options.MaxBackups, options.MaxAge); err != nil {
+			return err
+		}
+		output = rotatingFile
+	}
+
+	if options.JSON {
+		log.SetFlags(0)
+		log.SetOutput(jsonLogWriter{output: output})
+	} else {
+		log.SetFlags(log.LstdFlags)
+		log.SetOutput(output)
+	}
+
+	previous := logFile
+	logFile = rotatingFile
+	if previous != nil {
+		return previous.Close()
+	}
+	return nil
+}
+
+// jsonLogWriter Rewrites each line of the standard logger as a JSON object for log shippers
+// a leading level tag such as [WARN] is moved to the level field, untagged lines are info
+type jsonLogWriter struct {
+	output io.Writer //Writer receiving the JSON lines
+}
+
+// jsonLogLevels Level field of each level tag
+var jsonLogLevels = map[string]string{"DEBUG": "debug", "WARN": "warn", "ERR": "error", "ERROR": "error"}
+
+// Write writes a log line as a JSON object
+func (writer jsonLogWriter) Write(p []byte) (int, error) {
+	message := strings.TrimSuffix(string(p), "\n")
+	level := "info"
+	if strings.HasPrefix(message, "[") {
+		if end := strings.Index(message, "] "); end != -1 {
+			if jsonLevel, ok := jsonLogLevels[message[1:end]]; ok {
+				level, message = jsonLevel, message[end+2:]
+			}
+		}
+	}
+
+	line, err := json.Marshal(map[string]string{
+		"time":    time.Now().UTC().Format(time.RFC3339Nano),
+		"level":   level,
+		"message": message,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err = writer.output.Write(append(line, '\n')); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WarningSampler Logs the first of identical warnings and how many were suppressed within a time window
+type WarningSampler struct {
+	mutex    sync.Mutex                 //Guards the warnings map
+	window   time.Duration              //Time during which identical warnings are suppressed
+	warnings map[string]*sampledWarning //State of each distinct warning message
+}
+
+// sampledWarning State of a distinct warning message
+type sampledWarning struct {
+	loggedAt   time.Time //Last time the warning was logged
+	suppressed int       //Number of occurrences suppressed since then
+}
+
+// NewWarningSampler is a function that returns a sampler suppressing identical warnings within window
+func NewWarningSampler(window time.Duration) *WarningSampler {
+	return &WarningSampler{window: window, warnings: make(map[string]*sampledWarning)}
+}
+
+// Println logs its arguments unless an identical warning was logged within the window
+func (sampler *WarningSampler) Println(v ...interface{}) {
+	message := strings.TrimSuffix(fmt.Sprintln(v...), "\n")
+	now := time.Now()
+
+	sampler.mutex.Lock()
+	defer sampler.mutex.Unlock()
+
+	warning, ok := sampler.warnings[message]
+	if !ok {
+		sampler.warnings[message] = &sampledWarning{loggedAt: now}
+		log.Println(message)
+		return
+	}
+
+	if now.Sub(warning.loggedAt) < sampler.window {
+		warning.suppressed++
+		return
+	}
+
+	if warning.suppressed > 0 {
+		log.Println(fmt.Sprintf("%s (repeated %v times)", message, warning.suppressed+1))
+	} else {
+		log.Println(message)
+	}
+	warning.loggedAt, warning.suppressed = now, 0
+}
+
+// retryLogger Routes retryablehttp logs, request traces go to debug level and failures to sampled warnings
+type retryLogger struct{}
+
+// Printf logs a retryablehttp message
+func (retryLogger) Printf(format string, v ...interface{}) {
+	message := fmt.Sprintf(format, v...)
+	if strings.HasPrefix(message, "[DEBUG]") {
+		if debugLogging {
+			log.Println(message)
+		}
+		return
+	}
+	defaultWarningSampler.Println(message)
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSetupLoggingReplacesLogFile checks setting up logging again moves the logger to the new output and closes the previous log file
+func TestSetupLoggingReplacesLogFile(t *testing.T) {
+	defer SetupLogging(LogOptions{})
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.log"), filepath.Join(dir, "second.log")
+
+	if err := SetupLogging(LogOptions{File: first}); err != nil {
+		t.Fatal(err)
+	}
+	previous := logFile
+	log.Println("to the first file")
+	if err := SetupLogging(LogOptions{File: second, JSON: true}); err != nil {
+		t.Fatal(err)
+	}
+	log.Println("to the second file")
+
+	if _, err := previous.Write([]byte("after close\n")); err == nil {
+		t.Errorf("previous log file still open")
+	}
+	firstContent, _ := ioutil.ReadFile(first)
+	secondContent, _ := ioutil.ReadFile(second)
+	if !strings.Contains(string(firstContent), "to the first file") || strings.Contains(string(firstContent), "second") {
+		t.Errorf("first log file holds %q", firstContent)
+	}
+	if !strings.Contains(string(secondContent), `"message":"to the second file"`) {
+		t.Errorf("second log file holds %q", secondContent)
+	}
+
+	if err := SetupLogging(LogOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if logFile != nil {
+		t.Errorf("log file kept after logging moved to stderr")
+	}
+}
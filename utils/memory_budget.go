@@ -0,0 +1,84 @@
+package utils
+
+import "sync"
+
+// MemoryBudget Bytes of memory buffers may hold at most, shared by every user of the budget
+// a nil budget is unlimited
+type MemoryBudget struct {
+	limit int64      //Bytes the buffers may hold at most
+	inUse int64      //Bytes held
+	mutex sync.Mutex //Guards inUse
+	freed *sync.Cond //Signaled when bytes are released
+}
+
+// NewMemoryBudget is a function that returns a budget of limit bytes, nil when limit isn't positive
+func NewMemoryBudget(limit int64) *MemoryBudget {
+	if limit <= 0 {
+		return nil
+	}
+
+	budget := &MemoryBudget{limit: limit}
+	budget.freed = sync.NewCond(&budget.mutex)
+	return budget
+}
+
+// Acquire blocks until size bytes fit in the budget and holds them, Release returns them
+// a size larger than the whole budget is held alone once nothing else is
+func (budget *MemoryBudget) Acquire(size int64) {
+	if budget == nil {
+		return
+	}
+
+	budget.mutex.Lock()
+	defer budget.mutex.Unlock()
+
+	if size > budget.limit {
+		size = budget.limit
+	}
+	for budget.inUse+size > budget.limit {
+		budget.freed.Wait()
+	}
+	budget.inUse += size
+}
+
+// TryAcquire holds up to size bytes without blocking, it returns the bytes held which are fewer when the budget is short of size
+func (budget *MemoryBudget) TryAcquire(size int64) int64 {
+	if budget == nil {
+		return size
+	}
+
+	budget.mutex.Lock()
+	defer budget.mutex.Unlock()
+
+	if available := budget.limit - budget.inUse; size > available {
+		size = available
+	}
+	budget.inUse += size
+	return size
+}
+
+// Release returns size bytes held by Acquire or TryAcquire to the budget
+func (budget *MemoryBudget) Release(size int64) {
+	if budget == nil || size <= 0 {
+		return
+	}
+
+	budget.mutex.Lock()
+	if size > budget.limit {
+		size = budget.limit
+	}
+	budget.inUse -= size
+	budget.mutex.Unlock()
+	budget.freed.Broadcast()
+}
+
+// InUse is a function that returns the bytes held
+func (budget *MemoryBudget) InUse() int64 {
+	if budget == nil {
+		return 0
+	}
+
+	budget.mutex.Lock()
+	defer budget.mutex.Unlock()
+	return budget.inUse
+}
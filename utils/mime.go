@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// SniffLength Number of leading bytes SniffContentType looks at
+const SniffLength = 512
+
+// videoSignature Magic bytes identifying a video container
+type videoSignature struct {
+	offset      int    //Offset of the magic bytes
+	magic       []byte //Magic bytes
+	contentType string //MIME type of the container
+}
+
+// videoSignatures Magic bytes of the video containers http.DetectContentType doesn't know
+var videoSignatures = []videoSignature{
+	{4, []byte("ftypqt"), "video/quicktime"},
+	{4, []byte("ftyp3gp"), "video/3gpp"},
+	{4, []byte("ftyp"), "video/mp4"},
+	{0, []byte{0x1A, 0x45, 0xDF, 0xA3}, "video/x-matroska"},
+	{8, []byte("AVI "), "video/x-msvideo"},
+	{0, []byte("FLV"), "video/x-flv"},
+	{0, []byte{0x30, 0x26, 0xB2, 0x75, 0x8E, 0x66, 0xCF, 0x11}, "video/x-ms-asf"},
+	{0, []byte{0x00, 0x00, 0x01, 0xBA}, "video/mpeg"},
+}
+
+// mpegTSPacketSize Size of an MPEG transport stream packet, each starting with a sync byte
+const mpegTSPacketSize = 188
+
+// SniffContentType is a function to detect the MIME type of content from its leading bytes
+// video containers are recognized by their magic bytes, other content by http.DetectContentType
+func SniffContentType(header []byte) string {
+	for _, signature := range videoSignatures {
+		end := signature.offset + len(signature.magic)
+		if len(header) >= end && bytes.Equal(header[signature.offset:end], signature.magic) {
+			if signature.contentType == "video/x-matroska" && bytes.Contains(header, []byte("webm")) {
+				return "video/webm"
+			}
+			return signature.contentType
+		}
+	}
+	if len(header) > mpegTSPacketSize && header[0] == 0x47 && header[mpegTSPacketSize] == 0x47 {
+		return "video/mp2t"
+	}
+
+	return http.DetectContentType(header)
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Types of networks told apart by NetworkType
+const (
+	NetworkEthernet = "ethernet" //Wired, and anything not recognized as another type
+	NetworkWifi     = "wifi"     //Wireless LAN
+	NetworkCellular = "cellular" //Mobile broadband such as LTE, usually metered
+	NetworkLoopback = "loopback" //The host itself
+)
+
+// cellularPrefixes Name prefixes of mobile broadband interfaces across linux modem drivers, macOS and BSD
+var cellularPrefixes = []string{"wwan", "wwp", "rmnet", "ppp", "usb", "pdp_ip", "ccmni", "cdc-wdm"}
+
+// wifiPrefixes Name prefixes of wireless LAN interfaces
+var wifiPrefixes = []string{"wlan", "wlp", "wlx", "wifi", "ath", "ra"}
+
+// NetworkType is a function to get the type of network behind an interface from its name and, on linux, its sysfs entry
+func NetworkType(name string) string {
+	netInterface, err := net.InterfaceByName(name)
+	if err == nil && netInterface.Flags&net.FlagLoopback != 0 {
+		return NetworkLoopback
+	}
+	if _, err = os.Stat(filepath.Join("/sys/class/net", name, "wireless")); err == nil {
+		return NetworkWifi
+	}
+	for _, prefix := range cellularPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return NetworkCellular
+		}
+	}
+	for _, prefix := range wifiPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return NetworkWifi
+		}
+	}
+	return NetworkEthernet
+}
+
+// RouteInterface is a function to get the interface connections to host go through, the active interface of SetInterfaces when set
+// otherwise the one the routing table picks, found by connecting a UDP socket which sends nothing
+func RouteInterface(host string) (string, error) {
+	if name := ActiveInterface(); name != "" {
+		return name, nil
+	}
+
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "80")
+	}
+	conn, err := net.Dial("udp", host)
+	if err != nil {
+		return "", err
+	}
+	localIP := conn.LocalAddr().(*net.UDPAddr).IP
+	conn.Close()
+
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return "", err
+	}
+	for _, netInterface := range interfaces {
+		addrs, _ := netInterface.Addrs()
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(localIP) {
+				return netInterface.Name, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("No interface has address %s", localIP)
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"io"
+	"os"
+)
+
+// uncachedFileReader Reads a file sequentially, asking the kernel to drop the pages already read from the page cache
+// so that uploading a very large file doesn't evict the working set of other processes
+type uncachedFileReader struct {
+	file      *os.File //File being read
+	offset    int64    //Offset of the next byte read
+	dropped   int64    //Offset up to which pages were dropped
+	dropEvery int64    //Number of bytes read between two drops
+}
+
+// NewUncachedFileReader is a function that returns a reader of file, positioned at offset,
+// that keeps the pages it read out of the page cache where the platform supports it
+func NewUncachedFileReader(file *os.File, offset int64) io.ReadCloser {
+	adviseSequential(file)
+	return &uncachedFileReader{file: file, offset: offset, dropped: offset, dropEvery: 8 << 20}
+}
+
+// Read reads from the file, dropping the pages read once enough accumulated
+func (reader *uncachedFileReader) Read(p []byte) (int, error) {
+	n, err := reader.file.Read(p)
+	reader.offset += int64(n)
+	if reader.offset-reader.dropped >= reader.dropEvery || (err != nil && reader.offset > reader.dropped) {
+		dropPageCache(reader.file, reader.dropped, reader.offset-reader.dropped)
+		reader.dropped = reader.offset
+	}
+	return n, err
+}
+
+// Close drops the remaining pages read and closes the file
+func (reader *uncachedFileReader) Close() error {
+	if reader.offset > reader.dropped {
+		dropPageCache(reader.file, reader.dropped, reader.offset-reader.dropped)
+	}
+	return reader.file.Close()
+}
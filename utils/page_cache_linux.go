@@ -0,0 +1,29 @@
+//go:build linux && (amd64 || arm64)
+// +build linux
+// +build amd64 arm64
+
+package utils
+
+import (
+	"os"
+	"syscall"
+)
+
+// pageCacheHints Whether posix_fadvise hints are given to the kernel on this platform
+const pageCacheHints = true
+
+// posix_fadvise advice values on linux
+const (
+	fadviseSequential = 2
+	fadviseDontNeed   = 4
+)
+
+// adviseSequential is a function to tell the kernel a file is read sequentially so it reads ahead aggressively
+func adviseSequential(file *os.File) {
+	syscall.Syscall6(syscall.SYS_FADVISE64, file.Fd(), 0, 0, fadviseSequential, 0, 0)
+}
+
+// dropPageCache is a function to tell the kernel the pages of a file range won't be needed again
+func dropPageCache(file *os.File, offset int64, length int64) {
+	syscall.Syscall6(syscall.SYS_FADVISE64, file.Fd(), uintptr(offset), uintptr(length), fadviseDontNeed, 0, 0)
+}
@@ -0,0 +1,15 @@
+//go:build !linux || !(amd64 || arm64)
+// +build !linux !amd64,!arm64
+
+package utils
+
+import "os"
+
+// pageCacheHints Whether posix_fadvise hints are given to the kernel on this platform
+const pageCacheHints = false
+
+// adviseSequential is a no-op on platforms without posix_fadvise support
+func adviseSequential(file *os.File) {}
+
+// dropPageCache is a no-op on platforms without posix_fadvise support
+func dropPageCache(file *os.File, offset int64, length int64) {}
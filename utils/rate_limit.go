@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"io"
+	"time"
+)
+
+// rateLimitedReader Reads from the underlying reader no faster than a rate, sleeping once reads run ahead of it
+type rateLimitedReader struct {
+	source io.ReadCloser //Underlying reader
+	rateOf func() int64  //Bytes per second, asked before every read so the rate may change while reading, 0 is unlimited
+	rate   int64         //Rate the bytes read since start were paced at
+	start  time.Time     //Time of the first read at rate
+	read   int64         //Bytes read since start
+}
+
+// NewRateLimitedReader is a function that returns a reader of source delivering at most rate bytes per second on average
+func NewRateLimitedReader(source io.ReadCloser, rate int64) io.ReadCloser {
+	return NewShapedReader(source, func() int64 { return rate })
+}
+
+// NewShapedReader is a function that returns a reader of source delivering at most the bytes per second rate returns on average
+// rate is asked before every read, pacing starts over whenever it changes and a rate of 0 reads unlimited
+func NewShapedReader(source io.ReadCloser, rate func() int64) io.ReadCloser {
+	return &rateLimitedReader{source: source, rateOf: rate}
+}
+
+// Read reads from the underlying reader, waiting first when the bytes read so far are ahead of the rate
+func (reader *rateLimitedReader) Read(p []byte) (int, error) {
+	if rate := reader.rateOf(); rate != reader.rate || reader.start.IsZero() {
+		reader.rate, reader.start, reader.read = rate, time.Now(), 0
+	}
+	if reader.rate <= 0 {
+		return reader.source.Read(p)
+	}
+	// reads are at most a second worth of bytes so the rate holds over short spans too
+	if int64(len(p)) > reader.rate {
+		p = p[:reader.rate]
+	}
+
+	due := reader.start.Add(time.Duration(float64(reader.read) / float64(reader.rate) * float64(time.Second)))
+	if wait := time.Until(due); wait > 0 {
+		time.Sleep(wait)
+	}
+
+	n, err := reader.source.Read(p)
+	reader.read += int64(n)
+	return n, err
+}
+
+// Close closes the underlying reader
+func (reader *rateLimitedReader) Close() error {
+	return reader.source.Close()
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"io"
+	"sync"
+)
+
+// ReadAheadBlockSize Size of the blocks a read ahead reader fills in the background
+const ReadAheadBlockSize = 1 << 20
+
+// readAheadBlock A block read ahead of the consumer along with the error that ended it, if any
+type readAheadBlock struct {
+	data []byte //Bytes read
+	err  error  //Error returned by the underlying reader after data, nil if more blocks follow
+}
+
+// readAheadReader Reads sequentially from the underlying reader in the background, ahead of its consumer
+// so that the disk streams large sequential reads instead of seeking between small ones
+type readAheadReader struct {
+	source    io.ReadCloser       //Underlying reader
+	blockSize int                 //Size of the blocks read
+	onDepth   func(depth int)     //Called with the number of blocks buffered when it changes, may be nil
+	blocks    chan readAheadBlock //Blocks read but not consumed yet
+	current   []byte              //Unconsumed part of the block being consumed
+	err       error               //Error returned once current is consumed
+	done      chan struct{}       //Closed to stop the background reads
+	stopped   chan struct{}       //Closed once the background reads stopped
+	closeOnce sync.Once           //Guards closing done
+}
+
+// NewReadAheadReader is a function that returns a reader keeping up to size bytes of source read ahead
+// closing the returned reader stops the background reads and closes source
+func NewReadAheadReader(source io.ReadCloser, size int64) io.ReadCloser {
+	return NewBoundedReader(source, ReadAheadBlockSize, int(size/ReadAheadBlockSize), nil)
+}
+
+// NewBoundedReader is a function that returns a reader keeping up to blocks blocks of blockSize bytes of source read ahead
+// reading source pauses while all of them wait to be consumed, so a slow consumer never makes it buffer more
+// onDepth, when not nil, is called with the number of blocks buffered each time it changes
+func NewBoundedReader(source io.ReadCloser, blockSize int, blocks int, onDepth func(depth int)) io.ReadCloser {
+	if blocks < 1 {
+		blocks = 1
+	}
+
+	reader := &readAheadReader{
+		source:    source,
+		blockSize: blockSize,
+		onDepth:   onDepth,
+		blocks:    make(chan readAheadBlock, blocks),
+		done:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+	go reader.fill()
+	return reader
+}
+
+// reportDepth reports the number of blocks buffered
+func (reader *readAheadReader) reportDepth() {
+	if reader.onDepth != nil {
+		reader.onDepth(len(reader.blocks))
+	}
+}
+
+// fill reads blocks from the underlying reader until it fails or the reader is closed
+func (reader *readAheadReader) fill() {
+	defer close(reader.stopped)
+	for {
+		block := make([]byte, reader.blockSize)
+		n, err := io.ReadFull(reader.source, block)
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+
+		select {
+		case reader.blocks <- readAheadBlock{data: block[:n], err: err}:
+			reader.reportDepth()
+		case <-reader.done:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Read reads from the blocks read ahead, waiting for the next one when all were consumed
+func (reader *readAheadReader) Read(p []byte) (int, error) {
+	for len(reader.current) == 0 {
+		if reader.err != nil {
+			return 0, reader.err
+		}
+		block := <-reader.blocks
+		reader.reportDepth()
+		reader.current, reader.err = block.data, block.err
+	}
+
+	n := copy(p, reader.current)
+	reader.current = reader.current[n:]
+	return n, nil
+}
+
+// Close stops the background reads and closes the underlying reader
+func (reader *readAheadReader) Close() error {
+	reader.closeOnce.Do(func() { close(reader.done) })
+	<-reader.stopped
+	return reader.source.Close()
+}
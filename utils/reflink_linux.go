@@ -0,0 +1,36 @@
+//go:build linux
+// +build linux
+
+package utils
+
+import (
+	"os"
+	"syscall"
+)
+
+// reflinkSupported Whether reflinks may be created on this platform, the filesystem has the final say
+const reflinkSupported = true
+
+// ficlone FICLONE ioctl request sharing the extents of a file with another on copy on write filesystems such as btrfs or xfs
+const ficlone = 0x40049409
+
+// CloneFile is a function to create dst as a copy on write clone of src, failing on filesystems without reflink support
+func CloneFile(src string, dst string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	dstFile, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, dstFile.Fd(), ficlone, srcFile.Fd())
+	dstFile.Close()
+	if errno != 0 {
+		os.Remove(dst)
+		return &os.LinkError{Op: "reflink", Old: src, New: dst, Err: errno}
+	}
+	return nil
+}
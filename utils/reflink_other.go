@@ -0,0 +1,17 @@
+//go:build !linux
+// +build !linux
+
+package utils
+
+import (
+	"errors"
+	"os"
+)
+
+// reflinkSupported Whether reflinks may be created on this platform, the filesystem has the final say
+const reflinkSupported = false
+
+// CloneFile is a function that fails on platforms where reflinks aren't supported
+func CloneFile(src string, dst string) error {
+	return &os.LinkError{Op: "reflink", Old: src, New: dst, Err: errors.New("reflinks are not supported on this platform")}
+}
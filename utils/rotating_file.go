@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// RotatingFile A log file that is rotated when it grows beyond a max size
+// rotated files are named <path>.1 (newest) to <path>.<maxBackups> (oldest)
+type RotatingFile struct {
+	mutex      sync.Mutex    //Guards writes and rotation
+	path       string        //Path of the active file
+	maxSize    int64         //Size in bytes after which the file is rotated, 0 disables rotation
+	maxBackups int           //Number of rotated files to keep
+	maxAge     time.Duration //Age after which rotated files are removed, 0 keeps them regardless of age
+	file       *os.File      //Active file, nil when it couldn't be reopened after a rotation
+	size       int64         //Size of the active file
+}
+
+// OpenRotatingFile is a function that opens, or creates, a rotating file at path
+func OpenRotatingFile(path string, maxSize int64, maxBackups int, maxAge time.Duration) (*RotatingFile, error) {
+	rotatingFile := &RotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups, maxAge: maxAge}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := rotatingFile.open(); err != nil {
+		return nil, err
+	}
+
+	return rotatingFile, nil
+}
+
+// Write appends p to the active file, rotating it first if p would make it exceed the max size
+func (rotatingFile *RotatingFile) Write(p []byte) (int, error) {
+	rotatingFile.mutex.Lock()
+	defer rotatingFile.mutex.Unlock()
+
+	if rotatingFile.maxSize > 0 && rotatingFile.size > 0 && rotatingFile.size+int64(len(p)) > rotatingFile.maxSize {
+		// a rotation that failed leaves the active file open to write to, it is rotated again by the next write
+		if err := rotatingFile.rotate(); err != nil && rotatingFile.file == nil {
+			return 0, err
+		}
+	}
+	if rotatingFile.file == nil {
+		if err := rotatingFile.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	written, err := rotatingFile.file.Write(p)
+	rotatingFile.size += int64(written)
+	return written, err
+}
+
+// Close closes the active file
+func (rotatingFile *RotatingFile) Close() error {
+	rotatingFile.mutex.Lock()
+	defer rotatingFile.mutex.Unlock()
+
+	if rotatingFile.file == nil {
+		return nil
+	}
+	return rotatingFile.file.Close()
+}
+
+// open opens the active file in append mode
+func (rotatingFile *RotatingFile) open() error {
+	file, err := os.OpenFile(rotatingFile.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	rotatingFile.file, rotatingFile.size = file, fileInfo.Size()
+	return nil
+}
+
+// rotate shifts rotated files by one, moves the active file to <path>.1 and removes expired backups
+// the active file is reopened in append mode when it can't be moved, so logging continues in it
+func (rotatingFile *RotatingFile) rotate() error {
+	closeErr := rotatingFile.file.Close()
+	rotatingFile.file = nil
+
+	if rotatingFile.maxBackups <= 0 {
+		os.Remove(rotatingFile.path)
+	} else {
+		os.Remove(rotatingFile.backupPath(rotatingFile.maxBackups))
+		for idx := rotatingFile.maxBackups - 1; idx >= 1; idx-- {
+			os.Rename(rotatingFile.backupPath(idx), rotatingFile.backupPath(idx+1))
+		}
+		if err := os.Rename(rotatingFile.path, rotatingFile.backupPath(1)); err != nil {
+			if reopenErr := rotatingFile.open(); reopenErr != nil {
+				return reopenErr
+			}
+			return err
+		}
+	}
+
+	if rotatingFile.maxAge > 0 {
+		for idx := 1; idx <= rotatingFile.maxBackups; idx++ {
+			fileInfo, err := os.Stat(rotatingFile.backupPath(idx))
+			if err == nil && time.Since(fileInfo.ModTime()) > rotatingFile.maxAge {
+				os.Remove(rotatingFile.backupPath(idx))
+			}
+		}
+	}
+
+	if err := rotatingFile.open(); err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// backupPath returns the path of the idx-th rotated file
+func (rotatingFile *RotatingFile) backupPath(idx int) string {
+	return fmt.Sprintf("%s.%v", rotatingFile.path, idx)
+}
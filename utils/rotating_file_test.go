@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRotatingFileKeepsLoggingWhenRotationFails checks writes keep reaching the active file when it can't be moved aside,
+// and that it is rotated by a later write once it can
+func TestRotatingFileKeepsLoggingWhenRotationFails(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "videra.log")
+	rotatingFile, err := OpenRotatingFile(path, 10, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rotatingFile.Close()
+
+	// a non empty directory in place of the first backup can't be renamed over
+	if err = os.MkdirAll(filepath.Join(path+".1", "busy"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"first\n", "second\n"} {
+		if _, err = rotatingFile.Write([]byte(line)); err != nil {
+			t.Fatalf("write of %q failed: %v", line, err)
+		}
+	}
+	if content, _ := ioutil.ReadFile(path); string(content) != "first\nsecond\n" {
+		t.Fatalf("active file holds %q after the failed rotation", content)
+	}
+
+	if err = os.RemoveAll(path + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = rotatingFile.Write([]byte("third\n")); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := ioutil.ReadFile(path); string(content) != "third\n" {
+		t.Errorf("active file holds %q after the rotation, expected third", content)
+	}
+	if content, _ := ioutil.ReadFile(path + ".1"); string(content) != "first\nsecond\n" {
+		t.Errorf("backup holds %q, expected the lines written before the rotation", content)
+	}
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"os"
+	"syscall"
+)
+
+// ByteRange A half open range of bytes [Start, End) within a file
+type ByteRange struct {
+	Start int64 `json:"start"` //Offset of the first byte in the range
+	End   int64 `json:"end"`   //Offset right after the last byte in the range
+}
+
+// Contains checks whether the range fully covers length bytes starting at offset
+func (byteRange ByteRange) Contains(offset int64, length int64) bool {
+	return byteRange.Start <= offset && offset+length <= byteRange.End
+}
+
+// GetFileHoles is a function to get the holes of a sparse file using SEEK_DATA/SEEK_HOLE
+// returns nil without error on platforms and filesystems that can't report holes
+func GetFileHoles(filepath string) ([]ByteRange, error) {
+	if !holesSupported {
+		return nil, nil
+	}
+
+	file, err := os.Open(filepath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	fileSize, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	var holes []ByteRange
+	offset := int64(0)
+	for offset < fileSize {
+		dataStart, err := file.Seek(offset, seekData)
+		if errors.Is(err, syscall.ENXIO) {
+			// no data after offset, the rest of the file is a hole
+			holes = append(holes, ByteRange{Start: offset, End: fileSize})
+			break
+		}
+		if err != nil {
+			// filesystem doesn't support hole detection
+			return nil, nil
+		}
+		if dataStart > offset {
+			holes = append(holes, ByteRange{Start: offset, End: dataStart})
+		}
+
+		offset, err = file.Seek(dataStart, seekHole)
+		if err != nil {
+			return nil, nil
+		}
+	}
+
+	return holes, nil
+}
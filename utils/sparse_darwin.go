@@ -0,0 +1,13 @@
+//go:build darwin
+// +build darwin
+
+package utils
+
+// holesSupported Whether SEEK_DATA/SEEK_HOLE are available on this platform
+const holesSupported = true
+
+// whence values of lseek for hole detection on darwin
+const (
+	seekHole = 3
+	seekData = 4
+)
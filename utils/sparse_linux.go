@@ -0,0 +1,13 @@
+//go:build linux
+// +build linux
+
+package utils
+
+// holesSupported Whether SEEK_DATA/SEEK_HOLE are available on this platform
+const holesSupported = true
+
+// whence values of lseek for hole detection on linux
+const (
+	seekData = 3
+	seekHole = 4
+)
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package utils
+
+// holesSupported Whether SEEK_DATA/SEEK_HOLE are available on this platform
+const holesSupported = false
+
+// whence values are unused on platforms without hole detection
+const (
+	seekData = 0
+	seekHole = 0
+)
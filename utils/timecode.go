@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseTimecode is a function to parse a timecode such as 01:02:03.5, 02:03 or 3.5 into a duration
+func ParseTimecode(timecode string) (time.Duration, error) {
+	parts := strings.Split(timecode, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("Invalid timecode %s", timecode)
+	}
+
+	seconds := 0.0
+	for _, part := range parts {
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil || value < 0 {
+			return 0, fmt.Errorf("Invalid timecode %s", timecode)
+		}
+		seconds = seconds*60 + value
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// ParseTimeRange is a function to parse a range of timecodes such as 00:05:00-00:07:30
+func ParseTimeRange(timeRange string) (time.Duration, time.Duration, error) {
+	bounds := strings.Split(timeRange, "-")
+	if len(bounds) != 2 {
+		return 0, 0, fmt.Errorf("Invalid time range %s, expected start-end", timeRange)
+	}
+
+	start, err := ParseTimecode(bounds[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := ParseTimecode(bounds[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	if end <= start {
+		return 0, 0, fmt.Errorf("Invalid time range %s, end must be after start", timeRange)
+	}
+	return start, end, nil
+}
+
+// FormatTimecode is a function to format a duration as a timecode accepted by ffmpeg
+func FormatTimecode(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
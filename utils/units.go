@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sizeUnits Multiplier of each size unit, decimal units are powers of 1000 and binary ones powers of 1024
+var sizeUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"KB":  1000,
+	"MB":  1000 * 1000,
+	"GB":  1000 * 1000 * 1000,
+	"TB":  1000 * 1000 * 1000 * 1000,
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+	"TIB": 1 << 40,
+}
+
+// ParseSize is a function to parse a size such as 4MiB, 1.5GB or 4194304 into bytes
+// the decimal separator is always a dot regardless of locale, digit grouping isn't accepted
+func ParseSize(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	split := strings.IndexFunc(value, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if split == -1 {
+		split = len(value)
+	}
+	number, unit := value[:split], strings.ToUpper(strings.TrimSpace(value[split:]))
+
+	multiplier, ok := sizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit %q, expected B, KB, MB, GB, TB, KiB, MiB, GiB or TiB", value, value[split:])
+	}
+	if number == "" {
+		return 0, fmt.Errorf("invalid size %q: missing number", value)
+	}
+
+	if !strings.Contains(number, ".") {
+		count, err := strconv.ParseInt(number, 10, 64)
+		if err != nil || (count != 0 && count*multiplier/multiplier != count) {
+			return 0, fmt.Errorf("invalid size %q", value)
+		}
+		return count * multiplier, nil
+	}
+
+	count, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return int64(count * float64(multiplier)), nil
+}
+
+// ParseRate is a function to parse a transfer rate such as 20MB/s or 512KiB/s into bytes per second
+func ParseRate(value string) (int64, error) {
+	trimmed := strings.TrimSpace(value)
+	if !strings.HasSuffix(trimmed, "/s") {
+		return 0, fmt.Errorf("invalid rate %q: expected a size per second such as 20MB/s", value)
+	}
+
+	rate, err := ParseSize(strings.TrimSuffix(trimmed, "/s"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate %q: %v", value, err)
+	}
+	return rate, nil
+}
+
+// ParseDuration is a function to parse a duration such as 90s, 2h or 1h30m, also accepting days such as 7d
+func ParseDuration(value string) (time.Duration, error) {
+	trimmed := strings.TrimSpace(value)
+	if days := strings.TrimSuffix(trimmed, "d"); days != trimmed {
+		count, err := strconv.ParseFloat(days, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		return time.Duration(count * float64(24*time.Hour)), nil
+	}
+
+	duration, err := time.ParseDuration(trimmed)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: expected a number with a unit such as 90s, 2h or 7d", value)
+	}
+	return duration, nil
+}
+
+// FormatSize is a function to format a size in bytes with the largest binary unit it is a multiple of, e.g. 4MiB
+func FormatSize(size int64) string {
+	for _, unit := range []string{"TiB", "GiB", "MiB", "KiB"} {
+		multiplier := sizeUnits[strings.ToUpper(unit)]
+		if size != 0 && size%multiplier == 0 {
+			return fmt.Sprintf("%v%s", size/multiplier, unit)
+		}
+	}
+	return strconv.FormatInt(size, 10)
+}
+
+// Size A size in bytes parsed from human friendly values, usable as a flag and as a config value
+type Size int64
+
+// String formats the size, see FormatSize
+func (size *Size) String() string {
+	return FormatSize(int64(*size))
+}
+
+// Set parses a flag value into the size, see ParseSize
+func (size *Size) Set(value string) error {
+	parsed, err := ParseSize(value)
+	if err != nil {
+		return err
+	}
+	*size = Size(parsed)
+	return nil
+}
+
+// UnmarshalYAML parses a config value into the size, plain numbers are bytes
+func (size *Size) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	return size.Set(value)
+}
+
+// Rate A transfer rate in bytes per second parsed from values such as 20MB/s, usable as a flag and as a config value
+type Rate int64
+
+// String formats the rate
+func (rate *Rate) String() string {
+	return FormatSize(int64(*rate)) + "/s"
+}
+
+// Set parses a flag value into the rate, see ParseRate
+func (rate *Rate) Set(value string) error {
+	parsed, err := ParseRate(value)
+	if err != nil {
+		return err
+	}
+	*rate = Rate(parsed)
+	return nil
+}
+
+// UnmarshalYAML parses a config value into the rate
+func (rate *Rate) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	return rate.Set(value)
+}
+
+// Duration A duration parsed from values such as 90s, 2h or 7d, usable as a flag and as a config value
+type Duration time.Duration
+
+// String formats the duration
+func (duration *Duration) String() string {
+	return time.Duration(*duration).String()
+}
+
+// Set parses a flag value into the duration, see ParseDuration
+func (duration *Duration) Set(value string) error {
+	parsed, err := ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*duration = Duration(parsed)
+	return nil
+}
+
+// UnmarshalYAML parses a config value into the duration
+func (duration *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	return duration.Set(value)
+}
@@ -1,12 +1,18 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
-	"github.com/hashicorp/go-retryablehttp"
+	"github.com/SayedAlesawy/Videra-SDK/httpx"
 )
 
 // ValidateFlags validates if are flags exist
@@ -21,12 +27,25 @@ func ValidateFlags(args ...string) error {
 
 // NewClient is a function that returns customized http client
 func NewClient(maxRetries int, waitingTime int) *http.Client {
-	clientretry := retryablehttp.NewClient()
-	clientretry.RetryMax = maxRetries
-	clientretry.RetryWaitMin = time.Duration(time.Duration(waitingTime) * time.Second)
-	clientretry.RetryWaitMax = time.Duration(time.Duration(waitingTime) * time.Second)
+	return NewClientWithRetryHook(maxRetries, waitingTime, nil)
+}
+
+// NewClientWithRetryHook is a function that returns customized http client reporting retries to onRetry
+// onRetry receives the number of the upcoming attempt, the failure causing it and the delay before it
+func NewClientWithRetryHook(maxRetries int, waitingTime int, onRetry func(int, error, time.Duration)) *http.Client {
+	// every client shares one pool so connections to data nodes are kept alive across requests and uploads
+	return httpx.NewClient(httpx.Policy{
+		MaxRetries:  maxRetries,
+		WaitingTime: time.Duration(waitingTime) * time.Second,
+		OnRetry:     onRetry,
+		Logger:      retryLogger{},
+	}, poolTransport{})
+}
 
-	return clientretry.StandardClient()
+// NewRewindableRequest is a function that returns a request whose body can be re-created for every retry attempt
+// Deprecated: use httpx.NewRewindableRequest
+func NewRewindableRequest(method string, url string, body []byte) (*http.Request, error) {
+	return httpx.NewRewindableRequest(method, url, body)
 }
 
 // GetFileSize is a function to get file size
@@ -63,3 +82,93 @@ func GetFileFromOffset(filesSizes []int64, offset int64) (int, int64, error) {
 	}
 	return idx, readOffset, nil
 }
+
+// GetFileChecksum is a function to get the hex encoded sha256 digest of a file
+func GetFileChecksum(filepath string) (string, error) {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// NormalizeFilename is a function to get the base name of a path, windows paths give the same file name on every platform
+// on windows both / and \ separate directories and the volume is dropped, elsewhere \ and : are legal in file names so only
+// paths starting with a drive letter and separator, e.g. C:\videos\clip.mp4, or a UNC prefix, e.g. \\server\share, are read as windows paths
+func NormalizeFilename(filePath string) string {
+	return normalizeFilename(filePath, filepath.Separator == '\\')
+}
+
+// normalizeFilename is a function to get the base name of a path, read as a windows path when onWindows is set or when it is one
+func normalizeFilename(filePath string, onWindows bool) string {
+	name := filePath
+	if onWindows || windowsPath(filePath) {
+		name = strings.ReplaceAll(name, "\\", "/")
+		if strings.HasPrefix(name, "//") {
+			// \\server\share and \\?\C: prefixes name no file, whatever follows them does
+			parts := strings.SplitN(strings.TrimPrefix(name, "//"), "/", 3)
+			if len(parts) < 3 {
+				return ""
+			}
+			name = "/" + parts[2]
+		} else if len(name) >= 2 && name[1] == ':' && isDriveLetter(name[0]) {
+			name = name[2:]
+		}
+	}
+	name = strings.TrimRight(name, "/")
+
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+// windowsPath is a function to check whether a path can only be a windows path, i.e. it starts with a drive letter and a separator or \\
+func windowsPath(filePath string) bool {
+	if strings.HasPrefix(filePath, `\\`) {
+		return true
+	}
+	return len(filePath) >= 3 && isDriveLetter(filePath[0]) && filePath[1] == ':' && (filePath[2] == '\\' || filePath[2] == '/')
+}
+
+// isDriveLetter is a function to check whether a byte is a letter windows names drives with
+func isDriveLetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// WriteFileAtomic is a function to replace a file with new content without leaving it half written
+// the content is written and synced to a temporary file in the same directory which is then renamed over the file,
+// the directory is synced after the rename so the new content is what a crash leaves behind
+func WriteFileAtomic(filePath string, content []byte) error {
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	tempFile, err := ioutil.TempFile(dir, filepath.Base(filePath)+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err = tempFile.Write(content); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err = tempFile.Sync(); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err = tempFile.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(tempFile.Name(), filePath); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
@@ -0,0 +1,44 @@
+package utils
+
+import "testing"
+
+func TestNormalizeFilename(t *testing.T) {
+	tests := []struct {
+		path      string
+		onWindows bool
+		name      string
+	}{
+		// posix paths
+		{"clip.mp4", false, "clip.mp4"},
+		{"/videos/cam1/clip.mp4", false, "clip.mp4"},
+		{"videos/cam1/", false, "cam1"},
+		{`a\b.mp4`, false, `a\b.mp4`},
+		{`videos/a\b.mp4`, false, `a\b.mp4`},
+		{"c:clip.mp4", false, "c:clip.mp4"},
+		{"/videos/c:clip.mp4", false, "c:clip.mp4"},
+		{"1:clip.mp4", false, "1:clip.mp4"},
+
+		// windows paths read the same on every platform
+		{`C:\videos\clip.mp4`, false, "clip.mp4"},
+		{`C:\videos\clip.mp4`, true, "clip.mp4"},
+		{`d:/videos/clip.mp4`, false, "clip.mp4"},
+		{`C:\videos\cam1\`, false, "cam1"},
+		{`C:\`, false, ""},
+		{`\\server\share\videos\clip.mp4`, false, "clip.mp4"},
+		{`\\server\share\clip.mp4`, true, "clip.mp4"},
+		{`\\server\share`, false, ""},
+		{`\\?\C:\videos\clip.mp4`, false, "clip.mp4"},
+		{`\\?\UNC\server\share\clip.mp4`, true, "clip.mp4"},
+
+		// windows only reads
+		{`videos\clip.mp4`, true, "clip.mp4"},
+		{"c:clip.mp4", true, "clip.mp4"},
+		{"videos/clip.mp4", true, "clip.mp4"},
+	}
+
+	for _, test := range tests {
+		if name := normalizeFilename(test.path, test.onWindows); name != test.name {
+			t.Errorf("normalizeFilename(%q, windows %v) = %q, expected %q", test.path, test.onWindows, name, test.name)
+		}
+	}
+}
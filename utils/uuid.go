@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+)
+
+// NewUUIDv7 is a function that returns a random UUID version 7, its leading 48 bits hold the Unix time in milliseconds
+// so IDs sort by creation time, which makes them convenient to trace across logs
+func NewUUIDv7() (string, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[6:]); err != nil {
+		return "", err
+	}
+
+	var millis [8]byte
+	binary.BigEndian.PutUint64(millis[:], uint64(time.Now().UnixNano()/int64(time.Millisecond)))
+	copy(uuid[:6], millis[2:])
+	uuid[6] = uuid[6]&0x0f | 0x70
+	uuid[8] = uuid[8]&0x3f | 0x80
+
+	encoded := hex.EncodeToString(uuid[:])
+	return encoded[:8] + "-" + encoded[8:12] + "-" + encoded[12:16] + "-" + encoded[16:20] + "-" + encoded[20:], nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+)
+
+// verifyCommand is a function responsible for checking that a stored upload matches a local file
+// mismatching byte ranges are printed and reported as an error
+func verifyCommand(args []string) error {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	file := flags.String("file", "", "Path or URL of the local source the upload was made from")
+	output := flags.String("output", "human", "Format of the printed report, human or json")
+	flags.Usage = func() {
+		log.Println("Usage: verify <upload id> -file <local file> [-output human|json]")
+	}
+
+	// the ID comes first on the command line, flags follow it
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		flags.Usage()
+		return errors.New("Missing upload ID")
+	}
+	parseFlags(flags, args[1:])
+	if *file == "" {
+		flags.Usage()
+		return errors.New("Missing local file")
+	}
+	if err := validateOutputFormat(*output); err != nil {
+		return err
+	}
+
+	report, err := viderasdk.SDKInstance().Verify(args[0], *file)
+	if err != nil {
+		return err
+	}
+
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("Upload %s compared by %s\n", report.ID, report.Method)
+		fmt.Printf("  Size:     stored %v, local %v\n", report.Size, report.LocalSize)
+		if report.Checksum != "" {
+			fmt.Printf("  Checksum: stored %s\n", report.Checksum)
+		}
+		fmt.Printf("            local  %s\n", report.LocalChecksum)
+		for _, mismatch := range report.Mismatches {
+			fmt.Printf("  Mismatch: bytes %v-%v\n", mismatch.Start, mismatch.End-1)
+		}
+	}
+
+	if !report.Match() {
+		return errors.New("Stored upload differs from the local file")
+	}
+	log.Println("Stored upload matches the local file")
+	return nil
+}
@@ -0,0 +1,163 @@
+package videratest
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Request Describes a request the fake cluster received
+type Request struct {
+	Node     string      `json:"node"`               //master or data node
+	Method   string      `json:"method"`             //HTTP method of the request
+	Type     string      `json:"type"`               //Request-Type header, e.g. init or APPEND, empty for master requests
+	Session  string      `json:"session,omitempty"`  //ID of the session the request belongs to
+	Offset   int64       `json:"offset"`             //Offset header of chunks
+	Length   int64       `json:"length"`             //Bytes of the body as sent, i.e. compressed when Content-Encoding is set
+	Artifact string      `json:"artifact,omitempty"` //Artifact header of chunks of parallel sessions
+	Status   int         `json:"status"`             //Status code the fake cluster answered with
+	Header   http.Header `json:"header"`             //Every header of the request
+	Body     string      `json:"body,omitempty"`     //Body of requests other than chunks, e.g. the JSON init body
+	Time     time.Time   `json:"time"`               //Time at which the request was received
+}
+
+// session State of a session on the fake data node, chunks are counted and discarded
+type session struct {
+	size     int64 //Filesize announced by the init request
+	received int64 //Bytes appended so far
+}
+
+// Server A fake master and data node running in process, it accepts every upload, discards the data and records each request
+// meant for exercising the client without a cluster, e.g. in tests or simulated uploads
+type Server struct {
+	MaxRequestSize  int64 //Max-Request-Size pushed to init requests when positive
+	ProtocolVersion int   //Protocol-Version answered to init requests when positive, 2 accepts JSON init bodies
+
+	master   *httptest.Server
+	dataNode *httptest.Server
+	mutex    sync.Mutex          //Guards the fields below
+	sessions map[string]*session //Sessions keyed by ID
+	requests []Request           //Requests received in order
+	nextID   int                 //Number of sessions created so far
+}
+
+// NewServer is a function that starts a fake cluster, Close stops it
+func NewServer() *Server {
+	server := &Server{sessions: make(map[string]*session)}
+	server.dataNode = httptest.NewServer(http.HandlerFunc(server.serveDataNode))
+	server.master = httptest.NewServer(http.HandlerFunc(server.serveMaster))
+	return server
+}
+
+// URL is a function to get the upload endpoint of the fake master, the name_node_endpoint of SDKs using the fake cluster
+func (server *Server) URL() string {
+	return server.master.URL + "/upload"
+}
+
+// Requests is a function to get the requests received so far in order
+func (server *Server) Requests() []Request {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return append([]Request(nil), server.requests...)
+}
+
+// Close stops the fake master and data node
+func (server *Server) Close() {
+	server.master.Close()
+	server.dataNode.Close()
+}
+
+// serveMaster answers every request with the URL of the fake data node
+func (server *Server) serveMaster(w http.ResponseWriter, r *http.Request) {
+	server.record(Request{Node: "master", Status: http.StatusOK}, r, 0)
+	io.WriteString(w, server.dataNode.URL+"/data")
+}
+
+// serveDataNode creates sessions and accepts chunks appended at the offset the session reached
+func (server *Server) serveDataNode(w http.ResponseWriter, r *http.Request) {
+	request := Request{Node: "data node", Session: r.Header.Get("ID"), Artifact: r.Header.Get("Artifact")}
+	var length int64
+	if r.Header.Get("Request-Type") == "APPEND" {
+		length, _ = io.Copy(ioutil.Discard, r.Body)
+	} else {
+		body, _ := ioutil.ReadAll(r.Body)
+		request.Body, length = string(body), int64(len(body))
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	switch r.Header.Get("Request-Type") {
+	case "init":
+		size, _ := strconv.ParseInt(r.Header.Get("Filesize"), 10, 64)
+		server.nextID++
+		request.Session = strconv.Itoa(server.nextID)
+		server.sessions[request.Session] = &session{size: size}
+		w.Header().Set("ID", request.Session)
+		if server.ProtocolVersion > 0 {
+			w.Header().Set("Protocol-Version", strconv.Itoa(server.ProtocolVersion))
+		}
+		if server.MaxRequestSize > 0 {
+			w.Header().Set("Max-Request-Size", strconv.FormatInt(server.MaxRequestSize, 10))
+		}
+		request.Status = http.StatusCreated
+	case "APPEND":
+		request.Offset, _ = strconv.ParseInt(r.Header.Get("Offset"), 10, 64)
+		request.Status = server.appendChunk(w, r, request.Offset, length)
+	case "ABORT":
+		delete(server.sessions, request.Session)
+		request.Status = http.StatusOK
+	default:
+		// queries such as QUERY-CHUNKS find nothing as no data is stored
+		request.Status = http.StatusOK
+	}
+
+	server.recordLocked(request, r, length)
+	w.WriteHeader(request.Status)
+}
+
+// appendChunk is a function responsible for counting a chunk towards its session, it returns the status to answer with
+func (server *Server) appendChunk(w http.ResponseWriter, r *http.Request, offset int64, length int64) int {
+	session := server.sessions[r.Header.Get("ID")]
+	if session == nil {
+		return http.StatusNotFound
+	}
+	if offset != session.received {
+		w.Header().Set("Offset", strconv.FormatInt(session.received, 10))
+		return http.StatusBadRequest
+	}
+
+	// deduplicated, compressed and zero filled chunks carry their raw size in headers
+	size := length
+	if chunkSize := r.Header.Get("Chunk-Size"); chunkSize != "" {
+		size, _ = strconv.ParseInt(chunkSize, 10, 64)
+	} else if zeroFill := r.Header.Get("Zero-Fill"); zeroFill != "" {
+		size, _ = strconv.ParseInt(zeroFill, 10, 64)
+	}
+	session.received += size
+
+	if session.received >= session.size {
+		return http.StatusCreated
+	}
+	return http.StatusOK
+}
+
+// record is a function responsible for recording a request
+func (server *Server) record(request Request, r *http.Request, length int64) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.recordLocked(request, r, length)
+}
+
+// recordLocked is a function responsible for recording a request while the mutex is held
+func (server *Server) recordLocked(request Request, r *http.Request, length int64) {
+	request.Method, request.Header, request.Length, request.Time = r.Method, r.Header.Clone(), length, time.Now().UTC()
+	request.Type = r.Header.Get("Request-Type")
+	server.requests = append(server.requests, request)
+}
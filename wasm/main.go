@@ -0,0 +1,359 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+// the module is built for browsers into build/ by GOOS=js GOARCH=wasm go generate ./wasm, pages load it with wasm_exec.js of the
+// Go distribution, see https://go.dev/wiki/WebAssembly. Data nodes and masters must allow the origin of the page with CORS
+// and expose the headers the protocol reads, e.g. Access-Control-Expose-Headers: ID, Offset, Max-Request-Size, Max-File-Size
+// browsers have no state directory, state files only log that they can't be written, so configs leave catalog and usage_accounting off
+//go:generate go build -o ../build/videra.wasm .
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sync"
+	"syscall/js"
+	"time"
+
+	"github.com/SayedAlesawy/Videra-SDK/config"
+	viderasdk "github.com/SayedAlesawy/Videra-SDK/sdk"
+)
+
+// ErrNotInitialized Returned when an upload is started before videra.init
+var ErrNotInitialized = errors.New("videra.init wasn't called")
+
+// sdkMutex Guards sdk
+var sdkMutex sync.Mutex
+
+// sdk Instance of the SDK, nil until videra.init
+var sdk *viderasdk.VideraSDK
+
+// files Blobs added by the page that uploads read, by name
+var files = &blobFS{blobs: make(map[string]js.Value)}
+
+// main is a function responsible for exposing the JS API as globalThis.videra, it never returns so the callbacks stay valid
+//
+//	await videra.init(yamlConfig)                     reads the content of an sdk_config.yaml, once before any upload
+//	videra.addFile(name, blob)                        adds a Blob or File uploads refer to by name, e.g. from an <input type=file>
+//	videra.onProgress((name, done, total) => {})      called as the chunks of an upload complete
+//	videra.onWarning((kind, message) => {})           called with the non-fatal anomalies of uploads
+//	await videra.uploadVideo(name, modelID)           sends the init and append requests of a video, resolves with its result
+//	await videra.uploadModel(model, config, code)     same for a model with its config and code
+func main() {
+	js.Global().Set("videra", js.ValueOf(map[string]interface{}{
+		"init":        js.FuncOf(initSDK),
+		"addFile":     js.FuncOf(addFile),
+		"onProgress":  js.FuncOf(onProgress),
+		"onWarning":   js.FuncOf(onWarning),
+		"uploadVideo": js.FuncOf(uploadVideo),
+		"uploadModel": js.FuncOf(uploadModel),
+	}))
+	select {}
+}
+
+// initSDK is a function responsible for starting the SDK with the config given as YAML, uploads read files added with addFile
+func initSDK(this js.Value, args []js.Value) interface{} {
+	return promise(func() (interface{}, error) {
+		if len(args) != 1 || args[0].Type() != js.TypeString {
+			return nil, errors.New("Expected the content of sdk_config.yaml")
+		}
+
+		sdkMutex.Lock()
+		defer sdkMutex.Unlock()
+		if sdk != nil {
+			return nil, errors.New("videra.init was already called")
+		}
+		var configObj config.SDKConfig
+		if err := config.RetrieveFromBytes([]byte(args[0].String()), &configObj); err != nil {
+			return nil, err
+		}
+		config.SDKConfigFile.Set(configObj)
+		sdk = viderasdk.SDKInstance()
+		sdk.SetFileSystem(files)
+		return js.Undefined(), nil
+	})
+}
+
+// addFile is a function responsible for adding a blob uploads refer to by name, adding a name again replaces its blob
+func addFile(this js.Value, args []js.Value) interface{} {
+	if len(args) != 2 || args[0].Type() != js.TypeString || !args[1].InstanceOf(js.Global().Get("Blob")) {
+		return jsError(errors.New("Expected a name and a Blob"))
+	}
+	if !fs.ValidPath(args[0].String()) {
+		return jsError(fmt.Errorf("Invalid file name %s", args[0].String()))
+	}
+
+	files.add(args[0].String(), args[1])
+	return js.Undefined()
+}
+
+// onProgress is a function responsible for setting the callback receiving the progress of uploads, null removes it
+func onProgress(this js.Value, args []js.Value) interface{} {
+	return withSDK(func(sdk *viderasdk.VideraSDK) {
+		options := sdk.ClientOptions()
+		options.OnProgress = nil
+		if len(args) == 1 && args[0].Type() == js.TypeFunction {
+			callback := args[0]
+			options.OnProgress = func(name string, done int64, total int64) {
+				callback.Invoke(name, done, total)
+			}
+		}
+		sdk.SetClientOptions(options)
+	})
+}
+
+// onWarning is a function responsible for setting the callback receiving the warnings of uploads, null removes it
+func onWarning(this js.Value, args []js.Value) interface{} {
+	return withSDK(func(sdk *viderasdk.VideraSDK) {
+		options := sdk.ClientOptions()
+		options.OnWarning = nil
+		if len(args) == 1 && args[0].Type() == js.TypeFunction {
+			callback := args[0]
+			options.OnWarning = func(warning viderasdk.Warning) {
+				callback.Invoke(warning.Kind, warning.Message)
+			}
+		}
+		sdk.SetClientOptions(options)
+	})
+}
+
+// uploadVideo is a function responsible for uploading the video added by name for the model with the given ID
+func uploadVideo(this js.Value, args []js.Value) interface{} {
+	return promise(func() (interface{}, error) {
+		names, err := stringArgs(args, "video", "model ID")
+		if err != nil {
+			return nil, err
+		}
+		sdk, err := currentSDK()
+		if err != nil {
+			return nil, err
+		}
+		result, err := sdk.UploadVideo(names[0], names[1])
+		return jsResult(result), err
+	})
+}
+
+// uploadModel is a function responsible for uploading the model, config and code added by name
+func uploadModel(this js.Value, args []js.Value) interface{} {
+	return promise(func() (interface{}, error) {
+		names, err := stringArgs(args, "model", "config", "code")
+		if err != nil {
+			return nil, err
+		}
+		sdk, err := currentSDK()
+		if err != nil {
+			return nil, err
+		}
+		result, err := sdk.UploadModel(names[0], names[1], names[2])
+		return jsResult(result), err
+	})
+}
+
+// currentSDK is a function that returns the SDK started by videra.init
+func currentSDK() (*viderasdk.VideraSDK, error) {
+	sdkMutex.Lock()
+	defer sdkMutex.Unlock()
+	if sdk == nil {
+		return nil, ErrNotInitialized
+	}
+	return sdk, nil
+}
+
+// withSDK is a function to run update on the SDK started by videra.init, returning an Error to JS when it wasn't
+func withSDK(update func(sdk *viderasdk.VideraSDK)) interface{} {
+	sdk, err := currentSDK()
+	if err != nil {
+		return jsError(err)
+	}
+	update(sdk)
+	return js.Undefined()
+}
+
+// stringArgs is a function to check the arguments of a call are the named strings
+func stringArgs(args []js.Value, names ...string) ([]string, error) {
+	if len(args) != len(names) {
+		return nil, fmt.Errorf("Expected %v arguments, got %v", len(names), len(args))
+	}
+
+	values := make([]string, len(args))
+	for i, arg := range args {
+		if arg.Type() != js.TypeString {
+			return nil, fmt.Errorf("Expected %s to be a string", names[i])
+		}
+		values[i] = arg.String()
+	}
+	return values, nil
+}
+
+// jsResult is a function to convert the result of an upload to a JS object
+func jsResult(result viderasdk.UploadResult) interface{} {
+	return map[string]interface{}{
+		"id":             result.ID,
+		"bytesSent":      result.BytesSent,
+		"durationMillis": result.Duration.Milliseconds(),
+		"dataNode":       result.DataNode,
+		"checksum":       result.Checksum,
+		"retries":        result.Retries,
+	}
+}
+
+// jsError is a function to convert an error to a JS Error
+func jsError(err error) js.Value {
+	return js.Global().Get("Error").New(err.Error())
+}
+
+// promise is a function that returns a JS Promise settled by run, which runs in its own goroutine
+// as it may block on fetch and blob reads, that can't be awaited on the goroutine JS called into
+func promise(run func() (interface{}, error)) js.Value {
+	executor := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		resolve, reject := args[0], args[1]
+		go func() {
+			value, err := run()
+			if err != nil {
+				reject.Invoke(jsError(err))
+				return
+			}
+			resolve.Invoke(value)
+		}()
+		return nil
+	})
+	defer executor.Release()
+	return js.Global().Get("Promise").New(executor)
+}
+
+// await is a function to block until a JS Promise settles, returning its value or its rejection as an error
+func await(promise js.Value) (js.Value, error) {
+	values := make(chan js.Value, 1)
+	failures := make(chan js.Value, 1)
+	onResolve := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		values <- args[0]
+		return nil
+	})
+	defer onResolve.Release()
+	onReject := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		failures <- args[0]
+		return nil
+	})
+	defer onReject.Release()
+
+	promise.Call("then", onResolve, onReject)
+	select {
+	case value := <-values:
+		return value, nil
+	case failure := <-failures:
+		return js.Undefined(), fmt.Errorf("%s", failure.Call("toString").String())
+	}
+}
+
+// blobFS An fs.FS of the blobs added by the page, so uploads read them into chunks like local files without os.File
+type blobFS struct {
+	mutex sync.Mutex          //Guards blobs
+	blobs map[string]js.Value //Blobs by name
+}
+
+// add is a function responsible for adding a blob by name
+func (fileSystem *blobFS) add(name string, blob js.Value) {
+	fileSystem.mutex.Lock()
+	defer fileSystem.mutex.Unlock()
+	fileSystem.blobs[name] = blob
+}
+
+// Open opens the blob added by name
+func (fileSystem *blobFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	fileSystem.mutex.Lock()
+	blob, ok := fileSystem.blobs[name]
+	fileSystem.mutex.Unlock()
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	info := blobInfo{name: name, size: int64(blob.Get("size").Int())}
+	// plain Blobs have no modification time, Files do
+	if lastModified := blob.Get("lastModified"); lastModified.Type() == js.TypeNumber {
+		info.modTime = time.UnixMilli(int64(lastModified.Float()))
+	}
+	return &blobFile{blob: blob, info: info}, nil
+}
+
+// blobFile An open blob, read by slicing it with Blob.arrayBuffer
+type blobFile struct {
+	blob   js.Value //Blob or File read
+	info   blobInfo //Name, size and modification time of the blob
+	offset int64    //Offset the next read starts at
+}
+
+// Read reads the next bytes of the blob, blocking until the browser returns them
+func (file *blobFile) Read(buffer []byte) (int, error) {
+	if file.offset >= file.info.size {
+		return 0, io.EOF
+	}
+
+	end := file.offset + int64(len(buffer))
+	if end > file.info.size {
+		end = file.info.size
+	}
+	arrayBuffer, err := await(file.blob.Call("slice", file.offset, end).Call("arrayBuffer"))
+	if err != nil {
+		return 0, fmt.Errorf("Unable to read %s: %v", file.info.name, err)
+	}
+	read := js.CopyBytesToGo(buffer, js.Global().Get("Uint8Array").New(arrayBuffer))
+	file.offset += int64(read)
+	return read, nil
+}
+
+// Seek sets the offset the next read starts at
+func (file *blobFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += file.offset
+	case io.SeekEnd:
+		offset += file.info.size
+	}
+	if offset < 0 {
+		return file.offset, &fs.PathError{Op: "seek", Path: file.info.name, Err: fs.ErrInvalid}
+	}
+	file.offset = offset
+	return offset, nil
+}
+
+// Stat returns the file info of the blob
+func (file *blobFile) Stat() (fs.FileInfo, error) {
+	return file.info, nil
+}
+
+// Close releases nothing, blobs are kept until the page drops them
+func (file *blobFile) Close() error {
+	return nil
+}
+
+// blobInfo The fs.FileInfo of a blob
+type blobInfo struct {
+	name    string    //Name the blob was added with
+	size    int64     //Size of the blob in bytes
+	modTime time.Time //lastModified of Files, zero for Blobs
+}
+
+// Name returns the name the blob was added with
+func (info blobInfo) Name() string { return info.name }
+
+// Size returns the size of the blob in bytes
+func (info blobInfo) Size() int64 { return info.size }
+
+// Mode returns a read-only regular file mode
+func (info blobInfo) Mode() fs.FileMode { return 0444 }
+
+// ModTime returns the last modification time of the file, zero for blobs
+func (info blobInfo) ModTime() time.Time { return info.modTime }
+
+// IsDir returns false, blobs are files
+func (info blobInfo) IsDir() bool { return false }
+
+// Sys returns nil
+func (info blobInfo) Sys() interface{} { return nil }